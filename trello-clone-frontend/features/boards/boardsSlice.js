@@ -1,6 +1,11 @@
 import { createSlice, createAsyncThunk } from '@reduxjs/toolkit';
 import { apiClient } from '../../lib/apiClient'; // Import apiClient (named export)
 
+// Lists and cards are ordered by their position, a rank string compared character
+// by character; the ID breaks the rare tie.
+export const byPosition = (a, b) =>
+  a.position < b.position ? -1 : a.position > b.position ? 1 : a.id - b.id;
+
 // --- Thunks for Board List ---
 export const fetchUserBoards = createAsyncThunk(
   'boards/fetchUserBoards',
@@ -312,7 +317,7 @@ const boardsSlice = createSlice({
         if (!state.currentBoard.lists) state.currentBoard.lists = [];
         if (!state.currentBoard.lists.find(l => l.id === newList.id)) {
           state.currentBoard.lists.push({ ...newList, cards: newList.cards || [] });
-          state.currentBoard.lists.sort((a, b) => a.position - b.position);
+          state.currentBoard.lists.sort(byPosition);
         }
       }
     },
@@ -323,7 +328,7 @@ const boardsSlice = createSlice({
         if (listIndex !== -1) {
           const existingCards = state.currentBoard.lists[listIndex].cards;
           state.currentBoard.lists[listIndex] = { ...state.currentBoard.lists[listIndex], ...updatedList, cards: existingCards || [] };
-          state.currentBoard.lists.sort((a, b) => a.position - b.position);
+          state.currentBoard.lists.sort(byPosition);
         }
       }
     },
@@ -341,7 +346,7 @@ const boardsSlice = createSlice({
           if (!list.cards) list.cards = [];
           if (!list.cards.find(c => c.id === newCard.id)) {
             list.cards.push(newCard);
-            list.cards.sort((a, b) => a.position - b.position);
+            list.cards.sort(byPosition);
           }
         }
       }
@@ -356,7 +361,7 @@ const boardsSlice = createSlice({
             list.cards[cardIndex] = { ...list.cards[cardIndex], ...updatedCard };
           } else {
             list.cards.push(updatedCard); // If card moved list and updated
-            list.cards.sort((a, b) => a.position - b.position);
+            list.cards.sort(byPosition);
           }
         }
       }
@@ -385,7 +390,7 @@ const boardsSlice = createSlice({
             const cardIndex = newList.cards.findIndex(c => c.id === cardId);
             newList.cards[cardIndex] = updatedCard;
           }
-          newList.cards.sort((a, b) => a.position - b.position);
+          newList.cards.sort(byPosition);
         }
       }
     },
@@ -435,12 +440,12 @@ const boardsSlice = createSlice({
         if (boardData.lists) {
             boardData.lists.forEach(list => {
                 if (list.cards) {
-                    list.cards.sort((a, b) => a.position - b.position);
+                    list.cards.sort(byPosition);
                 } else {
                     list.cards = [];
                 }
             });
-            boardData.lists.sort((a,b) => a.position - b.position);
+            boardData.lists.sort(byPosition);
         } else {
             boardData.lists = [];
         }
@@ -475,7 +480,7 @@ const boardsSlice = createSlice({
           if (listIndex !== -1) {
             const existingCards = state.currentBoard.lists[listIndex].cards;
             state.currentBoard.lists[listIndex] = { ...updatedList, cards: existingCards || [] };
-            state.currentBoard.lists.sort((a, b) => a.position - b.position);
+            state.currentBoard.lists.sort(byPosition);
           }
         }
       })
@@ -556,7 +561,7 @@ const boardsSlice = createSlice({
             const targetList = state.currentBoard.lists.find(l => l.id === movedCard.listID);
             if (targetList) {
                 targetList.cards.push(movedCard);
-                targetList.cards.sort((a, b) => a.position - b.position);
+                targetList.cards.sort(byPosition);
             }
         }
       })
@@ -577,7 +582,7 @@ const boardsSlice = createSlice({
                 } else {
                      state.currentBoard.lists[listIndex].cards.push(updatedCard);
                 }
-                state.currentBoard.lists[listIndex].cards.sort((a, b) => a.position - b.position);
+                state.currentBoard.lists[listIndex].cards.sort(byPosition);
             }
         }
       })
@@ -716,7 +721,7 @@ const webSocketReducers = {
       // Check if list already exists to prevent duplicates from optimistic + WS update
       if (!state.currentBoard.lists.find(l => l.id === newList.id)) {
         state.currentBoard.lists.push({ ...newList, cards: newList.cards || [] });
-        state.currentBoard.lists.sort((a, b) => a.position - b.position);
+        state.currentBoard.lists.sort(byPosition);
       }
     }
   },
@@ -727,7 +732,7 @@ const webSocketReducers = {
       if (listIndex !== -1) {
         const existingCards = state.currentBoard.lists[listIndex].cards;
         state.currentBoard.lists[listIndex] = { ...state.currentBoard.lists[listIndex], ...updatedList, cards: existingCards };
-        state.currentBoard.lists.sort((a, b) => a.position - b.position);
+        state.currentBoard.lists.sort(byPosition);
       }
     }
   },
@@ -746,7 +751,7 @@ const webSocketReducers = {
          // Check if card already exists
         if (!list.cards.find(c => c.id === newCard.id)) {
           list.cards.push(newCard);
-          list.cards.sort((a, b) => a.position - b.position);
+          list.cards.sort(byPosition);
         }
       }
     }
@@ -761,7 +766,7 @@ const webSocketReducers = {
           list.cards[cardIndex] = { ...list.cards[cardIndex], ...updatedCard };
         } else { // Card might have moved to this list and updated simultaneously
           list.cards.push(updatedCard);
-          list.cards.sort((a, b) => a.position - b.position);
+          list.cards.sort(byPosition);
         }
       }
     }
@@ -782,7 +787,7 @@ const webSocketReducers = {
       const oldList = state.currentBoard.lists.find(l => l.id === oldListId);
       if (oldList) {
         oldList.cards = oldList.cards.filter(c => c.id !== cardId);
-        // oldList.cards.sort((a,b) => a.position - b.position); // Re-sort not strictly needed after removal unless positions are dense
+        // oldList.cards.sort(byPosition); // Re-sort not strictly needed after removal unless positions are dense
       }
       // Add to new list
       const newList = state.currentBoard.lists.find(l => l.id === newListId);
@@ -793,7 +798,7 @@ const webSocketReducers = {
             const cardIndex = newList.cards.findIndex(c => c.id === cardId);
             newList.cards[cardIndex] = updatedCard;
         }
-        newList.cards.sort((a, b) => a.position - b.position);
+        newList.cards.sort(byPosition);
       }
        // Ensure positions are correctly updated for remaining cards in oldList
       if (oldList && oldListId !== newListId) {
@@ -239,12 +239,8 @@ export default function BoardPage() {
       const newIndex = lists.findIndex(list => list.id === parseInt(over.id.replace('list-', '')));
 
       if (oldIndex !== newIndex) {
-        const newListOrder = arrayMoveImmutable(lists, oldIndex, newIndex);
-        newListOrder.forEach((list, index) => {
-          if (list.position !== index + 1) {
-            dispatch(updateList({ listId: list.id, listData: { position: index + 1 } }));
-          }
-        });
+        // Only the moved list changes; the others keep their positions
+        dispatch(updateList({ listId: lists[oldIndex].id, listData: { position: newIndex + 1 } }));
       }
     }
 
@@ -287,7 +283,7 @@ export default function BoardPage() {
         // Moving between different lists
         const newSourceCards = activeList.cards.filter(card => card.id !== activeCardId);
         const newDestinationCards = [...overList.cards];
-        newDestinationCards.splice(newPosition - 1, 0, { ...activeCard, listID: destinationListId, position: newPosition });
+        newDestinationCards.splice(newPosition - 1, 0, { ...activeCard, listID: destinationListId }); // The server assigns its new position
 
         dispatch(optimisticallyMoveCardBetweenLists({
           sourceListId: sourceListId,
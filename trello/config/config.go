@@ -1,11 +1,19 @@
 package config
 
 import (
+	"fmt"
+	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// DefaultJWTSecretKey is the fallback JWT secret, only accepted when DEV_MODE is on
+const DefaultJWTSecretKey = "defaultsecret"
+
 // Config struct holds all configuration for the application
 type Config struct {
 	DBHost       string
@@ -18,6 +26,76 @@ type Config struct {
 	JWTSecretKey string
 	ServerPort   string
 
+	AccessTokenTTL  time.Duration // Lifetime of JWT access tokens
+	RefreshTokenTTL time.Duration // Lifetime of refresh tokens
+
+	// Access token signing. With JWTSigningKeyFile set, tokens are signed with that
+	// RSA (RS256) or Ed25519 (EdDSA) private key instead of the JWTSecretKey HS256 secret.
+	JWTSigningKeyFile       string
+	JWTVerificationKeyFiles []string // Older keys whose tokens are still accepted, e.g. after a rotation
+
+	DevMode bool // Allows insecure defaults such as the built-in JWT secret
+
+	// Outgoing email. If SMTPHost is empty, mail is written to MailOutboxDir
+	// (or the log when that is empty too) instead of being sent.
+	SMTPHost         string
+	SMTPPort         string
+	SMTPUsername     string
+	SMTPPassword     string
+	MailFrom         string
+	MailOutboxDir    string
+	AppBaseURL       string        // Frontend URL used in links sent by email
+	PasswordResetTTL time.Duration // Lifetime of password reset links
+
+	EmailVerifyTTL       time.Duration // Lifetime of email verification links
+	RequireVerifiedEmail bool          // Block login and board invites for unverified users
+	TOTPIssuer           string        // Name shown for this app in authenticator apps
+	InvitationTTL        time.Duration // Lifetime of emailed board invitations
+
+	// OpenID Connect single sign-on. Disabled unless OIDCIssuerURL is set.
+	OIDCIssuerURL    string
+	OIDCClientID     string
+	OIDCClientSecret string
+	OIDCRedirectURL  string   // Must point at /auth/oidc/callback and be registered with the provider
+	OIDCScopes       []string // Requested in addition to "openid"
+
+	// Login lockout after repeated failures. LoginLockoutStore is "memory" (per process)
+	// or "database" (shared by every instance using the same database).
+	LoginLockoutStore       string
+	LoginMaxAccountFailures int
+	LoginMaxIPFailures      int
+	LoginLockoutBase        time.Duration // First lockout; doubles with each further failure
+	LoginLockoutMax         time.Duration
+	LoginFailureWindow      time.Duration // Failure counts start over after this long without failures
+
+	// Reverse proxies (IPs or CIDR ranges) whose X-Forwarded-For and X-Real-IP
+	// headers give the client IP. With none, the connection's address is used.
+	TrustedProxies []string
+
+	AdminEmails []string // Users made site administrators on startup
+
+	// Deleted boards, lists and cards stay in the trash for TrashRetention (zero keeps
+	// them forever); a job checks every TrashPurgeInterval for ones to delete for good.
+	TrashRetention     time.Duration
+	TrashPurgeInterval time.Duration
+
+	// Every RankRebalanceInterval a job gives the lists of a board, or the cards of a
+	// list, fresh ranks once one is longer than RankMaxLength or two share one.
+	RankRebalanceInterval time.Duration
+	RankMaxLength         int
+
+	// Card attachments are kept by BlobStore: "local" (files under BlobDir) or "s3"
+	// (a bucket of Amazon S3 or an S3-compatible server such as MinIO).
+	BlobStore              string
+	BlobDir                string
+	S3Endpoint             string
+	S3Region               string
+	S3Bucket               string
+	S3AccessKeyID          string
+	S3SecretAccessKey      string
+	AttachmentMaxSize      int64    // Largest accepted file in bytes
+	AttachmentAllowedTypes []string // MIME types such as application/pdf, or image/* for all images
+
 	// WebSocket specific configurations can be added here in the future
 	// For example:
 	// WebSocketReadBufferSize  int
@@ -31,7 +109,7 @@ func LoadConfig() (*Config, error) {
 	// This allows for environments where vars are set directly (e.g., Docker, K8s)
 	_ = godotenv.Load(".env.example")
 
-	return &Config{
+	cfg := &Config{
 		DBHost:       getEnv("DB_HOST", "localhost"),
 		DBUser:       getEnv("DB_USER", "postgres"),
 		DBPassword:   getEnv("DB_PASSWORD", "password"),
@@ -39,14 +117,77 @@ func LoadConfig() (*Config, error) {
 		DBPort:       getEnv("DB_PORT", "5432"),
 		DBSSLMode:    getEnv("DB_SSLMODE", "disable"),
 		DBTimeZone:   getEnv("DB_TIMEZONE", "UTC"),
-		JWTSecretKey: getEnv("JWT_SECRET_KEY", "defaultsecret"),
+		JWTSecretKey: getEnv("JWT_SECRET_KEY", DefaultJWTSecretKey),
 		ServerPort:   getEnv("SERVER_PORT", "8080"),
 
+		AccessTokenTTL:  getEnvAsDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL: getEnvAsDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+
+		JWTSigningKeyFile:       getEnv("JWT_SIGNING_KEY_FILE", ""),
+		JWTVerificationKeyFiles: getEnvAsSlice("JWT_VERIFICATION_KEY_FILES", nil),
+
+		DevMode: getEnvAsBool("DEV_MODE", false),
+
+		SMTPHost:         getEnv("SMTP_HOST", ""),
+		SMTPPort:         getEnv("SMTP_PORT", "587"),
+		SMTPUsername:     getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:     getEnv("SMTP_PASSWORD", ""),
+		MailFrom:         getEnv("MAIL_FROM", "no-reply@localhost"),
+		MailOutboxDir:    getEnv("MAIL_OUTBOX_DIR", ""),
+		AppBaseURL:       getEnv("APP_BASE_URL", "http://localhost:3000"),
+		PasswordResetTTL: getEnvAsDuration("PASSWORD_RESET_TTL", time.Hour),
+
+		EmailVerifyTTL:       getEnvAsDuration("EMAIL_VERIFY_TTL", 48*time.Hour),
+		RequireVerifiedEmail: getEnvAsBool("REQUIRE_VERIFIED_EMAIL", false),
+		TOTPIssuer:           getEnv("TOTP_ISSUER", "Trello Clone"),
+		InvitationTTL:        getEnvAsDuration("INVITATION_TTL", 7*24*time.Hour),
+
+		OIDCIssuerURL:    getEnv("OIDC_ISSUER_URL", ""),
+		OIDCClientID:     getEnv("OIDC_CLIENT_ID", ""),
+		OIDCClientSecret: getEnv("OIDC_CLIENT_SECRET", ""),
+		OIDCRedirectURL:  getEnv("OIDC_REDIRECT_URL", "http://localhost:8080/auth/oidc/callback"),
+		OIDCScopes:       getEnvAsSlice("OIDC_SCOPES", []string{"email", "profile"}),
+
+		LoginLockoutStore:       getEnv("LOGIN_LOCKOUT_STORE", "memory"),
+		LoginMaxAccountFailures: getEnvAsInt("LOGIN_MAX_ACCOUNT_FAILURES", 5),
+		LoginMaxIPFailures:      getEnvAsInt("LOGIN_MAX_IP_FAILURES", 20),
+		LoginLockoutBase:        getEnvAsDuration("LOGIN_LOCKOUT_BASE", time.Minute),
+		LoginLockoutMax:         getEnvAsDuration("LOGIN_LOCKOUT_MAX", time.Hour),
+		LoginFailureWindow:      getEnvAsDuration("LOGIN_FAILURE_WINDOW", 24*time.Hour),
+
+		TrustedProxies: getEnvAsSlice("TRUSTED_PROXIES", nil),
+
+		AdminEmails: getEnvAsSlice("ADMIN_EMAILS", nil),
+
+		TrashRetention:     getEnvAsDuration("TRASH_RETENTION", 30*24*time.Hour),
+		TrashPurgeInterval: getEnvAsDuration("TRASH_PURGE_INTERVAL", time.Hour),
+
+		RankRebalanceInterval: getEnvAsDuration("RANK_REBALANCE_INTERVAL", time.Hour),
+		RankMaxLength:         getEnvAsInt("RANK_MAX_LENGTH", 12),
+
+		BlobStore:              getEnv("BLOB_STORE", "local"),
+		BlobDir:                getEnv("BLOB_DIR", "uploads"),
+		S3Endpoint:             getEnv("S3_ENDPOINT", ""),
+		S3Region:               getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:               getEnv("S3_BUCKET", ""),
+		S3AccessKeyID:          getEnv("S3_ACCESS_KEY_ID", ""),
+		S3SecretAccessKey:      getEnv("S3_SECRET_ACCESS_KEY", ""),
+		AttachmentMaxSize:      int64(getEnvAsInt("ATTACHMENT_MAX_SIZE", 10<<20)),
+		AttachmentAllowedTypes: getEnvAsSlice("ATTACHMENT_ALLOWED_TYPES", nil),
+
 		// Example of loading WebSocket specific configurations:
 		// WebSocketReadBufferSize:  getEnvAsInt("WEBSOCKET_READ_BUFFER_SIZE", 1024),
 		// WebSocketWriteBufferSize: getEnvAsInt("WEBSOCKET_WRITE_BUFFER_SIZE", 1024),
 		// WebSocketAllowedOrigins:  getEnvAsSlice("WEBSOCKET_ALLOWED_ORIGINS", []string{"*"}),
-	}, nil
+	}
+
+	// Anyone who knows the built-in secret could mint tokens for any user
+	if cfg.JWTSigningKeyFile == "" && !cfg.DevMode {
+		if cfg.JWTSecretKey == "" || cfg.JWTSecretKey == DefaultJWTSecretKey {
+			return nil, fmt.Errorf("JWT_SECRET_KEY is unset or the default; set a strong secret or JWT_SIGNING_KEY_FILE (or DEV_MODE=true for local development)")
+		}
+	}
+	return cfg, nil
 }
 
 // Helper function to get an environment variable or return a default value
@@ -56,3 +197,54 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+// Helper function to get an environment variable as a time.Duration (e.g. "15m", "720h")
+func getEnvAsDuration(key string, fallback time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid duration for %s (%q), using default %s", key, value, fallback)
+		return fallback
+	}
+	return d
+}
+
+// Helper function to get an environment variable as an int
+func getEnvAsInt(key string, fallback int) int {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return fallback
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid integer for %s (%q), using default %d", key, value, fallback)
+		return fallback
+	}
+	return i
+}
+
+// Helper function to get an environment variable as a bool ("true", "1", "false", ...)
+func getEnvAsBool(key string, fallback bool) bool {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return fallback
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Invalid boolean for %s (%q), using default %t", key, value, fallback)
+		return fallback
+	}
+	return b
+}
+
+// Helper function to get an environment variable as a list, split on commas or spaces
+func getEnvAsSlice(key string, fallback []string) []string {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return fallback
+	}
+	return strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' })
+}
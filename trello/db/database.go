@@ -28,6 +28,10 @@ func InitDB(cfg *config.Config) (*gorm.DB, error) {
 
 	log.Println("Database connection established.")
 
+	if err := migratePositionsToRanks(db); err != nil {
+		return nil, err
+	}
+
 	// Auto-migrate schema
 	err = db.AutoMigrate(
 		&models.User{},
@@ -37,6 +41,24 @@ func InitDB(cfg *config.Config) (*gorm.DB, error) {
 		&models.BoardMember{},
 		&models.Comment{},
 		&models.CardCollaborator{},
+		&models.RefreshToken{},
+		&models.UserToken{},
+		&models.UserIdentity{},
+		&models.PersonalAccessToken{},
+		&models.RecoveryCode{},
+		&models.LoginThrottle{},
+		&models.AuditEvent{},
+		&models.Workspace{},
+		&models.WorkspaceMember{},
+		&models.BoardInvitation{},
+		&models.BoardInviteLink{},
+		&models.BoardShareLink{},
+		&models.BoardPreference{},
+		&models.Label{},
+		&models.CardLabel{},
+		&models.Checklist{},
+		&models.ChecklistItem{},
+		&models.Attachment{},
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to auto-migrate database schema: %w", err)
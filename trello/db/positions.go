@@ -0,0 +1,95 @@
+package db
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/rank"
+
+	"gorm.io/gorm"
+)
+
+// migratePositionsToRanks turns the integer positions of lists and cards, from
+// before they were ordered by rank, into ranks in the same order. Every row
+// takes part, archived and deleted ones too, so they come back where they were.
+// It does nothing for a table that is missing or already has ranks, and must
+// run before AutoMigrate, which can't change the column's type by itself.
+func migratePositionsToRanks(db *gorm.DB) error {
+	for _, t := range []struct {
+		model interface{}
+		table string
+		scope string
+	}{
+		{&models.List{}, "lists", "board_id"},
+		{&models.Card{}, "cards", "list_id"},
+	} {
+		migrated, err := migratePositions(db, t.model, t.table, t.scope)
+		if err != nil {
+			return fmt.Errorf("failed to convert the positions of %s to ranks: %w", t.table, err)
+		}
+		if migrated > 0 {
+			log.Printf("Converted the positions of %d %s to ranks.", migrated, t.table)
+		}
+	}
+	return nil
+}
+
+// migratePositions converts the position column of table and returns how many
+// rows it changed.
+func migratePositions(db *gorm.DB, model interface{}, table, scope string) (int, error) {
+	migrator := db.Migrator()
+	if !migrator.HasTable(table) {
+		return 0, nil
+	}
+	columns, err := migrator.ColumnTypes(table)
+	if err != nil {
+		return 0, err
+	}
+	integer := false
+	for _, column := range columns {
+		if column.Name() == "position" {
+			integer = strings.Contains(strings.ToLower(column.DatabaseTypeName()), "int")
+		}
+	}
+	if !integer {
+		return 0, nil
+	}
+
+	var rows []struct {
+		ID    uint
+		Scope uint
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table(table).Select("id, " + scope + " AS scope").
+			Order(scope + ", position, id").Scan(&rows).Error; err != nil {
+			return err
+		}
+		if err := tx.Migrator().RenameColumn(model, "position", "integer_position"); err != nil {
+			return err
+		}
+		if err := tx.Migrator().AddColumn(model, "Position"); err != nil {
+			return err
+		}
+		// Each scope's rows are in order, one after the other
+		for start := 0; start < len(rows); {
+			end := start + 1
+			for end < len(rows) && rows[end].Scope == rows[start].Scope {
+				end++
+			}
+			for i, r := range rank.Spread(end - start) {
+				if err := tx.Table(table).Where("id = ?", rows[start+i].ID).
+					UpdateColumn("position", r).Error; err != nil {
+					return err
+				}
+			}
+			start = end
+		}
+		return tx.Migrator().DropColumn(model, "integer_position")
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(rows), nil
+}
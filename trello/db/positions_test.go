@@ -0,0 +1,69 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/zayyadi/trello/models"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Lists and cards as they were stored with integer positions.
+type integerList struct {
+	gorm.Model
+	Name     string
+	BoardID  uint
+	Position uint
+}
+
+func (integerList) TableName() string { return "lists" }
+
+type integerCard struct {
+	gorm.Model
+	Title    string
+	ListID   uint
+	Position uint
+}
+
+func (integerCard) TableName() string { return "cards" }
+
+func TestMigratePositionsToRanks(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, db.AutoMigrate(&integerList{}, &integerCard{}))
+	for _, list := range []integerList{{Name: "Done", BoardID: 1, Position: 2}, {Name: "To do", BoardID: 1, Position: 1}, {Name: "Ideas", BoardID: 2, Position: 1}} {
+		assert.NoError(t, db.Create(&list).Error)
+	}
+	for _, card := range []integerCard{{Title: "c", ListID: 1, Position: 3}, {Title: "a", ListID: 1, Position: 1}, {Title: "b", ListID: 1, Position: 2}} {
+		assert.NoError(t, db.Create(&card).Error)
+	}
+	assert.NoError(t, db.Delete(&integerCard{}, 3).Error) // "b", in the trash
+
+	assert.NoError(t, migratePositionsToRanks(db))
+	assert.NoError(t, db.AutoMigrate(&models.List{}, &models.Card{}))
+
+	var lists []models.List
+	assert.NoError(t, db.Where("board_id = ?", 1).Order("position").Find(&lists).Error)
+	if assert.Len(t, lists, 2) {
+		assert.Equal(t, "To do", lists[0].Name)
+		assert.Equal(t, "Done", lists[1].Name)
+	}
+	var cards []models.Card
+	assert.NoError(t, db.Unscoped().Order("position").Find(&cards).Error)
+	if assert.Len(t, cards, 3) {
+		assert.Equal(t, []string{"a", "b", "c"}, []string{cards[0].Title, cards[1].Title, cards[2].Title}, "deleted cards keep their place")
+		assert.Equal(t, []string{"9", "i", "r"}, []string{cards[0].Position, cards[1].Position, cards[2].Position})
+	}
+	assert.False(t, db.Migrator().HasColumn("cards", "integer_position"))
+
+	// Ranks are left alone
+	assert.NoError(t, migratePositionsToRanks(db))
+	var again []models.Card
+	assert.NoError(t, db.Unscoped().Order("position").Find(&again).Error)
+	assert.Equal(t, cards, again)
+}
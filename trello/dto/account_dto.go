@@ -0,0 +1,117 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/zayyadi/trello/models"
+)
+
+type DeleteAccountRequest struct {
+	CurrentPassword string `json:"currentPassword" binding:"required"`
+	// DeleteOwnedBoards deletes owned boards instead of handing them over to a member.
+	DeleteOwnedBoards bool `json:"deleteOwnedBoards"`
+	// TransferTo picks the new owner of specific boards, keyed by board ID.
+	TransferTo map[uint]uint `json:"transferTo"`
+}
+
+// AccountExportResponse is the personal data export, served as a JSON download.
+type AccountExportResponse struct {
+	ExportedAt     time.Time                       `json:"exportedAt"`
+	Profile        UserResponse                    `json:"profile"`
+	Boards         []ExportedBoardResponse         `json:"boards"`
+	Cards          []ExportedCardResponse          `json:"cards"`
+	Comments       []ExportedCommentResponse       `json:"comments"`
+	Collaborations []ExportedCollaborationResponse `json:"collaborations"`
+}
+
+type ExportedBoardResponse struct {
+	ID          uint             `json:"id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	IsOwner     bool             `json:"isOwner"`
+	Role        models.BoardRole `json:"role,omitempty"`
+	JoinedAt    *time.Time       `json:"joinedAt,omitempty"`
+	CreatedAt   time.Time        `json:"createdAt"`
+}
+
+type ExportedCardResponse struct {
+	ID           uint              `json:"id"`
+	ListID       uint              `json:"listID"`
+	Title        string            `json:"title"`
+	Description  string            `json:"description"`
+	Status       models.CardStatus `json:"status"`
+	DueDate      *time.Time        `json:"dueDate,omitempty"`
+	IsAssignee   bool              `json:"isAssignee"`
+	IsSupervisor bool              `json:"isSupervisor"`
+	CreatedAt    time.Time         `json:"createdAt"`
+	UpdatedAt    time.Time         `json:"updatedAt"`
+}
+
+type ExportedCommentResponse struct {
+	ID        uint      `json:"id"`
+	CardID    uint      `json:"cardID"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type ExportedCollaborationResponse struct {
+	CardID    uint      `json:"cardID"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// MapAccountExportToResponse builds the export document from the user's data.
+func MapAccountExportToResponse(user *models.User, boards []models.Board, cards []models.Card, comments []models.Comment, collaborations []models.CardCollaborator, exportedAt time.Time) AccountExportResponse {
+	resp := AccountExportResponse{
+		ExportedAt:     exportedAt,
+		Profile:        MapUserToResponse(user),
+		Boards:         make([]ExportedBoardResponse, len(boards)),
+		Cards:          make([]ExportedCardResponse, len(cards)),
+		Comments:       make([]ExportedCommentResponse, len(comments)),
+		Collaborations: make([]ExportedCollaborationResponse, len(collaborations)),
+	}
+	for i, board := range boards {
+		item := ExportedBoardResponse{
+			ID:          board.ID,
+			Name:        board.Name,
+			Description: board.Description,
+			IsOwner:     board.OwnerID == user.ID,
+			CreatedAt:   board.CreatedAt,
+		}
+		for _, member := range board.Members {
+			if member.UserID == user.ID {
+				joinedAt := member.CreatedAt
+				item.Role = member.Role
+				item.JoinedAt = &joinedAt
+			}
+		}
+		resp.Boards[i] = item
+	}
+	for i, card := range cards {
+		resp.Cards[i] = ExportedCardResponse{
+			ID:           card.ID,
+			ListID:       card.ListID,
+			Title:        card.Title,
+			Description:  card.Description,
+			Status:       card.Status,
+			DueDate:      card.DueDate,
+			IsAssignee:   card.AssignedUserID != nil && *card.AssignedUserID == user.ID,
+			IsSupervisor: card.SupervisorID != nil && *card.SupervisorID == user.ID,
+			CreatedAt:    card.CreatedAt,
+			UpdatedAt:    card.UpdatedAt,
+		}
+	}
+	for i, comment := range comments {
+		resp.Comments[i] = ExportedCommentResponse{
+			ID:        comment.ID,
+			CardID:    comment.CardID,
+			Content:   comment.Content,
+			CreatedAt: comment.CreatedAt,
+			UpdatedAt: comment.UpdatedAt,
+		}
+	}
+	for i, collaboration := range collaborations {
+		resp.Collaborations[i] = ExportedCollaborationResponse{CardID: collaboration.CardID, CreatedAt: collaboration.CreatedAt}
+	}
+	return resp
+}
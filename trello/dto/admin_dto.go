@@ -0,0 +1,60 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/zayyadi/trello/models"
+)
+
+type UnlockLoginRequest struct {
+	Scope models.LoginThrottleScope `json:"scope" binding:"required,oneof=account ip"`
+	Key   string                    `json:"key" binding:"required"` // Email for an account, address for an IP
+}
+
+type LoginLockoutResponse struct {
+	Scope         models.LoginThrottleScope `json:"scope"`
+	Key           string                    `json:"key"`
+	Failures      int                       `json:"failures"`
+	LastFailureAt time.Time                 `json:"lastFailureAt"`
+	LockedUntil   *time.Time                `json:"lockedUntil"`
+}
+
+type AuditEventResponse struct {
+	ID        uint                  `json:"id"`
+	Type      models.AuditEventType `json:"type"`
+	ActorID   *uint                 `json:"actorID,omitempty"`
+	UserID    *uint                 `json:"userID,omitempty"`
+	Email     string                `json:"email,omitempty"`
+	IP        string                `json:"ip,omitempty"`
+	Detail    string                `json:"detail,omitempty"`
+	CreatedAt time.Time             `json:"createdAt"`
+}
+
+func MapLoginThrottleToResponse(throttle *models.LoginThrottle) LoginLockoutResponse {
+	if throttle == nil {
+		return LoginLockoutResponse{}
+	}
+	return LoginLockoutResponse{
+		Scope:         throttle.Scope,
+		Key:           throttle.Key,
+		Failures:      throttle.Failures,
+		LastFailureAt: throttle.LastFailureAt,
+		LockedUntil:   throttle.LockedUntil,
+	}
+}
+
+func MapAuditEventToResponse(event *models.AuditEvent) AuditEventResponse {
+	if event == nil {
+		return AuditEventResponse{}
+	}
+	return AuditEventResponse{
+		ID:        event.ID,
+		Type:      event.Type,
+		ActorID:   event.ActorID,
+		UserID:    event.UserID,
+		Email:     event.Email,
+		IP:        event.IP,
+		Detail:    event.Detail,
+		CreatedAt: event.CreatedAt,
+	}
+}
@@ -0,0 +1,83 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/zayyadi/trello/models"
+)
+
+// Archive DTOs
+type ArchivedItemsResponse struct {
+	Lists []ListResponse `json:"lists"`
+	Cards []CardResponse `json:"cards"`
+}
+
+// Trash DTOs. PurgeAt is when the item will be deleted for good; it is left out
+// when deleted items are kept forever.
+type TrashedListResponse struct {
+	ListResponse
+	DeletedAt time.Time  `json:"deletedAt"`
+	PurgeAt   *time.Time `json:"purgeAt,omitempty"`
+}
+
+type TrashedCardResponse struct {
+	CardResponse
+	DeletedAt time.Time  `json:"deletedAt"`
+	PurgeAt   *time.Time `json:"purgeAt,omitempty"`
+}
+
+type TrashResponse struct {
+	Lists []TrashedListResponse `json:"lists"`
+	Cards []TrashedCardResponse `json:"cards"`
+}
+
+type TrashedBoardResponse struct {
+	BoardResponse
+	DeletedAt time.Time  `json:"deletedAt"`
+	PurgeAt   *time.Time `json:"purgeAt,omitempty"`
+}
+
+// MapArchivedItemsToResponse maps a board's archived lists and cards.
+func MapArchivedItemsToResponse(lists []models.List, cards []models.Card) ArchivedItemsResponse {
+	resp := ArchivedItemsResponse{Lists: make([]ListResponse, len(lists)), Cards: make([]CardResponse, len(cards))}
+	for i := range lists {
+		resp.Lists[i] = MapListToResponse(&lists[i], false)
+	}
+	for i := range cards {
+		resp.Cards[i] = MapCardToResponse(&cards[i], false)
+	}
+	return resp
+}
+
+// MapTrashToResponse maps a board's deleted lists and cards. retention is how long
+// deleted items are kept, zero for forever.
+func MapTrashToResponse(lists []models.List, cards []models.Card, retention time.Duration) TrashResponse {
+	resp := TrashResponse{Lists: make([]TrashedListResponse, len(lists)), Cards: make([]TrashedCardResponse, len(cards))}
+	for i := range lists {
+		deletedAt := lists[i].DeletedAt.Time
+		resp.Lists[i] = TrashedListResponse{ListResponse: MapListToResponse(&lists[i], false), DeletedAt: deletedAt, PurgeAt: purgeAt(deletedAt, retention)}
+	}
+	for i := range cards {
+		deletedAt := cards[i].DeletedAt.Time
+		resp.Cards[i] = TrashedCardResponse{CardResponse: MapCardToResponse(&cards[i], false), DeletedAt: deletedAt, PurgeAt: purgeAt(deletedAt, retention)}
+	}
+	return resp
+}
+
+// MapTrashedBoardToResponse maps a deleted board. retention is as for MapTrashToResponse.
+func MapTrashedBoardToResponse(board *models.Board, retention time.Duration) TrashedBoardResponse {
+	deletedAt := board.DeletedAt.Time
+	return TrashedBoardResponse{
+		BoardResponse: MapBoardToResponse(board, false, false),
+		DeletedAt:     deletedAt,
+		PurgeAt:       purgeAt(deletedAt, retention),
+	}
+}
+
+func purgeAt(deletedAt time.Time, retention time.Duration) *time.Time {
+	if retention <= 0 {
+		return nil
+	}
+	at := deletedAt.Add(retention)
+	return &at
+}
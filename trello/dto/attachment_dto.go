@@ -0,0 +1,54 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/zayyadi/trello/models"
+)
+
+// SetCardCoverRequest picks the image attachment shown as the card's cover.
+type SetCardCoverRequest struct {
+	AttachmentID uint `json:"attachmentID" binding:"required"`
+}
+
+type AttachmentResponse struct {
+	ID          uint          `json:"id"`
+	CardID      uint          `json:"cardID"`
+	Filename    string        `json:"filename"`
+	ContentType string        `json:"contentType"`
+	Size        int64         `json:"size"`
+	UploaderID  uint          `json:"uploaderID"`
+	Uploader    *UserResponse `json:"uploader,omitempty"`
+	CreatedAt   time.Time     `json:"createdAt"`
+
+	// API paths to fetch the file and, for images we could scale, its thumbnail
+	DownloadURL  string `json:"downloadURL"`
+	ThumbnailURL string `json:"thumbnailURL,omitempty"`
+}
+
+// MapAttachmentToResponse maps models.Attachment to AttachmentResponse.
+func MapAttachmentToResponse(attachment *models.Attachment) AttachmentResponse {
+	if attachment == nil {
+		return AttachmentResponse{}
+	}
+	base := fmt.Sprintf("/api/cards/%d/attachments/%d", attachment.CardID, attachment.ID)
+	resp := AttachmentResponse{
+		ID:          attachment.ID,
+		CardID:      attachment.CardID,
+		Filename:    attachment.Filename,
+		ContentType: attachment.ContentType,
+		Size:        attachment.Size,
+		UploaderID:  attachment.UploaderID,
+		CreatedAt:   attachment.CreatedAt,
+		DownloadURL: base + "/download",
+	}
+	if attachment.ThumbnailKey != nil {
+		resp.ThumbnailURL = base + "/thumbnail"
+	}
+	if attachment.Uploader != nil && attachment.Uploader.ID != 0 {
+		uploader := MapUserToResponse(attachment.Uploader)
+		resp.Uploader = &uploader
+	}
+	return resp
+}
@@ -24,24 +24,68 @@ type BoardResponse struct {
 	Name        string                `json:"name"`
 	Description string                `json:"description"`
 	OwnerID     uint                  `json:"ownerID"`
+	WorkspaceID *uint                 `json:"workspaceID,omitempty"`
+	Visibility  string                `json:"visibility"`      // private, workspace or public
 	Owner       UserResponse          `json:"owner,omitempty"` // Uses dto.UserResponse
 	Lists       []ListResponse        `json:"lists,omitempty"` // Uses dto.ListResponse (to be created)
 	Members     []BoardMemberResponse `json:"members,omitempty"`
 	CreatedAt   time.Time             `json:"createdAt"`
 	UpdatedAt   time.Time             `json:"updatedAt"`
+
+	ArchivedAt *time.Time `json:"archivedAt,omitempty"`
+
+	IsTemplate bool `json:"isTemplate"`
+}
+
+// CopyBoardRequest copies a board, or starts a new board from a template. The
+// board's lists are always copied; collaborators and comments need cards.
+type CopyBoardRequest struct {
+	Name                 string `json:"name" binding:"max=100"` // Defaults to the template's name, or "<name> (copy)"
+	IncludeCards         bool   `json:"includeCards"`
+	IncludeCollaborators bool   `json:"includeCollaborators"`
+	IncludeComments      bool   `json:"includeComments"`
+}
+
+// UpdateTemplateRequest marks a board as a template, or makes it a normal board again.
+type UpdateTemplateRequest struct {
+	IsTemplate *bool `json:"isTemplate" binding:"required"`
+}
+
+// UserBoardResponse is a board in the current user's board list, with their own settings for it.
+type UserBoardResponse struct {
+	BoardResponse
+	Starred      bool       `json:"starred"`
+	Position     *int       `json:"position,omitempty"` // The user's own order, if they placed the board
+	LastViewedAt *time.Time `json:"lastViewedAt,omitempty"`
+}
+
+// SetBoardOrderRequest orders the user's board list. Boards left out lose their place.
+type SetBoardOrderRequest struct {
+	BoardIDs []uint `json:"boardIDs" binding:"max=1000"`
 }
 
 // Board Member DTOs
 type AddMemberRequest struct {
-	Email  *string `json:"email"`  // Add by email
-	UserID *uint   `json:"userID"` // Or by UserID
+	Email  *string          `json:"email"`                                              // Add by email
+	UserID *uint            `json:"userID"`                                             // Or by UserID
+	Role   models.BoardRole `json:"role" binding:"omitempty,oneof=admin member viewer"` // Defaults to member
+}
+
+type UpdateMemberRoleRequest struct {
+	Role models.BoardRole `json:"role" binding:"required,oneof=admin member viewer"`
+}
+
+// TransferOwnershipRequest hands a board to one of its members.
+type TransferOwnershipRequest struct {
+	NewOwnerID uint `json:"newOwnerID" binding:"required"`
 }
 
 type BoardMemberResponse struct {
-	BoardID   uint         `json:"boardID"`
-	UserID    uint         `json:"userID"`
-	User      UserResponse `json:"user"` // Uses dto.UserResponse
-	CreatedAt time.Time    `json:"createdAt"`
+	BoardID   uint             `json:"boardID"`
+	UserID    uint             `json:"userID"`
+	User      UserResponse     `json:"user"` // Uses dto.UserResponse
+	Role      models.BoardRole `json:"role"`
+	CreatedAt time.Time        `json:"createdAt"`
 }
 
 // MapBoardToResponse maps model.Board to BoardResponse
@@ -55,8 +99,14 @@ func MapBoardToResponse(board *models.Board, includeOwner, includeMembersAndList
 		Name:        board.Name,
 		Description: board.Description,
 		OwnerID:     board.OwnerID,
+		WorkspaceID: board.WorkspaceID,
+		Visibility:  string(board.Visibility),
 		CreatedAt:   board.Model.CreatedAt,
 		UpdatedAt:   board.Model.UpdatedAt,
+
+		ArchivedAt: board.ArchivedAt,
+
+		IsTemplate: board.IsTemplate,
 	}
 	if includeOwner && board.Owner.ID != 0 {
 		resp.Owner = MapUserToResponse(&board.Owner) // Assumes MapUserToResponse is in the same 'dto' package
@@ -80,6 +130,17 @@ func MapBoardToResponse(board *models.Board, includeOwner, includeMembersAndList
 	return resp
 }
 
+// MapUserBoardToResponse maps a board and the current user's preferences for it to UserBoardResponse.
+func MapUserBoardToResponse(board *models.Board, pref *models.BoardPreference) UserBoardResponse {
+	resp := UserBoardResponse{BoardResponse: MapBoardToResponse(board, true, false)}
+	if pref != nil {
+		resp.Starred = pref.Starred
+		resp.Position = pref.Position
+		resp.LastViewedAt = pref.LastViewedAt
+	}
+	return resp
+}
+
 // MapBoardMemberToResponse maps model.BoardMember to BoardMemberResponse
 func MapBoardMemberToResponse(member *models.BoardMember) BoardMemberResponse {
 	if member == nil {
@@ -89,6 +150,7 @@ func MapBoardMemberToResponse(member *models.BoardMember) BoardMemberResponse {
 		BoardID:   member.BoardID,
 		UserID:    member.UserID,
 		User:      MapUserToResponse(&member.User), // Assumes MapUserToResponse is in the same 'dto' package
+		Role:      member.Role,
 		CreatedAt: member.CreatedAt,
 	}
 }
@@ -34,7 +34,7 @@ type CardResponse struct {
 	Title          string            `json:"title"`
 	Description    string            `json:"description"`
 	ListID         uint              `json:"listID"`
-	Position       uint              `json:"position"`
+	Position       string            `json:"position"` // Sort cards by this rank
 	DueDate        *time.Time        `json:"dueDate,omitempty"`
 	Status         models.CardStatus `json:"status"`
 	AssignedUserID *uint             `json:"assignedUserID,omitempty"`
@@ -45,6 +45,17 @@ type CardResponse struct {
 	Collaborators  []UserResponse    `json:"collaborators,omitempty"` // Uses dto.UserResponse
 	CreatedAt      time.Time         `json:"createdAt"`
 	UpdatedAt      time.Time         `json:"updatedAt"`
+
+	ArchivedAt *time.Time `json:"archivedAt,omitempty"`
+
+	Labels []LabelResponse `json:"labels"`
+
+	// The card's checklists, and the progress over all their items
+	Checklists        []ChecklistResponse       `json:"checklists"`
+	ChecklistProgress ChecklistProgressResponse `json:"checklistProgress"`
+
+	CoverAttachmentID *uint               `json:"coverAttachmentID,omitempty"`
+	Cover             *AttachmentResponse `json:"cover,omitempty"`
 }
 
 type MoveCardRequest struct {
@@ -52,6 +63,11 @@ type MoveCardRequest struct {
 	NewPosition  uint `json:"newPosition" binding:"required,min=1"`
 }
 
+// ReorderCardsRequest lists all of the list's cards in their new order.
+type ReorderCardsRequest struct {
+	CardIDs []uint `json:"cardIDs" binding:"required"`
+}
+
 // Card Collaborator DTOs
 type CardAddCollaboratorRequest struct {
 	Email  *string `json:"email"`
@@ -76,6 +92,19 @@ func MapCardToResponse(card *models.Card, includeUserDetails bool) CardResponse
 		Color:          card.Color,
 		CreatedAt:      card.CreatedAt,
 		UpdatedAt:      card.UpdatedAt,
+
+		ArchivedAt: card.ArchivedAt,
+
+		Labels: mapLabels(card.Labels),
+
+		Checklists:        mapChecklists(card.Checklists),
+		ChecklistProgress: MapChecklistProgress(card.Checklists),
+
+		CoverAttachmentID: card.CoverAttachmentID,
+	}
+	if card.CoverAttachment != nil && card.CoverAttachment.ID != 0 {
+		cover := MapAttachmentToResponse(card.CoverAttachment)
+		resp.Cover = &cover
 	}
 
 	if includeUserDetails {
@@ -0,0 +1,137 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/zayyadi/trello/models"
+)
+
+// Checklist DTOs
+type CreateChecklistRequest struct {
+	Title string `json:"title" binding:"required,min=1,max=255"`
+}
+
+type UpdateChecklistRequest struct {
+	Title string `json:"title" binding:"required,min=1,max=255"`
+}
+
+// ReorderChecklistsRequest lists all of the card's checklists in their new order.
+type ReorderChecklistsRequest struct {
+	ChecklistIDs []uint `json:"checklistIDs" binding:"required"`
+}
+
+type CreateChecklistItemRequest struct {
+	Content        string     `json:"content" binding:"required,min=1,max=500"`
+	AssignedUserID *uint      `json:"assignedUserID,omitempty"`
+	DueDate        *time.Time `json:"dueDate,omitempty"`
+}
+
+type UpdateChecklistItemRequest struct {
+	Content        *string    `json:"content" binding:"omitempty,min=1,max=500"`
+	Completed      *bool      `json:"completed"`
+	AssignedUserID *uint      `json:"assignedUserID"` // 0 unassigns the item
+	DueDate        *time.Time `json:"dueDate,omitempty"`
+	ClearDueDate   bool       `json:"clearDueDate"`
+}
+
+// ReorderChecklistItemsRequest lists all of the checklist's items in their new order.
+type ReorderChecklistItemsRequest struct {
+	ItemIDs []uint `json:"itemIDs" binding:"required"`
+}
+
+type ChecklistItemResponse struct {
+	ID             uint          `json:"id"`
+	ChecklistID    uint          `json:"checklistID"`
+	Content        string        `json:"content"`
+	Position       uint          `json:"position"`
+	Completed      bool          `json:"completed"`
+	CompletedAt    *time.Time    `json:"completedAt,omitempty"`
+	AssignedUserID *uint         `json:"assignedUserID,omitempty"`
+	AssignedUser   *UserResponse `json:"assignedUser,omitempty"`
+	DueDate        *time.Time    `json:"dueDate,omitempty"`
+	CreatedAt      time.Time     `json:"createdAt"`
+	UpdatedAt      time.Time     `json:"updatedAt"`
+}
+
+type ChecklistResponse struct {
+	ID       uint                    `json:"id"`
+	CardID   uint                    `json:"cardID"`
+	Title    string                  `json:"title"`
+	Position uint                    `json:"position"`
+	Items    []ChecklistItemResponse `json:"items"`
+	ChecklistProgressResponse
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// ChecklistProgressResponse says how many checklist items are done, and what
+// percentage of them (rounded down; 0 without items).
+type ChecklistProgressResponse struct {
+	CompletedItems int `json:"completedItems"`
+	TotalItems     int `json:"totalItems"`
+	Progress       int `json:"progress"`
+}
+
+// MapChecklistProgress computes the progress over the given checklists.
+func MapChecklistProgress(checklists []models.Checklist) ChecklistProgressResponse {
+	completed, total := models.ChecklistProgress(checklists)
+	resp := ChecklistProgressResponse{CompletedItems: completed, TotalItems: total}
+	if total > 0 {
+		resp.Progress = completed * 100 / total
+	}
+	return resp
+}
+
+// MapChecklistToResponse maps models.Checklist, with its items, to ChecklistResponse.
+func MapChecklistToResponse(checklist *models.Checklist) ChecklistResponse {
+	if checklist == nil {
+		return ChecklistResponse{}
+	}
+	resp := ChecklistResponse{
+		ID:                        checklist.ID,
+		CardID:                    checklist.CardID,
+		Title:                     checklist.Title,
+		Position:                  checklist.Position,
+		Items:                     make([]ChecklistItemResponse, len(checklist.Items)),
+		ChecklistProgressResponse: MapChecklistProgress([]models.Checklist{*checklist}),
+		CreatedAt:                 checklist.CreatedAt,
+		UpdatedAt:                 checklist.UpdatedAt,
+	}
+	for i := range checklist.Items {
+		resp.Items[i] = MapChecklistItemToResponse(&checklist.Items[i])
+	}
+	return resp
+}
+
+// MapChecklistItemToResponse maps models.ChecklistItem to ChecklistItemResponse.
+func MapChecklistItemToResponse(item *models.ChecklistItem) ChecklistItemResponse {
+	if item == nil {
+		return ChecklistItemResponse{}
+	}
+	resp := ChecklistItemResponse{
+		ID:             item.ID,
+		ChecklistID:    item.ChecklistID,
+		Content:        item.Content,
+		Position:       item.Position,
+		Completed:      item.Completed,
+		CompletedAt:    item.CompletedAt,
+		AssignedUserID: item.AssignedUserID,
+		DueDate:        item.DueDate,
+		CreatedAt:      item.CreatedAt,
+		UpdatedAt:      item.UpdatedAt,
+	}
+	if item.AssignedUser != nil && item.AssignedUser.ID != 0 {
+		assignee := MapUserToResponse(item.AssignedUser)
+		resp.AssignedUser = &assignee
+	}
+	return resp
+}
+
+// mapChecklists maps a card's checklists, with an empty slice rather than null for none.
+func mapChecklists(checklists []models.Checklist) []ChecklistResponse {
+	responses := make([]ChecklistResponse, len(checklists))
+	for i := range checklists {
+		responses[i] = MapChecklistToResponse(&checklists[i])
+	}
+	return responses
+}
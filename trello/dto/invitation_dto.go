@@ -0,0 +1,93 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/zayyadi/trello/models"
+)
+
+// Board Invitation DTOs
+type CreateBoardInvitationRequest struct {
+	Email string           `json:"email" binding:"required,email"`
+	Role  models.BoardRole `json:"role" binding:"omitempty,oneof=admin member viewer"` // Defaults to member
+}
+
+// InvitationTokenRequest answers an invitation with the token from the invitation email.
+type InvitationTokenRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type BoardInvitationResponse struct {
+	ID        uint                    `json:"id"`
+	BoardID   uint                    `json:"boardID"`
+	BoardName string                  `json:"boardName,omitempty"` // Set when listing your own invitations
+	Email     string                  `json:"email"`
+	Role      models.BoardRole        `json:"role"`
+	Status    models.InvitationStatus `json:"status"`
+	Inviter   UserResponse            `json:"inviter"`
+	ExpiresAt time.Time               `json:"expiresAt"`
+	CreatedAt time.Time               `json:"createdAt"`
+}
+
+// Board Invite Link DTOs
+type CreateBoardInviteLinkRequest struct {
+	Role    models.BoardRole `json:"role" binding:"omitempty,oneof=admin member viewer"` // Defaults to member
+	MaxUses *int             `json:"maxUses" binding:"omitempty,min=1"`                  // Omit for unlimited uses
+	// ExpiresInHours sets how long the link works. Omit it (or use 0) for a link that never expires.
+	ExpiresInHours int `json:"expiresInHours" binding:"min=0,max=8760"`
+}
+
+type JoinBoardRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type BoardInviteLinkResponse struct {
+	ID        uint             `json:"id"`
+	BoardID   uint             `json:"boardID"`
+	Role      models.BoardRole `json:"role"`
+	MaxUses   *int             `json:"maxUses,omitempty"`
+	Uses      int              `json:"uses"`
+	ExpiresAt *time.Time       `json:"expiresAt,omitempty"`
+	CreatedAt time.Time        `json:"createdAt"`
+}
+
+// CreatedBoardInviteLinkResponse includes the raw token and the link to share, which are only ever shown once.
+type CreatedBoardInviteLinkResponse struct {
+	BoardInviteLinkResponse
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+// MapBoardInvitationToResponse maps models.BoardInvitation to BoardInvitationResponse.
+func MapBoardInvitationToResponse(invitation *models.BoardInvitation) BoardInvitationResponse {
+	if invitation == nil {
+		return BoardInvitationResponse{}
+	}
+	return BoardInvitationResponse{
+		ID:        invitation.ID,
+		BoardID:   invitation.BoardID,
+		BoardName: invitation.Board.Name,
+		Email:     invitation.Email,
+		Role:      invitation.Role,
+		Status:    invitation.Status,
+		Inviter:   MapUserToResponse(&invitation.Inviter),
+		ExpiresAt: invitation.ExpiresAt,
+		CreatedAt: invitation.CreatedAt,
+	}
+}
+
+// MapBoardInviteLinkToResponse maps models.BoardInviteLink to BoardInviteLinkResponse.
+func MapBoardInviteLinkToResponse(link *models.BoardInviteLink) BoardInviteLinkResponse {
+	if link == nil {
+		return BoardInviteLinkResponse{}
+	}
+	return BoardInviteLinkResponse{
+		ID:        link.ID,
+		BoardID:   link.BoardID,
+		Role:      link.Role,
+		MaxUses:   link.MaxUses,
+		Uses:      link.Uses,
+		ExpiresAt: link.ExpiresAt,
+		CreatedAt: link.CreatedAt,
+	}
+}
@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/zayyadi/trello/models"
+)
+
+// Label DTOs
+type CreateLabelRequest struct {
+	Name  string `json:"name" binding:"max=50"`    // May be empty for a color-only label
+	Color string `json:"color" binding:"required"` // Hex color like #RRGGBB
+}
+
+type UpdateLabelRequest struct {
+	Name  *string `json:"name" binding:"omitempty,max=50"`
+	Color *string `json:"color,omitempty"`
+}
+
+// AddCardLabelRequest puts one of the board's labels on a card.
+type AddCardLabelRequest struct {
+	LabelID uint `json:"labelID" binding:"required"`
+}
+
+type LabelResponse struct {
+	ID        uint      `json:"id"`
+	BoardID   uint      `json:"boardID"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// MapLabelToResponse maps models.Label to LabelResponse.
+func MapLabelToResponse(label *models.Label) LabelResponse {
+	if label == nil {
+		return LabelResponse{}
+	}
+	return LabelResponse{
+		ID:        label.ID,
+		BoardID:   label.BoardID,
+		Name:      label.Name,
+		Color:     label.Color,
+		CreatedAt: label.CreatedAt,
+		UpdatedAt: label.UpdatedAt,
+	}
+}
+
+// mapLabels maps a card's labels, with an empty slice rather than null for none.
+func mapLabels(labels []models.Label) []LabelResponse {
+	responses := make([]LabelResponse, len(labels))
+	for i := range labels {
+		responses[i] = MapLabelToResponse(&labels[i])
+	}
+	return responses
+}
@@ -18,14 +18,21 @@ type UpdateListRequest struct {
 	Position *uint   `json:"position"` // Optional: new position
 }
 
+// ReorderListsRequest lists all of the board's lists in their new order.
+type ReorderListsRequest struct {
+	ListIDs []uint `json:"listIDs" binding:"required"`
+}
+
 type ListResponse struct {
 	ID        uint           `json:"id"`
 	Name      string         `json:"name"`
 	BoardID   uint           `json:"boardID"`
-	Position  uint           `json:"position"`
+	Position  string         `json:"position"`        // Sort lists by this rank
 	Cards     []CardResponse `json:"cards,omitempty"` // Uses dto.CardResponse (to be created)
 	CreatedAt time.Time      `json:"createdAt"`
 	UpdatedAt time.Time      `json:"updatedAt"`
+
+	ArchivedAt *time.Time `json:"archivedAt,omitempty"`
 }
 
 // MapListToResponse maps model.List to ListResponse
@@ -41,6 +48,8 @@ func MapListToResponse(list *models.List, includeCards bool) ListResponse {
 		Position:  list.Position,
 		CreatedAt: list.CreatedAt,
 		UpdatedAt: list.UpdatedAt,
+
+		ArchivedAt: list.ArchivedAt,
 	}
 	if includeCards && len(list.Cards) > 0 {
 		resp.Cards = []CardResponse{}
@@ -0,0 +1,120 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/zayyadi/trello/models"
+)
+
+// Public board DTOs. Viewers who aren't logged in see the board's contents but
+// nothing about the people on it beyond the owner's public profile.
+type PublicUserResponse struct {
+	ID          uint   `json:"id"`
+	Username    string `json:"username"`
+	DisplayName string `json:"displayName,omitempty"`
+	AvatarURL   string `json:"avatarURL,omitempty"`
+}
+
+type PublicCardResponse struct {
+	ID          uint              `json:"id"`
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	Position    string            `json:"position"`
+	DueDate     *time.Time        `json:"dueDate,omitempty"`
+	Status      models.CardStatus `json:"status"`
+	Color       *string           `json:"color,omitempty"`
+
+	Labels            []LabelResponse           `json:"labels"`
+	ChecklistProgress ChecklistProgressResponse `json:"checklistProgress"` // Only the counts; the items aren't shown
+}
+
+type PublicListResponse struct {
+	ID       uint                 `json:"id"`
+	Name     string               `json:"name"`
+	Position string               `json:"position"`
+	Cards    []PublicCardResponse `json:"cards"`
+}
+
+type PublicBoardResponse struct {
+	ID          uint                 `json:"id"`
+	Name        string               `json:"name"`
+	Description string               `json:"description"`
+	Visibility  string               `json:"visibility"`
+	Owner       PublicUserResponse   `json:"owner"`
+	Lists       []PublicListResponse `json:"lists"`
+	UpdatedAt   time.Time            `json:"updatedAt"`
+	ArchivedAt  *time.Time           `json:"archivedAt,omitempty"`
+}
+
+// Board Share Link DTOs
+type CreateBoardShareLinkRequest struct {
+	// ExpiresInHours sets how long the link works. Omit it (or use 0) for a link that never expires.
+	ExpiresInHours int `json:"expiresInHours" binding:"min=0,max=8760"`
+}
+
+type BoardShareLinkResponse struct {
+	ID          uint       `json:"id"`
+	BoardID     uint       `json:"boardID"`
+	CreatedByID uint       `json:"createdByID"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+// CreatedBoardShareLinkResponse includes the raw token and the link to share, which are only ever shown once.
+type CreatedBoardShareLinkResponse struct {
+	BoardShareLinkResponse
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+// MapPublicBoardToResponse maps a board and its lists, with their cards, for viewers who aren't logged in.
+func MapPublicBoardToResponse(board *models.Board, lists []models.List) PublicBoardResponse {
+	resp := PublicBoardResponse{
+		ID:          board.ID,
+		Name:        board.Name,
+		Description: board.Description,
+		Visibility:  string(board.Visibility),
+		Owner: PublicUserResponse{
+			ID:          board.Owner.ID,
+			Username:    board.Owner.Username,
+			DisplayName: board.Owner.DisplayName,
+			AvatarURL:   board.Owner.AvatarURL,
+		},
+		Lists:      make([]PublicListResponse, len(lists)),
+		UpdatedAt:  board.UpdatedAt,
+		ArchivedAt: board.ArchivedAt,
+	}
+	for i, list := range lists {
+		cards := make([]PublicCardResponse, len(list.Cards))
+		for j, card := range list.Cards {
+			cards[j] = PublicCardResponse{
+				ID:          card.ID,
+				Title:       card.Title,
+				Description: card.Description,
+				Position:    card.Position,
+				DueDate:     card.DueDate,
+				Status:      card.Status,
+				Color:       card.Color,
+
+				Labels:            mapLabels(card.Labels),
+				ChecklistProgress: MapChecklistProgress(card.Checklists),
+			}
+		}
+		resp.Lists[i] = PublicListResponse{ID: list.ID, Name: list.Name, Position: list.Position, Cards: cards}
+	}
+	return resp
+}
+
+// MapBoardShareLinkToResponse maps models.BoardShareLink to BoardShareLinkResponse.
+func MapBoardShareLinkToResponse(link *models.BoardShareLink) BoardShareLinkResponse {
+	if link == nil {
+		return BoardShareLinkResponse{}
+	}
+	return BoardShareLinkResponse{
+		ID:          link.ID,
+		BoardID:     link.BoardID,
+		CreatedByID: link.CreatedByID,
+		ExpiresAt:   link.ExpiresAt,
+		CreatedAt:   link.CreatedAt,
+	}
+}
@@ -0,0 +1,48 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/zayyadi/trello/models"
+)
+
+type CreatePersonalAccessTokenRequest struct {
+	Name     string `json:"name" binding:"required,min=1,max=100"`
+	ReadOnly bool   `json:"readOnly"`
+	BoardID  *uint  `json:"boardID"` // Restrict the token to one board
+	// ExpiresInDays sets the token lifetime. Omit it (or use 0) for a token that never expires.
+	ExpiresInDays int `json:"expiresInDays" binding:"min=0,max=3650"`
+}
+
+type PersonalAccessTokenResponse struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	ReadOnly   bool       `json:"readOnly"`
+	BoardID    *uint      `json:"boardID,omitempty"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// CreatedPersonalAccessTokenResponse includes the raw token, which is only ever shown once.
+type CreatedPersonalAccessTokenResponse struct {
+	PersonalAccessTokenResponse
+	Token string `json:"token"`
+}
+
+func MapPersonalAccessTokenToResponse(token *models.PersonalAccessToken) PersonalAccessTokenResponse {
+	if token == nil {
+		return PersonalAccessTokenResponse{}
+	}
+	return PersonalAccessTokenResponse{
+		ID:         token.ID,
+		Name:       token.Name,
+		Prefix:     token.Prefix,
+		ReadOnly:   token.ReadOnly,
+		BoardID:    token.BoardID,
+		ExpiresAt:  token.ExpiresAt,
+		LastUsedAt: token.LastUsedAt,
+		CreatedAt:  token.CreatedAt,
+	}
+}
@@ -0,0 +1,96 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/zayyadi/trello/models"
+)
+
+// Workspace DTOs
+type CreateWorkspaceRequest struct {
+	Name        string `json:"name" binding:"required,min=1,max=100"`
+	Description string `json:"description" binding:"max=255"`
+}
+
+type UpdateWorkspaceRequest struct {
+	Name        *string `json:"name" binding:"omitempty,min=1,max=100"`
+	Description *string `json:"description" binding:"omitempty,max=255"`
+}
+
+type WorkspaceResponse struct {
+	ID          uint                      `json:"id"`
+	Name        string                    `json:"name"`
+	Description string                    `json:"description"`
+	Members     []WorkspaceMemberResponse `json:"members,omitempty"`
+	CreatedAt   time.Time                 `json:"createdAt"`
+	UpdatedAt   time.Time                 `json:"updatedAt"`
+}
+
+// Workspace Member DTOs
+type AddWorkspaceMemberRequest struct {
+	Email  *string              `json:"email"`                                       // Add by email
+	UserID *uint                `json:"userID"`                                      // Or by UserID
+	Role   models.WorkspaceRole `json:"role" binding:"omitempty,oneof=admin member"` // Defaults to member
+}
+
+type UpdateWorkspaceMemberRoleRequest struct {
+	Role models.WorkspaceRole `json:"role" binding:"required,oneof=admin member"`
+}
+
+type WorkspaceMemberResponse struct {
+	WorkspaceID uint                 `json:"workspaceID"`
+	UserID      uint                 `json:"userID"`
+	User        UserResponse         `json:"user"`
+	Role        models.WorkspaceRole `json:"role"`
+	CreatedAt   time.Time            `json:"createdAt"`
+}
+
+// CreateWorkspaceBoardRequest creates a board inside a workspace.
+type CreateWorkspaceBoardRequest struct {
+	Name        string                 `json:"name" binding:"required,min=1,max=100"`
+	Description string                 `json:"description" binding:"max=255"`
+	Visibility  models.BoardVisibility `json:"visibility" binding:"omitempty,oneof=private workspace public"` // Defaults to workspace
+}
+
+// MoveBoardToWorkspaceRequest puts a board into a workspace.
+type MoveBoardToWorkspaceRequest struct {
+	WorkspaceID uint                   `json:"workspaceID" binding:"required"`
+	Visibility  models.BoardVisibility `json:"visibility" binding:"omitempty,oneof=private workspace public"` // Defaults to private
+}
+
+type UpdateBoardVisibilityRequest struct {
+	Visibility models.BoardVisibility `json:"visibility" binding:"required,oneof=private workspace public"`
+}
+
+// MapWorkspaceToResponse maps models.Workspace to WorkspaceResponse.
+// Members are included when they were loaded.
+func MapWorkspaceToResponse(workspace *models.Workspace) WorkspaceResponse {
+	if workspace == nil {
+		return WorkspaceResponse{}
+	}
+	resp := WorkspaceResponse{
+		ID:          workspace.ID,
+		Name:        workspace.Name,
+		Description: workspace.Description,
+		CreatedAt:   workspace.CreatedAt,
+		UpdatedAt:   workspace.UpdatedAt,
+	}
+	for _, m := range workspace.Members {
+		resp.Members = append(resp.Members, MapWorkspaceMemberToResponse(&m))
+	}
+	return resp
+}
+
+// MapWorkspaceMemberToResponse maps models.WorkspaceMember to WorkspaceMemberResponse.
+func MapWorkspaceMemberToResponse(member *models.WorkspaceMember) WorkspaceMemberResponse {
+	if member == nil {
+		return WorkspaceMemberResponse{}
+	}
+	return WorkspaceMemberResponse{
+		WorkspaceID: member.WorkspaceID,
+		UserID:      member.UserID,
+		User:        MapUserToResponse(&member.User),
+		Role:        member.Role,
+		CreatedAt:   member.CreatedAt,
+	}
+}
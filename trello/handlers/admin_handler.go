@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zayyadi/trello/dto"
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/services"
+)
+
+// AdminHandler serves site administration routes. Access is checked by middleware.RequireAdmin.
+type AdminHandler struct {
+	loginThrottle *services.LoginThrottleService
+	auditService  *services.AuditService
+}
+
+func NewAdminHandler(loginThrottle *services.LoginThrottleService, auditService *services.AuditService) *AdminHandler {
+	return &AdminHandler{loginThrottle: loginThrottle, auditService: auditService}
+}
+
+func (h *AdminHandler) ListLockouts(c *gin.Context) {
+	throttles, err := h.loginThrottle.Locked()
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+
+	responses := make([]dto.LoginLockoutResponse, len(throttles))
+	for i := range throttles {
+		responses[i] = dto.MapLoginThrottleToResponse(&throttles[i])
+	}
+	RespondWithSuccess(c, http.StatusOK, "Login lockouts retrieved successfully", responses)
+}
+
+func (h *AdminHandler) Unlock(c *gin.Context) {
+	adminID, _ := c.Get("userID")
+
+	var req dto.UnlockLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	if err := h.loginThrottle.Unlock(req.Scope, req.Key, adminID.(uint)); err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Lockout cleared successfully", nil)
+}
+
+func (h *AdminHandler) ListAuditEvents(c *gin.Context) {
+	var userID *uint
+	if value := c.Query("userID"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			RespondWithError(c, http.StatusBadRequest, "Invalid user ID format")
+			return
+		}
+		uid := uint(id)
+		userID = &uid
+	}
+	limit := 0
+	if value := c.Query("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil {
+			RespondWithError(c, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+	}
+
+	events, err := h.auditService.List(models.AuditEventType(c.Query("type")), userID, limit)
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+
+	responses := make([]dto.AuditEventResponse, len(events))
+	for i := range events {
+		responses[i] = dto.MapAuditEventToResponse(&events[i])
+	}
+	RespondWithSuccess(c, http.StatusOK, "Audit events retrieved successfully", responses)
+}
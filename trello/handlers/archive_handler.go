@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zayyadi/trello/dto"
+	"github.com/zayyadi/trello/services"
+)
+
+type ArchiveHandler struct {
+	archiveService *services.ArchiveService
+}
+
+func NewArchiveHandler(archiveService *services.ArchiveService) *ArchiveHandler {
+	return &ArchiveHandler{archiveService: archiveService}
+}
+
+func (h *ArchiveHandler) ArchiveBoard(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	boardID, ok := uintParam(c, "boardID", "board ID")
+	if !ok {
+		return
+	}
+
+	board, err := h.archiveService.ArchiveBoard(boardID, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Board archived successfully", dto.MapBoardToResponse(board, true, false))
+}
+
+func (h *ArchiveHandler) UnarchiveBoard(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	boardID, ok := uintParam(c, "boardID", "board ID")
+	if !ok {
+		return
+	}
+
+	board, err := h.archiveService.UnarchiveBoard(boardID, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Board unarchived successfully", dto.MapBoardToResponse(board, true, false))
+}
+
+// GetArchivedBoards lists the current user's archived boards.
+func (h *ArchiveHandler) GetArchivedBoards(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	boards, err := h.archiveService.GetArchivedBoards(userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	responses := make([]dto.BoardResponse, len(boards))
+	for i := range boards {
+		responses[i] = dto.MapBoardToResponse(&boards[i], true, false)
+	}
+	RespondWithSuccess(c, http.StatusOK, "Archived boards retrieved successfully", responses)
+}
+
+func (h *ArchiveHandler) ArchiveList(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	listID, ok := uintParam(c, "listID", "list ID")
+	if !ok {
+		return
+	}
+
+	list, err := h.archiveService.ArchiveList(listID, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "List archived successfully", dto.MapListToResponse(list, false))
+}
+
+func (h *ArchiveHandler) UnarchiveList(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	listID, ok := uintParam(c, "listID", "list ID")
+	if !ok {
+		return
+	}
+
+	list, err := h.archiveService.UnarchiveList(listID, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "List unarchived successfully", dto.MapListToResponse(list, true))
+}
+
+func (h *ArchiveHandler) ArchiveCard(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	cardID, ok := uintParam(c, "cardID", "card ID")
+	if !ok {
+		return
+	}
+
+	card, err := h.archiveService.ArchiveCard(cardID, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Card archived successfully", dto.MapCardToResponse(card, true))
+}
+
+func (h *ArchiveHandler) UnarchiveCard(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	cardID, ok := uintParam(c, "cardID", "card ID")
+	if !ok {
+		return
+	}
+
+	card, err := h.archiveService.UnarchiveCard(cardID, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Card unarchived successfully", dto.MapCardToResponse(card, true))
+}
+
+// GetArchivedItems lists the board's archived lists and cards.
+func (h *ArchiveHandler) GetArchivedItems(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	boardID, ok := uintParam(c, "boardID", "board ID")
+	if !ok {
+		return
+	}
+
+	items, err := h.archiveService.GetArchivedItems(boardID, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Archived items retrieved successfully", dto.MapArchivedItemsToResponse(items.Lists, items.Cards))
+}
+
+// GetTrash lists the board's deleted lists and cards.
+func (h *ArchiveHandler) GetTrash(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	boardID, ok := uintParam(c, "boardID", "board ID")
+	if !ok {
+		return
+	}
+
+	items, err := h.archiveService.GetTrash(boardID, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Trash retrieved successfully", dto.MapTrashToResponse(items.Lists, items.Cards, h.archiveService.Retention()))
+}
+
+func (h *ArchiveHandler) RestoreList(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	boardID, ok := uintParam(c, "boardID", "board ID")
+	if !ok {
+		return
+	}
+	listID, ok := uintParam(c, "listID", "list ID")
+	if !ok {
+		return
+	}
+
+	list, err := h.archiveService.RestoreList(boardID, listID, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "List restored successfully", dto.MapListToResponse(list, true))
+}
+
+func (h *ArchiveHandler) RestoreCard(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	boardID, ok := uintParam(c, "boardID", "board ID")
+	if !ok {
+		return
+	}
+	cardID, ok := uintParam(c, "cardID", "card ID")
+	if !ok {
+		return
+	}
+
+	card, err := h.archiveService.RestoreCard(boardID, cardID, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Card restored successfully", dto.MapCardToResponse(card, true))
+}
+
+// GetDeletedBoards lists the boards the current user deleted that are still in the trash.
+func (h *ArchiveHandler) GetDeletedBoards(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	boards, err := h.archiveService.GetDeletedBoards(userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	responses := make([]dto.TrashedBoardResponse, len(boards))
+	for i := range boards {
+		responses[i] = dto.MapTrashedBoardToResponse(&boards[i], h.archiveService.Retention())
+	}
+	RespondWithSuccess(c, http.StatusOK, "Deleted boards retrieved successfully", responses)
+}
+
+func (h *ArchiveHandler) RestoreBoard(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	boardID, ok := uintParam(c, "boardID", "board ID")
+	if !ok {
+		return
+	}
+
+	board, err := h.archiveService.RestoreBoard(boardID, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Board restored successfully", dto.MapBoardToResponse(board, true, false))
+}
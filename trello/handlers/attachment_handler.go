@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zayyadi/trello/dto"
+	"github.com/zayyadi/trello/services"
+)
+
+// multipartOverhead allows for the form encoding around an uploaded file.
+const multipartOverhead = 1 << 20
+
+type AttachmentHandler struct {
+	attachmentService *services.AttachmentService
+}
+
+func NewAttachmentHandler(attachmentService *services.AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{attachmentService: attachmentService}
+}
+
+func (h *AttachmentHandler) GetAttachments(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	cardID, ok := uintParam(c, "cardID", "card ID")
+	if !ok {
+		return
+	}
+
+	attachments, err := h.attachmentService.GetAttachments(cardID, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	responses := make([]dto.AttachmentResponse, len(attachments))
+	for i := range attachments {
+		responses[i] = dto.MapAttachmentToResponse(&attachments[i])
+	}
+	RespondWithSuccess(c, http.StatusOK, "Attachments retrieved successfully", responses)
+}
+
+// UploadAttachment takes the file from the "file" field of a multipart form.
+func (h *AttachmentHandler) UploadAttachment(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	cardID, ok := uintParam(c, "cardID", "card ID")
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.attachmentService.MaxSize()+multipartOverhead)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			HandleServiceError(c, services.ErrAttachmentTooLarge)
+			return
+		}
+		RespondWithError(c, http.StatusBadRequest, "Invalid request: expected a multipart form with a \"file\" field")
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+	defer file.Close()
+
+	attachment, err := h.attachmentService.UploadAttachment(cardID, fileHeader.Filename, file, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusCreated, "Attachment uploaded successfully", dto.MapAttachmentToResponse(attachment))
+}
+
+func (h *AttachmentHandler) DownloadAttachment(c *gin.Context) {
+	h.serveAttachment(c, false)
+}
+
+func (h *AttachmentHandler) GetThumbnail(c *gin.Context) {
+	h.serveAttachment(c, true)
+}
+
+func (h *AttachmentHandler) DeleteAttachment(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	cardID, attachmentID, ok := attachmentParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.attachmentService.DeleteAttachment(cardID, attachmentID, userID.(uint)); err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Attachment deleted successfully", nil)
+}
+
+func (h *AttachmentHandler) SetCover(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	cardID, ok := uintParam(c, "cardID", "card ID")
+	if !ok {
+		return
+	}
+
+	var req dto.SetCardCoverRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	card, err := h.attachmentService.SetCover(cardID, &req.AttachmentID, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Card cover updated successfully", dto.MapCardToResponse(card, true))
+}
+
+func (h *AttachmentHandler) RemoveCover(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	cardID, ok := uintParam(c, "cardID", "card ID")
+	if !ok {
+		return
+	}
+
+	card, err := h.attachmentService.SetCover(cardID, nil, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Card cover removed successfully", dto.MapCardToResponse(card, true))
+}
+
+// serveAttachment streams an attachment's file or thumbnail. Images are shown
+// inline; everything else is sent as a download so browsers never render it.
+func (h *AttachmentHandler) serveAttachment(c *gin.Context, thumbnail bool) {
+	userID, _ := c.Get("userID")
+	cardID, attachmentID, ok := attachmentParams(c)
+	if !ok {
+		return
+	}
+
+	attachment, body, info, err := h.attachmentService.OpenAttachment(cardID, attachmentID, thumbnail, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	defer body.Close()
+
+	disposition := "attachment"
+	if attachment.IsImage() {
+		disposition = "inline"
+	}
+	contentType := info.ContentType
+	if contentType == "" {
+		contentType = attachment.ContentType
+	}
+	c.DataFromReader(http.StatusOK, info.Size, contentType, body, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType(disposition, map[string]string{"filename": attachment.Filename}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// attachmentParams reads the card and attachment IDs from the path.
+func attachmentParams(c *gin.Context) (cardID, attachmentID uint, ok bool) {
+	if cardID, ok = uintParam(c, "cardID", "card ID"); !ok {
+		return 0, 0, false
+	}
+	if attachmentID, ok = uintParam(c, "attachmentID", "attachment ID"); !ok {
+		return 0, 0, false
+	}
+	return cardID, attachmentID, true
+}
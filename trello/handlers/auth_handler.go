@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/zayyadi/trello/dto" // Import new dto package
+	"github.com/zayyadi/trello/models"
 	"github.com/zayyadi/trello/services"
 
 	"github.com/gin-gonic/gin"
@@ -24,14 +26,18 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	user, token, err := h.authService.Register(req.Username, req.Email, req.Password)
+	user, tokens, err := h.authService.Register(req.Username, req.Email, req.Password)
 	if err != nil {
 		HandleServiceError(c, err)
 		return
 	}
+	if tokens == nil {
+		// Email verification is required before the user can log in
+		RespondWithSuccess(c, http.StatusCreated, "User registered successfully. Check your email to verify your address before logging in", dto.MapUserToResponse(user))
+		return
+	}
 
-	userResp := dto.MapUserToResponse(user)                                                                                   // Use dto.MapUserToResponse
-	RespondWithSuccess(c, http.StatusCreated, "User registered successfully", dto.AuthResponse{User: userResp, Token: token}) // Use dto.AuthResponse
+	RespondWithSuccess(c, http.StatusCreated, "User registered successfully", mapAuthResponse(user, tokens))
 }
 
 func (h *AuthHandler) Login(c *gin.Context) {
@@ -41,14 +47,147 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	user, token, err := h.authService.Login(req.Email, req.Password)
+	user, tokens, err := h.authService.Login(req.Email, req.Password, c.ClientIP())
+	if respondWithTwoFactorChallenge(c, err) {
+		return
+	}
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, http.StatusOK, "Login successful", mapAuthResponse(user, tokens))
+}
+
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req dto.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	tokens, err := h.authService.Refresh(req.RefreshToken)
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, http.StatusOK, "Token refreshed successfully", dto.TokenResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    tokens.ExpiresAt,
+	})
+}
+
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req dto.LogoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	if err := h.authService.Logout(req.RefreshToken, req.AllSessions); err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, http.StatusOK, "Logged out successfully", nil)
+}
+
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req dto.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	if err := h.authService.RequestPasswordReset(req.Email); err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+
+	// Same response whether or not the account exists
+	RespondWithSuccess(c, http.StatusOK, "If an account exists for that email, a password reset link has been sent", nil)
+}
+
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req dto.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	if err := h.authService.ResetPassword(req.Token, req.NewPassword); err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, http.StatusOK, "Password has been reset, please log in again", nil)
+}
+
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req dto.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	user, err := h.authService.VerifyEmail(req.Token)
 	if err != nil {
 		HandleServiceError(c, err)
 		return
 	}
 
-	userResp := dto.MapUserToResponse(user)                                                                  // Use dto.MapUserToResponse
-	RespondWithSuccess(c, http.StatusOK, "Login successful", dto.AuthResponse{User: userResp, Token: token}) // Use dto.AuthResponse
+	RespondWithSuccess(c, http.StatusOK, "Email verified successfully", dto.MapUserToResponse(user))
+}
+
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	var req dto.ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	if err := h.authService.ResendVerificationEmail(req.Email); err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+
+	// Same response whether or not the account exists
+	RespondWithSuccess(c, http.StatusOK, "If an unverified account exists for that email, a verification link has been sent", nil)
+}
+
+// JWKS serves the public keys access tokens are signed with, in the standard
+// JSON Web Key Set format rather than the usual response envelope.
+func (h *AuthHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.authService.JWKS())
+}
+
+// mapAuthResponse builds the login/registration payload from the user and their new tokens.
+func mapAuthResponse(user *models.User, tokens *services.AuthTokens) dto.AuthResponse {
+	return dto.AuthResponse{
+		User:         dto.MapUserToResponse(user),
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    tokens.ExpiresAt,
+	}
 }
 
 // MapUserToResponse function is now in dto/auth_dto.go and will be removed from here.
+
+// respondWithTwoFactorChallenge answers a login that still needs a second factor
+// and reports whether err was one.
+func respondWithTwoFactorChallenge(c *gin.Context, err error) bool {
+	var challenge *services.TwoFactorRequiredError
+	if !errors.As(err, &challenge) {
+		return false
+	}
+	// The first factor was right; the client must now call /auth/login/2fa with a code
+	RespondWithSuccess(c, http.StatusOK, "Two-factor authentication code required", dto.TwoFactorChallengeResponse{
+		TwoFactorRequired: true,
+		ChallengeToken:    challenge.ChallengeToken,
+		ExpiresAt:         challenge.ExpiresAt,
+	})
+	return true
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zayyadi/trello/dto"
+	"github.com/zayyadi/trello/repositories"
+	"github.com/zayyadi/trello/services"
+)
+
+type BoardCopyHandler struct {
+	copyService *services.BoardCopyService
+}
+
+func NewBoardCopyHandler(copyService *services.BoardCopyService) *BoardCopyHandler {
+	return &BoardCopyHandler{copyService: copyService}
+}
+
+// CopyBoard copies a board, or starts a new board from a template. The body is optional.
+func (h *BoardCopyHandler) CopyBoard(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	boardID, ok := uintParam(c, "boardID", "board ID")
+	if !ok {
+		return
+	}
+
+	var req dto.CopyBoardRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) { // No body copies just the lists
+		RespondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	opts := repositories.BoardCopyOptions{
+		Cards:         req.IncludeCards,
+		Collaborators: req.IncludeCollaborators,
+		Comments:      req.IncludeComments,
+	}
+	board, err := h.copyService.CopyBoard(boardID, req.Name, opts, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusCreated, "Board copied successfully", dto.MapBoardToResponse(board, true, false))
+}
+
+func (h *BoardCopyHandler) UpdateTemplate(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	boardID, ok := uintParam(c, "boardID", "board ID")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	board, err := h.copyService.SetTemplate(boardID, *req.IsTemplate, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Board template setting updated successfully", dto.MapBoardToResponse(board, true, false))
+}
+
+// GetTemplates lists the templates the current user can start boards from.
+func (h *BoardCopyHandler) GetTemplates(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	boards, err := h.copyService.GetTemplates(userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	responses := make([]dto.BoardResponse, len(boards))
+	for i := range boards {
+		responses[i] = dto.MapBoardToResponse(&boards[i], true, false)
+	}
+	RespondWithSuccess(c, http.StatusOK, "Board templates retrieved successfully", responses)
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"log"
 	"net/http"
 	"strconv"
 
@@ -10,11 +11,12 @@ import (
 )
 
 type BoardHandler struct {
-	boardService services.BoardServiceInterface // Use interface type
+	boardService      services.BoardServiceInterface // Use interface type
+	preferenceService *services.BoardPreferenceService
 }
 
-func NewBoardHandler(boardService services.BoardServiceInterface) *BoardHandler { // Use interface type
-	return &BoardHandler{boardService: boardService}
+func NewBoardHandler(boardService services.BoardServiceInterface, preferenceService *services.BoardPreferenceService) *BoardHandler { // Use interface type
+	return &BoardHandler{boardService: boardService, preferenceService: preferenceService}
 }
 
 func (h *BoardHandler) CreateBoard(c *gin.Context) {
@@ -54,6 +56,10 @@ func (h *BoardHandler) GetBoardByID(c *gin.Context) {
 		HandleServiceError(c, err)
 		return
 	}
+	// Remember the visit for the user's recently viewed boards; failing to doesn't fail the request
+	if err := h.preferenceService.RecordView(fullBoard.ID, userID.(uint)); err != nil {
+		log.Printf("ERROR [BoardPreferences]: failed to record view of board %d by user %d: %v", fullBoard.ID, userID.(uint), err)
+	}
 	// Manually fetch lists if not preloaded by GetBoardByID (depends on service implementation)
 	// Assuming lists are not preloaded by default in BoardService.GetBoardByID for this specific path
 	// but GetListsByBoardID service method would be used.
@@ -61,18 +67,87 @@ func (h *BoardHandler) GetBoardByID(c *gin.Context) {
 	RespondWithSuccess(c, http.StatusOK, "Board retrieved successfully", dto.MapBoardToResponse(fullBoard, true, true)) // Use dto mapper
 }
 
+// GetBoardsForUser lists the user's boards, starred and recently viewed ones first.
 func (h *BoardHandler) GetBoardsForUser(c *gin.Context) {
 	userID, _ := c.Get("userID")
-	boards, err := h.boardService.GetBoardsForUser(userID.(uint))
+	boards, err := h.preferenceService.GetBoardsForUser(userID.(uint))
 	if err != nil {
 		HandleServiceError(c, err)
 		return
 	}
-	var boardResponses []dto.BoardResponse // Use dto type
-	for _, b := range boards {
-		boardResponses = append(boardResponses, dto.MapBoardToResponse(&b, true, false)) // Use dto mapper
+	RespondWithSuccess(c, http.StatusOK, "Boards retrieved successfully", mapUserBoards(boards))
+}
+
+// GetRecentBoards lists the boards the user viewed most recently, for a "recently viewed" section.
+func (h *BoardHandler) GetRecentBoards(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	limit := 0
+	if value := c.Query("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil {
+			RespondWithError(c, http.StatusBadRequest, "Invalid limit")
+			return
+		}
 	}
-	RespondWithSuccess(c, http.StatusOK, "Boards retrieved successfully", boardResponses)
+
+	boards, err := h.preferenceService.GetRecentBoards(userID.(uint), limit)
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Recent boards retrieved successfully", mapUserBoards(boards))
+}
+
+func (h *BoardHandler) StarBoard(c *gin.Context) {
+	h.setStarred(c, true, "Board starred successfully")
+}
+
+func (h *BoardHandler) UnstarBoard(c *gin.Context) {
+	h.setStarred(c, false, "Board unstarred successfully")
+}
+
+func (h *BoardHandler) setStarred(c *gin.Context, starred bool, message string) {
+	userID, _ := c.Get("userID")
+	boardID, ok := uintParam(c, "boardID", "board ID")
+	if !ok {
+		return
+	}
+
+	if err := h.preferenceService.SetStarred(boardID, starred, userID.(uint)); err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, message, nil)
+}
+
+// SetBoardOrder sets the order of the user's own board list.
+func (h *BoardHandler) SetBoardOrder(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var req dto.SetBoardOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	if err := h.preferenceService.SetBoardOrder(req.BoardIDs, userID.(uint)); err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	boards, err := h.preferenceService.GetBoardsForUser(userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Board order updated successfully", mapUserBoards(boards))
+}
+
+func mapUserBoards(boards []services.UserBoard) []dto.UserBoardResponse {
+	responses := make([]dto.UserBoardResponse, len(boards))
+	for i := range boards {
+		responses[i] = dto.MapUserBoardToResponse(&boards[i].Board, &boards[i].Preference)
+	}
+	return responses
 }
 
 func (h *BoardHandler) UpdateBoard(c *gin.Context) {
@@ -98,7 +173,7 @@ func (h *BoardHandler) UpdateBoard(c *gin.Context) {
 	RespondWithSuccess(c, http.StatusOK, "Board updated successfully", dto.MapBoardToResponse(board, true, false)) // Use dto mapper
 }
 
-func (h *BoardHandler) DeleteBoard(c *gin.Context) {
+func (h *BoardHandler) UpdateVisibility(c *gin.Context) {
 	userID, _ := c.Get("userID")
 	boardIDStr := c.Param("boardID")
 	boardID, err := strconv.ParseUint(boardIDStr, 10, 32)
@@ -107,15 +182,21 @@ func (h *BoardHandler) DeleteBoard(c *gin.Context) {
 		return
 	}
 
-	err = h.boardService.DeleteBoard(uint(boardID), userID.(uint))
+	var req dto.UpdateBoardVisibilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	board, err := h.boardService.UpdateVisibility(uint(boardID), req.Visibility, userID.(uint))
 	if err != nil {
 		HandleServiceError(c, err)
 		return
 	}
-	RespondWithSuccess(c, http.StatusOK, "Board deleted successfully", nil)
+	RespondWithSuccess(c, http.StatusOK, "Board visibility updated successfully", dto.MapBoardToResponse(board, true, false))
 }
 
-func (h *BoardHandler) AddMemberToBoard(c *gin.Context) {
+func (h *BoardHandler) DeleteBoard(c *gin.Context) {
 	userID, _ := c.Get("userID")
 	boardIDStr := c.Param("boardID")
 	boardID, err := strconv.ParseUint(boardIDStr, 10, 32)
@@ -124,29 +205,39 @@ func (h *BoardHandler) AddMemberToBoard(c *gin.Context) {
 		return
 	}
 
-	var req dto.AddMemberRequest // Use dto type
-	if err := c.ShouldBindJSON(&req); err != nil {
-		RespondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+	err = h.boardService.DeleteBoard(uint(boardID), userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
 		return
 	}
-	if req.Email == nil && req.UserID == nil {
-		RespondWithError(c, http.StatusBadRequest, "Either email or userID must be provided to add a member")
+	RespondWithSuccess(c, http.StatusOK, "Board deleted successfully", nil)
+}
+
+func (h *BoardHandler) RemoveMemberFromBoard(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	boardIDStr := c.Param("boardID")
+	boardID, err := strconv.ParseUint(boardIDStr, 10, 32)
+	if err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid board ID")
 		return
 	}
-	if req.Email != nil && req.UserID != nil {
-		RespondWithError(c, http.StatusBadRequest, "Provide either email or userID, not both")
+
+	memberUserIDStr := c.Param("memberUserID")
+	memberUserID, err := strconv.ParseUint(memberUserIDStr, 10, 32)
+	if err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid member user ID")
 		return
 	}
 
-	boardMember, err := h.boardService.AddMemberToBoard(uint(boardID), req.Email, req.UserID, userID.(uint))
+	err = h.boardService.RemoveMemberFromBoard(uint(boardID), uint(memberUserID), userID.(uint))
 	if err != nil {
 		HandleServiceError(c, err)
 		return
 	}
-	RespondWithSuccess(c, http.StatusCreated, "Member added to board successfully", dto.MapBoardMemberToResponse(boardMember)) // Use dto mapper
+	RespondWithSuccess(c, http.StatusOK, "Member removed from board successfully", nil)
 }
 
-func (h *BoardHandler) RemoveMemberFromBoard(c *gin.Context) {
+func (h *BoardHandler) UpdateMemberRole(c *gin.Context) {
 	userID, _ := c.Get("userID")
 	boardIDStr := c.Param("boardID")
 	boardID, err := strconv.ParseUint(boardIDStr, 10, 32)
@@ -162,12 +253,18 @@ func (h *BoardHandler) RemoveMemberFromBoard(c *gin.Context) {
 		return
 	}
 
-	err = h.boardService.RemoveMemberFromBoard(uint(boardID), uint(memberUserID), userID.(uint))
+	var req dto.UpdateMemberRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	boardMember, err := h.boardService.UpdateMemberRole(uint(boardID), uint(memberUserID), req.Role, userID.(uint))
 	if err != nil {
 		HandleServiceError(c, err)
 		return
 	}
-	RespondWithSuccess(c, http.StatusOK, "Member removed from board successfully", nil)
+	RespondWithSuccess(c, http.StatusOK, "Member role updated successfully", dto.MapBoardMemberToResponse(boardMember))
 }
 
 func (h *BoardHandler) GetBoardMembers(c *gin.Context) {
@@ -195,3 +292,27 @@ func (h *BoardHandler) GetBoardMembers(c *gin.Context) {
 // Mapping functions MapBoardToResponse and MapBoardMemberToResponse are now in dto/board_dto.go
 // MapUserToResponse is in dto/auth_dto.go (which will be imported as part of dto package)
 // MapListToResponse will be in dto/list_dto.go
+
+// TransferOwnership hands the board to an existing member (owner or site admin only).
+func (h *BoardHandler) TransferOwnership(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	boardIDStr := c.Param("boardID")
+	boardID, err := strconv.ParseUint(boardIDStr, 10, 32)
+	if err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid board ID")
+		return
+	}
+
+	var req dto.TransferOwnershipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	board, err := h.boardService.TransferOwnership(uint(boardID), req.NewOwnerID, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Board ownership transferred successfully", dto.MapBoardToResponse(board, true, false))
+}
@@ -163,6 +163,31 @@ func (h *CardHandler) MoveCard(c *gin.Context) {
 	RespondWithSuccess(c, http.StatusOK, "Card moved successfully", dto.MapCardToResponse(card, true)) // Use dto mapper
 }
 
+func (h *CardHandler) ReorderCards(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	listID, ok := uintParam(c, "listID", "list ID")
+	if !ok {
+		return
+	}
+
+	var req dto.ReorderCardsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	cards, err := h.cardService.ReorderCards(listID, req.CardIDs, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	cardResponses := make([]dto.CardResponse, len(cards))
+	for i := range cards {
+		cardResponses[i] = dto.MapCardToResponse(&cards[i], true)
+	}
+	RespondWithSuccess(c, http.StatusOK, "Cards reordered successfully", cardResponses)
+}
+
 // MapCardToResponse function is now in dto/card_dto.go
 // MapUserToResponse is in dto/auth_dto.go
 
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zayyadi/trello/dto"
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/services"
+)
+
+type ChecklistHandler struct {
+	checklistService *services.ChecklistService
+}
+
+func NewChecklistHandler(checklistService *services.ChecklistService) *ChecklistHandler {
+	return &ChecklistHandler{checklistService: checklistService}
+}
+
+func (h *ChecklistHandler) GetChecklists(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	cardID, ok := uintParam(c, "cardID", "card ID")
+	if !ok {
+		return
+	}
+
+	checklists, err := h.checklistService.GetChecklists(cardID, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Checklists retrieved successfully", mapChecklists(checklists))
+}
+
+func (h *ChecklistHandler) CreateChecklist(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	cardID, ok := uintParam(c, "cardID", "card ID")
+	if !ok {
+		return
+	}
+
+	var req dto.CreateChecklistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	checklist, err := h.checklistService.CreateChecklist(cardID, req.Title, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusCreated, "Checklist created successfully", dto.MapChecklistToResponse(checklist))
+}
+
+func (h *ChecklistHandler) UpdateChecklist(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	cardID, checklistID, ok := checklistParams(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateChecklistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	checklist, err := h.checklistService.UpdateChecklist(cardID, checklistID, req.Title, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Checklist updated successfully", dto.MapChecklistToResponse(checklist))
+}
+
+func (h *ChecklistHandler) DeleteChecklist(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	cardID, checklistID, ok := checklistParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.checklistService.DeleteChecklist(cardID, checklistID, userID.(uint)); err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Checklist deleted successfully", nil)
+}
+
+func (h *ChecklistHandler) ReorderChecklists(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	cardID, ok := uintParam(c, "cardID", "card ID")
+	if !ok {
+		return
+	}
+
+	var req dto.ReorderChecklistsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	checklists, err := h.checklistService.ReorderChecklists(cardID, req.ChecklistIDs, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Checklists reordered successfully", mapChecklists(checklists))
+}
+
+func (h *ChecklistHandler) CreateItem(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	cardID, checklistID, ok := checklistParams(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateChecklistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	item, err := h.checklistService.CreateItem(cardID, checklistID, req.Content, req.AssignedUserID, req.DueDate, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusCreated, "Checklist item created successfully", dto.MapChecklistItemToResponse(item))
+}
+
+func (h *ChecklistHandler) UpdateItem(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	cardID, checklistID, ok := checklistParams(c)
+	if !ok {
+		return
+	}
+	itemID, ok := uintParam(c, "itemID", "checklist item ID")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateChecklistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	update := services.ChecklistItemUpdate{
+		Content:        req.Content,
+		Completed:      req.Completed,
+		AssignedUserID: req.AssignedUserID,
+		DueDate:        req.DueDate,
+		ClearDueDate:   req.ClearDueDate,
+	}
+	item, err := h.checklistService.UpdateItem(cardID, checklistID, itemID, update, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Checklist item updated successfully", dto.MapChecklistItemToResponse(item))
+}
+
+func (h *ChecklistHandler) DeleteItem(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	cardID, checklistID, ok := checklistParams(c)
+	if !ok {
+		return
+	}
+	itemID, ok := uintParam(c, "itemID", "checklist item ID")
+	if !ok {
+		return
+	}
+
+	if err := h.checklistService.DeleteItem(cardID, checklistID, itemID, userID.(uint)); err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Checklist item deleted successfully", nil)
+}
+
+func (h *ChecklistHandler) ReorderItems(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	cardID, checklistID, ok := checklistParams(c)
+	if !ok {
+		return
+	}
+
+	var req dto.ReorderChecklistItemsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	checklist, err := h.checklistService.ReorderItems(cardID, checklistID, req.ItemIDs, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Checklist items reordered successfully", dto.MapChecklistToResponse(checklist))
+}
+
+// checklistParams reads the card and checklist IDs from the path.
+func checklistParams(c *gin.Context) (cardID, checklistID uint, ok bool) {
+	if cardID, ok = uintParam(c, "cardID", "card ID"); !ok {
+		return 0, 0, false
+	}
+	if checklistID, ok = uintParam(c, "checklistID", "checklist ID"); !ok {
+		return 0, 0, false
+	}
+	return cardID, checklistID, true
+}
+
+func mapChecklists(checklists []models.Checklist) []dto.ChecklistResponse {
+	responses := make([]dto.ChecklistResponse, len(checklists))
+	for i := range checklists {
+		responses[i] = dto.MapChecklistToResponse(&checklists[i])
+	}
+	return responses
+}
@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zayyadi/trello/dto"
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/services"
+)
+
+type InvitationHandler struct {
+	invitationService *services.InvitationService
+}
+
+func NewInvitationHandler(invitationService *services.InvitationService) *InvitationHandler {
+	return &InvitationHandler{invitationService: invitationService}
+}
+
+// CreateInvitation emails an invitation to join the board.
+func (h *InvitationHandler) CreateInvitation(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	boardID, ok := uintParam(c, "boardID", "board ID")
+	if !ok {
+		return
+	}
+
+	var req dto.CreateBoardInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	invitation, err := h.invitationService.InviteToBoard(boardID, req.Email, req.Role, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusCreated, "Invitation sent successfully", dto.MapBoardInvitationToResponse(invitation))
+}
+
+// AddMember invites someone to the board by email address or user ID. It takes
+// the place of adding members directly, so they get to accept or decline.
+func (h *InvitationHandler) AddMember(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	boardID, ok := uintParam(c, "boardID", "board ID")
+	if !ok {
+		return
+	}
+
+	var req dto.AddMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+	if req.Email == nil && req.UserID == nil {
+		RespondWithError(c, http.StatusBadRequest, "Either email or userID must be provided to invite a member")
+		return
+	}
+	if req.Email != nil && req.UserID != nil {
+		RespondWithError(c, http.StatusBadRequest, "Provide either email or userID, not both")
+		return
+	}
+
+	invitation, err := h.invitationService.InviteMember(boardID, req.Email, req.UserID, req.Role, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusCreated, "Invitation sent successfully", dto.MapBoardInvitationToResponse(invitation))
+}
+
+func (h *InvitationHandler) GetBoardInvitations(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	boardID, ok := uintParam(c, "boardID", "board ID")
+	if !ok {
+		return
+	}
+
+	invitations, err := h.invitationService.GetBoardInvitations(boardID, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Invitations retrieved successfully", mapInvitations(invitations))
+}
+
+func (h *InvitationHandler) RevokeInvitation(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	boardID, ok := uintParam(c, "boardID", "board ID")
+	if !ok {
+		return
+	}
+	invitationID, ok := uintParam(c, "invitationID", "invitation ID")
+	if !ok {
+		return
+	}
+
+	if err := h.invitationService.RevokeInvitation(boardID, invitationID, userID.(uint)); err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Invitation revoked successfully", nil)
+}
+
+// GetMyInvitations lists the pending invitations sent to the current user's email address.
+func (h *InvitationHandler) GetMyInvitations(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	invitations, err := h.invitationService.GetMyInvitations(userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Invitations retrieved successfully", mapInvitations(invitations))
+}
+
+func (h *InvitationHandler) AcceptInvitation(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	invitationID, ok := uintParam(c, "invitationID", "invitation ID")
+	if !ok {
+		return
+	}
+
+	member, err := h.invitationService.AcceptInvitation(invitationID, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Invitation accepted", dto.MapBoardMemberToResponse(member))
+}
+
+func (h *InvitationHandler) DeclineInvitation(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	invitationID, ok := uintParam(c, "invitationID", "invitation ID")
+	if !ok {
+		return
+	}
+
+	if err := h.invitationService.DeclineInvitation(invitationID, userID.(uint)); err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Invitation declined", nil)
+}
+
+// AcceptInvitationToken accepts the invitation from an emailed link.
+func (h *InvitationHandler) AcceptInvitationToken(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var req dto.InvitationTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	member, err := h.invitationService.AcceptInvitationToken(req.Token, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Invitation accepted", dto.MapBoardMemberToResponse(member))
+}
+
+// DeclineInvitationToken declines the invitation from an emailed link.
+func (h *InvitationHandler) DeclineInvitationToken(c *gin.Context) {
+	var req dto.InvitationTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	if err := h.invitationService.DeclineInvitationToken(req.Token); err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Invitation declined", nil)
+}
+
+// CreateInviteLink creates a shareable link for joining the board.
+func (h *InvitationHandler) CreateInviteLink(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	boardID, ok := uintParam(c, "boardID", "board ID")
+	if !ok {
+		return
+	}
+
+	var req dto.CreateBoardInviteLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	expiresIn := time.Duration(req.ExpiresInHours) * time.Hour
+	link, token, err := h.invitationService.CreateInviteLink(boardID, req.Role, req.MaxUses, expiresIn, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusCreated, "Invite link created. Copy it now, it won't be shown again", dto.CreatedBoardInviteLinkResponse{
+		BoardInviteLinkResponse: dto.MapBoardInviteLinkToResponse(link),
+		Token:                   token,
+		URL:                     h.invitationService.InviteLinkURL(token),
+	})
+}
+
+func (h *InvitationHandler) GetInviteLinks(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	boardID, ok := uintParam(c, "boardID", "board ID")
+	if !ok {
+		return
+	}
+
+	links, err := h.invitationService.GetInviteLinks(boardID, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	responses := make([]dto.BoardInviteLinkResponse, len(links))
+	for i := range links {
+		responses[i] = dto.MapBoardInviteLinkToResponse(&links[i])
+	}
+	RespondWithSuccess(c, http.StatusOK, "Invite links retrieved successfully", responses)
+}
+
+func (h *InvitationHandler) RevokeInviteLink(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	boardID, ok := uintParam(c, "boardID", "board ID")
+	if !ok {
+		return
+	}
+	linkID, ok := uintParam(c, "linkID", "invite link ID")
+	if !ok {
+		return
+	}
+
+	if err := h.invitationService.RevokeInviteLink(boardID, linkID, userID.(uint)); err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Invite link revoked successfully", nil)
+}
+
+// JoinBoard adds the current user to a board through an invite link.
+func (h *InvitationHandler) JoinBoard(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var req dto.JoinBoardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	member, err := h.invitationService.JoinWithInviteLink(req.Token, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Joined board successfully", dto.MapBoardMemberToResponse(member))
+}
+
+func mapInvitations(invitations []models.BoardInvitation) []dto.BoardInvitationResponse {
+	responses := make([]dto.BoardInvitationResponse, len(invitations))
+	for i := range invitations {
+		responses[i] = dto.MapBoardInvitationToResponse(&invitations[i])
+	}
+	return responses
+}
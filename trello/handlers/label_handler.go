@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zayyadi/trello/dto"
+	"github.com/zayyadi/trello/services"
+)
+
+type LabelHandler struct {
+	labelService *services.LabelService
+}
+
+func NewLabelHandler(labelService *services.LabelService) *LabelHandler {
+	return &LabelHandler{labelService: labelService}
+}
+
+func (h *LabelHandler) GetLabels(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	boardID, ok := uintParam(c, "boardID", "board ID")
+	if !ok {
+		return
+	}
+
+	labels, err := h.labelService.GetLabels(boardID, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	responses := make([]dto.LabelResponse, len(labels))
+	for i := range labels {
+		responses[i] = dto.MapLabelToResponse(&labels[i])
+	}
+	RespondWithSuccess(c, http.StatusOK, "Labels retrieved successfully", responses)
+}
+
+func (h *LabelHandler) CreateLabel(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	boardID, ok := uintParam(c, "boardID", "board ID")
+	if !ok {
+		return
+	}
+
+	var req dto.CreateLabelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	label, err := h.labelService.CreateLabel(boardID, req.Name, req.Color, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusCreated, "Label created successfully", dto.MapLabelToResponse(label))
+}
+
+func (h *LabelHandler) UpdateLabel(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	boardID, ok := uintParam(c, "boardID", "board ID")
+	if !ok {
+		return
+	}
+	labelID, ok := uintParam(c, "labelID", "label ID")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateLabelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	label, err := h.labelService.UpdateLabel(boardID, labelID, req.Name, req.Color, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Label updated successfully", dto.MapLabelToResponse(label))
+}
+
+func (h *LabelHandler) DeleteLabel(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	boardID, ok := uintParam(c, "boardID", "board ID")
+	if !ok {
+		return
+	}
+	labelID, ok := uintParam(c, "labelID", "label ID")
+	if !ok {
+		return
+	}
+
+	if err := h.labelService.DeleteLabel(boardID, labelID, userID.(uint)); err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Label deleted successfully", nil)
+}
+
+// AddLabelToCard puts one of the board's labels on a card.
+func (h *LabelHandler) AddLabelToCard(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	cardID, ok := uintParam(c, "cardID", "card ID")
+	if !ok {
+		return
+	}
+
+	var req dto.AddCardLabelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	if err := h.labelService.AddLabelToCard(cardID, req.LabelID, userID.(uint)); err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Label added to card successfully", nil)
+}
+
+func (h *LabelHandler) RemoveLabelFromCard(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	cardID, ok := uintParam(c, "cardID", "card ID")
+	if !ok {
+		return
+	}
+	labelID, ok := uintParam(c, "labelID", "label ID")
+	if !ok {
+		return
+	}
+
+	if err := h.labelService.RemoveLabelFromCard(cardID, labelID, userID.(uint)); err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Label removed from card successfully", nil)
+}
@@ -85,6 +85,31 @@ func (h *ListHandler) UpdateList(c *gin.Context) {
 	RespondWithSuccess(c, http.StatusOK, "List updated successfully", dto.MapListToResponse(list, false)) // Use dto mapper
 }
 
+func (h *ListHandler) ReorderLists(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	boardID, ok := uintParam(c, "boardID", "board ID")
+	if !ok {
+		return
+	}
+
+	var req dto.ReorderListsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	lists, err := h.listService.ReorderLists(boardID, req.ListIDs, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	listResponses := make([]dto.ListResponse, len(lists))
+	for i := range lists {
+		listResponses[i] = dto.MapListToResponse(&lists[i], true)
+	}
+	RespondWithSuccess(c, http.StatusOK, "Lists reordered successfully", listResponses)
+}
+
 func (h *ListHandler) DeleteList(c *gin.Context) {
 	userID, _ := c.Get("userID")
 	listIDStr := c.Param("listID")
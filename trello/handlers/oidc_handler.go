@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/zayyadi/trello/oidc"
+	"github.com/zayyadi/trello/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	oidcFlowCookie = "oidc_flow"
+	oidcCookiePath = "/auth/oidc"
+	oidcFlowMaxAge = 10 * 60 // Seconds the user has to finish logging in at the provider
+)
+
+type OIDCHandler struct {
+	oidcService   *services.OIDCService
+	secureCookies bool // Set the Secure flag on cookies (when served over HTTPS)
+}
+
+func NewOIDCHandler(oidcService *services.OIDCService, secureCookies bool) *OIDCHandler {
+	return &OIDCHandler{oidcService: oidcService, secureCookies: secureCookies}
+}
+
+// Login redirects the browser to the identity provider.
+func (h *OIDCHandler) Login(c *gin.Context) {
+	authURL, flow, err := h.oidcService.BeginLogin(c.Request.Context())
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+
+	// SameSite=Lax so the cookie is sent on the provider's top-level redirect back to us
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcFlowCookie, flow.Encode(), oidcFlowMaxAge, oidcCookiePath, "", h.secureCookies, true)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback completes the login after the provider redirects back with a code.
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	encoded, cookieErr := c.Cookie(oidcFlowCookie)
+	// The flow state is single use, clear it whatever happens next
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcFlowCookie, "", -1, oidcCookiePath, "", h.secureCookies, true)
+
+	if providerErr := c.Query("error"); providerErr != "" {
+		// e.g. the user declined consent at the provider
+		RespondWithError(c, http.StatusUnauthorized, "Single sign-on was cancelled or refused: "+providerErr)
+		return
+	}
+
+	var flow *oidc.FlowState
+	if cookieErr == nil {
+		flow, _ = oidc.DecodeFlowState(encoded) // A nil flow is reported as a state mismatch
+	}
+
+	user, tokens, err := h.oidcService.CompleteLogin(c.Request.Context(), flow, c.Query("state"), c.Query("code"))
+	if respondWithTwoFactorChallenge(c, err) {
+		return
+	}
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, http.StatusOK, "Login successful", mapAuthResponse(user, tokens))
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zayyadi/trello/dto"
+	"github.com/zayyadi/trello/services"
+)
+
+// PublicBoardHandler serves public and link-shared boards to anyone, and lets
+// board admins manage the share links.
+type PublicBoardHandler struct {
+	publicBoardService *services.PublicBoardService
+}
+
+func NewPublicBoardHandler(publicBoardService *services.PublicBoardService) *PublicBoardHandler {
+	return &PublicBoardHandler{publicBoardService: publicBoardService}
+}
+
+// GetPublicBoard shows a public board without logging in.
+func (h *PublicBoardHandler) GetPublicBoard(c *gin.Context) {
+	boardID, ok := uintParam(c, "boardID", "board ID")
+	if !ok {
+		return
+	}
+
+	board, err := h.publicBoardService.GetPublicBoard(boardID)
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Board retrieved successfully", dto.MapPublicBoardToResponse(board.Board, board.Lists))
+}
+
+// GetSharedBoard shows the board a share link is for, without logging in.
+func (h *PublicBoardHandler) GetSharedBoard(c *gin.Context) {
+	board, err := h.publicBoardService.GetSharedBoard(c.Param("token"))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Board retrieved successfully", dto.MapPublicBoardToResponse(board.Board, board.Lists))
+}
+
+// CreateShareLink creates an unlisted link for viewing the board.
+func (h *PublicBoardHandler) CreateShareLink(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	boardID, ok := uintParam(c, "boardID", "board ID")
+	if !ok {
+		return
+	}
+
+	var req dto.CreateBoardShareLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	expiresIn := time.Duration(req.ExpiresInHours) * time.Hour
+	link, token, err := h.publicBoardService.CreateShareLink(boardID, expiresIn, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusCreated, "Share link created. Copy it now, it won't be shown again", dto.CreatedBoardShareLinkResponse{
+		BoardShareLinkResponse: dto.MapBoardShareLinkToResponse(link),
+		Token:                  token,
+		URL:                    h.publicBoardService.ShareLinkURL(token),
+	})
+}
+
+func (h *PublicBoardHandler) GetShareLinks(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	boardID, ok := uintParam(c, "boardID", "board ID")
+	if !ok {
+		return
+	}
+
+	links, err := h.publicBoardService.GetShareLinks(boardID, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	responses := make([]dto.BoardShareLinkResponse, len(links))
+	for i := range links {
+		responses[i] = dto.MapBoardShareLinkToResponse(&links[i])
+	}
+	RespondWithSuccess(c, http.StatusOK, "Share links retrieved successfully", responses)
+}
+
+func (h *PublicBoardHandler) RevokeShareLink(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	boardID, ok := uintParam(c, "boardID", "board ID")
+	if !ok {
+		return
+	}
+	linkID, ok := uintParam(c, "linkID", "share link ID")
+	if !ok {
+		return
+	}
+
+	if err := h.publicBoardService.RevokeShareLink(boardID, linkID, userID.(uint)); err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Share link revoked successfully", nil)
+}
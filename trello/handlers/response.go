@@ -3,7 +3,9 @@ package handlers
 import (
 	"errors"
 	"log" // Import log package
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/zayyadi/trello/services"
@@ -45,12 +47,64 @@ func HandleServiceError(c *gin.Context, err error) {
 	case errors.Is(err, services.ErrInvalidCredentials):
 		log.Printf("INFO [ServiceError]: InvalidCredentials: %v (Request: %s %s)", err, method, path)
 		RespondWithError(c, http.StatusUnauthorized, "Invalid email or password")
+	case errors.Is(err, services.ErrInvalidRefreshToken):
+		log.Printf("INFO [ServiceError]: InvalidRefreshToken: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusUnauthorized, "Invalid or expired refresh token")
+	case errors.Is(err, services.ErrSessionRevoked):
+		log.Printf("INFO [ServiceError]: SessionRevoked: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusUnauthorized, "Session has been revoked, please log in again")
+	case errors.Is(err, services.ErrInvalidResetToken):
+		log.Printf("INFO [ServiceError]: InvalidResetToken: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusBadRequest, "Invalid or expired password reset link")
+	case errors.Is(err, services.ErrInvalidVerifyToken):
+		log.Printf("INFO [ServiceError]: InvalidVerifyToken: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusBadRequest, "Invalid or expired email verification link")
+	case errors.Is(err, services.ErrEmailNotVerified):
+		log.Printf("INFO [ServiceError]: EmailNotVerified: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusForbidden, "Email address has not been verified")
+	case errors.Is(err, services.ErrOIDCStateMismatch):
+		log.Printf("INFO [ServiceError]: OIDCStateMismatch: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusBadRequest, "Login attempt expired or was tampered with, please try again")
+	case errors.Is(err, services.ErrOIDCLoginFailed):
+		log.Printf("WARN [ServiceError]: OIDCLoginFailed: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusUnauthorized, "Single sign-on failed")
+	case errors.Is(err, services.ErrAccessTokenNotFound):
+		log.Printf("INFO [ServiceError]: AccessTokenNotFound: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusNotFound, "Personal access token not found")
+	case errors.Is(err, services.ErrInvalidAccessToken):
+		log.Printf("INFO [ServiceError]: InvalidAccessToken: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusUnauthorized, "Invalid, expired or revoked personal access token")
+	case errors.Is(err, services.ErrInvalidTwoFactorCode):
+		log.Printf("INFO [ServiceError]: InvalidTwoFactorCode: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusUnauthorized, "Invalid two-factor authentication code")
+	case errors.Is(err, services.ErrInvalidChallengeToken):
+		log.Printf("INFO [ServiceError]: InvalidChallengeToken: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusUnauthorized, "Login challenge is invalid or expired, please log in again")
+	case errors.Is(err, services.ErrTwoFactorAlreadyEnabled):
+		log.Printf("INFO [ServiceError]: TwoFactorAlreadyEnabled: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusConflict, "Two-factor authentication is already enabled")
+	case errors.Is(err, services.ErrTwoFactorNotEnabled):
+		log.Printf("INFO [ServiceError]: TwoFactorNotEnabled: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusBadRequest, "Two-factor authentication is not enabled")
+	case errors.Is(err, services.ErrLoginLocked):
+		log.Printf("INFO [ServiceError]: LoginLocked: %v (Request: %s %s)", err, method, path)
+		var locked *services.LoginLockedError
+		if errors.As(err, &locked) {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+		}
+		RespondWithError(c, http.StatusTooManyRequests, "Too many failed login attempts, please try again later")
+	case errors.Is(err, services.ErrLockoutNotFound):
+		log.Printf("INFO [ServiceError]: LockoutNotFound: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusNotFound, "No lockout found for that account or IP address")
 	case errors.Is(err, services.ErrEmailExists):
 		log.Printf("INFO [ServiceError]: EmailExists: %v (Request: %s %s)", err, method, path)
 		RespondWithError(c, http.StatusConflict, "Email already exists")
 	case errors.Is(err, services.ErrUsernameExists):
 		log.Printf("INFO [ServiceError]: UsernameExists: %v (Request: %s %s)", err, method, path)
 		RespondWithError(c, http.StatusConflict, "Username already exists")
+	case errors.Is(err, services.ErrIncorrectPassword):
+		log.Printf("INFO [ServiceError]: IncorrectPassword: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusForbidden, "Current password is incorrect")
 	case errors.Is(err, services.ErrUserNotFound):
 		log.Printf("INFO [ServiceError]: UserNotFound: %v (Request: %s %s)", err, method, path)
 		RespondWithError(c, http.StatusNotFound, "User not found")
@@ -78,6 +132,72 @@ func HandleServiceError(c *gin.Context, err error) {
 	case errors.Is(err, services.ErrCannotRemoveOwner):
 		log.Printf("INFO [ServiceError]: CannotRemoveOwner: %v (Request: %s %s)", err, method, path)
 		RespondWithError(c, http.StatusBadRequest, "Board owner cannot be removed this way.")
+	case errors.Is(err, services.ErrInvalidBoardRole):
+		log.Printf("WARN [ServiceError]: InvalidBoardRole: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusBadRequest, "Role must be one of: admin, member, viewer.")
+	case errors.Is(err, services.ErrCannotChangeOwner):
+		log.Printf("INFO [ServiceError]: CannotChangeOwner: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusBadRequest, "The board owner's role cannot be changed.")
+	case errors.Is(err, services.ErrNewOwnerNotMember):
+		log.Printf("INFO [ServiceError]: NewOwnerNotMember: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusBadRequest, "The new owner must already be a member of the board.")
+	case errors.Is(err, services.ErrAlreadyBoardOwner):
+		log.Printf("INFO [ServiceError]: AlreadyBoardOwner: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusBadRequest, "That user already owns this board.")
+	case errors.Is(err, services.ErrBoardArchived):
+		log.Printf("INFO [ServiceError]: BoardArchived: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusConflict, "The board is archived; unarchive it to make changes.")
+	case errors.Is(err, services.ErrWorkspaceNotFound):
+		log.Printf("INFO [ServiceError]: WorkspaceNotFound: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusNotFound, "Workspace not found")
+	case errors.Is(err, services.ErrWorkspaceMemberNotFound):
+		log.Printf("INFO [ServiceError]: WorkspaceMemberNotFound: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusNotFound, "Workspace member not found.")
+	case errors.Is(err, services.ErrUserAlreadyInWorkspace):
+		log.Printf("INFO [ServiceError]: UserAlreadyInWorkspace: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusConflict, "User is already a member of this workspace.")
+	case errors.Is(err, services.ErrInvalidWorkspaceRole):
+		log.Printf("WARN [ServiceError]: InvalidWorkspaceRole: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusBadRequest, "Role must be one of: admin, member.")
+	case errors.Is(err, services.ErrLastWorkspaceAdmin):
+		log.Printf("INFO [ServiceError]: LastWorkspaceAdmin: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusBadRequest, "A workspace must keep at least one admin; promote someone else first.")
+	case errors.Is(err, services.ErrInvitationNotFound):
+		log.Printf("INFO [ServiceError]: InvitationNotFound: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusNotFound, "Invitation not found.")
+	case errors.Is(err, services.ErrInvalidInvitation):
+		log.Printf("INFO [ServiceError]: InvalidInvitation: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusBadRequest, "Invitation is invalid, expired or has already been answered.")
+	case errors.Is(err, services.ErrInviteLinkNotFound):
+		log.Printf("INFO [ServiceError]: InviteLinkNotFound: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusNotFound, "Invite link not found.")
+	case errors.Is(err, services.ErrInvalidInviteLink):
+		log.Printf("INFO [ServiceError]: InvalidInviteLink: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusBadRequest, "Invite link is invalid, expired or no longer usable.")
+	case errors.Is(err, services.ErrShareLinkNotFound):
+		log.Printf("INFO [ServiceError]: ShareLinkNotFound: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusNotFound, "Share link not found.")
+	case errors.Is(err, services.ErrLabelNotFound):
+		log.Printf("INFO [ServiceError]: LabelNotFound: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusNotFound, "Label not found.")
+	case errors.Is(err, services.ErrLabelNotOnCard):
+		log.Printf("INFO [ServiceError]: LabelNotOnCard: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusNotFound, "The card doesn't have that label.")
+	case errors.Is(err, services.ErrChecklistNotFound):
+		log.Printf("INFO [ServiceError]: ChecklistNotFound: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusNotFound, "Checklist not found.")
+	case errors.Is(err, services.ErrChecklistItemNotFound):
+		log.Printf("INFO [ServiceError]: ChecklistItemNotFound: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusNotFound, "Checklist item not found.")
+	case errors.Is(err, services.ErrAttachmentNotFound):
+		log.Printf("INFO [ServiceError]: AttachmentNotFound: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusNotFound, "Attachment not found.")
+	case errors.Is(err, services.ErrAttachmentTooLarge):
+		log.Printf("INFO [ServiceError]: AttachmentTooLarge: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusRequestEntityTooLarge, "The file is too large.")
+	case errors.Is(err, services.ErrUnsupportedFileType):
+		log.Printf("INFO [ServiceError]: UnsupportedFileType: %v (Request: %s %s)", err, method, path)
+		RespondWithError(c, http.StatusUnsupportedMediaType, "This type of file can't be attached.")
 	case errors.Is(err, services.ErrInvalidInput):
 		log.Printf("WARN [ServiceError]: InvalidInput: %v (Request: %s %s)", err, method, path) // Log as WARN
 		RespondWithError(c, http.StatusBadRequest, err.Error())
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zayyadi/trello/dto"
+	"github.com/zayyadi/trello/services"
+)
+
+type TokenHandler struct {
+	tokenService *services.PersonalAccessTokenService
+}
+
+func NewTokenHandler(tokenService *services.PersonalAccessTokenService) *TokenHandler {
+	return &TokenHandler{tokenService: tokenService}
+}
+
+func (h *TokenHandler) CreateToken(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var req dto.CreatePersonalAccessTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresInDays > 0 {
+		t := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		expiresAt = &t
+	}
+
+	token, rawToken, err := h.tokenService.CreateToken(userID.(uint), req.Name, req.ReadOnly, req.BoardID, expiresAt)
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, http.StatusCreated, "Token created. Copy it now, it won't be shown again", dto.CreatedPersonalAccessTokenResponse{
+		PersonalAccessTokenResponse: dto.MapPersonalAccessTokenToResponse(token),
+		Token:                       rawToken,
+	})
+}
+
+func (h *TokenHandler) ListTokens(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	tokens, err := h.tokenService.ListTokens(userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+
+	responses := make([]dto.PersonalAccessTokenResponse, len(tokens))
+	for i := range tokens {
+		responses[i] = dto.MapPersonalAccessTokenToResponse(&tokens[i])
+	}
+	RespondWithSuccess(c, http.StatusOK, "Tokens retrieved successfully", responses)
+}
+
+func (h *TokenHandler) RevokeToken(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	tokenID, err := strconv.ParseUint(c.Param("tokenID"), 10, 32)
+	if err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid token ID")
+		return
+	}
+
+	if err := h.tokenService.RevokeToken(uint(tokenID), userID.(uint)); err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, http.StatusOK, "Token revoked successfully", nil)
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zayyadi/trello/dto"
+	"github.com/zayyadi/trello/services"
+)
+
+type TwoFactorHandler struct {
+	twoFactorService *services.TwoFactorService
+}
+
+func NewTwoFactorHandler(twoFactorService *services.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{twoFactorService: twoFactorService}
+}
+
+// CompleteLogin is the second step of login for users with two-factor auth.
+func (h *TwoFactorHandler) CompleteLogin(c *gin.Context) {
+	var req dto.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	user, tokens, err := h.twoFactorService.CompleteLogin(req.ChallengeToken, req.Code, c.ClientIP())
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, http.StatusOK, "Login successful", mapAuthResponse(user, tokens))
+}
+
+func (h *TwoFactorHandler) Status(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	enabled, remaining, err := h.twoFactorService.Status(userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, http.StatusOK, "Two-factor status retrieved successfully", dto.TwoFactorStatusResponse{
+		Enabled:                enabled,
+		RecoveryCodesRemaining: remaining,
+	})
+}
+
+func (h *TwoFactorHandler) Enroll(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	enrollment, err := h.twoFactorService.BeginEnrollment(userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, http.StatusOK, "Add this account to your authenticator app, then confirm with a code", dto.TwoFactorEnrollmentResponse{
+		Secret:          enrollment.Secret,
+		ProvisioningURI: enrollment.ProvisioningURI,
+	})
+}
+
+func (h *TwoFactorHandler) Confirm(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	var req dto.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	codes, err := h.twoFactorService.ConfirmEnrollment(userID.(uint), req.Code)
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, http.StatusOK, "Two-factor authentication enabled. Store these recovery codes somewhere safe", dto.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	var req dto.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	if err := h.twoFactorService.Disable(userID.(uint), req.Code); err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, http.StatusOK, "Two-factor authentication disabled", nil)
+}
+
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	var req dto.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	codes, err := h.twoFactorService.RegenerateRecoveryCodes(userID.(uint), req.Code)
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, http.StatusOK, "New recovery codes generated; the old ones no longer work", dto.RecoveryCodesResponse{RecoveryCodes: codes})
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zayyadi/trello/dto"
+	"github.com/zayyadi/trello/services"
+)
+
+// UserHandler serves the current user's own account ("me").
+type UserHandler struct {
+	userService    *services.UserService
+	accountService *services.AccountService
+}
+
+func NewUserHandler(userService *services.UserService, accountService *services.AccountService) *UserHandler {
+	return &UserHandler{userService: userService, accountService: accountService}
+}
+
+func (h *UserHandler) GetMe(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	user, err := h.userService.GetProfile(userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, http.StatusOK, "Profile retrieved successfully", dto.MapUserToResponse(user))
+}
+
+func (h *UserHandler) UpdateMe(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var req dto.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	user, err := h.userService.UpdateProfile(userID.(uint), services.ProfileUpdate{
+		Username:        req.Username,
+		Email:           req.Email,
+		DisplayName:     req.DisplayName,
+		AvatarURL:       req.AvatarURL,
+		CurrentPassword: req.CurrentPassword,
+	})
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, http.StatusOK, "Profile updated successfully", dto.MapUserToResponse(user))
+}
+
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var req dto.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	tokens, err := h.userService.ChangePassword(userID.(uint), req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+
+	// Every other session was signed out; hand back a fresh one for this client
+	RespondWithSuccess(c, http.StatusOK, "Password changed successfully", dto.TokenResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    tokens.ExpiresAt,
+	})
+}
+
+// ExportMe downloads everything we hold about the current user as a JSON file.
+func (h *UserHandler) ExportMe(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	data, err := h.accountService.Export(userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+
+	now := time.Now().UTC()
+	filename := fmt.Sprintf("trello-export-%s-%s.json", data.User.Username, now.Format("20060102"))
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.JSON(http.StatusOK, dto.MapAccountExportToResponse(data.User, data.Boards, data.Cards, data.Comments, data.Collaborations, now))
+}
+
+// DeleteMe permanently deletes the current user's account.
+func (h *UserHandler) DeleteMe(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var req dto.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	err := h.accountService.DeleteAccount(userID.(uint), services.AccountDeletion{
+		CurrentPassword:   req.CurrentPassword,
+		DeleteOwnedBoards: req.DeleteOwnedBoards,
+		TransferTo:        req.TransferTo,
+	})
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, http.StatusOK, "Account deleted successfully", nil)
+}
@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zayyadi/trello/dto"
+	"github.com/zayyadi/trello/services"
+)
+
+type WorkspaceHandler struct {
+	workspaceService *services.WorkspaceService
+}
+
+func NewWorkspaceHandler(workspaceService *services.WorkspaceService) *WorkspaceHandler {
+	return &WorkspaceHandler{workspaceService: workspaceService}
+}
+
+// uintParam parses a numeric path parameter, answering 400 if it isn't one.
+func uintParam(c *gin.Context, name, label string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 32)
+	if err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid "+label)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func (h *WorkspaceHandler) CreateWorkspace(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var req dto.CreateWorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	workspace, err := h.workspaceService.CreateWorkspace(req.Name, req.Description, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusCreated, "Workspace created successfully", dto.MapWorkspaceToResponse(workspace))
+}
+
+func (h *WorkspaceHandler) GetWorkspaces(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	workspaces, err := h.workspaceService.GetWorkspacesForUser(userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	responses := make([]dto.WorkspaceResponse, len(workspaces))
+	for i := range workspaces {
+		responses[i] = dto.MapWorkspaceToResponse(&workspaces[i])
+	}
+	RespondWithSuccess(c, http.StatusOK, "Workspaces retrieved successfully", responses)
+}
+
+func (h *WorkspaceHandler) GetWorkspace(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	workspaceID, ok := uintParam(c, "workspaceID", "workspace ID")
+	if !ok {
+		return
+	}
+
+	workspace, err := h.workspaceService.GetWorkspace(workspaceID, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Workspace retrieved successfully", dto.MapWorkspaceToResponse(workspace))
+}
+
+func (h *WorkspaceHandler) UpdateWorkspace(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	workspaceID, ok := uintParam(c, "workspaceID", "workspace ID")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateWorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	workspace, err := h.workspaceService.UpdateWorkspace(workspaceID, req.Name, req.Description, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Workspace updated successfully", dto.MapWorkspaceToResponse(workspace))
+}
+
+func (h *WorkspaceHandler) DeleteWorkspace(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	workspaceID, ok := uintParam(c, "workspaceID", "workspace ID")
+	if !ok {
+		return
+	}
+
+	if err := h.workspaceService.DeleteWorkspace(workspaceID, userID.(uint)); err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Workspace deleted successfully", nil)
+}
+
+func (h *WorkspaceHandler) GetMembers(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	workspaceID, ok := uintParam(c, "workspaceID", "workspace ID")
+	if !ok {
+		return
+	}
+
+	members, err := h.workspaceService.GetMembers(workspaceID, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	responses := make([]dto.WorkspaceMemberResponse, len(members))
+	for i := range members {
+		responses[i] = dto.MapWorkspaceMemberToResponse(&members[i])
+	}
+	RespondWithSuccess(c, http.StatusOK, "Workspace members retrieved successfully", responses)
+}
+
+func (h *WorkspaceHandler) AddMember(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	workspaceID, ok := uintParam(c, "workspaceID", "workspace ID")
+	if !ok {
+		return
+	}
+
+	var req dto.AddWorkspaceMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+	if req.Email == nil && req.UserID == nil {
+		RespondWithError(c, http.StatusBadRequest, "Either email or userID must be provided to add a member")
+		return
+	}
+	if req.Email != nil && req.UserID != nil {
+		RespondWithError(c, http.StatusBadRequest, "Provide either email or userID, not both")
+		return
+	}
+
+	member, err := h.workspaceService.AddMember(workspaceID, req.Email, req.UserID, req.Role, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusCreated, "Member added to workspace successfully", dto.MapWorkspaceMemberToResponse(member))
+}
+
+func (h *WorkspaceHandler) UpdateMemberRole(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	workspaceID, ok := uintParam(c, "workspaceID", "workspace ID")
+	if !ok {
+		return
+	}
+	memberUserID, ok := uintParam(c, "memberUserID", "member user ID")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateWorkspaceMemberRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	member, err := h.workspaceService.UpdateMemberRole(workspaceID, memberUserID, req.Role, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Member role updated successfully", dto.MapWorkspaceMemberToResponse(member))
+}
+
+func (h *WorkspaceHandler) RemoveMember(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	workspaceID, ok := uintParam(c, "workspaceID", "workspace ID")
+	if !ok {
+		return
+	}
+	memberUserID, ok := uintParam(c, "memberUserID", "member user ID")
+	if !ok {
+		return
+	}
+
+	if err := h.workspaceService.RemoveMember(workspaceID, memberUserID, userID.(uint)); err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Member removed from workspace successfully", nil)
+}
+
+func (h *WorkspaceHandler) GetBoards(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	workspaceID, ok := uintParam(c, "workspaceID", "workspace ID")
+	if !ok {
+		return
+	}
+
+	boards, err := h.workspaceService.GetBoards(workspaceID, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	responses := make([]dto.BoardResponse, len(boards))
+	for i := range boards {
+		responses[i] = dto.MapBoardToResponse(&boards[i], true, false)
+	}
+	RespondWithSuccess(c, http.StatusOK, "Boards retrieved successfully", responses)
+}
+
+func (h *WorkspaceHandler) CreateBoard(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	workspaceID, ok := uintParam(c, "workspaceID", "workspace ID")
+	if !ok {
+		return
+	}
+
+	var req dto.CreateWorkspaceBoardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	board, err := h.workspaceService.CreateBoard(workspaceID, req.Name, req.Description, req.Visibility, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusCreated, "Board created successfully", dto.MapBoardToResponse(board, true, false))
+}
+
+// MoveBoardIn puts an existing board into a workspace.
+func (h *WorkspaceHandler) MoveBoardIn(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	boardID, ok := uintParam(c, "boardID", "board ID")
+	if !ok {
+		return
+	}
+
+	var req dto.MoveBoardToWorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	board, err := h.workspaceService.MoveBoard(boardID, &req.WorkspaceID, req.Visibility, userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Board moved to workspace successfully", dto.MapBoardToResponse(board, true, false))
+}
+
+// MoveBoardOut takes a board out of its workspace, making it private.
+func (h *WorkspaceHandler) MoveBoardOut(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	boardID, ok := uintParam(c, "boardID", "board ID")
+	if !ok {
+		return
+	}
+
+	board, err := h.workspaceService.MoveBoard(boardID, nil, "", userID.(uint))
+	if err != nil {
+		HandleServiceError(c, err)
+		return
+	}
+	RespondWithSuccess(c, http.StatusOK, "Board removed from workspace successfully", dto.MapBoardToResponse(board, true, false))
+}
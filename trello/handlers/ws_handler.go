@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
@@ -8,11 +9,8 @@ import (
 	// "strings" // Not used yet, but might be useful later
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5" // Import jwt
 	"github.com/gorilla/websocket"
 
-	// "github.com/zayyadi/trello/config" // JWTSecret will be passed in NewWebSocketHandler
-	"github.com/zayyadi/trello/models"
 	"github.com/zayyadi/trello/realtime"
 	"github.com/zayyadi/trello/services" // Will be needed for BoardService
 )
@@ -32,15 +30,19 @@ var upgrader = websocket.Upgrader{
 type WebSocketHandler struct {
 	hub          *realtime.Hub
 	boardService services.BoardServiceInterface // Use interface type
-	jwtSecret    string
+	authService  *services.AuthService          // Validates tokens, including revocation
+
+	publicBoardService *services.PublicBoardService // Lets anonymous viewers follow public and shared boards
 }
 
 // NewWebSocketHandler creates a new WebSocketHandler.
-func NewWebSocketHandler(hub *realtime.Hub, boardService services.BoardServiceInterface, jwtSecret string) *WebSocketHandler {
+func NewWebSocketHandler(hub *realtime.Hub, boardService services.BoardServiceInterface, authService *services.AuthService, publicBoardService *services.PublicBoardService) *WebSocketHandler {
 	return &WebSocketHandler{
 		hub:          hub,
 		boardService: boardService,
-		jwtSecret:    jwtSecret,
+		authService:  authService,
+
+		publicBoardService: publicBoardService,
 	}
 }
 
@@ -54,12 +56,8 @@ func (h *WebSocketHandler) HandleConnections(c *gin.Context) {
 		return
 	}
 
-	claims := &models.Claims{}
-	token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
-		return []byte(h.jwtSecret), nil
-	})
-
-	if err != nil || !token.Valid {
+	claims, err := h.authService.ValidateAccessToken(tokenStr) // Also rejects tokens from revoked sessions
+	if err != nil {
 		log.Printf("WebSocket: Invalid token: %v", err)
 		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 		return
@@ -106,11 +104,12 @@ func (h *WebSocketHandler) HandleConnections(c *gin.Context) {
 	}
 
 	client := &realtime.Client{
-		Hub:     h.hub,
-		Conn:    conn,
-		Send:    make(chan []byte, 256),
-		BoardID: boardID,
-		UserID:  userID, // Set the UserID on the client
+		Hub:       h.hub,
+		Conn:      conn,
+		Send:      make(chan []byte, 256),
+		BoardID:   boardID,
+		UserID:    userID, // Set the UserID on the client
+		SessionID: claims.SessionID,
 	}
 
 	client.Hub.Register <- client // Pass client to register channel
@@ -122,3 +121,57 @@ func (h *WebSocketHandler) HandleConnections(c *gin.Context) {
 
 	log.Printf("WebSocket: Client (User: %d) connected to board %d", userID, boardID)
 }
+
+// HandlePublicConnections lets viewers who aren't logged in follow a board read-only:
+// a public board given by boardID, or any board through a share link given by
+// shareToken. Their messages leave out personal details, and they are disconnected
+// when the board stops being public or the link is revoked.
+func (h *WebSocketHandler) HandlePublicConnections(c *gin.Context) {
+	var boardID uint
+	if boardIDStr := c.Query("boardID"); boardIDStr != "" {
+		boardIDUint64, err := strconv.ParseUint(boardIDStr, 10, 32)
+		if err != nil {
+			log.Printf("WebSocket: Invalid boardID format: %s", boardIDStr)
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid boardID format"})
+			return
+		}
+		boardID = uint(boardIDUint64)
+	}
+	shareToken := c.Query("shareToken")
+	if boardID == 0 && shareToken == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "boardID or shareToken query parameter is required"})
+		return
+	}
+
+	boardID, shareLinkID, err := h.publicBoardService.AuthorizeViewer(boardID, shareToken)
+	if err != nil {
+		if errors.Is(err, services.ErrBoardNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Board not found"})
+			return
+		}
+		log.Printf("WebSocket: Error checking anonymous access to board %d: %v", boardID, err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Could not verify board access"})
+		return
+	}
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Printf("WebSocket: Failed to upgrade anonymous connection for board %d: %v", boardID, err)
+		return
+	}
+
+	client := &realtime.Client{
+		Hub:         h.hub,
+		Conn:        conn,
+		Send:        make(chan []byte, 256),
+		BoardID:     boardID,
+		Anonymous:   true,
+		ShareLinkID: shareLinkID,
+	}
+	client.Hub.Register <- client
+
+	go client.WritePump()
+	go client.ReadPump()
+
+	log.Printf("WebSocket: Anonymous client connected to board %d (share link: %d)", boardID, shareLinkID)
+}
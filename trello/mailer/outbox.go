@@ -0,0 +1,46 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// OutboxMailer "sends" messages by writing each one to a file in Dir.
+// With an empty Dir messages are written to the log instead.
+// Useful for development and tests where no mail server is available.
+type OutboxMailer struct {
+	Dir  string
+	From string
+
+	mu    sync.Mutex
+	count int
+}
+
+func NewOutboxMailer(dir, from string) *OutboxMailer {
+	return &OutboxMailer{Dir: dir, From: from}
+}
+
+func (m *OutboxMailer) Send(msg Message) error {
+	raw := format(m.From, msg)
+	if m.Dir == "" {
+		log.Printf("Mailer outbox: message to %v\n%s", msg.To, raw)
+		return nil
+	}
+
+	m.mu.Lock()
+	m.count++
+	name := fmt.Sprintf("%s-%04d.eml", time.Now().UTC().Format("20060102T150405.000000000"), m.count)
+	m.mu.Unlock()
+
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return fmt.Errorf("error creating mail outbox: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(m.Dir, name), raw, 0o600); err != nil {
+		return fmt.Errorf("error writing mail to outbox: %w", err)
+	}
+	return nil
+}
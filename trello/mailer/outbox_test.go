@@ -0,0 +1,45 @@
+package mailer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutboxMailer_WritesMessageFile(t *testing.T) {
+	dir := t.TempDir()
+	m := NewOutboxMailer(dir, "noreply@example.com")
+
+	err := m.Send(Message{To: []string{"user@example.com"}, Subject: "Hello", Body: "line one\nline two"})
+	assert.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	content, err := os.ReadFile(files[0])
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "From: noreply@example.com\r\n")
+	assert.Contains(t, string(content), "To: user@example.com\r\n")
+	assert.Contains(t, string(content), "Subject: Hello\r\n")
+	assert.Contains(t, string(content), "\r\n\r\nline one\r\nline two")
+}
+
+func TestOutboxMailer_OneFilePerMessage(t *testing.T) {
+	dir := t.TempDir()
+	m := NewOutboxMailer(dir, "noreply@example.com")
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, m.Send(Message{To: []string{"user@example.com"}, Subject: "Hi", Body: "body"}))
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	assert.Len(t, files, 3)
+}
+
+func TestOutboxMailer_LogsWithoutDir(t *testing.T) {
+	m := NewOutboxMailer("", "noreply@example.com")
+	assert.NoError(t, m.Send(Message{To: []string{"user@example.com"}, Subject: "Hi", Body: "body"}))
+}
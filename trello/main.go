@@ -2,14 +2,19 @@ package main
 
 import (
 	"log"
+	"strings"
 
 	"github.com/zayyadi/trello/config"
 	"github.com/zayyadi/trello/db"
 	"github.com/zayyadi/trello/handlers"
+	"github.com/zayyadi/trello/mailer"
 	middleware "github.com/zayyadi/trello/middlewares"
+	"github.com/zayyadi/trello/oidc"
 	"github.com/zayyadi/trello/realtime" // Import realtime package
 	"github.com/zayyadi/trello/repositories"
 	"github.com/zayyadi/trello/services"
+	"github.com/zayyadi/trello/storage"
+	"github.com/zayyadi/trello/utils"
 
 	"github.com/gin-gonic/gin"
 )
@@ -38,25 +43,181 @@ func main() {
 	cardRepo := repositories.NewCardRepository(dbInstance)
 	boardMemberRepo := repositories.NewBoardMemberRepository(dbInstance)
 	commentRepo := repositories.NewCommentRepository(dbInstance) // Initialize CommentRepository
+	refreshTokenRepo := repositories.NewRefreshTokenRepository(dbInstance)
+	userTokenRepo := repositories.NewUserTokenRepository(dbInstance)
+	userIdentityRepo := repositories.NewUserIdentityRepository(dbInstance)
+	personalAccessTokenRepo := repositories.NewPersonalAccessTokenRepository(dbInstance)
+	recoveryCodeRepo := repositories.NewRecoveryCodeRepository(dbInstance)
+	auditEventRepo := repositories.NewAuditEventRepository(dbInstance)
+	accountRepo := repositories.NewAccountRepository(dbInstance)
+	workspaceRepo := repositories.NewWorkspaceRepository(dbInstance)
+	workspaceMemberRepo := repositories.NewWorkspaceMemberRepository(dbInstance)
+	invitationRepo := repositories.NewBoardInvitationRepository(dbInstance)
+	inviteLinkRepo := repositories.NewBoardInviteLinkRepository(dbInstance)
+	archiveRepo := repositories.NewArchiveRepository(dbInstance)
+	boardCopyRepo := repositories.NewBoardCopyRepository(dbInstance)
+	shareLinkRepo := repositories.NewBoardShareLinkRepository(dbInstance)
+	boardPreferenceRepo := repositories.NewBoardPreferenceRepository(dbInstance)
+	labelRepo := repositories.NewLabelRepository(dbInstance)
+	checklistRepo := repositories.NewChecklistRepository(dbInstance)
+	attachmentRepo := repositories.NewAttachmentRepository(dbInstance)
+	rankRepo := repositories.NewRankRepository(dbInstance)
+
+	// Failed login counters: per process by default, or shared through the database
+	var loginThrottleRepo repositories.LoginThrottleRepositoryInterface
+	switch cfg.LoginLockoutStore {
+	case "database":
+		loginThrottleRepo = repositories.NewLoginThrottleRepository(dbInstance)
+	case "memory":
+		loginThrottleRepo = repositories.NewMemoryLoginThrottleRepository()
+	default:
+		log.Fatalf("Invalid LOGIN_LOCKOUT_STORE %q (want memory or database)", cfg.LoginLockoutStore)
+	}
+
+	// Initialize Mailer: real SMTP if configured, otherwise a local outbox
+	var mailSender mailer.Mailer
+	if cfg.SMTPHost != "" {
+		mailSender = mailer.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom)
+	} else {
+		log.Printf("SMTP_HOST not set, writing outgoing mail to outbox %q", cfg.MailOutboxDir)
+		mailSender = mailer.NewOutboxMailer(cfg.MailOutboxDir, cfg.MailFrom)
+	}
+
+	// Attachment files: on local disk by default, or in an S3-compatible bucket
+	var blobStore storage.BlobStore
+	switch cfg.BlobStore {
+	case "local":
+		blobStore = storage.NewLocalStore(cfg.BlobDir)
+	case "s3":
+		if cfg.S3Endpoint == "" || cfg.S3Bucket == "" {
+			log.Fatalf("BLOB_STORE=s3 needs S3_ENDPOINT and S3_BUCKET")
+		}
+		blobStore = storage.NewS3Store(storage.S3Config{
+			Endpoint:        cfg.S3Endpoint,
+			Region:          cfg.S3Region,
+			Bucket:          cfg.S3Bucket,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+		}, nil)
+	default:
+		log.Fatalf("Invalid BLOB_STORE %q (want local or s3)", cfg.BlobStore)
+	}
 
 	// Initialize Services
-	authService := services.NewAuthService(userRepo, cfg.JWTSecretKey)
-	boardService := services.NewBoardService(boardRepo, userRepo, boardMemberRepo, hub)                       // Pass hub
+	auditService := services.NewAuditService(auditEventRepo)
+	loginThrottle := services.NewLoginThrottleService(loginThrottleRepo, auditService, services.LoginThrottleOptions{
+		MaxAccountFailures: cfg.LoginMaxAccountFailures,
+		MaxIPFailures:      cfg.LoginMaxIPFailures,
+		BaseLockout:        cfg.LoginLockoutBase,
+		MaxLockout:         cfg.LoginLockoutMax,
+		FailureWindow:      cfg.LoginFailureWindow,
+	})
+	invitationService := services.NewInvitationService(invitationRepo, inviteLinkRepo, boardRepo, boardMemberRepo, userRepo, mailSender, hub, services.InvitationOptions{
+		InvitationTTL:        cfg.InvitationTTL,
+		AppBaseURL:           cfg.AppBaseURL,
+		RequireVerifiedEmail: cfg.RequireVerifiedEmail,
+	})
+	// Access tokens are signed with an RSA/Ed25519 key when one is configured, else the HS256 secret
+	var jwtKeys *utils.JWTKeySet
+	if cfg.JWTSigningKeyFile != "" {
+		jwtKeys, err = utils.LoadJWTKeySet(cfg.JWTSigningKeyFile, cfg.JWTVerificationKeyFiles)
+		if err != nil {
+			log.Fatalf("Failed to load JWT keys: %v", err)
+		}
+		log.Printf("Signing access tokens with key %s", jwtKeys.SigningKeyID())
+	} else if len(cfg.JWTVerificationKeyFiles) > 0 {
+		log.Fatalf("JWT_VERIFICATION_KEY_FILES needs JWT_SIGNING_KEY_FILE")
+	} else if cfg.DevMode && cfg.JWTSecretKey == config.DefaultJWTSecretKey {
+		log.Printf("WARN: DEV_MODE is on and access tokens are signed with the default JWT secret")
+	}
+	authService := services.NewAuthService(userRepo, refreshTokenRepo, userTokenRepo, mailSender, services.AuthOptions{
+		JWTSecretKey:         cfg.JWTSecretKey,
+		JWTKeys:              jwtKeys,
+		AccessTokenTTL:       cfg.AccessTokenTTL,
+		RefreshTokenTTL:      cfg.RefreshTokenTTL,
+		PasswordResetTTL:     cfg.PasswordResetTTL,
+		EmailVerifyTTL:       cfg.EmailVerifyTTL,
+		AppBaseURL:           cfg.AppBaseURL,
+		RequireVerifiedEmail: cfg.RequireVerifiedEmail,
+		LoginThrottle:        loginThrottle,
+		PersonalAccessTokens: personalAccessTokenRepo,
+		Hub:                  hub,
+		Invitations:          invitationService,
+	})
+	if err := authService.GrantAdmin(cfg.AdminEmails); err != nil {
+		log.Fatalf("Failed to grant admin from ADMIN_EMAILS: %v", err)
+	}
+	boardService := services.NewBoardService(boardRepo, userRepo, boardMemberRepo, hub, cfg.RequireVerifiedEmail, // Pass hub
+		services.WithAuditService(auditService))
 	listService := services.NewListService(listRepo, boardRepo, boardMemberRepo, hub)                         // Pass hub
 	cardService := services.NewCardService(cardRepo, listRepo, boardRepo, boardMemberRepo, userRepo, hub)     // Pass hub
 	commentService := services.NewCommentService(commentRepo, cardRepo, listRepo, boardRepo, boardMemberRepo) // Initialize CommentService
+	tokenService := services.NewPersonalAccessTokenService(personalAccessTokenRepo, boardRepo, boardMemberRepo)
+	boardResolver := services.NewBoardResolver(listRepo, cardRepo)
+	twoFactorService := services.NewTwoFactorService(authService, userRepo, userTokenRepo, recoveryCodeRepo, cfg.TOTPIssuer)
+	userService := services.NewUserService(userRepo, authService)
+	accountService := services.NewAccountService(userRepo, boardRepo, accountRepo, auditService)
+	workspaceService := services.NewWorkspaceService(workspaceRepo, workspaceMemberRepo, boardRepo, boardMemberRepo, userRepo, hub, cfg.RequireVerifiedEmail)
+	archiveService := services.NewArchiveService(archiveRepo, boardRepo, boardMemberRepo, listRepo, cardRepo, hub, cfg.TrashRetention)
+	boardCopyService := services.NewBoardCopyService(boardCopyRepo, boardRepo, boardMemberRepo, workspaceMemberRepo, hub)
+	publicBoardService := services.NewPublicBoardService(shareLinkRepo, boardRepo, boardMemberRepo, listRepo, hub, cfg.AppBaseURL)
+	boardPreferenceService := services.NewBoardPreferenceService(boardPreferenceRepo, boardRepo, boardMemberRepo)
+	labelService := services.NewLabelService(labelRepo, cardRepo, listRepo, boardRepo, boardMemberRepo, hub)
+	checklistService := services.NewChecklistService(checklistRepo, cardRepo, listRepo, boardRepo, boardMemberRepo, hub)
+	attachmentService := services.NewAttachmentService(attachmentRepo, cardRepo, listRepo, boardRepo, boardMemberRepo, blobStore, hub, services.AttachmentOptions{
+		MaxSize:      cfg.AttachmentMaxSize,
+		AllowedTypes: cfg.AttachmentAllowedTypes,
+	})
+	rankService := services.NewRankService(rankRepo, listRepo, hub, cfg.RankMaxLength)
+
+	// Empty the trash of items deleted longer than TRASH_RETENTION ago
+	go archiveService.RunRetentionJob(cfg.TrashPurgeInterval, nil)
+	// Delete the files attached to the cards it purges
+	go attachmentService.RunCleanupJob(cfg.TrashPurgeInterval, nil)
+	// Keep the ranks ordering lists and cards short
+	go rankService.RunRebalanceJob(cfg.RankRebalanceInterval, nil)
 
 	// Initialize Handlers
 	authHandler := handlers.NewAuthHandler(authService)
-	boardHandler := handlers.NewBoardHandler(boardService)
+	boardHandler := handlers.NewBoardHandler(boardService, boardPreferenceService)
 	listHandler := handlers.NewListHandler(listService)
 	cardHandler := handlers.NewCardHandler(cardService)
-	commentHandler := handlers.NewCommentHandler(commentService)                   // Initialize CommentHandler
-	wsHandler := handlers.NewWebSocketHandler(hub, boardService, cfg.JWTSecretKey) // Initialize WebSocketHandler
+	commentHandler := handlers.NewCommentHandler(commentService)                                  // Initialize CommentHandler
+	wsHandler := handlers.NewWebSocketHandler(hub, boardService, authService, publicBoardService) // Initialize WebSocketHandler
+	tokenHandler := handlers.NewTokenHandler(tokenService)
+	twoFactorHandler := handlers.NewTwoFactorHandler(twoFactorService)
+	adminHandler := handlers.NewAdminHandler(loginThrottle, auditService)
+	userHandler := handlers.NewUserHandler(userService, accountService)
+	workspaceHandler := handlers.NewWorkspaceHandler(workspaceService)
+	invitationHandler := handlers.NewInvitationHandler(invitationService)
+	archiveHandler := handlers.NewArchiveHandler(archiveService)
+	boardCopyHandler := handlers.NewBoardCopyHandler(boardCopyService)
+	publicBoardHandler := handlers.NewPublicBoardHandler(publicBoardService)
+	labelHandler := handlers.NewLabelHandler(labelService)
+	checklistHandler := handlers.NewChecklistHandler(checklistService)
+	attachmentHandler := handlers.NewAttachmentHandler(attachmentService)
+
+	// Single sign-on is optional; only wire it up when a provider is configured
+	var oidcHandler *handlers.OIDCHandler
+	if cfg.OIDCIssuerURL != "" {
+		provider := oidc.NewProvider(oidc.Config{
+			IssuerURL:    cfg.OIDCIssuerURL,
+			ClientID:     cfg.OIDCClientID,
+			ClientSecret: cfg.OIDCClientSecret,
+			RedirectURL:  cfg.OIDCRedirectURL,
+			Scopes:       cfg.OIDCScopes,
+		}, nil)
+		oidcService := services.NewOIDCService(provider, authService, userRepo, userIdentityRepo)
+		oidcHandler = handlers.NewOIDCHandler(oidcService, strings.HasPrefix(cfg.OIDCRedirectURL, "https://"))
+	}
 
 	// Setup Gin router
 	// gin.SetMode(gin.ReleaseMode) // Uncomment for production
 	router := gin.Default()
+	// Client IPs count login failures, so forwarded headers are only believed from known proxies
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
 
 	// CORS Middleware (Allow All for development)
 	// For production, configure origins properly
@@ -78,30 +239,143 @@ func main() {
 	{
 		authRoutes.POST("/register", authHandler.Register)
 		authRoutes.POST("/login", authHandler.Login)
+		authRoutes.POST("/login/2fa", twoFactorHandler.CompleteLogin)
+		authRoutes.POST("/refresh", authHandler.Refresh)
+		authRoutes.POST("/logout", authHandler.Logout)
+		authRoutes.POST("/forgot-password", authHandler.ForgotPassword)
+		authRoutes.POST("/reset-password", authHandler.ResetPassword)
+		authRoutes.POST("/verify-email", authHandler.VerifyEmail)
+		authRoutes.POST("/resend-verification", authHandler.ResendVerification)
+
+		if oidcHandler != nil {
+			authRoutes.GET("/oidc/login", oidcHandler.Login)
+			authRoutes.GET("/oidc/callback", oidcHandler.Callback)
+		}
+	}
+
+	// Read-only board views for anyone, no login needed
+	publicRoutes := router.Group("/public")
+	{
+		publicRoutes.GET("/boards/:boardID", publicBoardHandler.GetPublicBoard)
+		publicRoutes.GET("/shared-boards/:token", publicBoardHandler.GetSharedBoard)
 	}
 
 	// Protected routes
 	api := router.Group("/api")
-	api.Use(middleware.AuthMiddleware(cfg.JWTSecretKey))
+	api.Use(middleware.AuthMiddleware(authService, tokenService))
+	api.Use(middleware.TokenScopeMiddleware(boardResolver))
 	{
+		// Personal access token routes (need a real login, not another token)
+		tokens := api.Group("/tokens", middleware.RequireSession())
+		tokens.POST("", tokenHandler.CreateToken)
+		tokens.GET("", tokenHandler.ListTokens)
+		tokens.DELETE("/:tokenID", tokenHandler.RevokeToken)
+
+		// The current user's profile (reading it works with a personal access token)
+		api.GET("/me", userHandler.GetMe)
+		api.PATCH("/me", middleware.RequireSession(), userHandler.UpdateMe)
+		api.POST("/me/password", middleware.RequireSession(), userHandler.ChangePassword)
+		api.GET("/me/export", middleware.RequireSession(), userHandler.ExportMe)
+		api.DELETE("/me", middleware.RequireSession(), userHandler.DeleteMe)
+
+		// Two-factor authentication settings (also need a real login)
+		twoFactor := api.Group("/me/2fa", middleware.RequireSession())
+		twoFactor.GET("", twoFactorHandler.Status)
+		twoFactor.POST("/enroll", twoFactorHandler.Enroll)
+		twoFactor.POST("/confirm", twoFactorHandler.Confirm)
+		twoFactor.POST("/disable", twoFactorHandler.Disable)
+		twoFactor.POST("/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes)
+
+		// Site administration
+		admin := api.Group("/admin", middleware.RequireSession(), middleware.RequireAdmin(authService))
+		admin.GET("/lockouts", adminHandler.ListLockouts)
+		admin.POST("/lockouts/unlock", adminHandler.Unlock)
+		admin.GET("/audit-events", adminHandler.ListAuditEvents)
+
 		// Board routes
 		api.POST("/boards", boardHandler.CreateBoard)
 		api.GET("/boards", boardHandler.GetBoardsForUser)
 		api.GET("/boards/:boardID", boardHandler.GetBoardByID)
 		api.PUT("/boards/:boardID", boardHandler.UpdateBoard)
 		api.DELETE("/boards/:boardID", boardHandler.DeleteBoard)
+		api.PATCH("/boards/:boardID/visibility", middleware.RequireSession(), boardHandler.UpdateVisibility)
+		api.PUT("/boards/:boardID/workspace", workspaceHandler.MoveBoardIn)
+		api.DELETE("/boards/:boardID/workspace", workspaceHandler.MoveBoardOut)
+
+		// The user's own board settings: stars, their board order and recently viewed boards
+		api.GET("/boards/recent", boardHandler.GetRecentBoards)
+		api.PUT("/boards/order", boardHandler.SetBoardOrder)
+		api.PUT("/boards/:boardID/star", boardHandler.StarBoard)
+		api.DELETE("/boards/:boardID/star", boardHandler.UnstarBoard)
+
+		// Archived boards, and deleted boards still in the trash (owners)
+		api.GET("/boards/archived", archiveHandler.GetArchivedBoards)
+		api.POST("/boards/:boardID/archive", archiveHandler.ArchiveBoard)
+		api.POST("/boards/:boardID/unarchive", archiveHandler.UnarchiveBoard)
+		api.GET("/boards/trash", archiveHandler.GetDeletedBoards)
+		api.POST("/boards/:boardID/restore", archiveHandler.RestoreBoard)
+
+		// A board's archived lists and cards, and its trash of deleted ones (board admins)
+		api.GET("/boards/:boardID/archived", archiveHandler.GetArchivedItems)
+		api.GET("/boards/:boardID/trash", archiveHandler.GetTrash)
+		api.POST("/boards/:boardID/trash/lists/:listID/restore", archiveHandler.RestoreList)
+		api.POST("/boards/:boardID/trash/cards/:cardID/restore", archiveHandler.RestoreCard)
+
+		// Copying boards, and templates to start new boards from
+		api.GET("/boards/templates", boardCopyHandler.GetTemplates)
+		api.POST("/boards/:boardID/copy", middleware.RequireSession(), boardCopyHandler.CopyBoard)
+		api.PATCH("/boards/:boardID/template", boardCopyHandler.UpdateTemplate)
+
+		// Unlisted links for viewing a board without logging in (board admins)
+		api.POST("/boards/:boardID/share-links", middleware.RequireSession(), publicBoardHandler.CreateShareLink)
+		api.GET("/boards/:boardID/share-links", publicBoardHandler.GetShareLinks)
+		api.DELETE("/boards/:boardID/share-links/:linkID", publicBoardHandler.RevokeShareLink)
+
+		// Workspace routes
+		api.POST("/workspaces", workspaceHandler.CreateWorkspace)
+		api.GET("/workspaces", workspaceHandler.GetWorkspaces)
+		api.GET("/workspaces/:workspaceID", workspaceHandler.GetWorkspace)
+		api.PUT("/workspaces/:workspaceID", workspaceHandler.UpdateWorkspace)
+		api.DELETE("/workspaces/:workspaceID", workspaceHandler.DeleteWorkspace)
+		api.GET("/workspaces/:workspaceID/members", workspaceHandler.GetMembers)
+		api.POST("/workspaces/:workspaceID/members", workspaceHandler.AddMember)
+		api.PATCH("/workspaces/:workspaceID/members/:memberUserID", workspaceHandler.UpdateMemberRole)
+		api.DELETE("/workspaces/:workspaceID/members/:memberUserID", workspaceHandler.RemoveMember)
+		api.GET("/workspaces/:workspaceID/boards", workspaceHandler.GetBoards)
+		api.POST("/workspaces/:workspaceID/boards", workspaceHandler.CreateBoard)
 
 		// Board Member routes
-		api.POST("/boards/:boardID/members", boardHandler.AddMemberToBoard)
+		api.POST("/boards/:boardID/members", invitationHandler.AddMember) // Invites rather than adds, so people can decline
 		api.GET("/boards/:boardID/members", boardHandler.GetBoardMembers)
+		api.PATCH("/boards/:boardID/members/:memberUserID", boardHandler.UpdateMemberRole)
 		api.DELETE("/boards/:boardID/members/:memberUserID", boardHandler.RemoveMemberFromBoard)
+		api.POST("/boards/:boardID/transfer-ownership", middleware.RequireSession(), boardHandler.TransferOwnership)
+
+		// Board invitations, by email or shareable link (board admins)
+		api.POST("/boards/:boardID/invitations", invitationHandler.CreateInvitation)
+		api.GET("/boards/:boardID/invitations", invitationHandler.GetBoardInvitations)
+		api.DELETE("/boards/:boardID/invitations/:invitationID", invitationHandler.RevokeInvitation)
+		api.POST("/boards/:boardID/invite-links", invitationHandler.CreateInviteLink)
+		api.GET("/boards/:boardID/invite-links", invitationHandler.GetInviteLinks)
+		api.DELETE("/boards/:boardID/invite-links/:linkID", invitationHandler.RevokeInviteLink)
+
+		// Answering invitations (needs a real login, not a personal access token)
+		invitations := api.Group("/invitations", middleware.RequireSession())
+		invitations.GET("", invitationHandler.GetMyInvitations)
+		invitations.POST("/accept", invitationHandler.AcceptInvitationToken)
+		invitations.POST("/decline", invitationHandler.DeclineInvitationToken)
+		invitations.POST("/:invitationID/accept", invitationHandler.AcceptInvitation)
+		invitations.POST("/:invitationID/decline", invitationHandler.DeclineInvitation)
+		api.POST("/invite-links/join", middleware.RequireSession(), invitationHandler.JoinBoard)
 
 		// List routes
 		api.POST("/boards/:boardID/lists", listHandler.CreateList)
 		api.GET("/boards/:boardID/lists", listHandler.GetListsByBoardID)
 		api.PUT("/lists/:listID", listHandler.UpdateList)
 		api.DELETE("/lists/:listID", listHandler.DeleteList)
-		// Consider a route for reordering lists: PATCH /api/boards/:boardID/lists/reorder
+		api.POST("/lists/:listID/archive", archiveHandler.ArchiveList)
+		api.POST("/lists/:listID/unarchive", archiveHandler.UnarchiveList)
+		api.PATCH("/boards/:boardID/lists/reorder", listHandler.ReorderLists)
 
 		// Card routes
 		api.POST("/lists/:listID/cards", cardHandler.CreateCard)
@@ -110,7 +384,9 @@ func main() {
 		api.PUT("/cards/:cardID", cardHandler.UpdateCard)
 		api.DELETE("/cards/:cardID", cardHandler.DeleteCard)
 		api.PATCH("/cards/:cardID/move", cardHandler.MoveCard)
-		// Consider a route for reordering cards within a list: PATCH /api/lists/:listID/cards/reorder
+		api.POST("/cards/:cardID/archive", archiveHandler.ArchiveCard)
+		api.POST("/cards/:cardID/unarchive", archiveHandler.UnarchiveCard)
+		api.PATCH("/lists/:listID/cards/reorder", cardHandler.ReorderCards)
 
 		// Comment routes
 		api.POST("/cards/:cardID/comments", commentHandler.CreateComment)
@@ -120,10 +396,42 @@ func main() {
 		api.POST("/cards/:cardID/collaborators", cardHandler.AddCollaborator)
 		api.GET("/cards/:cardID/collaborators", cardHandler.GetCollaborators)
 		api.DELETE("/cards/:cardID/collaborators/:userID", cardHandler.RemoveCollaborator)
+
+		// Board labels, and putting them on cards
+		api.GET("/boards/:boardID/labels", labelHandler.GetLabels)
+		api.POST("/boards/:boardID/labels", labelHandler.CreateLabel)
+		api.PUT("/boards/:boardID/labels/:labelID", labelHandler.UpdateLabel)
+		api.DELETE("/boards/:boardID/labels/:labelID", labelHandler.DeleteLabel)
+		api.POST("/cards/:cardID/labels", labelHandler.AddLabelToCard)
+		api.DELETE("/cards/:cardID/labels/:labelID", labelHandler.RemoveLabelFromCard)
+
+		// Card checklists and their items
+		api.GET("/cards/:cardID/checklists", checklistHandler.GetChecklists)
+		api.POST("/cards/:cardID/checklists", checklistHandler.CreateChecklist)
+		api.PATCH("/cards/:cardID/checklists/reorder", checklistHandler.ReorderChecklists)
+		api.PUT("/cards/:cardID/checklists/:checklistID", checklistHandler.UpdateChecklist)
+		api.DELETE("/cards/:cardID/checklists/:checklistID", checklistHandler.DeleteChecklist)
+		api.POST("/cards/:cardID/checklists/:checklistID/items", checklistHandler.CreateItem)
+		api.PATCH("/cards/:cardID/checklists/:checklistID/items/reorder", checklistHandler.ReorderItems)
+		api.PUT("/cards/:cardID/checklists/:checklistID/items/:itemID", checklistHandler.UpdateItem)
+		api.DELETE("/cards/:cardID/checklists/:checklistID/items/:itemID", checklistHandler.DeleteItem)
+
+		// Card attachments (multipart uploads), and the image shown as the card's cover
+		api.GET("/cards/:cardID/attachments", attachmentHandler.GetAttachments)
+		api.POST("/cards/:cardID/attachments", attachmentHandler.UploadAttachment)
+		api.GET("/cards/:cardID/attachments/:attachmentID/download", attachmentHandler.DownloadAttachment)
+		api.GET("/cards/:cardID/attachments/:attachmentID/thumbnail", attachmentHandler.GetThumbnail)
+		api.DELETE("/cards/:cardID/attachments/:attachmentID", attachmentHandler.DeleteAttachment)
+		api.PUT("/cards/:cardID/cover", attachmentHandler.SetCover)
+		api.DELETE("/cards/:cardID/cover", attachmentHandler.RemoveCover)
 	}
 
 	// WebSocket route
 	router.GET("/ws", wsHandler.HandleConnections)
+	router.GET("/ws/public", wsHandler.HandlePublicConnections) // Public and link-shared boards, read-only
+
+	// Public keys for services that verify our access tokens (empty with an HS256 secret)
+	router.GET("/.well-known/jwks.json", authHandler.JWKS)
 
 	// Start server
 	port := cfg.ServerPort
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/zayyadi/trello/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminChecker reports whether a user is a site administrator.
+// Implemented by services.AuthService.
+type AdminChecker interface {
+	IsAdmin(userID uint) (bool, error)
+}
+
+// RequireAdmin only lets site administrators through. It must run after AuthMiddleware.
+func RequireAdmin(checker AdminChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("userID")
+		isAdmin, err := checker.IsAdmin(userID.(uint))
+		if err != nil {
+			handlers.HandleServiceError(c, err)
+			return
+		}
+		if !isAdmin {
+			handlers.RespondWithError(c, http.StatusForbidden, "Administrator access required")
+			return
+		}
+		c.Next()
+	}
+}
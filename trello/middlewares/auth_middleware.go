@@ -5,12 +5,29 @@ import (
 	"strings"
 
 	"github.com/zayyadi/trello/handlers" // For RespondWithError
-	"github.com/zayyadi/trello/utils"
+	"github.com/zayyadi/trello/models"
 
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
+// PersonalAccessTokenKey is the context key holding the *models.PersonalAccessToken
+// a request was authenticated with. It is unset for requests using a JWT.
+const PersonalAccessTokenKey = "personalAccessToken"
+
+// AccessTokenValidator checks an access token's signature, expiry and revocation status.
+// Implemented by services.AuthService.
+type AccessTokenValidator interface {
+	ValidateAccessToken(tokenString string) (*models.Claims, error)
+}
+
+// PersonalAccessTokenValidator looks up an active personal access token.
+// Implemented by services.PersonalAccessTokenService.
+type PersonalAccessTokenValidator interface {
+	ValidatePersonalAccessToken(tokenString string) (*models.PersonalAccessToken, error)
+}
+
+// AuthMiddleware accepts either a JWT access token or a personal access token as the bearer token.
+func AuthMiddleware(validator AccessTokenValidator, patValidator PersonalAccessTokenValidator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -25,7 +42,19 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 		}
 
 		tokenString := parts[1]
-		claims, err := utils.ValidateJWT(tokenString, jwtSecret)
+		if strings.HasPrefix(tokenString, models.PersonalAccessTokenPrefix) {
+			pat, err := patValidator.ValidatePersonalAccessToken(tokenString)
+			if err != nil {
+				handlers.RespondWithError(c, http.StatusUnauthorized, "Invalid or expired token: "+err.Error())
+				return
+			}
+			c.Set("userID", pat.UserID)
+			c.Set(PersonalAccessTokenKey, pat) // Scopes are enforced by TokenScopeMiddleware
+			c.Next()
+			return
+		}
+
+		claims, err := validator.ValidateAccessToken(tokenString) // Also rejects tokens from revoked sessions
 		if err != nil {
 			handlers.RespondWithError(c, http.StatusUnauthorized, "Invalid or expired token: "+err.Error())
 			return
@@ -35,3 +64,15 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// RequireSession rejects requests authenticated with a personal access token,
+// for routes such as token management that need a real login.
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, isPAT := c.Get(PersonalAccessTokenKey); isPAT {
+			handlers.RespondWithError(c, http.StatusForbidden, "This action requires logging in; personal access tokens cannot be used")
+			return
+		}
+		c.Next()
+	}
+}
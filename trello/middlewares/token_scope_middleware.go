@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/zayyadi/trello/handlers"
+	"github.com/zayyadi/trello/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// BoardResolver finds the board a list or card belongs to.
+// Implemented by services.BoardResolver.
+type BoardResolver interface {
+	BoardIDForList(listID uint) (uint, error)
+	BoardIDForCard(cardID uint) (uint, error)
+}
+
+// TokenScopeMiddleware enforces the scopes of personal access tokens.
+// Read-only tokens may only make GET/HEAD requests. Board-scoped tokens may only
+// use routes that identify a resource (board, list or card) on their board.
+// Requests authenticated with a JWT pass through untouched.
+func TokenScopeMiddleware(resolver BoardResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, isPAT := c.Get(PersonalAccessTokenKey)
+		if !isPAT {
+			c.Next()
+			return
+		}
+		pat := value.(*models.PersonalAccessToken)
+
+		if pat.ReadOnly && c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			handlers.RespondWithError(c, http.StatusForbidden, "This access token is read-only")
+			return
+		}
+
+		if pat.BoardID != nil {
+			boardID, found, err := boardIDForRequest(c, resolver)
+			if err != nil {
+				handlers.HandleServiceError(c, err)
+				return
+			}
+			if !found || boardID != *pat.BoardID {
+				handlers.RespondWithError(c, http.StatusForbidden, "This access token is limited to a different board")
+				return
+			}
+			// Moving a card names its target list in the body, which may be on another board
+			if listID, ok := bodyTargetListID(c); ok {
+				targetBoardID, err := resolver.BoardIDForList(listID)
+				if err != nil {
+					handlers.HandleServiceError(c, err)
+					return
+				}
+				if targetBoardID != *pat.BoardID {
+					handlers.RespondWithError(c, http.StatusForbidden, "This access token is limited to a different board")
+					return
+				}
+			}
+		}
+		c.Next()
+	}
+}
+
+// boardIDForRequest works out which board the route targets from its path parameters.
+// found is false for routes that aren't about a single board (e.g. listing all boards).
+func boardIDForRequest(c *gin.Context, resolver BoardResolver) (boardID uint, found bool, err error) {
+	if id, ok := uintParam(c, "boardID"); ok {
+		return id, true, nil
+	}
+	if id, ok := uintParam(c, "listID"); ok {
+		boardID, err = resolver.BoardIDForList(id)
+		return boardID, err == nil, err
+	}
+	if id, ok := uintParam(c, "cardID"); ok {
+		boardID, err = resolver.BoardIDForCard(id)
+		return boardID, err == nil, err
+	}
+	return 0, false, nil
+}
+
+// bodyTargetListID reads the "targetListID" of a JSON request body, and puts the
+// body back for the handler to bind.
+func bodyTargetListID(c *gin.Context) (uint, bool) {
+	if c.Request.Body == nil || c.ContentType() != binding.MIMEJSON {
+		return 0, false
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return 0, false
+	}
+	var req struct {
+		TargetListID uint `json:"targetListID"`
+	}
+	if json.Unmarshal(body, &req) != nil || req.TargetListID == 0 {
+		return 0, false
+	}
+	return req.TargetListID, true
+}
+
+func uintParam(c *gin.Context, name string) (uint, bool) {
+	value := c.Param(name)
+	if value == "" {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,14 @@
+package models
+
+// AccountDeletionPlan says what happens to a user's data when their account is deleted.
+// It is worked out by the service and carried out in a single transaction.
+type AccountDeletionPlan struct {
+	UserID uint
+	// Anonymized replaces the user's profile. The row is kept (soft-deleted) so that
+	// their comments still have an author, but no personal data is left on it.
+	Anonymized *User
+	// TransferBoards maps owned board IDs to the member who takes them over.
+	TransferBoards map[uint]uint
+	// DeleteBoards lists owned boards to delete along with their lists and cards.
+	DeleteBoards []uint
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Attachment is a file uploaded to a card. The file itself is kept in the blob store
+// under StorageKey, with a smaller copy under ThumbnailKey for images we can scale.
+//
+// Copies of a card share their blobs, so a blob is only deleted along with the last
+// attachment that uses it. There is deliberately no foreign key to the card: when a
+// card is purged from the trash its attachments stay behind until the cleanup job has
+// deleted their blobs.
+type Attachment struct {
+	ID           uint      `gorm:"primarykey" json:"id"`
+	CardID       uint      `gorm:"not null;index" json:"cardID"`
+	UploaderID   uint      `gorm:"not null;index" json:"uploaderID"`
+	Uploader     *User     `gorm:"foreignKey:UploaderID" json:"uploader,omitempty"`
+	Filename     string    `gorm:"type:varchar(255);not null" json:"filename"`
+	ContentType  string    `gorm:"type:varchar(255);not null" json:"contentType"`
+	Size         int64     `gorm:"not null" json:"size"`
+	StorageKey   string    `gorm:"type:varchar(255);not null;index" json:"-"`
+	ThumbnailKey *string   `gorm:"type:varchar(255);index" json:"-"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// IsImage says whether the attachment is an image, and so can be a card cover.
+func (a *Attachment) IsImage() bool {
+	return strings.HasPrefix(a.ContentType, "image/")
+}
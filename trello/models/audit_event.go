@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// AuditEventType names a security-relevant event.
+type AuditEventType string
+
+const (
+	AuditEventLoginSucceeded AuditEventType = "login.succeeded"
+	AuditEventLoginFailed    AuditEventType = "login.failed"
+	AuditEventLoginLocked    AuditEventType = "login.locked"   // Too many failures; logins refused for a while
+	AuditEventLoginUnlocked  AuditEventType = "login.unlocked" // Lock cleared by an admin
+
+	AuditEventAccountExported AuditEventType = "account.exported" // Personal data export downloaded
+	AuditEventAccountDeleted  AuditEventType = "account.deleted"
+
+	AuditEventBoardTransferred AuditEventType = "board.transferred" // A site admin gave someone else's board a new owner
+)
+
+// AuditEvent is an append-only record of a security-relevant event.
+type AuditEvent struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time      `gorm:"index" json:"createdAt"`
+	Type      AuditEventType `gorm:"type:varchar(64);not null;index" json:"type"`
+	ActorID   *uint          `gorm:"index" json:"actorID,omitempty"` // User who performed the action, if any (e.g. the admin)
+	UserID    *uint          `gorm:"index" json:"userID,omitempty"`  // User the event is about, if known
+	Email     string         `gorm:"type:varchar(255)" json:"email,omitempty"`
+	IP        string         `gorm:"type:varchar(64)" json:"ip,omitempty"`
+	Detail    string         `gorm:"type:text" json:"detail,omitempty"`
+}
@@ -1,9 +1,31 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
+// BoardVisibility controls who besides the board's owner and members can see it.
+type BoardVisibility string
+
+const (
+	BoardVisibilityPrivate   BoardVisibility = "private"   // Only the owner and members
+	BoardVisibilityWorkspace BoardVisibility = "workspace" // Also every member of the board's workspace
+	BoardVisibilityPublic    BoardVisibility = "public"    // Also anyone, read-only and without logging in
+)
+
+// IsValid reports whether v is one of the predefined visibilities.
+func (v BoardVisibility) IsValid() bool {
+	return v == BoardVisibilityPrivate || v == BoardVisibilityWorkspace || v == BoardVisibilityPublic
+}
+
+// SharedWithWorkspace reports whether members of the board's workspace can use a
+// board with this visibility. Public boards are shared with their workspace too.
+func (v BoardVisibility) SharedWithWorkspace() bool {
+	return v == BoardVisibilityWorkspace || v == BoardVisibilityPublic
+}
+
 // Board model
 type Board struct {
 	gorm.Model
@@ -13,6 +35,16 @@ type Board struct {
 	Owner       User          `gorm:"foreignKey:OwnerID" json:"owner"` // Belongs to Owner
 	Lists       []List        `gorm:"foreignKey:BoardID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"lists,omitempty"`
 	Members     []BoardMember `gorm:"foreignKey:BoardID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"members,omitempty"`
+
+	// Workspace the board belongs to, if any. With workspace visibility its members can use the board too.
+	WorkspaceID *uint           `gorm:"index" json:"workspaceID,omitempty"`
+	Visibility  BoardVisibility `gorm:"type:varchar(20);not null;default:'private'" json:"visibility"`
+
+	// Set while the board is archived: it is left out of board lists and can't be changed.
+	ArchivedAt *time.Time `gorm:"index" json:"archivedAt,omitempty"`
+
+	// Templates are boards meant to be copied: anyone who can see one can start a board from it.
+	IsTemplate bool `gorm:"not null;default:false" json:"isTemplate"`
 }
 
 // TableName returns the table name for the Board model
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// InvitationStatus is where a board invitation is in its life.
+type InvitationStatus string
+
+const (
+	InvitationStatusPending  InvitationStatus = "pending"
+	InvitationStatusAccepted InvitationStatus = "accepted"
+	InvitationStatusDeclined InvitationStatus = "declined"
+	InvitationStatusRevoked  InvitationStatus = "revoked"
+)
+
+// BoardInvitation asks the owner of an email address to join a board. The
+// address doesn't need an account yet: pending invitations are matched by email
+// when one is registered. Only a hash of the emailed token is stored.
+type BoardInvitation struct {
+	gorm.Model
+	BoardID     uint             `gorm:"not null;index" json:"boardID"`
+	Board       Board            `gorm:"foreignKey:BoardID;constraint:OnDelete:CASCADE;" json:"-"`
+	InviterID   uint             `gorm:"not null" json:"inviterID"`
+	Inviter     User             `gorm:"foreignKey:InviterID" json:"inviter,omitempty"`
+	Email       string           `gorm:"type:varchar(255);not null;index" json:"email"` // Lower case
+	Role        BoardRole        `gorm:"type:varchar(20);not null;default:'member'" json:"role"`
+	TokenHash   string           `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	Status      InvitationStatus `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
+	ExpiresAt   time.Time        `gorm:"not null" json:"expiresAt"`
+	RespondedAt *time.Time       `json:"respondedAt,omitempty"`
+	AcceptedBy  *uint            `json:"acceptedBy,omitempty"` // The user who accepted, who may have signed up with another address
+}
+
+// IsPending reports whether the invitation can still be accepted or declined at time now.
+func (i *BoardInvitation) IsPending(now time.Time) bool {
+	return i.Status == InvitationStatusPending && now.Before(i.ExpiresAt)
+}
+
+// BoardInviteLink is a shareable link that lets anyone with an account join a
+// board with Role, until it expires, is revoked or has been used MaxUses times.
+type BoardInviteLink struct {
+	gorm.Model
+	BoardID     uint       `gorm:"not null;index" json:"boardID"`
+	Board       Board      `gorm:"foreignKey:BoardID;constraint:OnDelete:CASCADE;" json:"-"`
+	CreatedByID uint       `gorm:"not null" json:"createdByID"`
+	Role        BoardRole  `gorm:"type:varchar(20);not null;default:'member'" json:"role"`
+	TokenHash   string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	MaxUses     *int       `json:"maxUses,omitempty"` // Nil means unlimited
+	Uses        int        `gorm:"not null;default:0" json:"uses"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"` // Nil means the link never expires
+	RevokedAt   *time.Time `json:"revokedAt,omitempty"`
+}
+
+// IsActive reports whether the link can be used at time now.
+func (l *BoardInviteLink) IsActive(now time.Time) bool {
+	return l.RevokedAt == nil &&
+		(l.ExpiresAt == nil || now.Before(*l.ExpiresAt)) &&
+		(l.MaxUses == nil || l.Uses < *l.MaxUses)
+}
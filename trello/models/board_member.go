@@ -4,12 +4,45 @@ import (
 	"time"
 )
 
+// BoardRole is the permission level a member holds on a board.
+type BoardRole string
+
+const (
+	BoardRoleAdmin  BoardRole = "admin"  // Manage members, lists and board settings
+	BoardRoleMember BoardRole = "member" // Create and edit lists, cards and comments
+	BoardRoleViewer BoardRole = "viewer" // Read-only access
+)
+
+// rank orders roles from least to most privileged. Unknown roles rank lowest.
+func (r BoardRole) rank() int {
+	switch r {
+	case BoardRoleAdmin:
+		return 3
+	case BoardRoleMember:
+		return 2
+	case BoardRoleViewer:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// IsValid reports whether r is one of the predefined board roles.
+func (r BoardRole) IsValid() bool {
+	return r.rank() > 0
+}
+
+// AtLeast reports whether r grants at least the privileges of min.
+func (r BoardRole) AtLeast(min BoardRole) bool {
+	return r.IsValid() && r.rank() >= min.rank()
+}
+
 // BoardMember represents the many-to-many relationship between Users and Boards
 type BoardMember struct {
 	BoardID   uint      `gorm:"primaryKey" json:"boardID"`
 	UserID    uint      `gorm:"primaryKey" json:"userID"`
 	User      User      `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"user,omitempty"`
 	Board     Board     `gorm:"foreignKey:BoardID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"` // To avoid circular refs in JSON
+	Role      BoardRole `gorm:"type:varchar(20);not null;default:'member'" json:"role"`
 	CreatedAt time.Time `json:"createdAt"`
-	// Role    string `gorm:"default:'member'" json:"role"` // e.g., "admin", "member", "viewer"
 }
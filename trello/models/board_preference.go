@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+// BoardPreference holds one user's own settings for a board: whether they starred
+// it, where they put it in their board list, and when they last opened it. Rows
+// are created the first time any of these is set.
+type BoardPreference struct {
+	UserID       uint       `gorm:"primaryKey" json:"userID"`
+	BoardID      uint       `gorm:"primaryKey;index" json:"boardID"`
+	User         User       `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	Board        Board      `gorm:"foreignKey:BoardID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	Starred      bool       `gorm:"not null;default:false" json:"starred"`
+	Position     *int       `json:"position,omitempty"` // The user's own order; nil if they haven't placed the board
+	LastViewedAt *time.Time `json:"lastViewedAt,omitempty"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BoardShareLink is an unlisted link that lets anyone who has it view a board
+// read-only, without logging in and whatever the board's visibility. Only a hash
+// of the token is stored.
+type BoardShareLink struct {
+	gorm.Model
+	BoardID     uint       `gorm:"not null;index" json:"boardID"`
+	Board       Board      `gorm:"foreignKey:BoardID;constraint:OnDelete:CASCADE;" json:"-"`
+	CreatedByID uint       `gorm:"not null" json:"createdByID"`
+	TokenHash   string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"` // Nil means the link never expires
+	RevokedAt   *time.Time `json:"revokedAt,omitempty"`
+}
+
+// IsActive reports whether the link can be used at time now.
+func (l *BoardShareLink) IsActive(now time.Time) bool {
+	return l.RevokedAt == nil && (l.ExpiresAt == nil || now.Before(*l.ExpiresAt))
+}
@@ -23,8 +23,8 @@ type Card struct {
 	Description    string     `json:"description"`
 	ListID         uint       `gorm:"not null" json:"listID"`
 	List           List       `gorm:"foreignKey:ListID" json:"-"`
-	Position       uint       `gorm:"not null;default:0" json:"position"`
-	DueDate        *time.Time `json:"dueDate,omitempty"` // Already exists, ensure it's used
+	Position       string     `gorm:"type:varchar(255);not null;default:''" json:"position"` // Rank within the list, see package rank
+	DueDate        *time.Time `json:"dueDate,omitempty"`                                     // Already exists, ensure it's used
 	Status         CardStatus `gorm:"type:varchar(20);default:'TO_DO'" json:"status"`
 	AssignedUserID *uint      `json:"assignedUserID,omitempty"` // User doing the task
 	AssignedUser   *User      `gorm:"foreignKey:AssignedUserID" json:"assignedUser,omitempty"`
@@ -33,4 +33,16 @@ type Card struct {
 	Comments       []Comment  `gorm:"foreignKey:CardID;constraint:OnDelete:CASCADE;" json:"comments,omitempty"`
 	Collaborators  []*User    `gorm:"many2many:card_collaborators;constraint:OnDelete:CASCADE;" json:"collaborators,omitempty"`
 	Color          *string    `gorm:"type:varchar(7)" json:"color,omitempty"` // Hex color like #RRGGBB
+
+	// Set while the card is archived: hidden from its list, but kept out of the trash.
+	ArchivedAt *time.Time `gorm:"index" json:"archivedAt,omitempty"`
+
+	// Labels from the card's board. Color above stays as the card's cover color.
+	Labels []Label `gorm:"many2many:card_labels;constraint:OnDelete:CASCADE;" json:"labels,omitempty"`
+
+	Checklists []Checklist `gorm:"foreignKey:CardID;constraint:OnDelete:CASCADE;" json:"checklists,omitempty"`
+
+	// An image attachment of the card shown as its cover
+	CoverAttachmentID *uint       `json:"coverAttachmentID,omitempty"`
+	CoverAttachment   *Attachment `gorm:"foreignKey:CoverAttachmentID;constraint:OnDelete:SET NULL;" json:"coverAttachment,omitempty"`
 }
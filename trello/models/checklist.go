@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+)
+
+// Checklist is a named list of items to tick off on a card, such as its definition of done.
+type Checklist struct {
+	ID        uint            `gorm:"primarykey" json:"id"`
+	CardID    uint            `gorm:"not null;index" json:"cardID"`
+	Card      Card            `gorm:"foreignKey:CardID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	Title     string          `gorm:"type:varchar(255);not null" json:"title"`
+	Position  uint            `gorm:"not null;default:0" json:"position"`
+	Items     []ChecklistItem `gorm:"foreignKey:ChecklistID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"items,omitempty"`
+	CreatedAt time.Time       `json:"createdAt"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+}
+
+// ChecklistItem is one entry of a checklist. Each item can have its own assignee and due date.
+type ChecklistItem struct {
+	ID             uint       `gorm:"primarykey" json:"id"`
+	ChecklistID    uint       `gorm:"not null;index" json:"checklistID"`
+	Content        string     `gorm:"type:varchar(500);not null" json:"content"`
+	Position       uint       `gorm:"not null;default:0" json:"position"`
+	Completed      bool       `gorm:"not null;default:false" json:"completed"`
+	CompletedAt    *time.Time `json:"completedAt,omitempty"`
+	AssignedUserID *uint      `gorm:"index" json:"assignedUserID,omitempty"`
+	AssignedUser   *User      `gorm:"foreignKey:AssignedUserID" json:"assignedUser,omitempty"`
+	DueDate        *time.Time `json:"dueDate,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+}
+
+// ChecklistProgress counts the completed items of the given checklists.
+func ChecklistProgress(checklists []Checklist) (completed, total int) {
+	for _, checklist := range checklists {
+		for _, item := range checklist.Items {
+			total++
+			if item.Completed {
+				completed++
+			}
+		}
+	}
+	return completed, total
+}
@@ -6,6 +6,7 @@ import (
 
 // Claims defines the custom claims for the JWT
 type Claims struct {
-	UserID uint `json:"userID"`
+	UserID    uint   `json:"userID"`
+	SessionID string `json:"sid,omitempty"` // Login session the token belongs to; used for revocation
 	jwt.RegisteredClaims
 }
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+)
+
+// Label is one of a board's labels. Labels are defined per board and can be put
+// on any number of the board's cards.
+type Label struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	BoardID   uint      `gorm:"not null;index" json:"boardID"`
+	Board     Board     `gorm:"foreignKey:BoardID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	Name      string    `gorm:"type:varchar(50);not null;default:''" json:"name"` // May be empty for a color-only label
+	Color     string    `gorm:"type:varchar(7);not null" json:"color"`            // Hex color like #RRGGBB
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// CardLabel defines the join table for the many-to-many relationship
+// between Cards and Labels.
+type CardLabel struct {
+	CardID    uint      `gorm:"primaryKey;autoIncrement:false" json:"cardID"`
+	LabelID   uint      `gorm:"primaryKey;autoIncrement:false;index" json:"labelID"`
+	CreatedAt time.Time `json:"createdAt,omitempty"`
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
@@ -10,6 +12,9 @@ type List struct {
 	Name      string `gorm:"not null" json:"name"`
 	BoardID   uint   `gorm:"not null" json:"boardID"`
 	Board     Board  `gorm:"foreignKey:BoardID" json:"-"`        // Belongs to Board
-	Position  uint   `gorm:"not null;default:0" json:"position"` // Order of the list within the board
+	Position  string `gorm:"type:varchar(255);not null;default:''" json:"position"` // Rank of the list within the board, see package rank
 	Cards     []Card `gorm:"foreignKey:ListID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"cards,omitempty"`
+
+	// Set while the list is archived: hidden from the board, but kept out of the trash.
+	ArchivedAt *time.Time `gorm:"index" json:"archivedAt,omitempty"`
 }
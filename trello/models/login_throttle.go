@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// LoginThrottleScope says what a LoginThrottle counts failed logins for.
+type LoginThrottleScope string
+
+const (
+	LoginThrottleScopeAccount LoginThrottleScope = "account" // Key is the normalized email address
+	LoginThrottleScopeIP      LoginThrottleScope = "ip"      // Key is the client IP address
+)
+
+// LoginThrottle counts recent failed logins for an account or an IP address,
+// and records until when further logins are refused.
+// Rows are deleted rather than soft-deleted, so it does not embed gorm.Model.
+type LoginThrottle struct {
+	ID            uint               `gorm:"primarykey" json:"id"`
+	Scope         LoginThrottleScope `gorm:"type:varchar(16);not null;uniqueIndex:idx_login_throttle_scope_key" json:"scope"`
+	Key           string             `gorm:"type:varchar(255);not null;uniqueIndex:idx_login_throttle_scope_key" json:"key"`
+	Failures      int                `gorm:"not null;default:0" json:"failures"`
+	LastFailureAt time.Time          `gorm:"not null" json:"lastFailureAt"`
+	LockedUntil   *time.Time         `json:"lockedUntil,omitempty"`
+	CreatedAt     time.Time          `json:"createdAt"`
+	UpdatedAt     time.Time          `json:"updatedAt"`
+}
+
+// IsLocked reports whether logins are refused at time now.
+func (t *LoginThrottle) IsLocked(now time.Time) bool {
+	return t.LockedUntil != nil && now.Before(*t.LockedUntil)
+}
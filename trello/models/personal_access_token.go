@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PersonalAccessTokenPrefix starts every personal access token, so they can be
+// told apart from JWTs and recognised if leaked.
+const PersonalAccessTokenPrefix = "tpat_"
+
+// PersonalAccessToken is a long-lived, named credential a user creates for
+// scripts and integrations. Only a hash of the token is stored.
+type PersonalAccessToken struct {
+	gorm.Model
+	UserID    uint   `gorm:"not null;index" json:"userID"`
+	Name      string `gorm:"type:varchar(100);not null" json:"name"`
+	TokenHash string `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	// Prefix is the start of the token, shown so users can tell their tokens apart.
+	Prefix string `gorm:"type:varchar(16);not null" json:"prefix"`
+
+	// Scopes. A read-only token may only make safe (GET) requests;
+	// a board-scoped token may only touch that board and its contents.
+	ReadOnly bool  `gorm:"not null;default:false" json:"readOnly"`
+	BoardID  *uint `gorm:"index" json:"boardID,omitempty"`
+
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"` // Nil means the token never expires
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
+
+// IsActive reports whether the token can be used at time now.
+func (t *PersonalAccessToken) IsActive(now time.Time) bool {
+	return t.RevokedAt == nil && (t.ExpiresAt == nil || now.Before(*t.ExpiresAt))
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RefreshToken is a long-lived, single-use credential that can be exchanged
+// for a new access token. Only a hash of the token is stored.
+// Every token issued from the same login shares a SessionID, so a whole
+// session can be revoked at once.
+type RefreshToken struct {
+	gorm.Model
+	UserID    uint       `gorm:"not null;index" json:"userID"`
+	SessionID string     `gorm:"type:varchar(64);not null;index" json:"sessionID"`
+	TokenHash string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expiresAt"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+}
+
+// IsActive reports whether the token can still be exchanged at time now.
+func (t *RefreshToken) IsActive(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
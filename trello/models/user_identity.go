@@ -0,0 +1,13 @@
+package models
+
+import "gorm.io/gorm"
+
+// UserIdentity links a User to an account at an external OpenID Connect
+// identity provider. An issuer/subject pair identifies the external account.
+type UserIdentity struct {
+	gorm.Model
+	UserID  uint   `gorm:"not null;index" json:"userID"`
+	Issuer  string `gorm:"type:varchar(255);not null;uniqueIndex:idx_identity_issuer_subject" json:"issuer"`
+	Subject string `gorm:"type:varchar(255);not null;uniqueIndex:idx_identity_issuer_subject" json:"subject"`
+	Email   string `json:"email"` // Email reported by the provider at last login
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TokenPurpose says what a UserToken may be used for.
+type TokenPurpose string
+
+const (
+	TokenPurposePasswordReset     TokenPurpose = "password_reset"
+	TokenPurposeEmailVerification TokenPurpose = "email_verification"
+	// Issued after a correct password when the user has two-factor auth enabled.
+	TokenPurposeTwoFactorChallenge TokenPurpose = "two_factor_challenge"
+)
+
+// UserToken is a single-use, expiring token emailed to a user to prove they
+// control their address (e.g. to reset a password). Only a hash is stored.
+type UserToken struct {
+	gorm.Model
+	UserID    uint         `gorm:"not null;index" json:"userID"`
+	Purpose   TokenPurpose `gorm:"type:varchar(32);not null;index" json:"purpose"`
+	TokenHash string       `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time    `gorm:"not null" json:"expiresAt"`
+	UsedAt    *time.Time   `json:"usedAt,omitempty"`
+	Attempts  int          `gorm:"not null;default:0" json:"attempts"` // Failed attempts to redeem the token
+}
+
+// IsUsable reports whether the token is unused and unexpired at time now.
+func (t *UserToken) IsUsable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
@@ -9,7 +11,15 @@ type User struct {
 	gorm.Model                       // Includes ID, CreatedAt, UpdatedAt, DeletedAt
 	Username           string        `gorm:"uniqueIndex;not null" json:"username"`
 	Email              string        `gorm:"uniqueIndex;not null" json:"email"`
-	Password           string        `gorm:"not null" json:"-"`                  // json:"-" to hide password hash
+	Password           string        `gorm:"not null" json:"-"` // json:"-" to hide password hash
+	DisplayName        string        `gorm:"type:varchar(100)" json:"displayName"`
+	AvatarURL          string        `gorm:"type:varchar(2048)" json:"avatarURL"`
+	EmailVerified      bool          `gorm:"not null;default:false" json:"emailVerified"`
+	EmailVerifiedAt    *time.Time    `json:"emailVerifiedAt,omitempty"`
+	IsAdmin            bool          `gorm:"not null;default:false" json:"isAdmin"`
+	TwoFactorEnabled   bool          `gorm:"not null;default:false" json:"twoFactorEnabled"`
+	TwoFactorSecret    string        `gorm:"type:varchar(64)" json:"-"`          // Base32 TOTP secret; set but not enabled while enrollment is pending
+	TwoFactorLastStep  int64         `gorm:"not null;default:0" json:"-"`        // Last TOTP time step used, so a code can't be replayed
 	Boards             []Board       `gorm:"foreignKey:OwnerID" json:"-"`        // Boards owned by this user
 	MemberOfBoards     []BoardMember `gorm:"foreignKey:UserID" json:"-"`         // Boards this user is a member of
 	AssignedCards      []Card        `gorm:"foreignKey:AssignedUserID" json:"-"` // Cards assigned to this user
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WorkspaceRole is the permission level a member holds in a workspace.
+type WorkspaceRole string
+
+const (
+	WorkspaceRoleAdmin  WorkspaceRole = "admin"  // Manage the workspace, its members and its boards
+	WorkspaceRoleMember WorkspaceRole = "member" // Create boards and use the workspace's shared boards
+)
+
+// rank orders roles from least to most privileged. Unknown roles rank lowest.
+func (r WorkspaceRole) rank() int {
+	switch r {
+	case WorkspaceRoleAdmin:
+		return 2
+	case WorkspaceRoleMember:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// IsValid reports whether r is one of the predefined workspace roles.
+func (r WorkspaceRole) IsValid() bool {
+	return r.rank() > 0
+}
+
+// AtLeast reports whether r grants at least the privileges of min.
+func (r WorkspaceRole) AtLeast(min WorkspaceRole) bool {
+	return r.IsValid() && r.rank() >= min.rank()
+}
+
+// Workspace groups boards and the people who work on them, so a team can
+// share boards without adding everyone to each board by hand.
+type Workspace struct {
+	gorm.Model
+	Name        string            `gorm:"not null" json:"name"`
+	Description string            `json:"description"`
+	Members     []WorkspaceMember `gorm:"foreignKey:WorkspaceID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"members,omitempty"`
+}
+
+// WorkspaceMember links a user to a workspace with a role.
+type WorkspaceMember struct {
+	WorkspaceID uint          `gorm:"primaryKey" json:"workspaceID"`
+	UserID      uint          `gorm:"primaryKey" json:"userID"`
+	User        User          `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"user,omitempty"`
+	Role        WorkspaceRole `gorm:"type:varchar(20);not null;default:'member'" json:"role"`
+	CreatedAt   time.Time     `json:"createdAt"`
+}
@@ -0,0 +1,159 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/zayyadi/trello/oidc"
+	"github.com/zayyadi/trello/oidc/oidctest"
+)
+
+const testRedirectURL = "http://app.test/auth/oidc/callback"
+
+func newTestProvider(t *testing.T) (*oidctest.Provider, *oidc.Provider) {
+	idp := oidctest.NewProvider("trello-client", "trello-secret")
+	t.Cleanup(idp.Close)
+	rp := oidc.NewProvider(oidc.Config{
+		IssuerURL:    idp.Issuer(),
+		ClientID:     "trello-client",
+		ClientSecret: "trello-secret",
+		RedirectURL:  testRedirectURL,
+		Scopes:       []string{"email", "profile"},
+	}, nil)
+	return idp, rp
+}
+
+// loginAtProvider simulates the browser round trip: it reads the parameters our
+// authorization URL sends and has the stand-in provider issue a code for them.
+func loginAtProvider(t *testing.T, idp *oidctest.Provider, rp *oidc.Provider, flow *oidc.FlowState, user oidctest.User) string {
+	authURL, err := rp.AuthCodeURL(context.Background(), flow.State, flow.Nonce, flow.CodeVerifier)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	u, err := url.Parse(authURL)
+	assert.NoError(t, err)
+	q := u.Query()
+	return idp.IssueCode(user, q.Get("nonce"), q.Get("redirect_uri"), q.Get("code_challenge"))
+}
+
+func TestProvider_AuthCodeURL(t *testing.T) {
+	idp, rp := newTestProvider(t)
+	flow, err := oidc.NewFlowState()
+	assert.NoError(t, err)
+
+	authURL, err := rp.AuthCodeURL(context.Background(), flow.State, flow.Nonce, flow.CodeVerifier)
+	assert.NoError(t, err)
+
+	u, err := url.Parse(authURL)
+	assert.NoError(t, err)
+	assert.Equal(t, idp.Issuer()+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+	q := u.Query()
+	assert.Equal(t, "code", q.Get("response_type"))
+	assert.Equal(t, "trello-client", q.Get("client_id"))
+	assert.Equal(t, testRedirectURL, q.Get("redirect_uri"))
+	assert.Equal(t, "openid email profile", q.Get("scope"))
+	assert.Equal(t, flow.State, q.Get("state"))
+	assert.Equal(t, flow.Nonce, q.Get("nonce"))
+	assert.Equal(t, "S256", q.Get("code_challenge_method"))
+	assert.Equal(t, oidc.CodeChallenge(flow.CodeVerifier), q.Get("code_challenge"))
+	assert.NotContains(t, authURL, flow.CodeVerifier, "the PKCE verifier must never leave the server")
+}
+
+func TestProvider_Exchange_Success(t *testing.T) {
+	idp, rp := newTestProvider(t)
+	flow, _ := oidc.NewFlowState()
+	code := loginAtProvider(t, idp, rp, flow, oidctest.User{
+		Subject: "user-123", Email: "jane@example.com", EmailVerified: true, Name: "Jane", PreferredUsername: "jane",
+	})
+
+	identity, err := rp.Exchange(context.Background(), code, flow.CodeVerifier, flow.Nonce)
+	assert.NoError(t, err)
+	assert.Equal(t, idp.Issuer(), identity.Issuer)
+	assert.Equal(t, "user-123", identity.Subject)
+	assert.Equal(t, "jane@example.com", identity.Email)
+	assert.True(t, identity.EmailVerified)
+	assert.Equal(t, "Jane", identity.Name)
+	assert.Equal(t, "jane", identity.PreferredUsername)
+
+	// Codes are single use
+	_, err = rp.Exchange(context.Background(), code, flow.CodeVerifier, flow.Nonce)
+	assert.Error(t, err)
+}
+
+func TestProvider_Exchange_WrongCodeVerifier(t *testing.T) {
+	idp, rp := newTestProvider(t)
+	flow, _ := oidc.NewFlowState()
+	code := loginAtProvider(t, idp, rp, flow, oidctest.User{Subject: "user-123"})
+
+	_, err := rp.Exchange(context.Background(), code, "not-the-verifier", flow.Nonce)
+	assert.Error(t, err)
+}
+
+func TestProvider_Exchange_NonceMismatch(t *testing.T) {
+	idp, rp := newTestProvider(t)
+	flow, _ := oidc.NewFlowState()
+	code := loginAtProvider(t, idp, rp, flow, oidctest.User{Subject: "user-123"})
+
+	_, err := rp.Exchange(context.Background(), code, flow.CodeVerifier, "another-nonce")
+	assert.ErrorIs(t, err, oidc.ErrNonceMismatch)
+}
+
+func TestProvider_VerifyIDToken_Rejections(t *testing.T) {
+	idp, rp := newTestProvider(t)
+	user := oidctest.User{Subject: "user-123", Email: "jane@example.com"}
+
+	idp.Audience = "some-other-client"
+	token, err := idp.SignIDToken(user, "n")
+	assert.NoError(t, err)
+	_, err = rp.VerifyIDToken(context.Background(), token, "n")
+	assert.ErrorIs(t, err, oidc.ErrInvalidIDToken, "token for another client")
+
+	idp.Audience = ""
+	idp.TokenTTL = -time.Hour
+	token, err = idp.SignIDToken(user, "n")
+	assert.NoError(t, err)
+	_, err = rp.VerifyIDToken(context.Background(), token, "n")
+	assert.ErrorIs(t, err, oidc.ErrInvalidIDToken, "expired token")
+
+	// Correct claims and key ID, but signed by someone else's key
+	forgerKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	forged := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss": idp.Issuer(), "sub": "user-123", "aud": "trello-client", "nonce": "n",
+		"exp": time.Now().Add(time.Minute).Unix(),
+	})
+	forged.Header["kid"] = idp.KeyID
+	token, err = forged.SignedString(forgerKey)
+	assert.NoError(t, err)
+	_, err = rp.VerifyIDToken(context.Background(), token, "n")
+	assert.ErrorIs(t, err, oidc.ErrInvalidIDToken, "forged signature")
+}
+
+func TestProvider_DiscoveryIssuerMismatch(t *testing.T) {
+	idp := oidctest.NewProvider("trello-client", "trello-secret")
+	defer idp.Close()
+	rp := oidc.NewProvider(oidc.Config{IssuerURL: idp.Issuer() + "/other", ClientID: "trello-client"}, nil)
+
+	_, err := rp.AuthCodeURL(context.Background(), "s", "n", "v")
+	assert.Error(t, err)
+}
+
+func TestFlowState_EncodeDecodeAndCheck(t *testing.T) {
+	flow, err := oidc.NewFlowState()
+	assert.NoError(t, err)
+
+	decoded, err := oidc.DecodeFlowState(flow.Encode())
+	assert.NoError(t, err)
+	assert.Equal(t, flow, decoded)
+	assert.NoError(t, decoded.CheckState(flow.State))
+	assert.ErrorIs(t, decoded.CheckState("forged"), oidc.ErrStateMismatch)
+
+	_, err = oidc.DecodeFlowState("garbage")
+	assert.Error(t, err)
+}
@@ -0,0 +1,172 @@
+// Package policy decides whether a user may perform an action on a board or
+// on one of the resources (lists, cards, comments) that live inside it, and
+// on the workspaces boards are grouped in.
+//
+// Services resolve who the caller is relative to the board (owner, role,
+// card participant) and ask Can; the permission matrix itself lives only here.
+package policy
+
+import (
+	"github.com/zayyadi/trello/models"
+)
+
+// Action is something a user can attempt on a board or its contents.
+type Action string
+
+const (
+	// Board-wide read access. Covers the board itself, its members, lists,
+	// cards, card collaborators and comments.
+	ViewBoard Action = "board:view"
+
+	UpdateBoard   Action = "board:update"
+	DeleteBoard   Action = "board:delete"
+	ManageMembers Action = "board:manage_members"
+	// Hand the board to another member. Site admins may also do this, see BoardService.TransferOwnership.
+	TransferOwnership Action = "board:transfer_ownership"
+
+	CreateList Action = "list:create"
+	UpdateList Action = "list:update"
+	DeleteList Action = "list:delete"
+
+	CreateCard          Action = "card:create"
+	EditCard            Action = "card:edit"   // Description, dates, assignee, status, color, labels, position
+	RenameCard          Action = "card:rename" // Title changes
+	MoveCard            Action = "card:move"   // Between lists
+	DeleteCard          Action = "card:delete"
+	ManageCollaborators Action = "card:manage_collaborators"
+
+	CreateComment Action = "comment:create"
+
+	// Archiving is undone with the same action. Archived boards are read-only otherwise.
+	ArchiveBoard Action = "board:archive"
+	ArchiveList  Action = "list:archive"
+	ArchiveCard  Action = "card:archive"
+	// See and restore the board's deleted lists and cards.
+	ManageTrash Action = "board:manage_trash"
+
+	// Copy the board into a new board of one's own, e.g. to start a board from a template.
+	CopyBoard Action = "board:copy"
+	// Create and revoke links that let anyone view the board without logging in.
+	ShareBoard Action = "board:share"
+
+	// Create, rename, recolor and delete the board's labels. Putting labels on
+	// cards is part of EditCard.
+	ManageLabels Action = "board:manage_labels"
+)
+
+// Workspace actions, checked with CanInWorkspace.
+const (
+	ViewWorkspace          Action = "workspace:view" // The workspace, its members and its boards
+	UpdateWorkspace        Action = "workspace:update"
+	DeleteWorkspace        Action = "workspace:delete"
+	ManageWorkspaceMembers Action = "workspace:manage_members"
+	CreateWorkspaceBoard   Action = "workspace:create_board" // Create a board in, or move a board into, the workspace
+)
+
+// Subject describes the caller's relationship to the board an action targets.
+type Subject struct {
+	UserID uint
+	// Role is the caller's role on the board. Empty if they have none.
+	Role models.BoardRole
+	// IsOwner is true when the caller owns the board.
+	IsOwner bool
+	// IsCardParticipant is true when the caller is a collaborator on, or the
+	// assignee of, the card being acted upon. Only meaningful for card actions.
+	IsCardParticipant bool
+}
+
+// rule is one row of the permission matrix.
+type rule struct {
+	minRole models.BoardRole // Lowest role allowed to perform the action
+	// participantRole, if set, lets card participants through with this lower role.
+	participantRole models.BoardRole
+	ownerOnly       bool // Only the board owner may perform the action
+}
+
+// rules is the permission matrix. Actions missing from it are always denied.
+var rules = map[Action]rule{
+	ViewBoard:     {minRole: models.BoardRoleViewer},
+	UpdateBoard:   {minRole: models.BoardRoleAdmin},
+	DeleteBoard:   {ownerOnly: true},
+	ManageMembers: {minRole: models.BoardRoleAdmin},
+
+	TransferOwnership: {ownerOnly: true},
+
+	CreateList: {minRole: models.BoardRoleMember},
+	UpdateList: {minRole: models.BoardRoleMember},
+	DeleteList: {minRole: models.BoardRoleAdmin},
+
+	CreateCard:          {minRole: models.BoardRoleMember},
+	EditCard:            {minRole: models.BoardRoleAdmin, participantRole: models.BoardRoleMember},
+	RenameCard:          {minRole: models.BoardRoleAdmin},
+	MoveCard:            {minRole: models.BoardRoleMember},
+	DeleteCard:          {minRole: models.BoardRoleAdmin},
+	ManageCollaborators: {minRole: models.BoardRoleAdmin},
+
+	CreateComment: {minRole: models.BoardRoleMember},
+
+	ArchiveBoard: {minRole: models.BoardRoleAdmin},
+	ArchiveList:  {minRole: models.BoardRoleMember},
+	ArchiveCard:  {minRole: models.BoardRoleAdmin, participantRole: models.BoardRoleMember},
+	ManageTrash:  {minRole: models.BoardRoleAdmin},
+
+	CopyBoard:  {minRole: models.BoardRoleViewer},
+	ShareBoard: {minRole: models.BoardRoleAdmin},
+
+	ManageLabels: {minRole: models.BoardRoleMember},
+}
+
+// Can reports whether s may perform action.
+// The board owner may do anything; everyone else is judged by their role.
+func Can(s Subject, action Action) bool {
+	r, ok := rules[action]
+	if !ok {
+		return false
+	}
+	if s.IsOwner {
+		return true
+	}
+	if r.ownerOnly {
+		return false
+	}
+	if s.Role.AtLeast(r.minRole) {
+		return true
+	}
+	return r.participantRole != "" && s.IsCardParticipant && s.Role.AtLeast(r.participantRole)
+}
+
+// WorkspaceSubject describes the caller's membership of a workspace.
+type WorkspaceSubject struct {
+	UserID uint
+	// Role is the caller's role in the workspace. Empty if they are not a member.
+	Role models.WorkspaceRole
+}
+
+// workspaceRules is the permission matrix for workspace actions: the lowest role allowed.
+var workspaceRules = map[Action]models.WorkspaceRole{
+	ViewWorkspace:          models.WorkspaceRoleMember,
+	UpdateWorkspace:        models.WorkspaceRoleAdmin,
+	DeleteWorkspace:        models.WorkspaceRoleAdmin,
+	ManageWorkspaceMembers: models.WorkspaceRoleAdmin,
+	CreateWorkspaceBoard:   models.WorkspaceRoleMember,
+}
+
+// CanInWorkspace reports whether s may perform a workspace action.
+func CanInWorkspace(s WorkspaceSubject, action Action) bool {
+	minRole, ok := workspaceRules[action]
+	return ok && s.Role.AtLeast(minRole)
+}
+
+// WorkspaceBoardRole is the board role a workspace member gets on the
+// workspace's boards that have workspace visibility. Workspace admins run
+// those boards; everyone else in the workspace can work on them.
+func WorkspaceBoardRole(role models.WorkspaceRole) models.BoardRole {
+	switch role {
+	case models.WorkspaceRoleAdmin:
+		return models.BoardRoleAdmin
+	case models.WorkspaceRoleMember:
+		return models.BoardRoleMember
+	default:
+		return ""
+	}
+}
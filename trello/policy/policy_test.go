@@ -0,0 +1,123 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zayyadi/trello/models"
+)
+
+func TestCan_PermissionMatrix(t *testing.T) {
+	owner := Subject{UserID: 1, Role: models.BoardRoleAdmin, IsOwner: true}
+	admin := Subject{UserID: 2, Role: models.BoardRoleAdmin}
+	member := Subject{UserID: 3, Role: models.BoardRoleMember}
+	viewer := Subject{UserID: 4, Role: models.BoardRoleViewer}
+	outsider := Subject{UserID: 5}
+
+	// Each row lists the expected outcome for owner, admin, member, viewer and outsider.
+	tests := []struct {
+		action   Action
+		owner    bool
+		admin    bool
+		member   bool
+		viewer   bool
+		outsider bool
+	}{
+		{ViewBoard, true, true, true, true, false},
+		{UpdateBoard, true, true, false, false, false},
+		{DeleteBoard, true, false, false, false, false},
+		{ManageMembers, true, true, false, false, false},
+		{TransferOwnership, true, false, false, false, false},
+		{CreateList, true, true, true, false, false},
+		{UpdateList, true, true, true, false, false},
+		{DeleteList, true, true, false, false, false},
+		{CreateCard, true, true, true, false, false},
+		{EditCard, true, true, false, false, false},
+		{RenameCard, true, true, false, false, false},
+		{MoveCard, true, true, true, false, false},
+		{DeleteCard, true, true, false, false, false},
+		{ManageCollaborators, true, true, false, false, false},
+		{CreateComment, true, true, true, false, false},
+		{ArchiveBoard, true, true, false, false, false},
+		{ArchiveList, true, true, true, false, false},
+		{ArchiveCard, true, true, false, false, false},
+		{ManageTrash, true, true, false, false, false},
+		{CopyBoard, true, true, true, true, false},
+		{ShareBoard, true, true, false, false, false},
+		{ManageLabels, true, true, true, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.action), func(t *testing.T) {
+			assert.Equal(t, tt.owner, Can(owner, tt.action), "owner")
+			assert.Equal(t, tt.admin, Can(admin, tt.action), "admin")
+			assert.Equal(t, tt.member, Can(member, tt.action), "member")
+			assert.Equal(t, tt.viewer, Can(viewer, tt.action), "viewer")
+			assert.Equal(t, tt.outsider, Can(outsider, tt.action), "outsider")
+		})
+	}
+}
+
+func TestCan_CardParticipants(t *testing.T) {
+	tests := []struct {
+		name     string
+		subject  Subject
+		action   Action
+		expected bool
+	}{
+		{"Member participant can edit card", Subject{Role: models.BoardRoleMember, IsCardParticipant: true}, EditCard, true},
+		{"Member participant cannot rename card", Subject{Role: models.BoardRoleMember, IsCardParticipant: true}, RenameCard, false},
+		{"Member participant cannot delete card", Subject{Role: models.BoardRoleMember, IsCardParticipant: true}, DeleteCard, false},
+		{"Member participant can archive card", Subject{Role: models.BoardRoleMember, IsCardParticipant: true}, ArchiveCard, true},
+		{"Viewer participant cannot edit card", Subject{Role: models.BoardRoleViewer, IsCardParticipant: true}, EditCard, false},
+		{"Outsider participant cannot edit card", Subject{IsCardParticipant: true}, EditCard, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Can(tt.subject, tt.action))
+		})
+	}
+}
+
+func TestCan_UnknownActionDenied(t *testing.T) {
+	assert.False(t, Can(Subject{Role: models.BoardRoleAdmin, IsOwner: true}, Action("board:launch")))
+}
+
+func TestCan_InvalidRoleDenied(t *testing.T) {
+	assert.False(t, Can(Subject{Role: models.BoardRole("superuser")}, ViewBoard))
+}
+
+func TestCanInWorkspace(t *testing.T) {
+	admin := WorkspaceSubject{UserID: 1, Role: models.WorkspaceRoleAdmin}
+	member := WorkspaceSubject{UserID: 2, Role: models.WorkspaceRoleMember}
+	outsider := WorkspaceSubject{UserID: 3}
+
+	tests := []struct {
+		action   Action
+		admin    bool
+		member   bool
+		outsider bool
+	}{
+		{ViewWorkspace, true, true, false},
+		{UpdateWorkspace, true, false, false},
+		{DeleteWorkspace, true, false, false},
+		{ManageWorkspaceMembers, true, false, false},
+		{CreateWorkspaceBoard, true, true, false},
+		{ViewBoard, false, false, false}, // Board actions are not workspace actions
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.action), func(t *testing.T) {
+			assert.Equal(t, tt.admin, CanInWorkspace(admin, tt.action), "admin")
+			assert.Equal(t, tt.member, CanInWorkspace(member, tt.action), "member")
+			assert.Equal(t, tt.outsider, CanInWorkspace(outsider, tt.action), "outsider")
+		})
+	}
+}
+
+func TestWorkspaceBoardRole(t *testing.T) {
+	assert.Equal(t, models.BoardRoleAdmin, WorkspaceBoardRole(models.WorkspaceRoleAdmin))
+	assert.Equal(t, models.BoardRoleMember, WorkspaceBoardRole(models.WorkspaceRoleMember))
+	assert.Equal(t, models.BoardRole(""), WorkspaceBoardRole(models.WorkspaceRole("owner")))
+}
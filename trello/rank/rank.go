@@ -0,0 +1,147 @@
+// Package rank generates sortable keys that order the lists of a board and the
+// cards of a list. A rank is a string of the digits 0-9 and a-z read as a
+// base-36 fraction: "i" is 0.5 and "9" is 0.25. There is always another rank
+// between two different ones, so moving an item only changes its own rank.
+//
+// Ranks sort the same way byte by byte and in the database's default
+// collation, since they only hold digits and lowercase letters.
+package rank
+
+import (
+	"errors"
+	"strings"
+)
+
+const digits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+const base = len(digits)
+
+// ErrInvalid is returned by Between for a rank that isn't valid, or bounds
+// that are the wrong way round or the same.
+var ErrInvalid = errors.New("invalid rank bounds")
+
+// Valid says whether r is a rank: at least one digit, not ending in "0" so
+// there is always room before it.
+func Valid(r string) bool {
+	if r == "" || r[len(r)-1] == '0' {
+		return false
+	}
+	for i := 0; i < len(r); i++ {
+		if strings.IndexByte(digits, r[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Between returns a rank that sorts after a and before b. An empty a means the
+// start and an empty b the end, so Between("", "") is the first rank of an
+// empty list. Between two ranks the result is as short as possible, and grows
+// by about one digit for every five ranks squeezed into the same gap. At the
+// start and end ranks step by one digit instead, so adding items there only
+// makes them longer every seventeen or so.
+func Between(a, b string) (string, error) {
+	if (a != "" && !Valid(a)) || (b != "" && !Valid(b)) || (a != "" && b != "" && a >= b) {
+		return "", ErrInvalid
+	}
+	switch {
+	case a != "" && b == "":
+		return after(a), nil
+	case a == "" && b != "":
+		return before(b), nil
+	}
+	return midpoint(a, b), nil
+}
+
+// Spread returns n ranks in order, evenly spaced so that there is room for
+// several moves into every gap before ranks get longer.
+func Spread(n int) []string {
+	if n <= 0 {
+		return nil
+	}
+	// The smallest number of digits that leaves a gap of at least base between neighbours
+	width, space := 1, uint64(base)
+	for space/uint64(n+1) < uint64(base) {
+		width++
+		space *= uint64(base)
+	}
+	step := space / uint64(n+1)
+	ranks := make([]string, n)
+	buf := make([]byte, width)
+	for i := range ranks {
+		value := step * uint64(i+1)
+		for j := width - 1; j >= 0; j-- {
+			buf[j] = digits[value%uint64(base)]
+			value /= uint64(base)
+		}
+		ranks[i] = strings.TrimRight(string(buf), "0")
+	}
+	return ranks
+}
+
+// after returns a short rank after r by stepping up its first digit below z.
+func after(r string) string {
+	for i := 0; i < len(r); i++ {
+		if d := digitOf(r[i]); d < base-1 {
+			return r[:i] + string(digits[d+1])
+		}
+	}
+	return midpoint(r, "")
+}
+
+// before returns a short rank before r by stepping down its first digit above 1.
+func before(r string) string {
+	for i := 0; i < len(r); i++ {
+		if d := digitOf(r[i]); d > 1 {
+			return r[:i] + string(digits[d-1])
+		}
+	}
+	return midpoint("", r)
+}
+
+// midpoint finds a rank between a and b, where a < b and either may be empty.
+func midpoint(a, b string) string {
+	if b != "" {
+		// Keep the digits they share; a counts as padded with zeros
+		n := 0
+		for n < len(b) && digitAt(a, n) == digitOf(b[n]) {
+			n++
+		}
+		if n > 0 {
+			rest := ""
+			if n < len(a) {
+				rest = a[n:]
+			}
+			return b[:n] + midpoint(rest, b[n:])
+		}
+	}
+
+	low, high := digitAt(a, 0), base
+	if b != "" {
+		high = digitOf(b[0])
+	}
+	if high-low > 1 {
+		return string(digits[(low+high)/2])
+	}
+	// The first digits are next to each other
+	if len(b) > 1 {
+		return b[:1]
+	}
+	rest := ""
+	if len(a) > 1 {
+		rest = a[1:]
+	}
+	return string(digits[low]) + midpoint(rest, "")
+}
+
+// digitAt returns the value of r's i'th digit, or 0 past its end.
+func digitAt(r string, i int) int {
+	if i >= len(r) {
+		return 0
+	}
+	return digitOf(r[i])
+}
+
+func digitOf(c byte) int {
+	return strings.IndexByte(digits, c)
+}
@@ -0,0 +1,82 @@
+package rank
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBetween(t *testing.T) {
+	for _, tt := range []struct{ a, b, want string }{
+		{"", "", "i"},
+		{"i", "", "j"},
+		{"i5", "", "j"},
+		{"", "i", "h"},
+		{"", "05", "04"},
+		{"a", "b", "ai"},
+		{"z", "", "zi"},
+		{"", "1", "0i"},
+		{"a", "a1", "a0i"},
+		{"a", "a05", "a02"},
+		{"a5", "b", "ak"},
+	} {
+		got, err := Between(tt.a, tt.b)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, got, "between %q and %q", tt.a, tt.b)
+	}
+
+	for _, bounds := range [][2]string{{"b", "a"}, {"a", "a"}, {"a0", ""}, {"", "A"}} {
+		_, err := Between(bounds[0], bounds[1])
+		assert.ErrorIs(t, err, ErrInvalid, "between %q and %q", bounds[0], bounds[1])
+	}
+}
+
+func TestBetween_KeepsOrderThroughRandomInserts(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	var ranks []string
+	for i := 0; i < 2000; i++ {
+		at := random.Intn(len(ranks) + 1)
+		var before, after string
+		if at > 0 {
+			before = ranks[at-1]
+		}
+		if at < len(ranks) {
+			after = ranks[at]
+		}
+		r, err := Between(before, after)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.True(t, Valid(r))
+		ranks = append(ranks[:at], append([]string{r}, ranks[at:]...)...)
+	}
+	assert.True(t, sort.StringsAreSorted(ranks))
+}
+
+func TestBetween_GrowsSlowlyAtTheEnds(t *testing.T) {
+	last, first := "", ""
+	for i := 0; i < 100; i++ {
+		last, _ = Between(last, "")
+		first, _ = Between("", first)
+	}
+	assert.LessOrEqual(t, len(last), 7)
+	assert.LessOrEqual(t, len(first), 7)
+}
+
+func TestSpread(t *testing.T) {
+	assert.Nil(t, Spread(0))
+	assert.Equal(t, []string{"i"}, Spread(1))
+	assert.Equal(t, []string{"9", "i", "r"}, Spread(3))
+
+	ranks := Spread(5000)
+	assert.Len(t, ranks, 5000)
+	assert.True(t, sort.StringsAreSorted(ranks))
+	for i, r := range ranks {
+		assert.True(t, Valid(r), r)
+		if i > 0 {
+			assert.NotEqual(t, ranks[i-1], r)
+		}
+	}
+}
@@ -32,6 +32,14 @@ type Client struct {
 	BoardID uint // Changed to BoardID to match ws_handler.go
 	// UserID of the connected user.
 	UserID uint
+	// SessionID of the access token the user connected with, so the connection
+	// can be closed when the session is revoked.
+	SessionID string
+
+	// Anonymous clients follow a public or link-shared board without logging in.
+	// They get messages without personal details, see Hub.Run.
+	Anonymous   bool
+	ShareLinkID uint // The share link they came through; zero if they came because the board is public
 }
 
 // readPump pumps messages from the websocket connection to the hub.
@@ -3,6 +3,8 @@ package realtime
 import (
 	"encoding/json"
 	"log"
+
+	"github.com/zayyadi/trello/dto"
 )
 
 // Hub maintains the set of active clients and broadcasts messages to the
@@ -19,6 +21,35 @@ type Hub struct {
 
 	// Unregister requests from clients.
 	unregister chan *Client
+
+	// Requests to drop anonymous clients who lost access to a board.
+	disconnect chan anonymousAccess
+
+	// Requests to drop a user's clients, e.g. when they were signed out.
+	disconnectUser chan userAccess
+}
+
+// AnyShareLink, as a share link ID, stands for all of a board's anonymous
+// clients, however they came in.
+const AnyShareLink = ^uint(0)
+
+// anonymousAccess identifies the anonymous clients of a board that came in one way:
+// through the share link with ShareLinkID, or because the board is public if it is zero.
+type anonymousAccess struct {
+	BoardID     uint
+	ShareLinkID uint
+}
+
+// AnyBoard, as a board ID, stands for every board a user is connected to.
+const AnyBoard = ^uint(0)
+
+// userAccess identifies a user's clients on the board with BoardID, or on every
+// board for AnyBoard. If SessionID is set, only those the user opened with an
+// access token of that session.
+type userAccess struct {
+	UserID    uint
+	BoardID   uint
+	SessionID string
 }
 
 // NewHub creates a new Hub instance.
@@ -27,7 +58,10 @@ func NewHub() *Hub {
 		broadcast:  make(chan *WebSocketMessage), // Use WebSocketMessage
 		Register:   make(chan *Client),
 		unregister: make(chan *Client),
+		disconnect: make(chan anonymousAccess),
 		clients:    make(map[uint]map[*Client]bool),
+
+		disconnectUser: make(chan userAccess),
 	}
 }
 
@@ -42,6 +76,26 @@ func (h *Hub) Submit(msg *WebSocketMessage) {
 	h.broadcast <- msg
 }
 
+// DisconnectAnonymous closes the connections of the board's anonymous clients that
+// came through the share link with shareLinkID, or because the board is public if
+// it is zero, or all of them for AnyShareLink. Services call it when that access ends.
+func (h *Hub) DisconnectAnonymous(boardID, shareLinkID uint) {
+	h.disconnect <- anonymousAccess{BoardID: boardID, ShareLinkID: shareLinkID}
+}
+
+// DisconnectUser closes the user's connections to the board, or to every board
+// for AnyBoard. Services call it when the user loses access, e.g. when they are
+// removed from the board or signed out everywhere.
+func (h *Hub) DisconnectUser(userID, boardID uint) {
+	h.disconnectUser <- userAccess{UserID: userID, BoardID: boardID}
+}
+
+// DisconnectSession closes the connections the user opened with an access token
+// of the session, once the session is revoked.
+func (h *Hub) DisconnectSession(userID uint, sessionID string) {
+	h.disconnectUser <- userAccess{UserID: userID, BoardID: AnyBoard, SessionID: sessionID}
+}
+
 // Run starts the hub's event loop.
 func (h *Hub) Run() {
 	for {
@@ -66,6 +120,33 @@ func (h *Hub) Run() {
 					log.Printf("Client unregistered from board %d. Total clients on board: %d", client.BoardID, len(boardClients))
 				}
 			}
+		case access := <-h.disconnect:
+			boardClients := h.clients[access.BoardID]
+			for client := range boardClients {
+				if client.Anonymous && (access.ShareLinkID == AnyShareLink || client.ShareLinkID == access.ShareLinkID) {
+					delete(boardClients, client)
+					close(client.Send) // WritePump sends a close message and the pumps clean up
+				}
+			}
+			if len(boardClients) == 0 {
+				delete(h.clients, access.BoardID)
+			}
+		case access := <-h.disconnectUser:
+			for boardID, boardClients := range h.clients {
+				if access.BoardID != AnyBoard && boardID != access.BoardID {
+					continue
+				}
+				for client := range boardClients {
+					if !client.Anonymous && client.UserID == access.UserID &&
+						(access.SessionID == "" || client.SessionID == access.SessionID) {
+						delete(boardClients, client)
+						close(client.Send)
+					}
+				}
+				if len(boardClients) == 0 {
+					delete(h.clients, boardID)
+				}
+			}
 		case wsMessage := <-h.broadcast:
 			boardClients, ok := h.clients[wsMessage.BoardID]
 			if !ok {
@@ -89,6 +170,8 @@ func (h *Hub) Run() {
 			}
 
 			log.Printf("Broadcasting message type '%s' to %d clients on board %d (Originating UserID: %d)", wsMessage.Type, len(boardClients), wsMessage.BoardID, wsMessage.UserID)
+			var anonymousBytes []byte // Made on demand, nil if the message isn't for anonymous clients
+			anonymousReady := false
 			for client := range boardClients {
 				// Don't send message back to the originating user if UserID is set on the message
 				// and the client has a UserID.
@@ -96,9 +179,20 @@ func (h *Hub) Run() {
 					log.Printf("Skipping broadcast to originating client (User: %d) for message type '%s' on board %d", client.UserID, wsMessage.Type, wsMessage.BoardID)
 					continue
 				}
+				toSend := messageBytes
+				if client.Anonymous {
+					if !anonymousReady {
+						anonymousBytes = anonymousMessage(wsMessage.Type, messageBytes)
+						anonymousReady = true
+					}
+					if anonymousBytes == nil {
+						continue
+					}
+					toSend = anonymousBytes
+				}
 
 				select {
-				case client.Send <- messageBytes:
+				case client.Send <- toSend:
 				default:
 					// If the client's send buffer is full, it implies the client is slow
 					// or disconnected. readPump/writePump will handle actual unregistration.
@@ -115,3 +209,101 @@ func (h *Hub) Run() {
 		}
 	}
 }
+
+// Anonymous clients see what the public board view shows, dto.MapPublicBoardToResponse:
+// no one but the owner, and of cards no more than their labels and checklist
+// progress. These wrap the public DTOs with the fields a client needs to apply
+// a change, and leave out lists and cards a message doesn't carry.
+type publicBoardPayload struct {
+	dto.PublicBoardResponse
+	Lists []dto.PublicListResponse `json:"lists,omitempty"`
+}
+
+type publicListPayload struct {
+	dto.PublicListResponse
+	Cards []publicCardPayload `json:"cards,omitempty"`
+}
+
+type publicCardPayload struct {
+	dto.PublicCardResponse
+	ListID uint `json:"listID"`
+}
+
+// anonymousPayloads are the only message types anonymous clients get. Each
+// gives the public payload to decode the message's payload into, or is nil
+// when the payload only has IDs and positions and is sent as is.
+var anonymousPayloads = map[string]func() interface{}{
+	MessageTypeBoardUpdated:    func() interface{} { return &publicBoardPayload{} },
+	MessageTypeBoardDeleted:    nil,
+	MessageTypeBoardArchived:   func() interface{} { return &publicBoardPayload{} },
+	MessageTypeBoardUnarchived: func() interface{} { return &publicBoardPayload{} },
+
+	MessageTypeListCreated:    func() interface{} { return &publicListPayload{} },
+	MessageTypeListUpdated:    func() interface{} { return &publicListPayload{} },
+	MessageTypeListDeleted:    nil,
+	MessageTypeListsReordered: nil,
+	MessageTypeListArchived:   nil,
+	MessageTypeListUnarchived: func() interface{} { return &publicListPayload{} },
+	MessageTypeListRestored:   func() interface{} { return &publicListPayload{} },
+
+	MessageTypeCardCreated:    func() interface{} { return &publicCardPayload{} },
+	MessageTypeCardUpdated:    func() interface{} { return &publicCardPayload{} },
+	MessageTypeCardDeleted:    nil,
+	MessageTypeCardMoved:      nil,
+	MessageTypeCardsReordered: nil,
+	MessageTypeCardArchived:   nil,
+	MessageTypeCardUnarchived: func() interface{} { return &publicCardPayload{} },
+	MessageTypeCardRestored:   func() interface{} { return &publicCardPayload{} },
+
+	MessageTypeLabelCreated:     nil,
+	MessageTypeLabelUpdated:     nil,
+	MessageTypeLabelDeleted:     nil,
+	MessageTypeCardLabelAdded:   nil,
+	MessageTypeCardLabelRemoved: nil,
+}
+
+// anonymousMessage returns the message for anonymous clients, with its payload
+// mapped to the public view, or nil if they shouldn't get it at all.
+func anonymousMessage(messageType string, messageBytes []byte) []byte {
+	newPayload, ok := anonymousPayloads[messageType]
+	if !ok {
+		return nil
+	}
+	if newPayload == nil {
+		return messageBytes
+	}
+	var message struct {
+		Payload json.RawMessage `json:"payload"`
+	}
+	payload := newPayload()
+	if err := json.Unmarshal(messageBytes, &message); err != nil || json.Unmarshal(message.Payload, payload) != nil {
+		log.Printf("Error decoding WebSocket message type '%s' for anonymous clients", messageType)
+		return nil
+	}
+	anonymousBytes, err := json.Marshal(WebSocketMessage{Type: messageType, Payload: payload})
+	if err != nil {
+		log.Printf("Error marshalling WebSocket message type '%s' for anonymous clients: %v", messageType, err)
+		return nil
+	}
+	return anonymousBytes
+}
+
+// Broadcaster is implemented by anything that can accept messages for
+// delivery to board subscribers. Services depend on this rather than *Hub
+// so they can be exercised without a running hub.
+type Broadcaster interface {
+	Submit(msg *WebSocketMessage)
+}
+
+// AnonymousDisconnecter is implemented by broadcasters that can drop anonymous
+// clients, see Hub.DisconnectAnonymous.
+type AnonymousDisconnecter interface {
+	DisconnectAnonymous(boardID, shareLinkID uint)
+}
+
+// UserDisconnecter is implemented by broadcasters that can drop a user's clients,
+// see Hub.DisconnectUser and Hub.DisconnectSession.
+type UserDisconnecter interface {
+	DisconnectUser(userID, boardID uint)
+	DisconnectSession(userID uint, sessionID string)
+}
@@ -10,16 +10,18 @@ type WebSocketMessage struct {
 
 // Message Types Constants
 const (
-	MessageTypeBoardCreated      = "BOARD_CREATED"
-	MessageTypeBoardUpdated      = "BOARD_UPDATED"
-	MessageTypeBoardDeleted      = "BOARD_DELETED"
-	MessageTypeBoardMemberAdded  = "BOARD_MEMBER_ADDED"
-	MessageTypeBoardMemberRemoved = "BOARD_MEMBER_REMOVED"
+	MessageTypeBoardCreated           = "BOARD_CREATED"
+	MessageTypeBoardUpdated           = "BOARD_UPDATED"
+	MessageTypeBoardDeleted           = "BOARD_DELETED"
+	MessageTypeBoardMemberAdded       = "BOARD_MEMBER_ADDED"
+	MessageTypeBoardMemberRemoved     = "BOARD_MEMBER_REMOVED"
+	MessageTypeBoardMemberRoleUpdated = "BOARD_MEMBER_ROLE_UPDATED"
+	MessageTypeBoardOwnerChanged      = "BOARD_OWNER_CHANGED"
 
-	MessageTypeListCreated = "LIST_CREATED"
-	MessageTypeListUpdated = "LIST_UPDATED"
-	MessageTypeListDeleted = "LIST_DELETED"
-	// MessageTypeListMoved   = "LIST_MOVED" // If list reordering is implemented as a distinct event
+	MessageTypeListCreated    = "LIST_CREATED"
+	MessageTypeListUpdated    = "LIST_UPDATED"
+	MessageTypeListDeleted    = "LIST_DELETED"
+	MessageTypeListsReordered = "LISTS_REORDERED"
 
 	MessageTypeCardCreated             = "CARD_CREATED"
 	MessageTypeCardUpdated             = "CARD_UPDATED"
@@ -29,7 +31,40 @@ const (
 	MessageTypeCardUnassigned          = "CARD_UNASSIGNED"
 	MessageTypeCardCollaboratorAdded   = "CARD_COLLABORATOR_ADDED"
 	MessageTypeCardCollaboratorRemoved = "CARD_COLLABORATOR_REMOVED"
+	MessageTypeCardsReordered          = "CARDS_REORDERED"
 	// Add more as needed, e.g., CARD_COMMENT_ADDED
+
+	// Archiving and the trash. Unarchived and restored items carry the full list or card.
+	MessageTypeBoardArchived   = "BOARD_ARCHIVED"
+	MessageTypeBoardUnarchived = "BOARD_UNARCHIVED"
+	MessageTypeListArchived    = "LIST_ARCHIVED"
+	MessageTypeListUnarchived  = "LIST_UNARCHIVED"
+	MessageTypeListRestored    = "LIST_RESTORED"
+	MessageTypeCardArchived    = "CARD_ARCHIVED"
+	MessageTypeCardUnarchived  = "CARD_UNARCHIVED"
+	MessageTypeCardRestored    = "CARD_RESTORED"
+
+	// Board labels, and labels put on or taken off cards
+	MessageTypeLabelCreated     = "LABEL_CREATED"
+	MessageTypeLabelUpdated     = "LABEL_UPDATED"
+	MessageTypeLabelDeleted     = "LABEL_DELETED"
+	MessageTypeCardLabelAdded   = "CARD_LABEL_ADDED"
+	MessageTypeCardLabelRemoved = "CARD_LABEL_REMOVED"
+
+	// Card checklists and their items. Created and updated ones carry the full checklist or item.
+	MessageTypeChecklistCreated        = "CHECKLIST_CREATED"
+	MessageTypeChecklistUpdated        = "CHECKLIST_UPDATED"
+	MessageTypeChecklistDeleted        = "CHECKLIST_DELETED"
+	MessageTypeChecklistsReordered     = "CHECKLISTS_REORDERED"
+	MessageTypeChecklistItemCreated    = "CHECKLIST_ITEM_CREATED"
+	MessageTypeChecklistItemUpdated    = "CHECKLIST_ITEM_UPDATED"
+	MessageTypeChecklistItemDeleted    = "CHECKLIST_ITEM_DELETED"
+	MessageTypeChecklistItemsReordered = "CHECKLIST_ITEMS_REORDERED"
+
+	// Card attachments. Created ones carry the full attachment.
+	MessageTypeAttachmentCreated = "ATTACHMENT_CREATED"
+	MessageTypeAttachmentDeleted = "ATTACHMENT_DELETED"
+	MessageTypeCardCoverUpdated  = "CARD_COVER_UPDATED"
 )
 
 // Example Payloads (can also use DTOs from handlers package directly if suitable)
@@ -52,32 +87,109 @@ type CardBasicInfo struct {
 	BoardID uint `json:"boardId"` // Include BoardID for client-side context
 }
 
-// CardMovedPayload details the specifics of a card move operation
+// CardMovedPayload details the specifics of a card move operation. Only the
+// moved card changes; the others in both lists keep their positions.
 type CardMovedPayload struct {
-	CardID       uint `json:"cardId"`
-	OldListID    uint `json:"oldListId"`
-	NewListID    uint `json:"newListId"`
-	OldPosition  uint `json:"oldPosition"`
-	NewPosition  uint `json:"newPosition"`
-	BoardID      uint `json:"boardId"`      // For client-side context if card is moved between boards (not current model)
-	UpdatedCards []struct { // Optional: if positions of other cards in affected lists are sent
-		ID       uint `json:"id"`
-		Position uint `json:"position"`
-		ListID   uint `json:"listId"`
-	} `json:"updatedCards,omitempty"`
+	CardID      uint   `json:"cardId"`
+	OldListID   uint   `json:"oldListId"`
+	NewListID   uint   `json:"newListId"`
+	OldPosition string `json:"oldPosition"`
+	NewPosition string `json:"newPosition"`
+	BoardID     uint   `json:"boardId"` // Moves to another board are sent as CARD_DELETED and CARD_CREATED instead
+}
+
+// ListsReorderedPayload gives the new order of a board's lists, with their
+// new positions in the same order
+type ListsReorderedPayload struct {
+	BoardID   uint     `json:"boardId"`
+	ListIDs   []uint   `json:"listIds"`
+	Positions []string `json:"positions"`
+}
+
+// CardsReorderedPayload gives the new order of a list's cards, with their new
+// positions in the same order
+type CardsReorderedPayload struct {
+	ListID    uint     `json:"listId"`
+	BoardID   uint     `json:"boardId"`
+	CardIDs   []uint   `json:"cardIds"`
+	Positions []string `json:"positions"`
 }
 
 // BoardMemberPayload for member changes
 type BoardMemberPayload struct {
-	BoardID  uint `json:"boardId"`
-	UserID   uint `json:"userId"`
+	BoardID  uint   `json:"boardId"`
+	UserID   uint   `json:"userId"`
 	UserName string `json:"userName,omitempty"` // Or full User DTO
 }
 
+// BoardOwnerChangedPayload for ownership transfers
+type BoardOwnerChangedPayload struct {
+	BoardID         uint `json:"boardId"`
+	PreviousOwnerID uint `json:"previousOwnerId"`
+	NewOwnerID      uint `json:"newOwnerId"`
+}
+
 // CardCollaboratorPayload for collaborator changes
 type CardCollaboratorPayload struct {
-	CardID   uint `json:"cardId"`
-	UserID   uint `json:"userId"`
-	BoardID  uint `json:"boardId"` // For client-side context
+	CardID   uint   `json:"cardId"`
+	UserID   uint   `json:"userId"`
+	BoardID  uint   `json:"boardId"` // For client-side context
 	UserName string `json:"userName,omitempty"`
 }
+
+// LabelBasicInfo for label deletions
+type LabelBasicInfo struct {
+	ID      uint `json:"id"`
+	BoardID uint `json:"boardId"`
+}
+
+// CardLabelPayload for labels put on or taken off a card
+type CardLabelPayload struct {
+	CardID  uint `json:"cardId"`
+	LabelID uint `json:"labelId"`
+	BoardID uint `json:"boardId"`
+}
+
+// ChecklistBasicInfo for checklist deletions
+type ChecklistBasicInfo struct {
+	ID      uint `json:"id"`
+	CardID  uint `json:"cardId"`
+	BoardID uint `json:"boardId"`
+}
+
+// ChecklistItemBasicInfo for checklist item deletions
+type ChecklistItemBasicInfo struct {
+	ID          uint `json:"id"`
+	ChecklistID uint `json:"checklistId"`
+	CardID      uint `json:"cardId"`
+	BoardID     uint `json:"boardId"`
+}
+
+// ChecklistsReorderedPayload gives the new order of a card's checklists
+type ChecklistsReorderedPayload struct {
+	CardID       uint   `json:"cardId"`
+	BoardID      uint   `json:"boardId"`
+	ChecklistIDs []uint `json:"checklistIds"`
+}
+
+// ChecklistItemsReorderedPayload gives the new order of a checklist's items
+type ChecklistItemsReorderedPayload struct {
+	ChecklistID uint   `json:"checklistId"`
+	CardID      uint   `json:"cardId"`
+	BoardID     uint   `json:"boardId"`
+	ItemIDs     []uint `json:"itemIds"`
+}
+
+// AttachmentBasicInfo for attachment deletions
+type AttachmentBasicInfo struct {
+	ID      uint `json:"id"`
+	CardID  uint `json:"cardId"`
+	BoardID uint `json:"boardId"`
+}
+
+// CardCoverPayload gives a card's new cover attachment; none when the cover was removed
+type CardCoverPayload struct {
+	CardID            uint  `json:"cardId"`
+	BoardID           uint  `json:"boardId"`
+	CoverAttachmentID *uint `json:"coverAttachmentId"`
+}
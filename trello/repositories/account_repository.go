@@ -0,0 +1,170 @@
+package repositories
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/zayyadi/trello/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AccountRepository struct {
+	db *gorm.DB
+}
+
+func NewAccountRepository(db *gorm.DB) AccountRepositoryInterface {
+	return &AccountRepository{db: db}
+}
+
+// FindCardsForUser returns the cards the user is assigned to, supervises or collaborates on.
+func (r *AccountRepository) FindCardsForUser(userID uint) ([]models.Card, error) {
+	var cards []models.Card
+	err := r.db.
+		Where("assigned_user_id = ? OR supervisor_id = ?", userID, userID).
+		Or("id IN (?)", r.db.Model(&models.CardCollaborator{}).Select("card_id").Where("user_id = ?", userID)).
+		Order("id").Find(&cards).Error
+	return cards, err
+}
+
+func (r *AccountRepository) FindCommentsByUser(userID uint) ([]models.Comment, error) {
+	var comments []models.Comment
+	err := r.db.Where("user_id = ?", userID).Order("created_at asc").Find(&comments).Error
+	return comments, err
+}
+
+func (r *AccountRepository) FindCollaborations(userID uint) ([]models.CardCollaborator, error) {
+	var collaborations []models.CardCollaborator
+	err := r.db.Where("user_id = ?", userID).Order("card_id").Find(&collaborations).Error
+	return collaborations, err
+}
+
+// DeleteAccount carries out the plan in one transaction: owned boards are handed over or
+// deleted, the user is removed from boards and cards, their credentials are deleted,
+// and their profile is replaced with the anonymized one and soft-deleted.
+func (r *AccountRepository) DeleteAccount(plan *models.AccountDeletionPlan) error {
+	userID := plan.UserID
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for boardID, newOwnerID := range plan.TransferBoards {
+			if err := tx.Model(&models.Board{}).Where("id = ? AND owner_id = ?", boardID, userID).
+				Update("owner_id", newOwnerID).Error; err != nil {
+				return err
+			}
+			// The new owner is always an admin member
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "board_id"}, {Name: "user_id"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{"role": models.BoardRoleAdmin}),
+			}).Create(&models.BoardMember{BoardID: boardID, UserID: newOwnerID, Role: models.BoardRoleAdmin}).Error; err != nil {
+				return err
+			}
+		}
+		if len(plan.DeleteBoards) > 0 {
+			if err := tx.Where("owner_id = ?", userID).Delete(&models.Board{}, plan.DeleteBoards).Error; err != nil {
+				return err
+			}
+		}
+
+		// Take the user off every workspace, board and card. These rows would also go through the
+		// ON DELETE CASCADE constraints if the user row were removed, but it is kept.
+		var adminOf []uint
+		if err := tx.Model(&models.WorkspaceMember{}).Where("user_id = ? AND role = ?", userID, models.WorkspaceRoleAdmin).
+			Pluck("workspace_id", &adminOf).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.WorkspaceMember{}).Error; err != nil {
+			return err
+		}
+		if err := promoteWorkspaceSuccessors(tx, adminOf); err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.BoardMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.CardCollaborator{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.BoardPreference{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Card{}).Where("assigned_user_id = ?", userID).
+			Update("assigned_user_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Card{}).Where("supervisor_id = ?", userID).
+			Update("supervisor_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.ChecklistItem{}).Where("assigned_user_id = ?", userID).
+			Update("assigned_user_id", nil).Error; err != nil {
+			return err
+		}
+
+		// Credentials: revoke sessions and tokens, drop everything that could log in as the user
+		now := time.Now()
+		if err := tx.Model(&models.RefreshToken{}).Where("user_id = ? AND revoked_at IS NULL", userID).
+			Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.PersonalAccessToken{}).Where("user_id = ? AND revoked_at IS NULL", userID).
+			Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		for _, model := range []interface{}{&models.UserToken{}, &models.RecoveryCode{}, &models.UserIdentity{}} {
+			if err := tx.Unscoped().Where("user_id = ?", userID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		// Invitations to the old address would let whoever registers it next join those boards
+		var emails []string
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Pluck("email", &emails).Error; err != nil {
+			return err
+		}
+		if len(emails) > 0 {
+			if err := tx.Model(&models.BoardInvitation{}).
+				Where("(email = ? OR inviter_id = ?) AND status = ?", strings.ToLower(strings.TrimSpace(emails[0])), userID, models.InvitationStatusPending).
+				Updates(map[string]interface{}{"status": models.InvitationStatusRevoked, "responded_at": now}).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&models.BoardInviteLink{}).Where("created_by_id = ? AND revoked_at IS NULL", userID).
+			Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+
+		// Comments stay, attributed to the anonymized user
+		if err := tx.Save(plan.Anonymized).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.User{}, userID).Error
+	})
+}
+
+// promoteWorkspaceSuccessors makes the longest-standing member an admin of each
+// workspace that has been left without one.
+func promoteWorkspaceSuccessors(tx *gorm.DB, workspaceIDs []uint) error {
+	for _, workspaceID := range workspaceIDs {
+		var admins int64
+		if err := tx.Model(&models.WorkspaceMember{}).Where("workspace_id = ? AND role = ?", workspaceID, models.WorkspaceRoleAdmin).
+			Count(&admins).Error; err != nil {
+			return err
+		}
+		if admins > 0 {
+			continue
+		}
+		var successor models.WorkspaceMember
+		err := tx.Where("workspace_id = ?", workspaceID).Order("created_at").First(&successor).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue // Nobody left; the empty workspace is kept for its boards
+		}
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&models.WorkspaceMember{}).Where("workspace_id = ? AND user_id = ?", workspaceID, successor.UserID).
+			Update("role", models.WorkspaceRoleAdmin).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zayyadi/trello/models"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestAccountRepository_FindUserData(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewAccountRepository(db)
+
+	jane := models.User{Username: "jane", Email: "jane@example.com", Password: "x"}
+	john := models.User{Username: "john", Email: "john@example.com", Password: "x"}
+	assert.NoError(t, db.Create(&jane).Error)
+	assert.NoError(t, db.Create(&john).Error)
+	board := models.Board{Name: "Board", OwnerID: john.ID}
+	assert.NoError(t, db.Create(&board).Error)
+	list := models.List{Name: "To Do", BoardID: board.ID}
+	assert.NoError(t, db.Create(&list).Error)
+
+	assigned := models.Card{Title: "Assigned", ListID: list.ID, AssignedUserID: &jane.ID}
+	supervised := models.Card{Title: "Supervised", ListID: list.ID, SupervisorID: &jane.ID}
+	collaborating := models.Card{Title: "Collaborating", ListID: list.ID}
+	unrelated := models.Card{Title: "Unrelated", ListID: list.ID, AssignedUserID: &john.ID}
+	for _, card := range []*models.Card{&assigned, &supervised, &collaborating, &unrelated} {
+		assert.NoError(t, db.Create(card).Error)
+	}
+	assert.NoError(t, db.Create(&models.CardCollaborator{CardID: collaborating.ID, UserID: jane.ID}).Error)
+	assert.NoError(t, db.Create(&models.Comment{CardID: unrelated.ID, UserID: jane.ID, Content: "Mine"}).Error)
+	assert.NoError(t, db.Create(&models.Comment{CardID: unrelated.ID, UserID: john.ID, Content: "His"}).Error)
+
+	cards, err := repo.FindCardsForUser(jane.ID)
+	assert.NoError(t, err)
+	var titles []string
+	for _, c := range cards {
+		titles = append(titles, c.Title)
+	}
+	assert.Equal(t, []string{"Assigned", "Supervised", "Collaborating"}, titles)
+
+	comments, err := repo.FindCommentsByUser(jane.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, comments, 1) {
+		assert.Equal(t, "Mine", comments[0].Content)
+	}
+
+	collaborations, err := repo.FindCollaborations(jane.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, collaborations, 1) {
+		assert.Equal(t, collaborating.ID, collaborations[0].CardID)
+	}
+}
+
+func TestAccountRepository_DeleteAccount(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewAccountRepository(db)
+
+	jane := models.User{Username: "jane", Email: "jane@example.com", Password: "x", DisplayName: "Jane"}
+	john := models.User{Username: "john", Email: "john@example.com", Password: "x"}
+	assert.NoError(t, db.Create(&jane).Error)
+	assert.NoError(t, db.Create(&john).Error)
+
+	kept := models.Board{Name: "Handed over", OwnerID: jane.ID}
+	dropped := models.Board{Name: "Deleted", OwnerID: jane.ID}
+	johns := models.Board{Name: "John's", OwnerID: john.ID}
+	for _, b := range []*models.Board{&kept, &dropped, &johns} {
+		assert.NoError(t, db.Create(b).Error)
+	}
+	assert.NoError(t, db.Create(&models.BoardMember{BoardID: kept.ID, UserID: jane.ID, Role: models.BoardRoleAdmin}).Error)
+	assert.NoError(t, db.Create(&models.BoardMember{BoardID: kept.ID, UserID: john.ID, Role: models.BoardRoleViewer}).Error)
+	assert.NoError(t, db.Create(&models.BoardMember{BoardID: johns.ID, UserID: jane.ID, Role: models.BoardRoleMember}).Error)
+
+	// Jane is the only admin of a workspace John is a member of
+	workspace := models.Workspace{Name: "Team", Members: []models.WorkspaceMember{
+		{UserID: jane.ID, Role: models.WorkspaceRoleAdmin},
+		{UserID: john.ID, Role: models.WorkspaceRoleMember},
+	}}
+	assert.NoError(t, db.Create(&workspace).Error)
+
+	list := models.List{Name: "To Do", BoardID: johns.ID}
+	assert.NoError(t, db.Create(&list).Error)
+	card := models.Card{Title: "Task", ListID: list.ID, AssignedUserID: &jane.ID, SupervisorID: &jane.ID}
+	assert.NoError(t, db.Create(&card).Error)
+	assert.NoError(t, db.Create(&models.CardCollaborator{CardID: card.ID, UserID: jane.ID}).Error)
+	comment := models.Comment{CardID: card.ID, UserID: jane.ID, Content: "Done"}
+	assert.NoError(t, db.Create(&comment).Error)
+
+	assert.NoError(t, db.Create(&models.RefreshToken{UserID: jane.ID, SessionID: "s", TokenHash: "rt", ExpiresAt: time.Now().Add(time.Hour)}).Error)
+	assert.NoError(t, db.Create(&models.PersonalAccessToken{UserID: jane.ID, Name: "ci", TokenHash: "pat", Prefix: "tpat_x"}).Error)
+	assert.NoError(t, db.Create(&models.UserToken{UserID: jane.ID, Purpose: models.TokenPurposePasswordReset, TokenHash: "ut", ExpiresAt: time.Now().Add(time.Hour)}).Error)
+	assert.NoError(t, db.Create(&models.RecoveryCode{UserID: jane.ID, CodeHash: "rc"}).Error)
+	assert.NoError(t, db.Create(&models.UserIdentity{UserID: jane.ID, Issuer: "https://idp", Subject: "jane"}).Error)
+
+	anonymized := &models.User{Model: gorm.Model{ID: jane.ID, CreatedAt: jane.CreatedAt}, Username: "deleted-user-1", Email: "deleted-user-1@deleted.invalid", DisplayName: "Deleted user"}
+	err := repo.DeleteAccount(&models.AccountDeletionPlan{
+		UserID:         jane.ID,
+		Anonymized:     anonymized,
+		TransferBoards: map[uint]uint{kept.ID: john.ID},
+		DeleteBoards:   []uint{dropped.ID},
+	})
+	assert.NoError(t, err)
+
+	// Owned boards were handed over (the new owner becomes an admin) or deleted
+	var board models.Board
+	assert.NoError(t, db.First(&board, kept.ID).Error)
+	assert.Equal(t, john.ID, board.OwnerID)
+	var newOwner models.BoardMember
+	assert.NoError(t, db.Where("board_id = ? AND user_id = ?", kept.ID, john.ID).First(&newOwner).Error)
+	assert.Equal(t, models.BoardRoleAdmin, newOwner.Role)
+	assert.ErrorIs(t, db.First(&models.Board{}, dropped.ID).Error, gorm.ErrRecordNotFound)
+
+	// The user is off every board and card
+	var count int64
+	db.Model(&models.BoardMember{}).Where("user_id = ?", jane.ID).Count(&count)
+	assert.Zero(t, count)
+	db.Model(&models.CardCollaborator{}).Where("user_id = ?", jane.ID).Count(&count)
+	assert.Zero(t, count)
+	var reloaded models.Card
+	assert.NoError(t, db.First(&reloaded, card.ID).Error)
+	assert.Nil(t, reloaded.AssignedUserID)
+	assert.Nil(t, reloaded.SupervisorID)
+
+	// The workspace passed to the remaining member
+	var successor models.WorkspaceMember
+	assert.NoError(t, db.Where("workspace_id = ?", workspace.ID).First(&successor).Error)
+	assert.Equal(t, john.ID, successor.UserID)
+	assert.Equal(t, models.WorkspaceRoleAdmin, successor.Role)
+
+	// Credentials are gone or revoked
+	var refreshToken models.RefreshToken
+	assert.NoError(t, db.Where("user_id = ?", jane.ID).First(&refreshToken).Error)
+	assert.NotNil(t, refreshToken.RevokedAt)
+	var pat models.PersonalAccessToken
+	assert.NoError(t, db.Where("user_id = ?", jane.ID).First(&pat).Error)
+	assert.NotNil(t, pat.RevokedAt)
+	for _, model := range []interface{}{&models.UserToken{}, &models.RecoveryCode{}, &models.UserIdentity{}} {
+		db.Unscoped().Model(model).Where("user_id = ?", jane.ID).Count(&count)
+		assert.Zero(t, count)
+	}
+
+	// The profile is anonymized and soft-deleted; the comment keeps its (anonymous) author
+	assert.ErrorIs(t, db.First(&models.User{}, jane.ID).Error, gorm.ErrRecordNotFound)
+	var ghost models.User
+	assert.NoError(t, db.Unscoped().First(&ghost, jane.ID).Error)
+	assert.Equal(t, "deleted-user-1", ghost.Username)
+	assert.Equal(t, "Deleted user", ghost.DisplayName)
+	_, err = NewUserRepository(db).FindByEmail("jane@example.com")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound, "the email is free again")
+
+	comments, err := NewCommentRepository(db).FindByCardID(card.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, comments, 1) {
+		assert.Equal(t, "deleted-user-1", comments[0].User.Username)
+	}
+}
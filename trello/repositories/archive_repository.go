@@ -0,0 +1,211 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/zayyadi/trello/models"
+	"gorm.io/gorm"
+)
+
+type ArchiveRepository struct {
+	db *gorm.DB
+}
+
+func NewArchiveRepository(db *gorm.DB) ArchiveRepositoryInterface {
+	return &ArchiveRepository{db: db}
+}
+
+func (r *ArchiveRepository) SetBoardArchived(boardID uint, at *time.Time) error {
+	result := r.db.Model(&models.Board{}).Where("id = ?", boardID).Update("archived_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *ArchiveRepository) ArchiveList(listID uint, at time.Time) error {
+	return setArchivedAt(r.db, &models.List{}, listID, &at)
+}
+
+func (r *ArchiveRepository) UnarchiveList(listID uint) error {
+	return setArchivedAt(r.db, &models.List{}, listID, nil)
+}
+
+func (r *ArchiveRepository) ArchiveCard(cardID uint, at time.Time) error {
+	return setArchivedAt(r.db, &models.Card{}, cardID, &at)
+}
+
+func (r *ArchiveRepository) UnarchiveCard(cardID uint) error {
+	return setArchivedAt(r.db, &models.Card{}, cardID, nil)
+}
+
+// FindArchivedLists returns the board's archived lists, most recently archived first.
+func (r *ArchiveRepository) FindArchivedLists(boardID uint) ([]models.List, error) {
+	var lists []models.List
+	err := r.db.Where("board_id = ? AND archived_at IS NOT NULL", boardID).Order("archived_at DESC").Find(&lists).Error
+	return lists, err
+}
+
+// FindArchivedCards returns the archived cards in the board's lists, most recently archived first.
+func (r *ArchiveRepository) FindArchivedCards(boardID uint) ([]models.Card, error) {
+	var cards []models.Card
+	err := r.db.Where("archived_at IS NOT NULL AND list_id IN (?)", r.db.Model(&models.List{}).Select("id").Where("board_id = ?", boardID)).
+		Order("archived_at DESC").Find(&cards).Error
+	return cards, err
+}
+
+func (r *ArchiveRepository) FindDeletedList(id uint) (*models.List, error) {
+	var list models.List
+	if err := r.db.Unscoped().Where("deleted_at IS NOT NULL").First(&list, id).Error; err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+func (r *ArchiveRepository) FindDeletedCard(id uint) (*models.Card, error) {
+	var card models.Card
+	if err := r.db.Unscoped().Where("deleted_at IS NOT NULL").First(&card, id).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Unscoped().First(&card.List, card.ListID).Error; err != nil {
+		return nil, err
+	}
+	return &card, nil
+}
+
+// FindDeletedLists returns the board's deleted lists, most recently deleted first.
+func (r *ArchiveRepository) FindDeletedLists(boardID uint) ([]models.List, error) {
+	var lists []models.List
+	err := r.db.Unscoped().Where("board_id = ? AND deleted_at IS NOT NULL", boardID).Order("deleted_at DESC").Find(&lists).Error
+	return lists, err
+}
+
+// FindDeletedCards returns the deleted cards of the board's lists (deleted or not), most recently deleted first.
+func (r *ArchiveRepository) FindDeletedCards(boardID uint) ([]models.Card, error) {
+	var cards []models.Card
+	err := r.db.Unscoped().
+		Where("deleted_at IS NOT NULL AND list_id IN (?)", r.db.Unscoped().Model(&models.List{}).Select("id").Where("board_id = ?", boardID)).
+		Order("deleted_at DESC").Find(&cards).Error
+	return cards, err
+}
+
+func (r *ArchiveRepository) RestoreList(listID uint) error {
+	return restore(r.db, &models.List{}, listID)
+}
+
+func (r *ArchiveRepository) RestoreCard(cardID uint) error {
+	return restore(r.db, &models.Card{}, cardID)
+}
+
+// FindDeletedBoards returns the owner's deleted boards, most recently deleted first.
+func (r *ArchiveRepository) FindDeletedBoards(ownerID uint) ([]models.Board, error) {
+	var boards []models.Board
+	err := r.db.Unscoped().Where("owner_id = ? AND deleted_at IS NOT NULL", ownerID).Order("deleted_at DESC").Find(&boards).Error
+	return boards, err
+}
+
+// RestoreBoard undeletes one of the owner's boards. It returns gorm.ErrRecordNotFound
+// if the owner has no such deleted board.
+func (r *ArchiveRepository) RestoreBoard(boardID, ownerID uint) error {
+	result := r.db.Unscoped().Model(&models.Board{}).
+		Where("id = ? AND owner_id = ? AND deleted_at IS NOT NULL", boardID, ownerID).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Purge removes everything in a purged board or list as well, deleted or not, in one
+// transaction. Rows that point at purged cards and boards are deleted first, so it
+// doesn't rely on the database's ON DELETE CASCADE constraints.
+func (r *ArchiveRepository) Purge(before time.Time) (int64, error) {
+	var boardIDs, listIDs, cardIDs []uint
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&models.Board{}).Where("deleted_at < ?", before).Pluck("id", &boardIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&models.List{}).Where("deleted_at < ? OR board_id IN ?", before, boardIDs).
+			Pluck("id", &listIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&models.Card{}).Where("deleted_at < ? OR list_id IN ?", before, listIDs).
+			Pluck("id", &cardIDs).Error; err != nil {
+			return err
+		}
+
+		if len(cardIDs) > 0 {
+			if err := tx.Where("checklist_id IN (?)", tx.Model(&models.Checklist{}).Select("id").Where("card_id IN ?", cardIDs)).
+				Delete(&models.ChecklistItem{}).Error; err != nil {
+				return err
+			}
+			for _, model := range []interface{}{&models.Comment{}, &models.CardCollaborator{}, &models.CardLabel{}, &models.Checklist{}} {
+				if err := tx.Unscoped().Where("card_id IN ?", cardIDs).Delete(model).Error; err != nil {
+					return err
+				}
+			}
+			// Attachments are left for AttachmentService.PurgeOrphaned, which deletes their files too
+			if err := tx.Unscoped().Where("id IN ?", cardIDs).Delete(&models.Card{}).Error; err != nil {
+				return err
+			}
+		}
+		if len(listIDs) > 0 {
+			if err := tx.Unscoped().Where("id IN ?", listIDs).Delete(&models.List{}).Error; err != nil {
+				return err
+			}
+		}
+		if len(boardIDs) > 0 {
+			for _, model := range []interface{}{&models.BoardMember{}, &models.BoardInvitation{}, &models.BoardInviteLink{}, &models.BoardShareLink{}, &models.BoardPreference{}, &models.Label{}} {
+				if err := tx.Unscoped().Where("board_id IN ?", boardIDs).Delete(model).Error; err != nil {
+					return err
+				}
+			}
+			if err := tx.Unscoped().Where("id IN ?", boardIDs).Delete(&models.Board{}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(boardIDs) + len(listIDs) + len(cardIDs)), nil
+}
+
+// setArchivedAt archives a list or card at the given time, or unarchives it if
+// at is nil. Its rank is left alone, so once unarchived it is back where it was
+// among the others.
+func setArchivedAt(db *gorm.DB, model interface{}, id uint, at *time.Time) error {
+	query := db.Model(model).Where("id = ?", id)
+	if at != nil {
+		query = query.Where("archived_at IS NULL")
+	} else {
+		query = query.Where("archived_at IS NOT NULL")
+	}
+	result := query.Update("archived_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// restore undeletes a list or card, which kept its rank while in the trash.
+func restore(db *gorm.DB, model interface{}, id uint) error {
+	result := db.Unscoped().Model(model).Where("id = ? AND deleted_at IS NOT NULL", id).Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
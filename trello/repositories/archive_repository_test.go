@@ -0,0 +1,122 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zayyadi/trello/models"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// createCards adds cards with the given titles to the list, in order.
+func createCards(t *testing.T, repo CardRepositoryInterface, listID uint, titles ...string) []models.Card {
+	t.Helper()
+	cards := make([]models.Card, len(titles))
+	for i, title := range titles {
+		cards[i] = models.Card{Title: title, ListID: listID}
+		if err := repo.Create(&cards[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return cards
+}
+
+func cardTitles(t *testing.T, repo CardRepositoryInterface, listID uint) []string {
+	t.Helper()
+	cards, err := repo.FindByListID(listID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	titles := make([]string, len(cards))
+	for i, card := range cards {
+		titles[i] = card.Title
+		if i > 0 {
+			assert.Less(t, cards[i-1].Position, card.Position, "sorted by distinct ranks")
+		}
+	}
+	return titles
+}
+
+func TestArchiveRepository_ArchiveAndRestoreKeepOrder(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewArchiveRepository(db)
+	cardRepo := NewCardRepository(db)
+	owner := models.User{Username: "alice", Email: "alice@example.com", Password: "x"}
+	assert.NoError(t, db.Create(&owner).Error)
+	board := models.Board{Name: "Board", OwnerID: owner.ID}
+	assert.NoError(t, db.Create(&board).Error)
+	list := models.List{Name: "To do", BoardID: board.ID}
+	assert.NoError(t, NewListRepository(db).Create(&list))
+	cards := createCards(t, cardRepo, list.ID, "a", "b", "c", "d")
+
+	assert.NoError(t, repo.ArchiveCard(cards[1].ID, time.Now()))
+	assert.Equal(t, []string{"a", "c", "d"}, cardTitles(t, cardRepo, list.ID))
+	assert.ErrorIs(t, repo.ArchiveCard(cards[1].ID, time.Now()), gorm.ErrRecordNotFound, "already archived")
+	archived, err := repo.FindArchivedCards(board.ID)
+	assert.NoError(t, err)
+	assert.Len(t, archived, 1)
+
+	assert.NoError(t, cardRepo.Delete(cards[2].ID))
+	assert.Equal(t, []string{"a", "d"}, cardTitles(t, cardRepo, list.ID))
+	trash, err := repo.FindDeletedCards(board.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, trash, 1) {
+		assert.Equal(t, "c", trash[0].Title)
+	}
+
+	// A card moved in the meantime doesn't change where the others come back
+	assert.NoError(t, cardRepo.MoveCard(cards[3].ID, list.ID, 1))
+	assert.Equal(t, []string{"d", "a"}, cardTitles(t, cardRepo, list.ID))
+
+	// Each comes back at the place it had when it left
+	assert.NoError(t, repo.RestoreCard(cards[2].ID))
+	assert.Equal(t, []string{"d", "a", "c"}, cardTitles(t, cardRepo, list.ID))
+	assert.NoError(t, repo.UnarchiveCard(cards[1].ID))
+	assert.Equal(t, []string{"d", "a", "b", "c"}, cardTitles(t, cardRepo, list.ID))
+	assert.ErrorIs(t, repo.RestoreCard(cards[2].ID), gorm.ErrRecordNotFound, "not in the trash any more")
+}
+
+func TestArchiveRepository_Purge(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewArchiveRepository(db)
+	cardRepo := NewCardRepository(db)
+	owner := models.User{Username: "alice", Email: "alice@example.com", Password: "x"}
+	assert.NoError(t, db.Create(&owner).Error)
+
+	kept := models.Board{Name: "Kept", OwnerID: owner.ID}
+	assert.NoError(t, db.Create(&kept).Error)
+	list := models.List{Name: "List", BoardID: kept.ID}
+	assert.NoError(t, db.Create(&list).Error)
+	cards := createCards(t, cardRepo, list.ID, "old", "recent", "live")
+	assert.NoError(t, db.Create(&models.Comment{CardID: cards[0].ID, UserID: owner.ID, Content: "bye"}).Error)
+
+	gone := models.Board{Name: "Gone", OwnerID: owner.ID}
+	assert.NoError(t, db.Create(&gone).Error)
+	goneList := models.List{Name: "List", BoardID: gone.ID}
+	assert.NoError(t, db.Create(&goneList).Error)
+	createCards(t, cardRepo, goneList.ID, "inside")
+	assert.NoError(t, db.Create(&models.BoardMember{BoardID: gone.ID, UserID: owner.ID, Role: models.BoardRoleAdmin}).Error)
+
+	now := time.Now()
+	longAgo := now.Add(-48 * time.Hour)
+	assert.NoError(t, db.Model(&models.Card{}).Where("id = ?", cards[0].ID).Update("deleted_at", longAgo).Error)
+	assert.NoError(t, db.Model(&models.Card{}).Where("id = ?", cards[1].ID).Update("deleted_at", now).Error)
+	assert.NoError(t, db.Model(&models.Board{}).Where("id = ?", gone.ID).Update("deleted_at", longAgo).Error)
+
+	purged, err := repo.Purge(now.Add(-24 * time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), purged, "the old card, and the old board with its list and card")
+
+	var count int64
+	db.Unscoped().Model(&models.Card{}).Where("list_id = ?", list.ID).Count(&count)
+	assert.Equal(t, int64(2), count, "recently deleted cards stay in the trash")
+	db.Unscoped().Model(&models.Comment{}).Where("card_id = ?", cards[0].ID).Count(&count)
+	assert.Zero(t, count)
+	db.Unscoped().Model(&models.List{}).Where("board_id = ?", gone.ID).Count(&count)
+	assert.Zero(t, count)
+	db.Model(&models.BoardMember{}).Where("board_id = ?", gone.ID).Count(&count)
+	assert.Zero(t, count)
+	assert.ErrorIs(t, repo.RestoreBoard(gone.ID, owner.ID), gorm.ErrRecordNotFound)
+}
@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"github.com/zayyadi/trello/models"
+	"gorm.io/gorm"
+)
+
+type AttachmentRepository struct {
+	db *gorm.DB
+}
+
+func NewAttachmentRepository(db *gorm.DB) AttachmentRepositoryInterface {
+	return &AttachmentRepository{db: db}
+}
+
+func (r *AttachmentRepository) Create(attachment *models.Attachment) error {
+	return r.db.Omit("Uploader").Create(attachment).Error
+}
+
+func (r *AttachmentRepository) FindByID(id uint) (*models.Attachment, error) {
+	var attachment models.Attachment
+	if err := r.db.Preload("Uploader").First(&attachment, id).Error; err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// FindByCardID returns the card's attachments, newest first.
+func (r *AttachmentRepository) FindByCardID(cardID uint) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	err := r.db.Preload("Uploader").Where("card_id = ?", cardID).Order("created_at DESC, id DESC").Find(&attachments).Error
+	return attachments, err
+}
+
+// Delete removes the attachment, and takes it off any card that has it as its
+// cover (including cards in the trash), in one transaction.
+func (r *AttachmentRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&models.Card{}).Where("cover_attachment_id = ?", id).
+			UpdateColumn("cover_attachment_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Attachment{}, id).Error
+	})
+}
+
+// SetCover makes the attachment the card's cover, or removes the cover if attachmentID is nil.
+func (r *AttachmentRepository) SetCover(cardID uint, attachmentID *uint) error {
+	return r.db.Model(&models.Card{}).Where("id = ?", cardID).UpdateColumn("cover_attachment_id", attachmentID).Error
+}
+
+// IsBlobUsed says whether any attachment still keeps its file or thumbnail under key.
+func (r *AttachmentRepository) IsBlobUsed(key string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Attachment{}).Where("storage_key = ? OR thumbnail_key = ?", key, key).Count(&count).Error
+	return count > 0, err
+}
+
+// FindOrphaned returns up to limit attachments whose card has been purged. Cards in
+// the trash are soft-deleted and still count.
+func (r *AttachmentRepository) FindOrphaned(limit int) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	err := r.db.Where("card_id NOT IN (?)", r.db.Unscoped().Model(&models.Card{}).Select("id")).
+		Order("id").Limit(limit).Find(&attachments).Error
+	return attachments, err
+}
@@ -0,0 +1,31 @@
+package repositories
+
+import (
+	"github.com/zayyadi/trello/models"
+	"gorm.io/gorm"
+)
+
+type AuditEventRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditEventRepository(db *gorm.DB) AuditEventRepositoryInterface {
+	return &AuditEventRepository{db: db}
+}
+
+func (r *AuditEventRepository) Create(event *models.AuditEvent) error {
+	return r.db.Create(event).Error
+}
+
+func (r *AuditEventRepository) List(eventType models.AuditEventType, userID *uint, limit int) ([]models.AuditEvent, error) {
+	query := r.db.Order("created_at DESC, id DESC").Limit(limit)
+	if eventType != "" {
+		query = query.Where("type = ?", eventType)
+	}
+	if userID != nil {
+		query = query.Where("user_id = ? OR actor_id = ?", *userID, *userID)
+	}
+	var events []models.AuditEvent
+	err := query.Find(&events).Error
+	return events, err
+}
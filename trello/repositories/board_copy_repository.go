@@ -0,0 +1,311 @@
+package repositories
+
+import (
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/rank"
+	"gorm.io/gorm"
+)
+
+// BoardCopyOptions says what is copied along with a board's lists.
+type BoardCopyOptions struct {
+	Cards bool
+	// Collaborators keeps the cards' assignees, supervisors and collaborators
+	// who are on the new board already. Nobody is added to it.
+	Collaborators bool
+	Comments      bool
+}
+
+type BoardCopyRepository struct {
+	db *gorm.DB
+}
+
+func NewBoardCopyRepository(db *gorm.DB) BoardCopyRepositoryInterface {
+	return &BoardCopyRepository{db: db}
+}
+
+// Copy creates board, with its owner as an admin member, and copies the labels and
+// active lists of the source board into it in one transaction. Archived and deleted
+// lists and cards are left behind; the copies get freshly spread ranks.
+func (r *BoardCopyRepository) Copy(sourceID uint, board *models.Board, opts BoardCopyOptions) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(board).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.BoardMember{BoardID: board.ID, UserID: board.OwnerID, Role: models.BoardRoleAdmin}).Error; err != nil {
+			return err
+		}
+		labelIDs, err := copyLabels(tx, sourceID, board.ID)
+		if err != nil {
+			return err
+		}
+
+		var lists []models.List
+		if err := tx.Where("board_id = ? AND archived_at IS NULL", sourceID).Order("position, id").Find(&lists).Error; err != nil {
+			return err
+		}
+		if len(lists) == 0 {
+			return nil
+		}
+		listIDs := make(map[uint]uint, len(lists)) // Source list ID to copied list ID
+		copies := make([]models.List, len(lists))
+		ranks := rank.Spread(len(lists))
+		for i, list := range lists {
+			copies[i] = models.List{Name: list.Name, BoardID: board.ID, Position: ranks[i]}
+		}
+		if err := tx.Create(&copies).Error; err != nil {
+			return err
+		}
+		sourceListIDs := make([]uint, len(lists))
+		for i, list := range lists {
+			listIDs[list.ID] = copies[i].ID
+			sourceListIDs[i] = list.ID
+		}
+
+		if !opts.Cards {
+			return nil
+		}
+		return copyCards(tx, board, sourceListIDs, listIDs, labelIDs, opts)
+	})
+}
+
+// copyLabels copies the source board's labels to the new board. It returns a map
+// of source label ID to copied label ID.
+func copyLabels(tx *gorm.DB, sourceID, boardID uint) (map[uint]uint, error) {
+	var labels []models.Label
+	if err := tx.Where("board_id = ?", sourceID).Order("id").Find(&labels).Error; err != nil {
+		return nil, err
+	}
+	labelIDs := make(map[uint]uint, len(labels))
+	if len(labels) == 0 {
+		return labelIDs, nil
+	}
+	copies := make([]models.Label, len(labels))
+	for i, label := range labels {
+		copies[i] = models.Label{BoardID: boardID, Name: label.Name, Color: label.Color}
+	}
+	if err := tx.Create(&copies).Error; err != nil {
+		return nil, err
+	}
+	for i, label := range labels {
+		labelIDs[label.ID] = copies[i].ID
+	}
+	return labelIDs, nil
+}
+
+// copyCards copies the active cards of the source lists into their copies with
+// their labels, checklists and attachments, and the collaborators and comments if opts says so.
+func copyCards(tx *gorm.DB, board *models.Board, sourceListIDs []uint, listIDs, labelIDs map[uint]uint, opts BoardCopyOptions) error {
+	var cards []models.Card
+	if err := tx.Where("list_id IN ? AND archived_at IS NULL", sourceListIDs).Order("list_id, position, id").Find(&cards).Error; err != nil {
+		return err
+	}
+	if len(cards) == 0 {
+		return nil
+	}
+
+	var onBoard map[uint]bool // People the copies may keep referring to
+	if opts.Collaborators {
+		var err error
+		if onBoard, err = boardPeople(tx, board); err != nil {
+			return err
+		}
+	}
+	counts := map[uint]int{} // Cards per source list
+	for _, card := range cards {
+		counts[card.ListID]++
+	}
+	ranks := make(map[uint][]string, len(counts)) // Source list ID to the ranks of its copied cards, in order
+	for listID, n := range counts {
+		ranks[listID] = rank.Spread(n)
+	}
+	copies := make([]models.Card, len(cards))
+	for i, card := range cards {
+		listID := listIDs[card.ListID]
+		copies[i] = models.Card{
+			Title:       card.Title,
+			Description: card.Description,
+			ListID:      listID,
+			Position:    ranks[card.ListID][0],
+			DueDate:     card.DueDate,
+			Status:      card.Status,
+			Color:       card.Color,
+		}
+		ranks[card.ListID] = ranks[card.ListID][1:]
+		if card.AssignedUserID != nil && onBoard[*card.AssignedUserID] {
+			copies[i].AssignedUserID = card.AssignedUserID
+		}
+		if card.SupervisorID != nil && onBoard[*card.SupervisorID] {
+			copies[i].SupervisorID = card.SupervisorID
+		}
+	}
+	if err := tx.Create(&copies).Error; err != nil {
+		return err
+	}
+	cardIDs := make(map[uint]uint, len(cards)) // Source card ID to copied card ID
+	sourceCardIDs := make([]uint, len(cards))
+	for i, card := range cards {
+		cardIDs[card.ID] = copies[i].ID
+		sourceCardIDs[i] = card.ID
+	}
+
+	var cardLabels []models.CardLabel
+	if err := tx.Where("card_id IN ?", sourceCardIDs).Find(&cardLabels).Error; err != nil {
+		return err
+	}
+	for i := range cardLabels {
+		cardLabels[i] = models.CardLabel{CardID: cardIDs[cardLabels[i].CardID], LabelID: labelIDs[cardLabels[i].LabelID]}
+	}
+	if len(cardLabels) > 0 {
+		if err := tx.Create(&cardLabels).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := copyChecklists(tx, sourceCardIDs, cardIDs, onBoard); err != nil {
+		return err
+	}
+	if err := copyAttachments(tx, cards, cardIDs); err != nil {
+		return err
+	}
+
+	if opts.Collaborators {
+		var sourceCollaborators []models.CardCollaborator
+		if err := tx.Where("card_id IN ?", sourceCardIDs).Find(&sourceCollaborators).Error; err != nil {
+			return err
+		}
+		var collaborators []models.CardCollaborator
+		for _, collaborator := range sourceCollaborators {
+			if onBoard[collaborator.UserID] {
+				collaborators = append(collaborators, models.CardCollaborator{CardID: cardIDs[collaborator.CardID], UserID: collaborator.UserID})
+			}
+		}
+		if len(collaborators) > 0 {
+			if err := tx.Create(&collaborators).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	if opts.Comments {
+		var comments []models.Comment
+		if err := tx.Where("card_id IN ?", sourceCardIDs).Order("id").Find(&comments).Error; err != nil {
+			return err
+		}
+		for i := range comments {
+			// Keep the author and when it was written, so the discussion reads as before
+			comments[i] = models.Comment{
+				Model:   gorm.Model{CreatedAt: comments[i].CreatedAt},
+				Content: comments[i].Content,
+				CardID:  cardIDs[comments[i].CardID],
+				UserID:  comments[i].UserID,
+			}
+		}
+		if len(comments) > 0 {
+			if err := tx.Create(&comments).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// boardPeople returns who is on the board: its members and, when it is shared with
+// its workspace, the workspace's members. Copies only keep references to them, so
+// copying a board never gives anyone access to it.
+func boardPeople(tx *gorm.DB, board *models.Board) (map[uint]bool, error) {
+	var userIDs []uint
+	if err := tx.Model(&models.BoardMember{}).Where("board_id = ?", board.ID).Pluck("user_id", &userIDs).Error; err != nil {
+		return nil, err
+	}
+	if board.WorkspaceID != nil && board.Visibility.SharedWithWorkspace() {
+		var workspaceUserIDs []uint
+		if err := tx.Model(&models.WorkspaceMember{}).Where("workspace_id = ?", *board.WorkspaceID).Pluck("user_id", &workspaceUserIDs).Error; err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, workspaceUserIDs...)
+	}
+	people := map[uint]bool{board.OwnerID: true}
+	for _, userID := range userIDs {
+		people[userID] = true
+	}
+	return people, nil
+}
+
+// copyChecklists copies the checklists of the source cards, with their items as
+// they stand, ticked or not. Item assignees are only kept when in onBoard.
+func copyChecklists(tx *gorm.DB, sourceCardIDs []uint, cardIDs map[uint]uint, onBoard map[uint]bool) error {
+	var checklists []models.Checklist
+	if err := tx.Preload("Items").Where("card_id IN ?", sourceCardIDs).Order("card_id, position, id").Find(&checklists).Error; err != nil {
+		return err
+	}
+	if len(checklists) == 0 {
+		return nil
+	}
+
+	copies := make([]models.Checklist, len(checklists))
+	for i, checklist := range checklists {
+		items := make([]models.ChecklistItem, len(checklist.Items))
+		for j, item := range checklist.Items {
+			items[j] = models.ChecklistItem{
+				Content:     item.Content,
+				Position:    item.Position,
+				Completed:   item.Completed,
+				CompletedAt: item.CompletedAt,
+				DueDate:     item.DueDate,
+			}
+			if item.AssignedUserID != nil && onBoard[*item.AssignedUserID] {
+				items[j].AssignedUserID = item.AssignedUserID
+			}
+		}
+		copies[i] = models.Checklist{
+			CardID:   cardIDs[checklist.CardID],
+			Title:    checklist.Title,
+			Position: checklist.Position,
+			Items:    items, // Created along with the checklist
+		}
+	}
+	return tx.Create(&copies).Error
+}
+
+// copyAttachments copies the attachments of the source cards, and their covers. The
+// copies share the files of the originals; they are only deleted with the last
+// attachment that uses them.
+func copyAttachments(tx *gorm.DB, cards []models.Card, cardIDs map[uint]uint) error {
+	sourceCardIDs := make([]uint, len(cards))
+	for i, card := range cards {
+		sourceCardIDs[i] = card.ID
+	}
+	var attachments []models.Attachment
+	if err := tx.Where("card_id IN ?", sourceCardIDs).Order("id").Find(&attachments).Error; err != nil {
+		return err
+	}
+	if len(attachments) == 0 {
+		return nil
+	}
+
+	copies := make([]models.Attachment, len(attachments))
+	for i, attachment := range attachments {
+		copies[i] = attachment
+		copies[i].ID = 0
+		copies[i].CardID = cardIDs[attachment.CardID]
+	}
+	if err := tx.Create(&copies).Error; err != nil {
+		return err
+	}
+	attachmentIDs := make(map[uint]uint, len(attachments)) // Source attachment ID to copied attachment ID
+	for i, attachment := range attachments {
+		attachmentIDs[attachment.ID] = copies[i].ID
+	}
+	for _, card := range cards {
+		if card.CoverAttachmentID == nil {
+			continue
+		}
+		if coverID, ok := attachmentIDs[*card.CoverAttachmentID]; ok {
+			if err := tx.Model(&models.Card{}).Where("id = ?", cardIDs[card.ID]).
+				UpdateColumn("cover_attachment_id", coverID).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/zayyadi/trello/models"
+	"gorm.io/gorm"
+)
+
+type BoardInvitationRepository struct {
+	db *gorm.DB
+}
+
+func NewBoardInvitationRepository(db *gorm.DB) BoardInvitationRepositoryInterface {
+	return &BoardInvitationRepository{db: db}
+}
+
+func (r *BoardInvitationRepository) Create(invitation *models.BoardInvitation) error {
+	return r.db.Create(invitation).Error
+}
+
+func (r *BoardInvitationRepository) FindByID(id uint) (*models.BoardInvitation, error) {
+	var invitation models.BoardInvitation
+	if err := r.db.Preload("Inviter").First(&invitation, id).Error; err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *BoardInvitationRepository) FindByHash(tokenHash string) (*models.BoardInvitation, error) {
+	var invitation models.BoardInvitation
+	if err := r.db.Preload("Inviter").Where("token_hash = ?", tokenHash).First(&invitation).Error; err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// FindPendingByBoard returns the board's unexpired pending invitations, newest first.
+func (r *BoardInvitationRepository) FindPendingByBoard(boardID uint) ([]models.BoardInvitation, error) {
+	var invitations []models.BoardInvitation
+	err := r.db.Preload("Inviter").
+		Where("board_id = ? AND status = ? AND expires_at > ?", boardID, models.InvitationStatusPending, time.Now()).
+		Order("created_at DESC").Find(&invitations).Error
+	return invitations, err
+}
+
+// FindPendingByEmail returns the unexpired pending invitations sent to email
+// (lower case) for boards that still exist, newest first.
+func (r *BoardInvitationRepository) FindPendingByEmail(email string) ([]models.BoardInvitation, error) {
+	var invitations []models.BoardInvitation
+	err := r.db.Preload("Inviter").Preload("Board").
+		Joins("JOIN boards ON boards.id = board_invitations.board_id AND boards.deleted_at IS NULL").
+		Where("board_invitations.email = ? AND board_invitations.status = ? AND board_invitations.expires_at > ?",
+			email, models.InvitationStatusPending, time.Now()).
+		Order("board_invitations.created_at DESC").Find(&invitations).Error
+	return invitations, err
+}
+
+// RevokePending revokes the pending invitations to email for the board,
+// e.g. when a fresh invitation replaces them.
+func (r *BoardInvitationRepository) RevokePending(boardID uint, email string) error {
+	return r.db.Model(&models.BoardInvitation{}).
+		Where("board_id = ? AND email = ? AND status = ?", boardID, email, models.InvitationStatusPending).
+		Updates(map[string]interface{}{"status": models.InvitationStatusRevoked, "responded_at": time.Now()}).Error
+}
+
+// Respond moves a pending invitation to status. It returns gorm.ErrRecordNotFound
+// if the invitation is no longer pending, so it can only be answered once.
+func (r *BoardInvitationRepository) Respond(id uint, status models.InvitationStatus, userID *uint) error {
+	result := r.db.Model(&models.BoardInvitation{}).
+		Where("id = ? AND status = ?", id, models.InvitationStatusPending).
+		Updates(map[string]interface{}{"status": status, "responded_at": time.Now(), "accepted_by": userID})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
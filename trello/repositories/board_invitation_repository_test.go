@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zayyadi/trello/models"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestBoardInvitationRepository_FindPendingByEmail(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewBoardInvitationRepository(db)
+
+	alice := models.User{Username: "alice", Email: "alice@example.com", Password: "x"}
+	assert.NoError(t, db.Create(&alice).Error)
+	live := models.Board{Name: "Live", OwnerID: alice.ID}
+	deleted := models.Board{Name: "Deleted", OwnerID: alice.ID}
+	assert.NoError(t, db.Create(&live).Error)
+	assert.NoError(t, db.Create(&deleted).Error)
+
+	invite := func(boardID uint, hash string, expiresAt time.Time) *models.BoardInvitation {
+		invitation := &models.BoardInvitation{BoardID: boardID, InviterID: alice.ID, Email: "bob@example.com",
+			Role: models.BoardRoleMember, TokenHash: hash, Status: models.InvitationStatusPending, ExpiresAt: expiresAt}
+		assert.NoError(t, repo.Create(invitation))
+		return invitation
+	}
+	pending := invite(live.ID, "a", time.Now().Add(time.Hour))
+	invite(live.ID, "b", time.Now().Add(-time.Hour)) // Expired
+	invite(deleted.ID, "c", time.Now().Add(time.Hour))
+	assert.NoError(t, db.Delete(&deleted).Error)
+
+	found, err := repo.FindPendingByEmail("bob@example.com")
+	assert.NoError(t, err)
+	if assert.Len(t, found, 1) {
+		assert.Equal(t, pending.ID, found[0].ID)
+		assert.Equal(t, "Live", found[0].Board.Name)
+		assert.Equal(t, "alice", found[0].Inviter.Username)
+	}
+
+	// Answering is one-shot
+	assert.NoError(t, repo.Respond(pending.ID, models.InvitationStatusAccepted, &alice.ID))
+	assert.ErrorIs(t, repo.Respond(pending.ID, models.InvitationStatusDeclined, nil), gorm.ErrRecordNotFound)
+	found, err = repo.FindPendingByEmail("bob@example.com")
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+}
+
+func TestBoardInviteLinkRepository_Use(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewBoardInviteLinkRepository(db)
+
+	alice := models.User{Username: "alice", Email: "alice@example.com", Password: "x"}
+	assert.NoError(t, db.Create(&alice).Error)
+	board := models.Board{Name: "Board", OwnerID: alice.ID}
+	assert.NoError(t, db.Create(&board).Error)
+
+	maxUses := 2
+	limited := &models.BoardInviteLink{BoardID: board.ID, CreatedByID: alice.ID, Role: models.BoardRoleMember, TokenHash: "a", MaxUses: &maxUses}
+	expired := time.Now().Add(-time.Minute)
+	stale := &models.BoardInviteLink{BoardID: board.ID, CreatedByID: alice.ID, Role: models.BoardRoleMember, TokenHash: "b", ExpiresAt: &expired}
+	assert.NoError(t, repo.Create(limited))
+	assert.NoError(t, repo.Create(stale))
+
+	assert.NoError(t, repo.Use(limited.ID))
+	assert.NoError(t, repo.Use(limited.ID))
+	assert.ErrorIs(t, repo.Use(limited.ID), gorm.ErrRecordNotFound, "used up")
+	assert.ErrorIs(t, repo.Use(stale.ID), gorm.ErrRecordNotFound, "expired")
+
+	found, err := repo.FindByHash("a")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, found.Uses)
+
+	assert.ErrorIs(t, repo.Revoke(limited.ID, board.ID+1), gorm.ErrRecordNotFound, "other board")
+	assert.NoError(t, repo.Revoke(limited.ID, board.ID))
+	links, err := repo.FindByBoard(board.ID)
+	assert.NoError(t, err)
+	assert.Len(t, links, 1)
+}
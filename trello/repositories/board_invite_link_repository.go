@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/zayyadi/trello/models"
+	"gorm.io/gorm"
+)
+
+type BoardInviteLinkRepository struct {
+	db *gorm.DB
+}
+
+func NewBoardInviteLinkRepository(db *gorm.DB) BoardInviteLinkRepositoryInterface {
+	return &BoardInviteLinkRepository{db: db}
+}
+
+func (r *BoardInviteLinkRepository) Create(link *models.BoardInviteLink) error {
+	return r.db.Create(link).Error
+}
+
+func (r *BoardInviteLinkRepository) FindByHash(tokenHash string) (*models.BoardInviteLink, error) {
+	var link models.BoardInviteLink
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&link).Error; err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// FindByBoard returns the board's unrevoked links, newest first.
+// Expired and used up links are included so admins can see them.
+func (r *BoardInviteLinkRepository) FindByBoard(boardID uint) ([]models.BoardInviteLink, error) {
+	var links []models.BoardInviteLink
+	err := r.db.Where("board_id = ? AND revoked_at IS NULL", boardID).Order("created_at DESC").Find(&links).Error
+	return links, err
+}
+
+// Revoke revokes one of the board's links. It returns gorm.ErrRecordNotFound
+// if the board has no such unrevoked link.
+func (r *BoardInviteLinkRepository) Revoke(id, boardID uint) error {
+	result := r.db.Model(&models.BoardInviteLink{}).
+		Where("id = ? AND board_id = ? AND revoked_at IS NULL", id, boardID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Use counts one use of the link. It returns gorm.ErrRecordNotFound if the link
+// is revoked, expired or used up, checked in the same statement so two
+// concurrent joins cannot both take the last use.
+func (r *BoardInviteLinkRepository) Use(id uint) error {
+	result := r.db.Model(&models.BoardInviteLink{}).
+		Where("id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?) AND (max_uses IS NULL OR uses < max_uses)", id, time.Now()).
+		Update("uses", gorm.Expr("uses + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
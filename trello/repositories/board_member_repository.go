@@ -41,3 +41,37 @@ func (r *BoardMemberRepository) FindByBoardIDAndUserID(boardID, userID uint) (*m
 	err := r.db.Preload("User").Where("board_id = ? AND user_id = ?", boardID, userID).First(&boardMember).Error
 	return &boardMember, err
 }
+
+// GetRole returns the role userID holds on boardID.
+// gorm.ErrRecordNotFound is returned if the user is not a member.
+func (r *BoardMemberRepository) GetRole(boardID, userID uint) (models.BoardRole, error) {
+	var boardMember models.BoardMember
+	err := r.db.Select("role").Where("board_id = ? AND user_id = ?", boardID, userID).First(&boardMember).Error
+	if err != nil {
+		return "", err
+	}
+	return boardMember.Role, nil
+}
+
+func (r *BoardMemberRepository) UpdateRole(boardID, userID uint, role models.BoardRole) error {
+	result := r.db.Model(&models.BoardMember{}).Where("board_id = ? AND user_id = ?", boardID, userID).Update("role", role)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *BoardMemberRepository) GetWorkspaceRole(boardID, userID uint) (models.WorkspaceRole, error) {
+	var member models.WorkspaceMember
+	err := r.db.Select("workspace_members.role").
+		Joins("JOIN boards ON boards.workspace_id = workspace_members.workspace_id AND boards.deleted_at IS NULL").
+		Where("boards.id = ? AND workspace_members.user_id = ?", boardID, userID).
+		First(&member).Error
+	if err != nil {
+		return "", err
+	}
+	return member.Role, nil
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/zayyadi/trello/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BoardPreferenceRepository struct {
+	db *gorm.DB
+}
+
+func NewBoardPreferenceRepository(db *gorm.DB) BoardPreferenceRepositoryInterface {
+	return &BoardPreferenceRepository{db: db}
+}
+
+func (r *BoardPreferenceRepository) FindByUser(userID uint) ([]models.BoardPreference, error) {
+	var prefs []models.BoardPreference
+	err := r.db.Where("user_id = ?", userID).Find(&prefs).Error
+	return prefs, err
+}
+
+func (r *BoardPreferenceRepository) SetStarred(userID, boardID uint, starred bool) error {
+	return r.upsert(r.db, &models.BoardPreference{UserID: userID, BoardID: boardID, Starred: starred}, "starred")
+}
+
+func (r *BoardPreferenceRepository) SetLastViewed(userID, boardID uint, at time.Time) error {
+	return r.upsert(r.db, &models.BoardPreference{UserID: userID, BoardID: boardID, LastViewedAt: &at}, "last_viewed_at")
+}
+
+// SetOrder places boardIDs first to last in the user's board list, in one
+// transaction. Boards left out lose their place.
+func (r *BoardPreferenceRepository) SetOrder(userID uint, boardIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.BoardPreference{}).Where("user_id = ? AND position IS NOT NULL", userID).
+			Update("position", nil).Error; err != nil {
+			return err
+		}
+		for i, boardID := range boardIDs {
+			position := i + 1
+			if err := r.upsert(tx, &models.BoardPreference{UserID: userID, BoardID: boardID, Position: &position}, "position"); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// upsert creates the user's preferences for the board, or only updates column if they exist.
+func (r *BoardPreferenceRepository) upsert(db *gorm.DB, pref *models.BoardPreference, column string) error {
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "board_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{column, "updated_at"}),
+	}).Create(pref).Error
+}
@@ -3,6 +3,7 @@ package repositories
 import (
 	"github.com/zayyadi/trello/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type BoardRepository struct {
@@ -31,12 +32,31 @@ func (r *BoardRepository) FindByOwnerOrMember(userID uint) ([]models.Board, erro
 	// Option 1: Two queries and merge
 	// Option 2: Subquery or JOIN
 	// Let's try a JOIN approach
+	// Boards shared with a workspace the user belongs to count as well
 	err := r.db.Joins("LEFT JOIN board_members on board_members.board_id = boards.id").
-		Where("boards.owner_id = ? OR board_members.user_id = ?", userID, userID).
+		Where("boards.owner_id = ? OR board_members.user_id = ? OR (boards.visibility IN ? AND boards.workspace_id IN (?))",
+			userID, userID, workspaceVisibilities, r.workspacesOf(userID)).
 		Preload("Owner").Preload("Members.User").Distinct().Find(&boards).Error
 	return boards, err
 }
 
+func (r *BoardRepository) FindByWorkspace(workspaceID, userID uint) ([]models.Board, error) {
+	var boards []models.Board
+	err := r.db.Where("boards.workspace_id = ?", workspaceID).
+		Where("boards.visibility IN ? OR boards.owner_id = ? OR boards.id IN (?)",
+			workspaceVisibilities, userID, r.db.Model(&models.BoardMember{}).Select("board_id").Where("user_id = ?", userID)).
+		Preload("Owner").Order("boards.name").Find(&boards).Error
+	return boards, err
+}
+
+// workspaceVisibilities are the visibilities that share a board with its workspace.
+var workspaceVisibilities = []models.BoardVisibility{models.BoardVisibilityWorkspace, models.BoardVisibilityPublic}
+
+// workspacesOf is a subquery selecting the IDs of the workspaces userID belongs to.
+func (r *BoardRepository) workspacesOf(userID uint) *gorm.DB {
+	return r.db.Model(&models.WorkspaceMember{}).Select("workspace_id").Where("user_id = ?", userID)
+}
+
 func (r *BoardRepository) Update(board *models.Board) error {
 	return r.db.Save(board).Error
 }
@@ -52,3 +72,21 @@ func (r *BoardRepository) IsOwner(boardID, userID uint) (bool, error) {
 	}
 	return board.OwnerID == userID, nil
 }
+
+// TransferOwnership hands the board to newOwnerID in one transaction. The new
+// owner becomes an admin member; the previous owner's membership row is left as is.
+func (r *BoardRepository) TransferOwnership(boardID, newOwnerID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Board{}).Where("id = ?", boardID).Update("owner_id", newOwnerID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "board_id"}, {Name: "user_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"role": models.BoardRoleAdmin}),
+		}).Create(&models.BoardMember{BoardID: boardID, UserID: newOwnerID, Role: models.BoardRoleAdmin}).Error
+	})
+}
@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/zayyadi/trello/models"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestBoardRepository_TransferOwnership(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewBoardRepository(db)
+	memberRepo := NewBoardMemberRepository(db)
+
+	alice := models.User{Username: "alice", Email: "alice@example.com", Password: "x"}
+	bob := models.User{Username: "bob", Email: "bob@example.com", Password: "x"}
+	assert.NoError(t, db.Create(&alice).Error)
+	assert.NoError(t, db.Create(&bob).Error)
+	board := models.Board{Name: "Board", OwnerID: alice.ID}
+	assert.NoError(t, repo.Create(&board))
+	assert.NoError(t, memberRepo.AddMember(&models.BoardMember{BoardID: board.ID, UserID: alice.ID, Role: models.BoardRoleAdmin}))
+	assert.NoError(t, memberRepo.AddMember(&models.BoardMember{BoardID: board.ID, UserID: bob.ID, Role: models.BoardRoleViewer}))
+
+	assert.NoError(t, repo.TransferOwnership(board.ID, bob.ID))
+
+	found, err := repo.FindByID(board.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, bob.ID, found.OwnerID)
+	role, err := memberRepo.GetRole(board.ID, bob.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.BoardRoleAdmin, role, "the new owner is promoted to admin")
+	role, err = memberRepo.GetRole(board.ID, alice.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.BoardRoleAdmin, role, "the previous owner stays on the board")
+
+	assert.ErrorIs(t, repo.TransferOwnership(board.ID+1, bob.ID), gorm.ErrRecordNotFound)
+}
@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/zayyadi/trello/models"
+	"gorm.io/gorm"
+)
+
+type BoardShareLinkRepository struct {
+	db *gorm.DB
+}
+
+func NewBoardShareLinkRepository(db *gorm.DB) BoardShareLinkRepositoryInterface {
+	return &BoardShareLinkRepository{db: db}
+}
+
+func (r *BoardShareLinkRepository) Create(link *models.BoardShareLink) error {
+	return r.db.Create(link).Error
+}
+
+func (r *BoardShareLinkRepository) FindByHash(tokenHash string) (*models.BoardShareLink, error) {
+	var link models.BoardShareLink
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&link).Error; err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// FindByBoard returns the board's unrevoked links, newest first.
+// Expired links are included so admins can see them.
+func (r *BoardShareLinkRepository) FindByBoard(boardID uint) ([]models.BoardShareLink, error) {
+	var links []models.BoardShareLink
+	err := r.db.Where("board_id = ? AND revoked_at IS NULL", boardID).Order("created_at DESC").Find(&links).Error
+	return links, err
+}
+
+// Revoke revokes one of the board's links. It returns gorm.ErrRecordNotFound
+// if the board has no such unrevoked link.
+func (r *BoardShareLinkRepository) Revoke(id, boardID uint) error {
+	result := r.db.Model(&models.BoardShareLink{}).
+		Where("id = ? AND board_id = ? AND revoked_at IS NULL", id, boardID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"errors"
 	"log" // Import log package
 
 	"github.com/zayyadi/trello/models"
@@ -16,35 +17,43 @@ func NewCardRepository(db *gorm.DB) CardRepositoryInterface {
 }
 
 func (r *CardRepository) Create(card *models.Card) error {
-	// Set position to max_position + 1 for the list
-	var maxPosition uint
-	r.db.Model(&models.Card{}).Where("list_id = ?", card.ListID).Select("COALESCE(MAX(position), 0)").Row().Scan(&maxPosition)
-	card.Position = maxPosition + 1
-	err := r.db.Create(card).Error
-	if err != nil {
-		log.Printf("ERROR [CardRepository.Create]: Failed to create card in DB. Input: %+v, Error: %v\n", card, err)
-	}
-	return err
+	// Put the card at the end of the list. Archived cards are out of the order.
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		position, err := rankAt(tx, &models.Card{}, "list_id", card.ListID, 0, 0)
+		if err != nil {
+			return err
+		}
+		card.Position = position
+		if err := tx.Create(card).Error; err != nil {
+			log.Printf("ERROR [CardRepository.Create]: Failed to create card in DB. Input: %+v, Error: %v\n", card, err)
+			return err
+		}
+		return nil
+	})
 }
 
 func (r *CardRepository) FindByID(id uint) (*models.Card, error) {
 	var card models.Card
-	// Preload AssignedUser, Supervisor and Collaborators
-	err := r.db.Preload("AssignedUser").Preload("Supervisor").Preload("Collaborators").First(&card, id).Error
+	// Preload AssignedUser, Supervisor, Collaborators, Labels, Checklists and the cover
+	err := preloadCardChecklists(r.db.Preload("AssignedUser").Preload("Supervisor").Preload("Collaborators").Preload("Labels").Preload("CoverAttachment")).
+		First(&card, id).Error
 	return &card, err
 }
 
 func (r *CardRepository) FindByListID(listID uint) ([]models.Card, error) {
 	var cards []models.Card
-	// Preload AssignedUser, Supervisor and Collaborators for each card
-	err := r.db.Where("list_id = ?", listID).Order("position ASC").
-		Preload("AssignedUser").Preload("Supervisor").Preload("Collaborators").
+	// Preload AssignedUser, Supervisor, Collaborators, Labels, Checklists and the cover for each card. Archived cards are left out.
+	err := preloadCardChecklists(r.db.Where("list_id = ? AND archived_at IS NULL", listID).Order("position ASC")).
+		Preload("AssignedUser").Preload("Supervisor").Preload("Collaborators").Preload("Labels").Preload("CoverAttachment").
 		Find(&cards).Error
 	return cards, err
 }
 
 func (r *CardRepository) Update(card *models.Card) error {
-	err := r.db.Save(card).Error
+	// Labels, checklists and attachments have their own repositories; saving the loaded
+	// copies here could bring back ones removed in the meantime. The rank only
+	// changes through MoveCard, so a stale copy can't undo a move either.
+	err := r.db.Omit("Labels", "Checklists", "CoverAttachment", "Position").Save(card).Error
 	if err != nil {
 		log.Printf("ERROR [CardRepository.Update]: Failed to update card in DB. Input: %+v, Error: %v\n", card, err)
 	}
@@ -67,77 +76,103 @@ func (r *CardRepository) GetListIDByCardID(cardID uint) (uint, error) {
 	return card.ListID, nil
 }
 
-func (r *CardRepository) UpdateCardPosition(cardID, listID, newPosition uint) error {
-	return r.db.Model(&models.Card{}).Where("id = ?", cardID).Updates(map[string]interface{}{"list_id": listID, "position": newPosition}).Error
+func (r *CardRepository) PerformTransaction(fn func(tx *gorm.DB) error) error {
+	return r.db.Transaction(fn)
 }
 
-// ShiftPositions adjusts positions of cards in a list, typically when a card is added, moved, or removed.
-// listID: The ID of the list whose cards' positions need adjustment.
-// startPosition: The position from which to start shifting.
-// shiftAmount: The amount to shift by (positive to increase positions, negative to decrease).
-// excludedCardID: (Optional) A card ID to exclude from shifting (e.g., the card being moved).
-func (r *CardRepository) ShiftPositions(listID uint, startPosition uint, shiftAmount int, excludedCardID *uint) error {
-	query := r.db.Model(&models.Card{}).Where("list_id = ? AND position >= ?", listID, startPosition)
-	if excludedCardID != nil {
-		query = query.Where("id != ?", *excludedCardID)
-	}
-	return query.UpdateColumn("position", gorm.Expr("position + ?", shiftAmount)).Error
+func (r *CardRepository) MoveCard(cardID, listID uint, position uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return moveCard(tx, cardID, listID, position)
+	})
 }
 
-func (r *CardRepository) GetMaxPosition(listID uint) (uint, error) {
-	var maxPosition uint
-	result := r.db.Model(&models.Card{}).Where("list_id = ?", listID).Select("COALESCE(MAX(position), 0)").Row()
-	if err := result.Scan(&maxPosition); err != nil {
-		return 0, err
-	}
-	return maxPosition, nil
+// Reorder gives the list's cards evenly spread ranks in the given order, which
+// must list each of its active cards exactly once (ErrOrderMismatch otherwise).
+func (r *CardRepository) Reorder(listID uint, cardIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return reorder(tx, &models.Card{}, "list_id", listID, cardIDs)
+	})
 }
 
-// MoveCard updates the ListID and Position of a card.
-// It also handles reordering in the source and destination lists.
-func (r *CardRepository) PerformTransaction(fn func(tx *gorm.DB) error) error {
-	return r.db.Transaction(fn)
+// CardBoardMove describes moving a card to a list on another board.
+type CardBoardMove struct {
+	CardID        uint
+	NewListID     uint
+	NewPosition   uint
+	TargetBoardID uint
+	// People who can't see the target board. They are unassigned from the card
+	// and its checklist items, and stop being collaborators.
+	RemovedUserIDs []uint
 }
 
-func (r *CardRepository) MoveCard(cardID, oldListID, newListID uint, newPosition uint) error {
+// MoveCardToBoard moves a card to a list on another board in one transaction.
+// Labels belong to a board, so each of the card's labels is swapped for the
+// target board's label with the same name and color, or taken off if there is none.
+func (r *CardRepository) MoveCardToBoard(move CardBoardMove) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
-		var cardToMove models.Card
-		if err := tx.First(&cardToMove, cardID).Error; err != nil {
-			return err // Card not found
+		if err := moveCard(tx, move.CardID, move.NewListID, move.NewPosition); err != nil {
+			return err
 		}
-		currentPosition := cardToMove.Position
-
-		// 1. Shift cards in the old list (if moving from a list)
-		if cardToMove.ListID == oldListID { // Ensure it's actually in oldListID
-			// Decrement positions of cards that were after the moved card in the old list
-			if err := tx.Model(&models.Card{}).
-				Where("list_id = ? AND position > ?", oldListID, currentPosition).
-				Update("position", gorm.Expr("position - 1")).Error; err != nil {
+
+		if removed := move.RemovedUserIDs; len(removed) > 0 {
+			for _, column := range []string{"assigned_user_id", "supervisor_id"} {
+				if err := tx.Model(&models.Card{}).Where("id = ? AND "+column+" IN ?", move.CardID, removed).
+					Update(column, nil).Error; err != nil {
+					return err
+				}
+			}
+			if err := tx.Where("card_id = ? AND user_id IN ?", move.CardID, removed).Delete(&models.CardCollaborator{}).Error; err != nil {
+				return err
+			}
+			checklistIDs := tx.Model(&models.Checklist{}).Select("id").Where("card_id = ?", move.CardID)
+			if err := tx.Model(&models.ChecklistItem{}).Where("checklist_id IN (?) AND assigned_user_id IN ?", checklistIDs, removed).
+				Update("assigned_user_id", nil).Error; err != nil {
 				return err
 			}
 		}
 
-		// 2. Shift cards in the new list
-		// Increment positions of cards at or after the new position in the new list
-		if err := tx.Model(&models.Card{}).
-			Where("list_id = ? AND position >= ?", newListID, newPosition).
-			Update("position", gorm.Expr("position + 1")).Error; err != nil {
+		var labels []models.Label
+		if err := tx.Joins("JOIN card_labels ON card_labels.label_id = labels.id").
+			Where("card_labels.card_id = ?", move.CardID).Find(&labels).Error; err != nil {
 			return err
 		}
-
-		// 3. Update the card itself
-		cardToMove.ListID = newListID
-		cardToMove.Position = newPosition
-		if err := tx.Save(&cardToMove).Error; err != nil {
-			log.Printf("ERROR [CardRepository.MoveCard.Save]: Failed to save moved card %d. Error: %v\n", cardID, err)
+		if err := tx.Where("card_id = ?", move.CardID).Delete(&models.CardLabel{}).Error; err != nil {
 			return err
 		}
-
+		for _, label := range labels {
+			var match models.Label
+			err := tx.Where("board_id = ? AND name = ? AND color = ?", move.TargetBoardID, label.Name, label.Color).
+				Order("id").First(&match).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			} else if err != nil {
+				return err
+			}
+			if err := tx.Create(&models.CardLabel{CardID: move.CardID, LabelID: match.ID}).Error; err != nil {
+				return err
+			}
+		}
 		return nil
 	})
-	// err is implicitly returned by the Transaction func if any step fails
-	// Adding a generic log here if transaction itself fails might be useful too, but GORM handles that.
-	return err // return the error from the transaction
+}
+
+// moveCard puts a card in a list at a 1-based position by giving it a rank
+// between its new neighbours. The other cards are left as they are.
+func moveCard(tx *gorm.DB, cardID, listID uint, position uint) error {
+	newRank, err := rankAt(tx, &models.Card{}, "list_id", listID, cardID, position)
+	if err != nil {
+		return err
+	}
+	result := tx.Model(&models.Card{}).Where("id = ?", cardID).
+		Updates(map[string]interface{}{"list_id": listID, "position": newRank})
+	if result.Error != nil {
+		log.Printf("ERROR [CardRepository.MoveCard]: Failed to save moved card %d. Error: %v\n", cardID, result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
 }
 
 func (r *CardRepository) AddCollaborator(cardID uint, userID uint) error {
@@ -204,3 +239,12 @@ func (r *CardRepository) IsUserCollaboratorOrAssignee(cardID uint, userID uint)
 	// If not the assignee, check if the user is a collaborator
 	return r.IsCollaborator(cardID, userID)
 }
+
+// preloadCardChecklists loads cards' checklists and their items, in position order.
+func preloadCardChecklists(db *gorm.DB) *gorm.DB {
+	return db.Preload("Checklists", func(db *gorm.DB) *gorm.DB {
+		return db.Order("checklists.position, checklists.id")
+	}).Preload("Checklists.Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("checklist_items.position, checklist_items.id")
+	}).Preload("Checklists.Items.AssignedUser")
+}
@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"github.com/zayyadi/trello/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ChecklistRepository struct {
+	db *gorm.DB
+}
+
+func NewChecklistRepository(db *gorm.DB) ChecklistRepositoryInterface {
+	return &ChecklistRepository{db: db}
+}
+
+// Create adds the checklist at the end of the card's checklists.
+func (r *ChecklistRepository) Create(checklist *models.Checklist) error {
+	var maxPosition uint
+	if err := r.db.Model(&models.Checklist{}).Where("card_id = ?", checklist.CardID).
+		Select("COALESCE(MAX(position), 0)").Row().Scan(&maxPosition); err != nil {
+		return err
+	}
+	checklist.Position = maxPosition + 1
+	return r.db.Create(checklist).Error
+}
+
+func (r *ChecklistRepository) FindByID(id uint) (*models.Checklist, error) {
+	var checklist models.Checklist
+	if err := preloadChecklistItems(r.db).First(&checklist, id).Error; err != nil {
+		return nil, err
+	}
+	return &checklist, nil
+}
+
+// FindByCardID returns the card's checklists with their items, both in position order.
+func (r *ChecklistRepository) FindByCardID(cardID uint) ([]models.Checklist, error) {
+	var checklists []models.Checklist
+	err := preloadChecklistItems(r.db).Where("card_id = ?", cardID).Order("position, id").Find(&checklists).Error
+	return checklists, err
+}
+
+func (r *ChecklistRepository) Update(checklist *models.Checklist) error {
+	return r.db.Omit(clause.Associations).Save(checklist).Error
+}
+
+// Delete removes the checklist and its items in one transaction.
+func (r *ChecklistRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("checklist_id = ?", id).Delete(&models.ChecklistItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Checklist{}, id).Error
+	})
+}
+
+// Reorder numbers the card's checklists 1..n in the order given, in one transaction.
+func (r *ChecklistRepository) Reorder(cardID uint, checklistIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i, id := range checklistIDs {
+			if err := tx.Model(&models.Checklist{}).Where("id = ? AND card_id = ?", id, cardID).
+				UpdateColumn("position", i+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CreateItem adds the item at the end of its checklist.
+func (r *ChecklistRepository) CreateItem(item *models.ChecklistItem) error {
+	var maxPosition uint
+	if err := r.db.Model(&models.ChecklistItem{}).Where("checklist_id = ?", item.ChecklistID).
+		Select("COALESCE(MAX(position), 0)").Row().Scan(&maxPosition); err != nil {
+		return err
+	}
+	item.Position = maxPosition + 1
+	return r.db.Create(item).Error
+}
+
+func (r *ChecklistRepository) FindItemByID(id uint) (*models.ChecklistItem, error) {
+	var item models.ChecklistItem
+	if err := r.db.Preload("AssignedUser").First(&item, id).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *ChecklistRepository) UpdateItem(item *models.ChecklistItem) error {
+	// Leave out the loaded assignee, which would otherwise overwrite a changed AssignedUserID
+	return r.db.Omit(clause.Associations).Save(item).Error
+}
+
+func (r *ChecklistRepository) DeleteItem(id uint) error {
+	return r.db.Delete(&models.ChecklistItem{}, id).Error
+}
+
+// ReorderItems numbers the checklist's items 1..n in the order given, in one transaction.
+func (r *ChecklistRepository) ReorderItems(checklistID uint, itemIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i, id := range itemIDs {
+			if err := tx.Model(&models.ChecklistItem{}).Where("id = ? AND checklist_id = ?", id, checklistID).
+				UpdateColumn("position", i+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// preloadChecklistItems loads checklists' items in position order, with their assignees.
+func preloadChecklistItems(db *gorm.DB) *gorm.DB {
+	return db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("checklist_items.position, checklist_items.id")
+	}).Preload("Items.AssignedUser")
+}
@@ -20,12 +20,18 @@ func (r *CommentRepository) Create(comment *models.Comment) error {
 func (r *CommentRepository) FindByCardID(cardID uint) ([]models.Comment, error) {
 	var comments []models.Comment
 	// Preload User details for each comment, order by creation date
-	err := r.db.Preload("User").Where("card_id = ?", cardID).Order("created_at asc").Find(&comments).Error
+	err := r.db.Preload("User", withDeletedUsers).Where("card_id = ?", cardID).Order("created_at asc").Find(&comments).Error
 	return comments, err
 }
 
 func (r *CommentRepository) FindByID(id uint) (*models.Comment, error) {
 	var comment models.Comment
-	err := r.db.Preload("User").First(&comment, id).Error
+	err := r.db.Preload("User", withDeletedUsers).First(&comment, id).Error
 	return &comment, err
 }
+
+// withDeletedUsers also loads authors whose accounts were deleted; their
+// comments are kept and shown under the anonymized profile.
+func withDeletedUsers(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
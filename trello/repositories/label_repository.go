@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"github.com/zayyadi/trello/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LabelRepository struct {
+	db *gorm.DB
+}
+
+func NewLabelRepository(db *gorm.DB) LabelRepositoryInterface {
+	return &LabelRepository{db: db}
+}
+
+func (r *LabelRepository) Create(label *models.Label) error {
+	return r.db.Create(label).Error
+}
+
+func (r *LabelRepository) FindByID(id uint) (*models.Label, error) {
+	var label models.Label
+	if err := r.db.First(&label, id).Error; err != nil {
+		return nil, err
+	}
+	return &label, nil
+}
+
+// FindByBoardID returns the board's labels in the order they were created.
+func (r *LabelRepository) FindByBoardID(boardID uint) ([]models.Label, error) {
+	var labels []models.Label
+	err := r.db.Where("board_id = ?", boardID).Order("id").Find(&labels).Error
+	return labels, err
+}
+
+func (r *LabelRepository) Update(label *models.Label) error {
+	return r.db.Save(label).Error
+}
+
+// Delete removes the label and takes it off every card, in one transaction.
+func (r *LabelRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("label_id = ?", id).Delete(&models.CardLabel{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Label{}, id).Error
+	})
+}
+
+// AddToCard puts the label on the card. Adding a label the card already has does nothing.
+func (r *LabelRepository) AddToCard(cardID, labelID uint) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.CardLabel{CardID: cardID, LabelID: labelID}).Error
+}
+
+// RemoveFromCard takes the label off the card. It returns gorm.ErrRecordNotFound
+// if the card doesn't have the label.
+func (r *LabelRepository) RemoveFromCard(cardID, labelID uint) error {
+	result := r.db.Where("card_id = ? AND label_id = ?", cardID, labelID).Delete(&models.CardLabel{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *LabelRepository) HasLabel(cardID, labelID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.CardLabel{}).Where("card_id = ? AND label_id = ?", cardID, labelID).Count(&count).Error
+	return count > 0, err
+}
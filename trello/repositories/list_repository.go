@@ -15,47 +15,63 @@ func NewListRepository(db *gorm.DB) ListRepositoryInterface {
 }
 
 func (r *ListRepository) Create(list *models.List) error {
-	// Set position to max_position + 1 for the board
-	var maxPosition uint
-	r.db.Model(&models.List{}).Where("board_id = ?", list.BoardID).Select("COALESCE(MAX(position), 0)").Row().Scan(&maxPosition)
-	list.Position = maxPosition + 1
-	return r.db.Create(list).Error
+	// Put the list at the end of the board. Archived lists are out of the order.
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		position, err := rankAt(tx, &models.List{}, "board_id", list.BoardID, 0, 0)
+		if err != nil {
+			return err
+		}
+		list.Position = position
+		return tx.Create(list).Error
+	})
 }
 
 func (r *ListRepository) FindByID(id uint) (*models.List, error) {
 	var list models.List
-	// Preload cards sorted by position
+	// Preload cards sorted by position, leaving out archived ones
 	err := r.db.Preload("Cards", func(db *gorm.DB) *gorm.DB {
-		return db.Order("cards.position ASC")
+		return db.Where("cards.archived_at IS NULL").Order("cards.position ASC")
 	}).First(&list, id).Error
 	return &list, err
 }
 
 func (r *ListRepository) FindByBoardID(boardID uint) ([]models.List, error) {
 	var lists []models.List
-	// Preload cards for each list, sorted by position
-	err := r.db.Where("board_id = ?", boardID).Order("position ASC").
+	// Preload cards for each list, sorted by position, with their labels, checklist items
+	// (for the cards' progress) and covers. Archived lists and cards are left out.
+	err := r.db.Where("board_id = ? AND archived_at IS NULL", boardID).Order("position ASC").
 		Preload("Cards", func(db *gorm.DB) *gorm.DB {
-			return db.Order("cards.position ASC")
+			return db.Where("cards.archived_at IS NULL").Order("cards.position ASC")
 		}).
+		Preload("Cards.Labels").Preload("Cards.Checklists.Items").Preload("Cards.CoverAttachment").
 		Find(&lists).Error
 	return lists, err
 }
 
+// Update saves everything but the rank, which only changes through Move.
 func (r *ListRepository) Update(list *models.List) error {
-	return r.db.Save(list).Error
+	return r.db.Omit("Position").Save(list).Error
 }
 
-// UpdatePositions updates positions of multiple lists (e.g., after drag-drop)
-// listsWithNewPositions is a map of listID to newPosition
-func (r *ListRepository) UpdatePositions(boardID uint, listsWithNewPositions map[uint]uint) error {
+func (r *ListRepository) Move(listID uint, position uint) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
-		for listID, newPosition := range listsWithNewPositions {
-			if err := tx.Model(&models.List{}).Where("id = ? AND board_id = ?", listID, boardID).Update("position", newPosition).Error; err != nil {
-				return err
-			}
+		var list models.List
+		if err := tx.Select("id, board_id").First(&list, listID).Error; err != nil {
+			return err
 		}
-		return nil
+		newRank, err := rankAt(tx, &models.List{}, "board_id", list.BoardID, list.ID, position)
+		if err != nil {
+			return err
+		}
+		return tx.Model(&list).UpdateColumn("position", newRank).Error
+	})
+}
+
+// Reorder gives the board's lists evenly spread ranks in the given order, which
+// must list each of its active lists exactly once (ErrOrderMismatch otherwise).
+func (r *ListRepository) Reorder(boardID uint, listIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return reorder(tx, &models.List{}, "board_id", boardID, listIDs)
 	})
 }
 
@@ -73,15 +89,6 @@ func (r *ListRepository) GetBoardIDByListID(listID uint) (uint, error) {
 	return list.BoardID, nil
 }
 
-func (r *ListRepository) GetMaxPosition(boardID uint) (uint, error) {
-	var maxPosition uint
-	result := r.db.Model(&models.List{}).Where("board_id = ?", boardID).Select("COALESCE(MAX(position), 0)").Row()
-	if err := result.Scan(&maxPosition); err != nil {
-		return 0, err
-	}
-	return maxPosition, nil
-}
-
 // PerformTransaction executes the given function within a database transaction.
 func (r *ListRepository) PerformTransaction(fn func(tx *gorm.DB) error) error {
 	return r.db.Transaction(fn)
@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/zayyadi/trello/models"
+	"gorm.io/gorm"
+)
+
+// memoryThrottlePruneSize is how many counters the in-memory store holds before
+// it drops the ones that have gone stale.
+const memoryThrottlePruneSize = 10000
+
+type throttleKey struct {
+	scope models.LoginThrottleScope
+	key   string
+}
+
+// MemoryLoginThrottleRepository keeps failed login counters in process memory.
+// Counters are lost on restart and are not shared between server instances.
+type MemoryLoginThrottleRepository struct {
+	mu        sync.Mutex
+	throttles map[throttleKey]*models.LoginThrottle
+	nextID    uint
+}
+
+func NewMemoryLoginThrottleRepository() LoginThrottleRepositoryInterface {
+	return &MemoryLoginThrottleRepository{throttles: make(map[throttleKey]*models.LoginThrottle)}
+}
+
+func (r *MemoryLoginThrottleRepository) Find(scope models.LoginThrottleScope, key string) (*models.LoginThrottle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	throttle, ok := r.throttles[throttleKey{scope, key}]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *throttle // Callers must not mutate the stored counter
+	return &copied, nil
+}
+
+func (r *MemoryLoginThrottleRepository) RecordFailure(scope models.LoginThrottleScope, key string, at time.Time, window time.Duration) (*models.LoginThrottle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := throttleKey{scope, key}
+	throttle, ok := r.throttles[k]
+	if !ok {
+		if len(r.throttles) >= memoryThrottlePruneSize {
+			r.pruneLocked(at, window)
+		}
+		r.nextID++
+		throttle = &models.LoginThrottle{ID: r.nextID, Scope: scope, Key: key, CreatedAt: at}
+		r.throttles[k] = throttle
+	}
+	if throttle.LastFailureAt.Before(at.Add(-window)) {
+		throttle.Failures = 0
+	}
+	throttle.Failures++
+	throttle.LastFailureAt = at
+	throttle.UpdatedAt = at
+
+	copied := *throttle
+	return &copied, nil
+}
+
+func (r *MemoryLoginThrottleRepository) Lock(scope models.LoginThrottleScope, key string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if throttle, ok := r.throttles[throttleKey{scope, key}]; ok {
+		throttle.LockedUntil = &until
+	}
+	return nil
+}
+
+func (r *MemoryLoginThrottleRepository) Delete(scope models.LoginThrottleScope, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	k := throttleKey{scope, key}
+	if _, ok := r.throttles[k]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	delete(r.throttles, k)
+	return nil
+}
+
+func (r *MemoryLoginThrottleRepository) FindLocked(now time.Time) ([]models.LoginThrottle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var locked []models.LoginThrottle
+	for _, throttle := range r.throttles {
+		if throttle.IsLocked(now) {
+			locked = append(locked, *throttle)
+		}
+	}
+	sort.Slice(locked, func(i, j int) bool { return locked[i].LockedUntil.After(*locked[j].LockedUntil) })
+	return locked, nil
+}
+
+// pruneLocked drops counters that are neither locked nor within the failure window.
+// The caller must hold r.mu.
+func (r *MemoryLoginThrottleRepository) pruneLocked(now time.Time, window time.Duration) {
+	for k, throttle := range r.throttles {
+		if !throttle.IsLocked(now) && throttle.LastFailureAt.Before(now.Add(-window)) {
+			delete(r.throttles, k)
+		}
+	}
+}
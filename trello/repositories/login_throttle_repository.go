@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/zayyadi/trello/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LoginThrottleRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginThrottleRepository(db *gorm.DB) LoginThrottleRepositoryInterface {
+	return &LoginThrottleRepository{db: db}
+}
+
+func (r *LoginThrottleRepository) Find(scope models.LoginThrottleScope, key string) (*models.LoginThrottle, error) {
+	var throttle models.LoginThrottle
+	err := r.db.Where("scope = ? AND key = ?", scope, key).First(&throttle).Error
+	return &throttle, err
+}
+
+// RecordFailure upserts the counter in a single statement, so concurrent failures
+// from several server instances are all counted.
+func (r *LoginThrottleRepository) RecordFailure(scope models.LoginThrottleScope, key string, at time.Time, window time.Duration) (*models.LoginThrottle, error) {
+	throttle := models.LoginThrottle{Scope: scope, Key: key, Failures: 1, LastFailureAt: at}
+	err := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "scope"}, {Name: "key"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"failures": gorm.Expr(
+				"CASE WHEN login_throttles.last_failure_at < ? THEN 1 ELSE login_throttles.failures + 1 END",
+				at.Add(-window),
+			),
+			"last_failure_at": at,
+			"updated_at":      at,
+		}),
+	}).Create(&throttle).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.Find(scope, key)
+}
+
+func (r *LoginThrottleRepository) Lock(scope models.LoginThrottleScope, key string, until time.Time) error {
+	return r.db.Model(&models.LoginThrottle{}).
+		Where("scope = ? AND key = ?", scope, key).
+		Update("locked_until", until).Error
+}
+
+// Delete removes the counter. It returns gorm.ErrRecordNotFound if there is none.
+func (r *LoginThrottleRepository) Delete(scope models.LoginThrottleScope, key string) error {
+	result := r.db.Where("scope = ? AND key = ?", scope, key).Delete(&models.LoginThrottle{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *LoginThrottleRepository) FindLocked(now time.Time) ([]models.LoginThrottle, error) {
+	var throttles []models.LoginThrottle
+	err := r.db.Where("locked_until > ?", now).Order("locked_until DESC").Find(&throttles).Error
+	return throttles, err
+}
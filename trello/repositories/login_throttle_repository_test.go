@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zayyadi/trello/models"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// Both stores must behave the same, so they share these tests.
+func loginThrottleStores(t *testing.T) map[string]LoginThrottleRepositoryInterface {
+	return map[string]LoginThrottleRepositoryInterface{
+		"database": NewLoginThrottleRepository(setupTestDB(t)),
+		"memory":   NewMemoryLoginThrottleRepository(),
+	}
+}
+
+func TestLoginThrottleRepository_RecordFailure(t *testing.T) {
+	for name, repo := range loginThrottleStores(t) {
+		t.Run(name, func(t *testing.T) {
+			start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+			scope := models.LoginThrottleScopeAccount
+
+			_, err := repo.Find(scope, "jane@example.com")
+			assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+			throttle, err := repo.RecordFailure(scope, "jane@example.com", start, time.Hour)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, throttle.Failures)
+			throttle, err = repo.RecordFailure(scope, "jane@example.com", start.Add(time.Minute), time.Hour)
+			assert.NoError(t, err)
+			assert.Equal(t, 2, throttle.Failures)
+			assert.True(t, throttle.LastFailureAt.Equal(start.Add(time.Minute)))
+
+			// The same key in another scope is a separate counter
+			throttle, err = repo.RecordFailure(models.LoginThrottleScopeIP, "jane@example.com", start, time.Hour)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, throttle.Failures)
+
+			// A failure after a quiet window starts the count over
+			throttle, err = repo.RecordFailure(scope, "jane@example.com", start.Add(2*time.Hour), time.Hour)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, throttle.Failures)
+		})
+	}
+}
+
+func TestLoginThrottleRepository_LockListAndDelete(t *testing.T) {
+	for name, repo := range loginThrottleStores(t) {
+		t.Run(name, func(t *testing.T) {
+			now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+			scope := models.LoginThrottleScopeIP
+
+			_, _ = repo.RecordFailure(scope, "10.0.0.1", now, time.Hour)
+			_, _ = repo.RecordFailure(scope, "10.0.0.2", now, time.Hour)
+			_, _ = repo.RecordFailure(scope, "10.0.0.3", now, time.Hour)
+			assert.NoError(t, repo.Lock(scope, "10.0.0.1", now.Add(time.Minute)))
+			assert.NoError(t, repo.Lock(scope, "10.0.0.2", now.Add(time.Hour)))
+			assert.NoError(t, repo.Lock(scope, "10.0.0.3", now.Add(-time.Minute))) // Already expired
+
+			throttle, err := repo.Find(scope, "10.0.0.1")
+			assert.NoError(t, err)
+			assert.True(t, throttle.IsLocked(now))
+
+			locked, err := repo.FindLocked(now)
+			assert.NoError(t, err)
+			if assert.Len(t, locked, 2) {
+				assert.Equal(t, "10.0.0.2", locked[0].Key, "longest lock first")
+				assert.Equal(t, "10.0.0.1", locked[1].Key)
+			}
+
+			assert.NoError(t, repo.Delete(scope, "10.0.0.2"))
+			assert.ErrorIs(t, repo.Delete(scope, "10.0.0.2"), gorm.ErrRecordNotFound)
+			_, err = repo.Find(scope, "10.0.0.2")
+			assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+			// Deleted counters start from scratch
+			throttle, err = repo.RecordFailure(scope, "10.0.0.2", now, time.Hour)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, throttle.Failures)
+			assert.Nil(t, throttle.LockedUntil)
+		})
+	}
+}
+
+func TestAuditEventRepository_List(t *testing.T) {
+	repo := NewAuditEventRepository(setupTestDB(t))
+	userID, adminID := uint(7), uint(1)
+
+	assert.NoError(t, repo.Create(&models.AuditEvent{Type: models.AuditEventLoginFailed, UserID: &userID, Email: "jane@example.com"}))
+	assert.NoError(t, repo.Create(&models.AuditEvent{Type: models.AuditEventLoginFailed, Email: "ghost@example.com"}))
+	assert.NoError(t, repo.Create(&models.AuditEvent{Type: models.AuditEventLoginUnlocked, ActorID: &adminID}))
+
+	events, err := repo.List("", nil, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, events, 3) {
+		assert.Equal(t, models.AuditEventLoginUnlocked, events[0].Type, "most recent first")
+	}
+
+	events, _ = repo.List(models.AuditEventLoginFailed, nil, 10)
+	assert.Len(t, events, 2)
+	events, _ = repo.List("", &userID, 10)
+	assert.Len(t, events, 1)
+	events, _ = repo.List("", &adminID, 10)
+	assert.Len(t, events, 1, "events are matched by actor too")
+	events, _ = repo.List("", nil, 2)
+	assert.Len(t, events, 2)
+}
@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zayyadi/trello/models"
+	"gorm.io/gorm"
+)
+
+func createPersonalAccessToken(t *testing.T, repo PersonalAccessTokenRepositoryInterface, userID uint, name, hash string) *models.PersonalAccessToken {
+	token := &models.PersonalAccessToken{UserID: userID, Name: name, TokenHash: hash, Prefix: "tpat_abc"}
+	if err := repo.Create(token); err != nil {
+		t.Fatalf("Failed to create personal access token: %v", err)
+	}
+	return token
+}
+
+func TestPersonalAccessTokenRepository_FindAndRevoke(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewPersonalAccessTokenRepository(db)
+	first := createPersonalAccessToken(t, repo, 1, "first", "hash-1")
+	createPersonalAccessToken(t, repo, 1, "second", "hash-2")
+	createPersonalAccessToken(t, repo, 2, "other user", "hash-3")
+
+	found, err := repo.FindByHash("hash-1")
+	assert.NoError(t, err)
+	assert.Equal(t, first.ID, found.ID)
+
+	tokens, err := repo.FindByUserID(1)
+	assert.NoError(t, err)
+	assert.Len(t, tokens, 2)
+
+	assert.ErrorIs(t, repo.Revoke(first.ID, 2), gorm.ErrRecordNotFound, "cannot revoke another user's token")
+	assert.NoError(t, repo.Revoke(first.ID, 1))
+	assert.ErrorIs(t, repo.Revoke(first.ID, 1), gorm.ErrRecordNotFound, "already revoked")
+
+	tokens, err = repo.FindByUserID(1)
+	assert.NoError(t, err)
+	if assert.Len(t, tokens, 1) {
+		assert.Equal(t, "second", tokens[0].Name)
+	}
+
+	assert.NoError(t, repo.RevokeAllForUser(1))
+	tokens, err = repo.FindByUserID(1)
+	assert.NoError(t, err)
+	assert.Empty(t, tokens)
+}
+
+func TestPersonalAccessTokenRepository_UpdateLastUsed(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewPersonalAccessTokenRepository(db)
+	token := createPersonalAccessToken(t, repo, 1, "script", "hash-1")
+
+	usedAt := time.Now().Add(-time.Minute).Truncate(time.Second)
+	assert.NoError(t, repo.UpdateLastUsed(token.ID, usedAt))
+
+	found, err := repo.FindByHash("hash-1")
+	assert.NoError(t, err)
+	if assert.NotNil(t, found.LastUsedAt) {
+		assert.True(t, usedAt.Equal(*found.LastUsedAt))
+	}
+}
@@ -0,0 +1,164 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/rank"
+	"gorm.io/gorm"
+)
+
+// RankRepository finds and respreads the ranks ordering a board's lists and a
+// list's cards once they have grown long or two rows share one.
+type RankRepository struct {
+	db *gorm.DB
+}
+
+func NewRankRepository(db *gorm.DB) RankRepositoryInterface {
+	return &RankRepository{db: db}
+}
+
+// FindBoardsToRebalance returns up to limit boards after afterID whose lists need new ranks.
+func (r *RankRepository) FindBoardsToRebalance(maxLength int, afterID uint, limit int) ([]uint, error) {
+	return findUnbalanced(r.db, &models.List{}, "board_id", maxLength, afterID, limit)
+}
+
+// FindListsToRebalance returns up to limit lists after afterID whose cards need new ranks.
+func (r *RankRepository) FindListsToRebalance(maxLength int, afterID uint, limit int) ([]uint, error) {
+	return findUnbalanced(r.db, &models.Card{}, "list_id", maxLength, afterID, limit)
+}
+
+func (r *RankRepository) RebalanceLists(boardID uint) (ids []uint, ranks []string, err error) {
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		ids, ranks, err = respread(tx, &models.List{}, "board_id", boardID)
+		return err
+	})
+	return ids, ranks, err
+}
+
+func (r *RankRepository) RebalanceCards(listID uint) (ids []uint, ranks []string, err error) {
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		ids, ranks, err = respread(tx, &models.Card{}, "list_id", listID)
+		return err
+	})
+	return ids, ranks, err
+}
+
+// findUnbalanced returns the scopes after afterID whose active rows have a rank
+// longer than maxLength, an empty rank, or a rank shared by two rows.
+func findUnbalanced(db *gorm.DB, model interface{}, scope string, maxLength int, afterID uint, limit int) ([]uint, error) {
+	var scopeIDs []uint
+	err := db.Model(model).Where("archived_at IS NULL AND "+scope+" > ?", afterID).Group(scope).
+		Having("MAX(LENGTH(position)) > ? OR MIN(LENGTH(position)) = 0 OR COUNT(DISTINCT position) < COUNT(*)", maxLength).
+		Order(scope).Limit(limit).Pluck(scope, &scopeIDs).Error
+	return scopeIDs, err
+}
+
+// rankAt returns the rank that puts a row at the 1-based position among the
+// active (neither deleted nor archived) rows of model that share scope =
+// scopeID, leaving out the row excludeID; 0 or a position past the end puts it
+// last. Only the two rows it goes between are read. If they share a rank, the
+// scope is respread first.
+func rankAt(tx *gorm.DB, model interface{}, scope string, scopeID, excludeID, position uint) (string, error) {
+	for respreadFirst := false; ; respreadFirst = true {
+		if respreadFirst {
+			if _, _, err := respread(tx, model, scope, scopeID); err != nil {
+				return "", err
+			}
+		}
+		before, after, err := neighbours(tx, model, scope, scopeID, excludeID, position)
+		if err != nil {
+			return "", err
+		}
+		r, err := rank.Between(before, after)
+		if err == nil || respreadFirst {
+			return r, err
+		}
+	}
+}
+
+// neighbours returns the ranks of the rows before and after a 1-based
+// position, as described for rankAt. Either is empty at the ends.
+func neighbours(tx *gorm.DB, model interface{}, scope string, scopeID, excludeID, position uint) (before, after string, err error) {
+	siblings := func() *gorm.DB {
+		return tx.Model(model).Where(scope+" = ? AND archived_at IS NULL AND id <> ?", scopeID, excludeID)
+	}
+	var ranks []string
+	switch {
+	case position == 1:
+		err = siblings().Order("position, id").Limit(1).Pluck("position", &ranks).Error
+		if len(ranks) > 0 {
+			after = ranks[0]
+		}
+		return before, after, err
+	case position > 1:
+		if err = siblings().Order("position, id").Offset(int(position)-2).Limit(2).Pluck("position", &ranks).Error; err != nil {
+			return "", "", err
+		}
+		if len(ranks) > 0 {
+			before = ranks[0]
+			if len(ranks) > 1 {
+				after = ranks[1]
+			}
+			return before, after, nil
+		}
+	}
+	// At the end
+	err = siblings().Order("position DESC, id DESC").Limit(1).Pluck("position", &ranks).Error
+	if len(ranks) > 0 {
+		before = ranks[0]
+	}
+	return before, after, err
+}
+
+// respread gives the active rows of model that share scope = scopeID evenly
+// spread ranks in their current order, and returns their IDs and new ranks.
+func respread(tx *gorm.DB, model interface{}, scope string, scopeID uint) ([]uint, []string, error) {
+	var ids []uint
+	if err := tx.Model(model).Where(scope+" = ? AND archived_at IS NULL", scopeID).
+		Order("position, id").Pluck("id", &ids).Error; err != nil {
+		return nil, nil, err
+	}
+	ranks := rank.Spread(len(ids))
+	return ids, ranks, setRanks(tx, model, scope, scopeID, ids, ranks)
+}
+
+// setRanks gives each of the rows ids of the scope the rank at the same index.
+func setRanks(tx *gorm.DB, model interface{}, scope string, scopeID uint, ids []uint, ranks []string) error {
+	for i, id := range ids {
+		if err := tx.Model(model).Where("id = ? AND "+scope+" = ?", id, scopeID).
+			UpdateColumn("position", ranks[i]).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ErrOrderMismatch is returned by Reorder when the new order doesn't list each of
+// the active lists of the board, or cards of the list, exactly once.
+var ErrOrderMismatch = errors.New("the new order must list each item exactly once")
+
+// reorder gives the rows ids of the scope evenly spread ranks in that order. The
+// ids are checked against the scope's active rows in the same transaction, so a
+// row added after the caller last looked fails the reorder instead of keeping a
+// rank that may clash with the new ones.
+func reorder(tx *gorm.DB, model interface{}, scope string, scopeID uint, ids []uint) error {
+	var current []uint
+	if err := tx.Model(model).Where(scope+" = ? AND archived_at IS NULL", scopeID).Pluck("id", &current).Error; err != nil {
+		return err
+	}
+	if len(ids) != len(current) {
+		return ErrOrderMismatch
+	}
+	remaining := make(map[uint]bool, len(current))
+	for _, id := range current {
+		remaining[id] = true
+	}
+	for _, id := range ids {
+		if !remaining[id] {
+			return ErrOrderMismatch
+		}
+		delete(remaining, id)
+	}
+	return setRanks(tx, model, scope, scopeID, ids, rank.Spread(len(ids)))
+}
@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/zayyadi/trello/models"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestCardRepository_MoveCardOnlyChangesTheMovedCard(t *testing.T) {
+	db := setupTestDB(t)
+	cardRepo := NewCardRepository(db)
+	owner := models.User{Username: "alice", Email: "alice@example.com", Password: "x"}
+	assert.NoError(t, db.Create(&owner).Error)
+	board := models.Board{Name: "Board", OwnerID: owner.ID}
+	assert.NoError(t, db.Create(&board).Error)
+	todo := models.List{Name: "To do", BoardID: board.ID}
+	assert.NoError(t, NewListRepository(db).Create(&todo))
+	done := models.List{Name: "Done", BoardID: board.ID}
+	assert.NoError(t, NewListRepository(db).Create(&done))
+	cards := createCards(t, cardRepo, todo.ID, "a", "b", "c", "d")
+	createCards(t, cardRepo, done.ID, "x")
+
+	assert.NoError(t, cardRepo.MoveCard(cards[3].ID, todo.ID, 2))
+	assert.Equal(t, []string{"a", "d", "b", "c"}, cardTitles(t, cardRepo, todo.ID))
+	assert.NoError(t, cardRepo.MoveCard(cards[0].ID, todo.ID, 99), "past the end goes last")
+	assert.Equal(t, []string{"d", "b", "c", "a"}, cardTitles(t, cardRepo, todo.ID))
+	assert.NoError(t, cardRepo.MoveCard(cards[1].ID, done.ID, 1))
+	assert.Equal(t, []string{"d", "c", "a"}, cardTitles(t, cardRepo, todo.ID))
+	assert.Equal(t, []string{"b", "x"}, cardTitles(t, cardRepo, done.ID))
+	unmoved, err := cardRepo.FindByID(cards[2].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, cards[2].Position, unmoved.Position)
+
+	// Cards sharing a rank, as after two moves at once, are respread before going between them
+	assert.NoError(t, db.Model(&models.Card{}).Where("id IN ?", []uint{cards[2].ID, cards[0].ID}).
+		UpdateColumn("position", "k").Error)
+	assert.NoError(t, cardRepo.MoveCard(cards[3].ID, todo.ID, 2))
+	assert.Equal(t, []string{"a", "d", "c"}, cardTitles(t, cardRepo, todo.ID), "ties keep the order of their IDs")
+}
+
+func TestCardRepository_ReorderListsEachActiveCardOnce(t *testing.T) {
+	db := setupTestDB(t)
+	cardRepo := NewCardRepository(db)
+	owner := models.User{Username: "alice", Email: "alice@example.com", Password: "x"}
+	assert.NoError(t, db.Create(&owner).Error)
+	board := models.Board{Name: "Board", OwnerID: owner.ID}
+	assert.NoError(t, db.Create(&board).Error)
+	todo := models.List{Name: "To do", BoardID: board.ID}
+	assert.NoError(t, NewListRepository(db).Create(&todo))
+	cards := createCards(t, cardRepo, todo.ID, "a", "b", "c")
+
+	assert.ErrorIs(t, cardRepo.Reorder(todo.ID, []uint{cards[1].ID, cards[0].ID}), ErrOrderMismatch, "a card added since is missing")
+	assert.ErrorIs(t, cardRepo.Reorder(todo.ID, []uint{cards[1].ID, cards[0].ID, cards[0].ID}), ErrOrderMismatch)
+	assert.Equal(t, []string{"a", "b", "c"}, cardTitles(t, cardRepo, todo.ID), "nothing changes")
+
+	assert.NoError(t, db.Model(&cards[2]).Update("archived_at", gorm.Expr("CURRENT_TIMESTAMP")).Error)
+	assert.NoError(t, cardRepo.Reorder(todo.ID, []uint{cards[1].ID, cards[0].ID}), "archived cards aren't listed")
+	assert.Equal(t, []string{"b", "a"}, cardTitles(t, cardRepo, todo.ID))
+}
+
+func TestRankRepository_Rebalance(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRankRepository(db)
+	cardRepo := NewCardRepository(db)
+	owner := models.User{Username: "alice", Email: "alice@example.com", Password: "x"}
+	assert.NoError(t, db.Create(&owner).Error)
+	board := models.Board{Name: "Board", OwnerID: owner.ID}
+	assert.NoError(t, db.Create(&board).Error)
+	var lists [3]models.List
+	for i := range lists {
+		lists[i] = models.List{Name: "List", BoardID: board.ID}
+		assert.NoError(t, NewListRepository(db).Create(&lists[i]))
+	}
+	long := createCards(t, cardRepo, lists[0].ID, "a", "b")
+	tied := createCards(t, cardRepo, lists[1].ID, "a", "b")
+	createCards(t, cardRepo, lists[2].ID, "a", "b")
+	assert.NoError(t, db.Model(&long[1]).UpdateColumn("position", "iiiiiiiiiiiii").Error)
+	assert.NoError(t, db.Model(&models.Card{}).Where("list_id = ?", lists[1].ID).UpdateColumn("position", "i").Error)
+
+	listIDs, err := repo.FindListsToRebalance(12, 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{lists[0].ID, lists[1].ID}, listIDs)
+	listIDs, err = repo.FindListsToRebalance(12, lists[0].ID, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{lists[1].ID}, listIDs, "paged by ID")
+	boardIDs, err := repo.FindBoardsToRebalance(12, 0, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, boardIDs)
+
+	ids, ranks, err := repo.RebalanceCards(lists[1].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{tied[0].ID, tied[1].ID}, ids, "ties keep the order of their IDs")
+	assert.Equal(t, []string{"c", "o"}, ranks)
+	_, _, err = repo.RebalanceCards(lists[0].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, cardTitles(t, cardRepo, lists[0].ID))
+	listIDs, err = repo.FindListsToRebalance(12, 0, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, listIDs)
+}
@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestRecoveryCodeRepository_ReplaceConsumeAndCount(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRecoveryCodeRepository(db)
+
+	assert.NoError(t, repo.ReplaceForUser(1, []string{"hash-a", "hash-b"}))
+	assert.NoError(t, repo.ReplaceForUser(2, []string{"hash-a"}))
+
+	count, err := repo.CountUnused(1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	assert.NoError(t, repo.Consume(1, "hash-a"))
+	assert.ErrorIs(t, repo.Consume(1, "hash-a"), gorm.ErrRecordNotFound, "codes are single use")
+	assert.ErrorIs(t, repo.Consume(1, "hash-x"), gorm.ErrRecordNotFound)
+
+	count, _ = repo.CountUnused(1)
+	assert.Equal(t, int64(1), count)
+	count, _ = repo.CountUnused(2)
+	assert.Equal(t, int64(1), count, "other users' codes are untouched")
+
+	// Replacing discards old codes, used or not
+	assert.NoError(t, repo.ReplaceForUser(1, []string{"hash-c"}))
+	assert.ErrorIs(t, repo.Consume(1, "hash-b"), gorm.ErrRecordNotFound)
+	count, _ = repo.CountUnused(1)
+	assert.Equal(t, int64(1), count)
+
+	assert.NoError(t, repo.ReplaceForUser(1, nil))
+	count, _ = repo.CountUnused(1)
+	assert.Equal(t, int64(0), count)
+}
@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zayyadi/trello/models"
+	"gorm.io/gorm"
+)
+
+func createRefreshToken(t *testing.T, repo RefreshTokenRepositoryInterface, userID uint, sessionID, hash string, expiresAt time.Time) *models.RefreshToken {
+	token := &models.RefreshToken{UserID: userID, SessionID: sessionID, TokenHash: hash, ExpiresAt: expiresAt}
+	if err := repo.Create(token); err != nil {
+		t.Fatalf("Failed to create refresh token: %v", err)
+	}
+	return token
+}
+
+func TestRefreshTokenRepository_FindByHash(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRefreshTokenRepository(db)
+	created := createRefreshToken(t, repo, 1, "session-a", "hash-a", time.Now().Add(time.Hour))
+
+	found, err := repo.FindByHash("hash-a")
+	assert.NoError(t, err)
+	assert.Equal(t, created.ID, found.ID)
+	assert.Equal(t, "session-a", found.SessionID)
+
+	_, err = repo.FindByHash("missing")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestRefreshTokenRepository_RevokeAndSessionActivity(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRefreshTokenRepository(db)
+	first := createRefreshToken(t, repo, 1, "session-a", "hash-a1", time.Now().Add(time.Hour))
+
+	active, err := repo.IsSessionActive("session-a")
+	assert.NoError(t, err)
+	assert.True(t, active)
+
+	// Rotation: old token revoked, new token keeps the session alive
+	assert.NoError(t, repo.Revoke(first.ID))
+	assert.ErrorIs(t, repo.Revoke(first.ID), gorm.ErrRecordNotFound, "already revoked")
+	createRefreshToken(t, repo, 1, "session-a", "hash-a2", time.Now().Add(time.Hour))
+	active, _ = repo.IsSessionActive("session-a")
+	assert.True(t, active)
+
+	revoked, err := repo.FindByHash("hash-a1")
+	assert.NoError(t, err)
+	assert.NotNil(t, revoked.RevokedAt)
+
+	assert.NoError(t, repo.RevokeSession("session-a"))
+	active, _ = repo.IsSessionActive("session-a")
+	assert.False(t, active)
+}
+
+func TestRefreshTokenRepository_ExpiredSessionInactive(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRefreshTokenRepository(db)
+	createRefreshToken(t, repo, 1, "session-old", "hash-old", time.Now().Add(-time.Minute))
+
+	active, err := repo.IsSessionActive("session-old")
+	assert.NoError(t, err)
+	assert.False(t, active)
+}
+
+func TestRefreshTokenRepository_RevokeAllForUser(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRefreshTokenRepository(db)
+	createRefreshToken(t, repo, 1, "session-a", "hash-a", time.Now().Add(time.Hour))
+	createRefreshToken(t, repo, 1, "session-b", "hash-b", time.Now().Add(time.Hour))
+	createRefreshToken(t, repo, 2, "session-c", "hash-c", time.Now().Add(time.Hour))
+
+	assert.NoError(t, repo.RevokeAllForUser(1))
+
+	activeA, _ := repo.IsSessionActive("session-a")
+	activeB, _ := repo.IsSessionActive("session-b")
+	activeC, _ := repo.IsSessionActive("session-c")
+	assert.False(t, activeA)
+	assert.False(t, activeB)
+	assert.True(t, activeC, "other users' sessions must be untouched")
+}
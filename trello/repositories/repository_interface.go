@@ -1,6 +1,8 @@
 package repositories
 
 import (
+	"time"
+
 	"github.com/zayyadi/trello/models"
 	"gorm.io/gorm"
 )
@@ -9,8 +11,10 @@ import (
 type UserRepositoryInterface interface {
 	Create(user *models.User) error
 	FindByEmail(email string) (*models.User, error)
+	FindByUsername(username string) (*models.User, error)
 	FindByID(id uint) (*models.User, error)
 	FindAll() ([]models.User, error)
+	Update(user *models.User) error
 }
 
 // BoardRepositoryInterface defines the contract for board repository operations.
@@ -21,6 +25,12 @@ type BoardRepositoryInterface interface {
 	Update(board *models.Board) error
 	Delete(id uint) error
 	IsOwner(boardID uint, userID uint) (bool, error)
+	// FindByWorkspace lists the workspace's boards userID can see: workspace-visible
+	// boards plus private ones they own or belong to.
+	FindByWorkspace(workspaceID uint, userID uint) ([]models.Board, error)
+	// TransferOwnership makes newOwnerID the board's owner and an admin member.
+	// It returns gorm.ErrRecordNotFound if the board doesn't exist.
+	TransferOwnership(boardID uint, newOwnerID uint) error
 }
 
 // BoardMemberRepositoryInterface defines the contract for board member repository operations.
@@ -30,6 +40,11 @@ type BoardMemberRepositoryInterface interface {
 	IsMember(boardID uint, userID uint) (bool, error)
 	FindMembersByBoardID(boardID uint) ([]models.BoardMember, error)
 	FindByBoardIDAndUserID(boardID uint, userID uint) (*models.BoardMember, error)
+	GetRole(boardID uint, userID uint) (models.BoardRole, error)
+	UpdateRole(boardID uint, userID uint, role models.BoardRole) error
+	// GetWorkspaceRole returns the role userID holds in the workspace boardID belongs to.
+	// gorm.ErrRecordNotFound is returned if the board has no workspace or the user is not in it.
+	GetWorkspaceRole(boardID uint, userID uint) (models.WorkspaceRole, error)
 }
 
 // ListRepositoryInterface defines the contract for list repository operations.
@@ -39,10 +54,13 @@ type ListRepositoryInterface interface {
 	FindByBoardID(boardID uint) ([]models.List, error)
 	Update(list *models.List) error
 	Delete(id uint) error
-	GetMaxPosition(boardID uint) (uint, error)
+	// Move puts the list at the 1-based position among the board's other active
+	// lists, or last if position is past the end. Only the list's rank changes.
+	Move(listID uint, position uint) error
 	// GetDB() *gorm.DB // For transaction handling - REMOVED
 	PerformTransaction(fn func(tx *gorm.DB) error) error // ADDED
 	GetBoardIDByListID(listID uint) (uint, error)
+	Reorder(boardID uint, listIDs []uint) error
 }
 
 // CardRepositoryInterface defines the contract for card repository operations.
@@ -54,9 +72,11 @@ type CardRepositoryInterface interface {
 	Delete(id uint) error
 	GetListIDByCardID(cardID uint) (uint, error)
 	PerformTransaction(fn func(tx *gorm.DB) error) error
-	MoveCard(cardID, oldListID, newListID uint, newPosition uint) error
-	GetMaxPosition(listID uint) (uint, error)
-	ShiftPositions(listID uint, startPosition uint, shiftAmount int, excludedCardID *uint) error
+	// MoveCard puts the card in the list at the 1-based position among its other
+	// active cards, or last if position is past the end. Only the card's row changes.
+	MoveCard(cardID, listID uint, position uint) error
+	MoveCardToBoard(move CardBoardMove) error
+	Reorder(listID uint, cardIDs []uint) error
 	AddCollaborator(cardID uint, userID uint) error
 	RemoveCollaborator(cardID uint, userID uint) error
 	GetCollaboratorsByCardID(cardID uint) ([]models.User, error)
@@ -70,3 +90,233 @@ type CommentRepositoryInterface interface {
 	FindByCardID(cardID uint) ([]models.Comment, error)
 	FindByID(id uint) (*models.Comment, error)
 }
+
+// RefreshTokenRepositoryInterface defines the contract for refresh token repository operations.
+type RefreshTokenRepositoryInterface interface {
+	Create(token *models.RefreshToken) error
+	FindByHash(tokenHash string) (*models.RefreshToken, error)
+	Revoke(id uint) error
+	RevokeSession(sessionID string) error
+	RevokeAllForUser(userID uint) error
+	IsSessionActive(sessionID string) (bool, error)
+}
+
+// UserTokenRepositoryInterface defines the contract for emailed, single-use user token operations.
+type UserTokenRepositoryInterface interface {
+	Create(token *models.UserToken) error
+	FindByHash(purpose models.TokenPurpose, tokenHash string) (*models.UserToken, error)
+	MarkUsed(id uint) error
+	IncrementAttempts(id uint) error
+	DeleteForUser(userID uint, purpose models.TokenPurpose) error
+}
+
+// UserIdentityRepositoryInterface defines the contract for external (OIDC) identity link operations.
+type UserIdentityRepositoryInterface interface {
+	Create(identity *models.UserIdentity) error
+	FindByIssuerAndSubject(issuer, subject string) (*models.UserIdentity, error)
+	Update(identity *models.UserIdentity) error
+}
+
+// PersonalAccessTokenRepositoryInterface defines the contract for personal access token operations.
+type PersonalAccessTokenRepositoryInterface interface {
+	Create(token *models.PersonalAccessToken) error
+	FindByHash(tokenHash string) (*models.PersonalAccessToken, error)
+	FindByUserID(userID uint) ([]models.PersonalAccessToken, error)
+	Revoke(id, userID uint) error
+	RevokeAllForUser(userID uint) error
+	UpdateLastUsed(id uint, usedAt time.Time) error
+}
+
+// RecoveryCodeRepositoryInterface defines the contract for two-factor recovery code operations.
+type RecoveryCodeRepositoryInterface interface {
+	ReplaceForUser(userID uint, codeHashes []string) error
+	Consume(userID uint, codeHash string) error
+	CountUnused(userID uint) (int64, error)
+}
+
+// LoginThrottleRepositoryInterface defines the contract for storing failed login counters.
+// It has a database implementation, shared between server instances, and an in-memory one.
+type LoginThrottleRepositoryInterface interface {
+	Find(scope models.LoginThrottleScope, key string) (*models.LoginThrottle, error)
+	// RecordFailure adds a failure and returns the updated counter. The count starts
+	// over if the previous failure was longer than window ago.
+	RecordFailure(scope models.LoginThrottleScope, key string, at time.Time, window time.Duration) (*models.LoginThrottle, error)
+	Lock(scope models.LoginThrottleScope, key string, until time.Time) error
+	Delete(scope models.LoginThrottleScope, key string) error
+	FindLocked(now time.Time) ([]models.LoginThrottle, error)
+}
+
+// AuditEventRepositoryInterface defines the contract for the append-only audit log.
+type AuditEventRepositoryInterface interface {
+	Create(event *models.AuditEvent) error
+	// List returns the most recent events first. An empty eventType matches all events.
+	List(eventType models.AuditEventType, userID *uint, limit int) ([]models.AuditEvent, error)
+}
+
+// AccountRepositoryInterface defines the contract for operations spanning all of a user's data,
+// used for data exports and account deletion.
+type AccountRepositoryInterface interface {
+	FindCardsForUser(userID uint) ([]models.Card, error)
+	FindCommentsByUser(userID uint) ([]models.Comment, error)
+	FindCollaborations(userID uint) ([]models.CardCollaborator, error)
+	DeleteAccount(plan *models.AccountDeletionPlan) error
+}
+
+// WorkspaceRepositoryInterface defines the contract for workspace repository operations.
+type WorkspaceRepositoryInterface interface {
+	Create(workspace *models.Workspace) error // Also creates the workspace's initial Members
+	FindByID(id uint) (*models.Workspace, error)
+	FindByMember(userID uint) ([]models.Workspace, error)
+	Update(workspace *models.Workspace) error
+	// Delete removes the workspace and its memberships. Its boards are kept,
+	// moved out of the workspace and made private.
+	Delete(id uint) error
+}
+
+// WorkspaceMemberRepositoryInterface defines the contract for workspace member repository operations.
+type WorkspaceMemberRepositoryInterface interface {
+	AddMember(member *models.WorkspaceMember) error
+	RemoveMember(workspaceID uint, userID uint) error
+	FindMembers(workspaceID uint) ([]models.WorkspaceMember, error)
+	FindMember(workspaceID uint, userID uint) (*models.WorkspaceMember, error)
+	GetRole(workspaceID uint, userID uint) (models.WorkspaceRole, error)
+	UpdateRole(workspaceID uint, userID uint, role models.WorkspaceRole) error
+	CountAdmins(workspaceID uint) (int64, error)
+}
+
+// BoardInvitationRepositoryInterface defines the contract for board invitation repository operations.
+type BoardInvitationRepositoryInterface interface {
+	Create(invitation *models.BoardInvitation) error
+	FindByID(id uint) (*models.BoardInvitation, error)
+	FindByHash(tokenHash string) (*models.BoardInvitation, error)
+	FindPendingByBoard(boardID uint) ([]models.BoardInvitation, error)
+	FindPendingByEmail(email string) ([]models.BoardInvitation, error)
+	RevokePending(boardID uint, email string) error
+	Respond(id uint, status models.InvitationStatus, userID *uint) error
+}
+
+// BoardInviteLinkRepositoryInterface defines the contract for shareable board invite link operations.
+type BoardInviteLinkRepositoryInterface interface {
+	Create(link *models.BoardInviteLink) error
+	FindByHash(tokenHash string) (*models.BoardInviteLink, error)
+	FindByBoard(boardID uint) ([]models.BoardInviteLink, error)
+	Revoke(id uint, boardID uint) error
+	Use(id uint) error
+}
+
+// BoardShareLinkRepositoryInterface defines the contract for read-only board share link operations.
+type BoardShareLinkRepositoryInterface interface {
+	Create(link *models.BoardShareLink) error
+	FindByHash(tokenHash string) (*models.BoardShareLink, error)
+	FindByBoard(boardID uint) ([]models.BoardShareLink, error)
+	Revoke(id uint, boardID uint) error
+}
+
+// BoardPreferenceRepositoryInterface defines the contract for each user's own board settings.
+type BoardPreferenceRepositoryInterface interface {
+	FindByUser(userID uint) ([]models.BoardPreference, error)
+	SetStarred(userID uint, boardID uint, starred bool) error
+	SetLastViewed(userID uint, boardID uint, at time.Time) error
+	// SetOrder gives boardIDs positions 1..n in the user's board list and clears the positions of the rest.
+	SetOrder(userID uint, boardIDs []uint) error
+}
+
+// LabelRepositoryInterface defines the contract for board label operations.
+type LabelRepositoryInterface interface {
+	Create(label *models.Label) error
+	FindByID(id uint) (*models.Label, error)
+	FindByBoardID(boardID uint) ([]models.Label, error)
+	Update(label *models.Label) error
+	// Delete removes the label from its board and from every card that has it.
+	Delete(id uint) error
+	AddToCard(cardID uint, labelID uint) error
+	RemoveFromCard(cardID uint, labelID uint) error
+	HasLabel(cardID uint, labelID uint) (bool, error)
+}
+
+// ChecklistRepositoryInterface defines the contract for card checklist and checklist item operations.
+type ChecklistRepositoryInterface interface {
+	Create(checklist *models.Checklist) error
+	FindByID(id uint) (*models.Checklist, error)
+	FindByCardID(cardID uint) ([]models.Checklist, error)
+	Update(checklist *models.Checklist) error
+	Delete(id uint) error
+	// Reorder gives the card's checklists positions 1..n in the order of checklistIDs.
+	Reorder(cardID uint, checklistIDs []uint) error
+
+	CreateItem(item *models.ChecklistItem) error
+	FindItemByID(id uint) (*models.ChecklistItem, error)
+	UpdateItem(item *models.ChecklistItem) error
+	DeleteItem(id uint) error
+	// ReorderItems gives the checklist's items positions 1..n in the order of itemIDs.
+	ReorderItems(checklistID uint, itemIDs []uint) error
+}
+
+// AttachmentRepositoryInterface defines the contract for card attachment operations.
+// The files themselves are kept in a storage.BlobStore.
+type AttachmentRepositoryInterface interface {
+	Create(attachment *models.Attachment) error
+	FindByID(id uint) (*models.Attachment, error)
+	FindByCardID(cardID uint) ([]models.Attachment, error)
+	// Delete removes the attachment, and takes it off cards that have it as their cover.
+	Delete(id uint) error
+	SetCover(cardID uint, attachmentID *uint) error
+	// IsBlobUsed says whether any attachment keeps its file or thumbnail under key.
+	IsBlobUsed(key string) (bool, error)
+	// FindOrphaned returns up to limit attachments of cards that have been purged.
+	FindOrphaned(limit int) ([]models.Attachment, error)
+}
+
+// ArchiveRepositoryInterface defines the contract for archiving boards, lists and
+// cards, and for the trash of soft-deleted ones.
+type ArchiveRepositoryInterface interface {
+	// SetBoardArchived archives the board at the given time, or unarchives it if at is nil.
+	SetBoardArchived(boardID uint, at *time.Time) error
+	// ArchiveList and ArchiveCard take the item out of its board's or list's order.
+	// It keeps its rank, so unarchiving puts it back at its old place. They return
+	// gorm.ErrRecordNotFound if the item doesn't exist or is already (un)archived.
+	ArchiveList(listID uint, at time.Time) error
+	UnarchiveList(listID uint) error
+	ArchiveCard(cardID uint, at time.Time) error
+	UnarchiveCard(cardID uint) error
+	FindArchivedLists(boardID uint) ([]models.List, error)
+	FindArchivedCards(boardID uint) ([]models.Card, error)
+
+	FindDeletedList(id uint) (*models.List, error)
+	// FindDeletedCard also fills in the card's List, whether or not it is deleted too.
+	FindDeletedCard(id uint) (*models.Card, error)
+	// FindDeletedLists and FindDeletedCards make up the board's trash. Cards in a
+	// deleted list only show up if they were deleted themselves.
+	FindDeletedLists(boardID uint) ([]models.List, error)
+	FindDeletedCards(boardID uint) ([]models.Card, error)
+	// RestoreList and RestoreCard undelete the item. It kept its rank, so it is back at its old place.
+	RestoreList(listID uint) error
+	RestoreCard(cardID uint) error
+	FindDeletedBoards(ownerID uint) ([]models.Board, error)
+	RestoreBoard(boardID uint, ownerID uint) error
+	// Purge permanently deletes the boards, lists and cards soft-deleted before the
+	// given time, with everything in them. It returns how many of them were purged.
+	Purge(before time.Time) (int64, error)
+}
+
+// RankRepositoryInterface defines the contract for keeping the ranks that order
+// lists and cards short. See package rank.
+type RankRepositoryInterface interface {
+	// FindBoardsToRebalance and FindListsToRebalance return, in order, up to limit
+	// boards or lists with an ID above afterID whose lists or cards have a rank
+	// longer than maxLength, or share one.
+	FindBoardsToRebalance(maxLength int, afterID uint, limit int) ([]uint, error)
+	FindListsToRebalance(maxLength int, afterID uint, limit int) ([]uint, error)
+	// RebalanceLists and RebalanceCards give the board's lists or the list's cards
+	// evenly spread ranks in their current order. They return the IDs in order
+	// and the new ranks.
+	RebalanceLists(boardID uint) ([]uint, []string, error)
+	RebalanceCards(listID uint) ([]uint, []string, error)
+}
+
+// BoardCopyRepositoryInterface defines the contract for copying boards, e.g. from templates.
+type BoardCopyRepositoryInterface interface {
+	// Copy creates board and fills it with the source board's lists and, depending
+	// on opts, their cards, collaborators and comments.
+	Copy(sourceID uint, board *models.Board, opts BoardCopyOptions) error
+}
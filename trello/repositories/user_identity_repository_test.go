@@ -0,0 +1,41 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zayyadi/trello/models"
+	"gorm.io/gorm"
+)
+
+func TestUserIdentityRepository_CreateAndFind(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewUserIdentityRepository(db)
+
+	identity := &models.UserIdentity{UserID: 1, Issuer: "https://idp.example.com", Subject: "sub-1", Email: "jane@example.com"}
+	assert.NoError(t, repo.Create(identity))
+
+	found, err := repo.FindByIssuerAndSubject("https://idp.example.com", "sub-1")
+	assert.NoError(t, err)
+	assert.Equal(t, identity.ID, found.ID)
+	assert.Equal(t, uint(1), found.UserID)
+
+	// The same subject at another issuer is a different identity
+	_, err = repo.FindByIssuerAndSubject("https://other-idp.example.com", "sub-1")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	found.Email = "jane@new.example.com"
+	assert.NoError(t, repo.Update(found))
+	updated, err := repo.FindByIssuerAndSubject("https://idp.example.com", "sub-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "jane@new.example.com", updated.Email)
+}
+
+func TestUserIdentityRepository_IssuerSubjectUnique(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewUserIdentityRepository(db)
+
+	assert.NoError(t, repo.Create(&models.UserIdentity{UserID: 1, Issuer: "https://idp.example.com", Subject: "sub-1"}))
+	err := repo.Create(&models.UserIdentity{UserID: 2, Issuer: "https://idp.example.com", Subject: "sub-1"})
+	assert.Error(t, err, "an external account can only be linked to one user")
+}
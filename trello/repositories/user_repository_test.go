@@ -29,7 +29,7 @@ func setupTestDB(t *testing.T) *gorm.DB {
 	// For UserRepository, only User model is directly interacted with,
 	// but User has relations to Board, BoardMember, Card.
 	// For simplicity here, only migrating User. If FK issues arise, migrate others.
-	err = db.AutoMigrate(&models.User{}, &models.Board{}, &models.List{}, &models.Card{}, &models.BoardMember{})
+	err = db.AutoMigrate(&models.User{}, &models.Board{}, &models.List{}, &models.Card{}, &models.BoardMember{}, &models.RefreshToken{}, &models.UserToken{}, &models.UserIdentity{}, &models.PersonalAccessToken{}, &models.RecoveryCode{}, &models.LoginThrottle{}, &models.AuditEvent{}, &models.Comment{}, &models.CardCollaborator{}, &models.Workspace{}, &models.WorkspaceMember{}, &models.BoardInvitation{}, &models.BoardInviteLink{}, &models.BoardShareLink{}, &models.BoardPreference{}, &models.Label{}, &models.CardLabel{}, &models.Checklist{}, &models.ChecklistItem{}, &models.Attachment{})
 	if err != nil {
 		t.Fatalf("Failed to migrate database: %v", err)
 	}
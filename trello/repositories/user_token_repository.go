@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/zayyadi/trello/models"
+	"gorm.io/gorm"
+)
+
+type UserTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewUserTokenRepository(db *gorm.DB) UserTokenRepositoryInterface {
+	return &UserTokenRepository{db: db}
+}
+
+func (r *UserTokenRepository) Create(token *models.UserToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *UserTokenRepository) FindByHash(purpose models.TokenPurpose, tokenHash string) (*models.UserToken, error) {
+	var token models.UserToken
+	if err := r.db.Where("purpose = ? AND token_hash = ?", purpose, tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed consumes the token. It returns gorm.ErrRecordNotFound if the token
+// was already used, so two concurrent requests cannot both redeem it.
+func (r *UserTokenRepository) MarkUsed(id uint) error {
+	result := r.db.Model(&models.UserToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// IncrementAttempts records a failed attempt to redeem the token.
+func (r *UserTokenRepository) IncrementAttempts(id uint) error {
+	return r.db.Model(&models.UserToken{}).Where("id = ?", id).
+		Update("attempts", gorm.Expr("attempts + 1")).Error
+}
+
+// DeleteForUser removes all of a user's tokens for the given purpose,
+// e.g. to invalidate older reset links when a new one is requested.
+func (r *UserTokenRepository) DeleteForUser(userID uint, purpose models.TokenPurpose) error {
+	return r.db.Where("user_id = ? AND purpose = ?", userID, purpose).Delete(&models.UserToken{}).Error
+}
@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zayyadi/trello/models"
+	"gorm.io/gorm"
+)
+
+func TestUserTokenRepository_FindByHash_ScopedToPurpose(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewUserTokenRepository(db)
+	token := &models.UserToken{UserID: 1, Purpose: models.TokenPurposePasswordReset, TokenHash: "hash-1", ExpiresAt: time.Now().Add(time.Hour)}
+	assert.NoError(t, repo.Create(token))
+
+	found, err := repo.FindByHash(models.TokenPurposePasswordReset, "hash-1")
+	assert.NoError(t, err)
+	assert.Equal(t, token.ID, found.ID)
+
+	_, err = repo.FindByHash(models.TokenPurpose("other"), "hash-1")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestUserTokenRepository_MarkUsed_OnlyOnce(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewUserTokenRepository(db)
+	token := &models.UserToken{UserID: 1, Purpose: models.TokenPurposePasswordReset, TokenHash: "hash-1", ExpiresAt: time.Now().Add(time.Hour)}
+	assert.NoError(t, repo.Create(token))
+
+	assert.NoError(t, repo.MarkUsed(token.ID))
+	assert.ErrorIs(t, repo.MarkUsed(token.ID), gorm.ErrRecordNotFound)
+
+	found, err := repo.FindByHash(models.TokenPurposePasswordReset, "hash-1")
+	assert.NoError(t, err)
+	assert.NotNil(t, found.UsedAt)
+	assert.False(t, found.IsUsable(time.Now()))
+}
+
+func TestUserTokenRepository_DeleteForUser(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewUserTokenRepository(db)
+	assert.NoError(t, repo.Create(&models.UserToken{UserID: 1, Purpose: models.TokenPurposePasswordReset, TokenHash: "hash-1", ExpiresAt: time.Now().Add(time.Hour)}))
+	assert.NoError(t, repo.Create(&models.UserToken{UserID: 2, Purpose: models.TokenPurposePasswordReset, TokenHash: "hash-2", ExpiresAt: time.Now().Add(time.Hour)}))
+
+	assert.NoError(t, repo.DeleteForUser(1, models.TokenPurposePasswordReset))
+
+	_, err := repo.FindByHash(models.TokenPurposePasswordReset, "hash-1")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	_, err = repo.FindByHash(models.TokenPurposePasswordReset, "hash-2")
+	assert.NoError(t, err)
+}
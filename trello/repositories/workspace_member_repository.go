@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"github.com/zayyadi/trello/models"
+	"gorm.io/gorm"
+)
+
+type WorkspaceMemberRepository struct {
+	db *gorm.DB
+}
+
+func NewWorkspaceMemberRepository(db *gorm.DB) WorkspaceMemberRepositoryInterface {
+	return &WorkspaceMemberRepository{db: db}
+}
+
+func (r *WorkspaceMemberRepository) AddMember(member *models.WorkspaceMember) error {
+	return r.db.Create(member).Error
+}
+
+func (r *WorkspaceMemberRepository) RemoveMember(workspaceID, userID uint) error {
+	result := r.db.Where("workspace_id = ? AND user_id = ?", workspaceID, userID).Delete(&models.WorkspaceMember{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *WorkspaceMemberRepository) FindMembers(workspaceID uint) ([]models.WorkspaceMember, error) {
+	var members []models.WorkspaceMember
+	err := r.db.Preload("User").Where("workspace_id = ?", workspaceID).Order("created_at").Find(&members).Error
+	return members, err
+}
+
+func (r *WorkspaceMemberRepository) FindMember(workspaceID, userID uint) (*models.WorkspaceMember, error) {
+	var member models.WorkspaceMember
+	err := r.db.Preload("User").Where("workspace_id = ? AND user_id = ?", workspaceID, userID).First(&member).Error
+	return &member, err
+}
+
+// GetRole returns the role userID holds in workspaceID.
+// gorm.ErrRecordNotFound is returned if the user is not a member.
+func (r *WorkspaceMemberRepository) GetRole(workspaceID, userID uint) (models.WorkspaceRole, error) {
+	var member models.WorkspaceMember
+	err := r.db.Select("role").Where("workspace_id = ? AND user_id = ?", workspaceID, userID).First(&member).Error
+	if err != nil {
+		return "", err
+	}
+	return member.Role, nil
+}
+
+func (r *WorkspaceMemberRepository) UpdateRole(workspaceID, userID uint, role models.WorkspaceRole) error {
+	result := r.db.Model(&models.WorkspaceMember{}).Where("workspace_id = ? AND user_id = ?", workspaceID, userID).Update("role", role)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *WorkspaceMemberRepository) CountAdmins(workspaceID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.WorkspaceMember{}).Where("workspace_id = ? AND role = ?", workspaceID, models.WorkspaceRoleAdmin).Count(&count).Error
+	return count, err
+}
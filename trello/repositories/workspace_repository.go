@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"github.com/zayyadi/trello/models"
+	"gorm.io/gorm"
+)
+
+type WorkspaceRepository struct {
+	db *gorm.DB
+}
+
+func NewWorkspaceRepository(db *gorm.DB) WorkspaceRepositoryInterface {
+	return &WorkspaceRepository{db: db}
+}
+
+func (r *WorkspaceRepository) Create(workspace *models.Workspace) error {
+	return r.db.Create(workspace).Error
+}
+
+func (r *WorkspaceRepository) FindByID(id uint) (*models.Workspace, error) {
+	var workspace models.Workspace
+	err := r.db.Preload("Members.User").First(&workspace, id).Error
+	return &workspace, err
+}
+
+func (r *WorkspaceRepository) FindByMember(userID uint) ([]models.Workspace, error) {
+	var workspaces []models.Workspace
+	err := r.db.Joins("JOIN workspace_members ON workspace_members.workspace_id = workspaces.id").
+		Where("workspace_members.user_id = ?", userID).
+		Order("workspaces.name").Find(&workspaces).Error
+	return workspaces, err
+}
+
+func (r *WorkspaceRepository) Update(workspace *models.Workspace) error {
+	return r.db.Omit("Members").Save(workspace).Error
+}
+
+func (r *WorkspaceRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Boards outlive their workspace, but only their own members keep access.
+		// Public boards stay public.
+		if err := tx.Model(&models.Board{}).Where("workspace_id = ? AND visibility = ?", id, models.BoardVisibilityWorkspace).
+			Update("visibility", models.BoardVisibilityPrivate).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Board{}).Where("workspace_id = ?", id).Update("workspace_id", nil).Error; err != nil {
+			return err
+		}
+		// A soft delete doesn't trigger the cascade, so drop the memberships explicitly
+		if err := tx.Where("workspace_id = ?", id).Delete(&models.WorkspaceMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Workspace{}, id).Error
+	})
+}
@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/zayyadi/trello/models"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestWorkspaceRepository_MembersAndBoards(t *testing.T) {
+	db := setupTestDB(t)
+	workspaceRepo := NewWorkspaceRepository(db)
+	memberRepo := NewWorkspaceMemberRepository(db)
+	boardRepo := NewBoardRepository(db)
+
+	alice := models.User{Username: "alice", Email: "alice@example.com", Password: "x"}
+	bob := models.User{Username: "bob", Email: "bob@example.com", Password: "x"}
+	assert.NoError(t, db.Create(&alice).Error)
+	assert.NoError(t, db.Create(&bob).Error)
+
+	workspace := models.Workspace{Name: "Team", Members: []models.WorkspaceMember{
+		{UserID: alice.ID, Role: models.WorkspaceRoleAdmin},
+		{UserID: bob.ID, Role: models.WorkspaceRoleMember},
+	}}
+	assert.NoError(t, workspaceRepo.Create(&workspace))
+
+	found, err := workspaceRepo.FindByID(workspace.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, found.Members, 2) {
+		assert.Equal(t, "alice", found.Members[0].User.Username)
+	}
+	mine, err := workspaceRepo.FindByMember(bob.ID)
+	assert.NoError(t, err)
+	assert.Len(t, mine, 1)
+	admins, err := memberRepo.CountAdmins(workspace.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), admins)
+
+	shared := models.Board{Name: "Shared", OwnerID: alice.ID, WorkspaceID: &workspace.ID, Visibility: models.BoardVisibilityWorkspace}
+	private := models.Board{Name: "Private", OwnerID: alice.ID, WorkspaceID: &workspace.ID, Visibility: models.BoardVisibilityPrivate}
+	personal := models.Board{Name: "Personal", OwnerID: alice.ID, Visibility: models.BoardVisibilityPrivate}
+	for _, b := range []*models.Board{&shared, &private, &personal} {
+		assert.NoError(t, boardRepo.Create(b))
+	}
+
+	// Bob sees the shared board through the workspace, and nothing else
+	boards, err := boardRepo.FindByOwnerOrMember(bob.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, boards, 1) {
+		assert.Equal(t, shared.ID, boards[0].ID)
+	}
+	boards, err = boardRepo.FindByWorkspace(workspace.ID, bob.ID)
+	assert.NoError(t, err)
+	assert.Len(t, boards, 1)
+	boards, err = boardRepo.FindByWorkspace(workspace.ID, alice.ID)
+	assert.NoError(t, err)
+	assert.Len(t, boards, 2)
+
+	role, err := NewBoardMemberRepository(db).GetWorkspaceRole(shared.ID, bob.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.WorkspaceRoleMember, role)
+	_, err = NewBoardMemberRepository(db).GetWorkspaceRole(personal.ID, bob.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	assert.NoError(t, memberRepo.RemoveMember(workspace.ID, bob.ID))
+	assert.ErrorIs(t, memberRepo.RemoveMember(workspace.ID, bob.ID), gorm.ErrRecordNotFound)
+	boards, err = boardRepo.FindByOwnerOrMember(bob.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, boards)
+}
+
+func TestWorkspaceRepository_DeleteDetachesBoards(t *testing.T) {
+	db := setupTestDB(t)
+	workspaceRepo := NewWorkspaceRepository(db)
+
+	alice := models.User{Username: "alice", Email: "alice@example.com", Password: "x"}
+	assert.NoError(t, db.Create(&alice).Error)
+	workspace := models.Workspace{Name: "Team", Members: []models.WorkspaceMember{{UserID: alice.ID, Role: models.WorkspaceRoleAdmin}}}
+	assert.NoError(t, workspaceRepo.Create(&workspace))
+	board := models.Board{Name: "Shared", OwnerID: alice.ID, WorkspaceID: &workspace.ID, Visibility: models.BoardVisibilityWorkspace}
+	assert.NoError(t, db.Create(&board).Error)
+
+	assert.NoError(t, workspaceRepo.Delete(workspace.ID))
+
+	_, err := workspaceRepo.FindByID(workspace.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	var count int64
+	db.Model(&models.WorkspaceMember{}).Where("workspace_id = ?", workspace.ID).Count(&count)
+	assert.Zero(t, count)
+
+	var kept models.Board
+	assert.NoError(t, db.First(&kept, board.ID).Error)
+	assert.Nil(t, kept.WorkspaceID)
+	assert.Equal(t, models.BoardVisibilityPrivate, kept.Visibility)
+}
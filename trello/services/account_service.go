@@ -0,0 +1,204 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/repositories"
+	"github.com/zayyadi/trello/utils"
+	"gorm.io/gorm"
+)
+
+// AccountData is everything we hold about a user, for a personal data export.
+type AccountData struct {
+	User           *models.User
+	Boards         []models.Board // Boards the user owns or is a member of, with members
+	Cards          []models.Card  // Cards the user is assigned to, supervises or collaborates on
+	Comments       []models.Comment
+	Collaborations []models.CardCollaborator
+}
+
+// AccountDeletion says what to do with the boards a user owns when their account is deleted.
+type AccountDeletion struct {
+	CurrentPassword string
+	// DeleteOwnedBoards deletes every owned board instead of handing it over.
+	DeleteOwnedBoards bool
+	// TransferTo picks the new owner for some boards (board ID to user ID). The new owner
+	// must already be a member. Other owned boards go to their longest-standing admin,
+	// then member, then viewer; boards nobody else can see are deleted.
+	TransferTo map[uint]uint
+}
+
+// AccountService handles data-subject requests: exporting and deleting a user's account.
+type AccountService struct {
+	userRepo    repositories.UserRepositoryInterface
+	boardRepo   repositories.BoardRepositoryInterface
+	accountRepo repositories.AccountRepositoryInterface
+	audit       *AuditService
+}
+
+func NewAccountService(
+	userRepo repositories.UserRepositoryInterface,
+	boardRepo repositories.BoardRepositoryInterface,
+	accountRepo repositories.AccountRepositoryInterface,
+	audit *AuditService,
+) *AccountService {
+	return &AccountService{
+		userRepo:    userRepo,
+		boardRepo:   boardRepo,
+		accountRepo: accountRepo,
+		audit:       audit,
+	}
+}
+
+// Export gathers the user's profile, boards, cards, comments and collaborations.
+func (s *AccountService) Export(userID uint) (*AccountData, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &AccountData{User: user}
+	if data.Boards, err = s.boardRepo.FindByOwnerOrMember(userID); err != nil {
+		return nil, err
+	}
+	if data.Cards, err = s.accountRepo.FindCardsForUser(userID); err != nil {
+		return nil, err
+	}
+	if data.Comments, err = s.accountRepo.FindCommentsByUser(userID); err != nil {
+		return nil, err
+	}
+	if data.Collaborations, err = s.accountRepo.FindCollaborations(userID); err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(&models.AuditEvent{Type: models.AuditEventAccountExported, UserID: &user.ID, ActorID: &user.ID})
+	return data, nil
+}
+
+// DeleteAccount deletes the user's account after checking their password. Owned boards
+// are handed over or deleted, the user is removed from every board and card, and their
+// comments are kept but attributed to an anonymous "Deleted user".
+func (s *AccountService) DeleteAccount(userID uint, req AccountDeletion) error {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return err
+	}
+	if !utils.CheckPasswordHash(req.CurrentPassword, user.Password) {
+		return ErrIncorrectPassword
+	}
+
+	plan, err := s.planBoards(user.ID, req)
+	if err != nil {
+		return err
+	}
+	plan.Anonymized = anonymizedUser(user)
+	if err := s.accountRepo.DeleteAccount(plan); err != nil {
+		return err
+	}
+
+	s.audit.Record(&models.AuditEvent{
+		Type:    models.AuditEventAccountDeleted,
+		UserID:  &user.ID,
+		ActorID: &user.ID,
+		Detail:  fmt.Sprintf("%d owned boards transferred, %d deleted", len(plan.TransferBoards), len(plan.DeleteBoards)),
+	})
+	return nil
+}
+
+// planBoards decides what happens to each board the user owns.
+func (s *AccountService) planBoards(userID uint, req AccountDeletion) (*models.AccountDeletionPlan, error) {
+	boards, err := s.boardRepo.FindByOwnerOrMember(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &models.AccountDeletionPlan{UserID: userID, TransferBoards: map[uint]uint{}}
+	owned := map[uint]bool{}
+	for i := range boards {
+		board := &boards[i]
+		if board.OwnerID != userID {
+			continue
+		}
+		owned[board.ID] = true
+
+		if newOwnerID, ok := req.TransferTo[board.ID]; ok {
+			if newOwnerID == userID || !isBoardMember(board, newOwnerID) {
+				return nil, fmt.Errorf("%w: user %d is not a member of board %d", ErrInvalidInput, newOwnerID, board.ID)
+			}
+			plan.TransferBoards[board.ID] = newOwnerID
+			continue
+		}
+		if !req.DeleteOwnedBoards {
+			if successor, ok := pickSuccessor(board, userID); ok {
+				plan.TransferBoards[board.ID] = successor
+				continue
+			}
+		}
+		plan.DeleteBoards = append(plan.DeleteBoards, board.ID)
+	}
+
+	for boardID := range req.TransferTo {
+		if !owned[boardID] {
+			return nil, fmt.Errorf("%w: you do not own board %d", ErrInvalidInput, boardID)
+		}
+	}
+	return plan, nil
+}
+
+func (s *AccountService) findUser(userID uint) (*models.User, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+func isBoardMember(board *models.Board, userID uint) bool {
+	for _, member := range board.Members {
+		if member.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// pickSuccessor chooses who inherits a board: the most privileged role wins,
+// then whoever joined first.
+func pickSuccessor(board *models.Board, ownerID uint) (uint, bool) {
+	var candidates []models.BoardMember
+	for _, member := range board.Members {
+		if member.UserID != ownerID && member.Role.IsValid() {
+			candidates = append(candidates, member)
+		}
+	}
+	if len(candidates) == 0 {
+		return 0, false
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Role != b.Role {
+			return a.Role.AtLeast(b.Role)
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+	return candidates[0].UserID, true
+}
+
+// anonymizedUser returns the user with every piece of personal data replaced. The
+// placeholder username and email are unique, and free the originals for reuse.
+func anonymizedUser(user *models.User) *models.User {
+	placeholder := fmt.Sprintf("deleted-user-%d", user.ID)
+	return &models.User{
+		Model:       gorm.Model{ID: user.ID, CreatedAt: user.CreatedAt},
+		Username:    placeholder,
+		Email:       placeholder + "@deleted.invalid",
+		Password:    "", // Never matches a bcrypt hash check
+		DisplayName: "Deleted user",
+	}
+}
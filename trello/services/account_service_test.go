@@ -0,0 +1,165 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/repositories"
+	"github.com/zayyadi/trello/utils"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// MockAccountRepository records the deletion plan it is given
+type MockAccountRepository struct {
+	Cards          []models.Card
+	Comments       []models.Comment
+	Collaborations []models.CardCollaborator
+
+	DeletedWith *models.AccountDeletionPlan
+}
+
+func (m *MockAccountRepository) FindCardsForUser(userID uint) ([]models.Card, error) {
+	return m.Cards, nil
+}
+
+func (m *MockAccountRepository) FindCommentsByUser(userID uint) ([]models.Comment, error) {
+	return m.Comments, nil
+}
+
+func (m *MockAccountRepository) FindCollaborations(userID uint) ([]models.CardCollaborator, error) {
+	return m.Collaborations, nil
+}
+
+func (m *MockAccountRepository) DeleteAccount(plan *models.AccountDeletionPlan) error {
+	m.DeletedWith = plan
+	return nil
+}
+
+var _ repositories.AccountRepositoryInterface = (*MockAccountRepository)(nil)
+
+type accountTestEnv struct {
+	service     *AccountService
+	user        *models.User
+	accountRepo *MockAccountRepository
+	auditRepo   *MockAuditEventRepository
+}
+
+func member(boardID, userID uint, role models.BoardRole, joined time.Time) models.BoardMember {
+	return models.BoardMember{BoardID: boardID, UserID: userID, Role: role, CreatedAt: joined}
+}
+
+// newAccountTestEnv sets up user 7 with four boards:
+// 1 owned, shared with an admin and a member; 2 owned, shared with two members;
+// 3 owned and private; 4 owned by someone else.
+func newAccountTestEnv(t *testing.T) *accountTestEnv {
+	hashedPassword, _ := utils.HashPassword("password123")
+	user := &models.User{Model: gorm.Model{ID: 7}, Username: "jane", Email: "jane@example.com", Password: hashedPassword, DisplayName: "Jane"}
+	userRepo := &MockUserRepository{}
+	inMemoryUsers(userRepo, user)
+
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	boards := []models.Board{
+		{Model: gorm.Model{ID: 1}, Name: "Shared", OwnerID: 7, Members: []models.BoardMember{
+			member(1, 7, models.BoardRoleAdmin, t0),
+			member(1, 20, models.BoardRoleMember, t0.Add(time.Hour)),
+			member(1, 21, models.BoardRoleAdmin, t0.Add(2*time.Hour)),
+		}},
+		{Model: gorm.Model{ID: 2}, Name: "Team", OwnerID: 7, Members: []models.BoardMember{
+			member(2, 7, models.BoardRoleAdmin, t0),
+			member(2, 31, models.BoardRoleMember, t0.Add(2*time.Hour)),
+			member(2, 30, models.BoardRoleMember, t0.Add(time.Hour)),
+		}},
+		{Model: gorm.Model{ID: 3}, Name: "Private", OwnerID: 7, Members: []models.BoardMember{
+			member(3, 7, models.BoardRoleAdmin, t0),
+		}},
+		{Model: gorm.Model{ID: 4}, Name: "Someone else's", OwnerID: 40, Members: []models.BoardMember{
+			member(4, 40, models.BoardRoleAdmin, t0),
+			member(4, 7, models.BoardRoleViewer, t0),
+		}},
+	}
+	boardRepo := &MockBoardRepository{
+		FindByOwnerOrMemberFunc: func(userID uint) ([]models.Board, error) { return boards, nil },
+	}
+
+	env := &accountTestEnv{user: user, accountRepo: &MockAccountRepository{}, auditRepo: &MockAuditEventRepository{}}
+	env.service = NewAccountService(userRepo, boardRepo, env.accountRepo, NewAuditService(env.auditRepo))
+	return env
+}
+
+func TestAccountService_Export(t *testing.T) {
+	env := newAccountTestEnv(t)
+	env.accountRepo.Comments = []models.Comment{{Model: gorm.Model{ID: 5}, CardID: 9, UserID: 7, Content: "Looks good"}}
+	env.accountRepo.Collaborations = []models.CardCollaborator{{CardID: 9, UserID: 7}}
+
+	data, err := env.service.Export(7)
+	assert.NoError(t, err)
+	assert.Equal(t, "jane", data.User.Username)
+	assert.Len(t, data.Boards, 4)
+	assert.Len(t, data.Comments, 1)
+	assert.Len(t, data.Collaborations, 1)
+	assert.Equal(t, []models.AuditEventType{models.AuditEventAccountExported}, env.auditRepo.types())
+
+	_, err = env.service.Export(99)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
+
+func TestAccountService_DeleteAccount_TransfersToSuccessors(t *testing.T) {
+	env := newAccountTestEnv(t)
+
+	err := env.service.DeleteAccount(7, AccountDeletion{CurrentPassword: "password123"})
+	assert.NoError(t, err)
+
+	plan := env.accountRepo.DeletedWith
+	if !assert.NotNil(t, plan) {
+		return
+	}
+	assert.Equal(t, uint(7), plan.UserID)
+	assert.Equal(t, map[uint]uint{
+		1: 21, // Admins are preferred over earlier members
+		2: 30, // Among equals, whoever joined first
+	}, plan.TransferBoards)
+	assert.Equal(t, []uint{3}, plan.DeleteBoards, "boards nobody else can see are deleted")
+
+	anon := plan.Anonymized
+	assert.Equal(t, uint(7), anon.ID)
+	assert.Equal(t, "deleted-user-7", anon.Username)
+	assert.Equal(t, "deleted-user-7@deleted.invalid", anon.Email)
+	assert.Equal(t, "Deleted user", anon.DisplayName)
+	assert.Empty(t, anon.Password)
+	assert.Empty(t, anon.AvatarURL)
+	assert.False(t, anon.TwoFactorEnabled)
+
+	assert.Equal(t, []models.AuditEventType{models.AuditEventAccountDeleted}, env.auditRepo.types())
+}
+
+func TestAccountService_DeleteAccount_ExplicitTransferAndDelete(t *testing.T) {
+	env := newAccountTestEnv(t)
+
+	err := env.service.DeleteAccount(7, AccountDeletion{
+		CurrentPassword:   "password123",
+		DeleteOwnedBoards: true,
+		TransferTo:        map[uint]uint{2: 31},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[uint]uint{2: 31}, env.accountRepo.DeletedWith.TransferBoards)
+	assert.ElementsMatch(t, []uint{1, 3}, env.accountRepo.DeletedWith.DeleteBoards)
+}
+
+func TestAccountService_DeleteAccount_Rejected(t *testing.T) {
+	env := newAccountTestEnv(t)
+
+	assert.ErrorIs(t, env.service.DeleteAccount(7, AccountDeletion{CurrentPassword: "wrong"}), ErrIncorrectPassword)
+	assert.ErrorIs(t, env.service.DeleteAccount(7, AccountDeletion{
+		CurrentPassword: "password123", TransferTo: map[uint]uint{1: 99},
+	}), ErrInvalidInput, "the new owner must be a member")
+	assert.ErrorIs(t, env.service.DeleteAccount(7, AccountDeletion{
+		CurrentPassword: "password123", TransferTo: map[uint]uint{4: 40},
+	}), ErrInvalidInput, "only owned boards can be transferred")
+	assert.ErrorIs(t, env.service.DeleteAccount(99, AccountDeletion{CurrentPassword: "password123"}), ErrUserNotFound)
+
+	assert.Nil(t, env.accountRepo.DeletedWith, "nothing was deleted")
+	assert.Empty(t, env.auditRepo.Events)
+}
@@ -0,0 +1,394 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/zayyadi/trello/dto"
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/policy"
+	"github.com/zayyadi/trello/realtime"
+	"github.com/zayyadi/trello/repositories"
+
+	"gorm.io/gorm"
+)
+
+// BoardItems are lists and cards set aside from a board, either archived or in the trash.
+type BoardItems struct {
+	Lists []models.List
+	Cards []models.Card
+}
+
+// ArchiveService archives and unarchives boards, lists and cards, and manages the
+// trash of deleted ones. Archiving hides an item without deleting it; deleted items
+// stay in the trash, where they can be restored, until the retention job purges them.
+type ArchiveService struct {
+	archiveRepo     repositories.ArchiveRepositoryInterface
+	boardRepo       repositories.BoardRepositoryInterface
+	boardMemberRepo repositories.BoardMemberRepositoryInterface
+	listRepo        repositories.ListRepositoryInterface
+	cardRepo        repositories.CardRepositoryInterface
+	hub             realtime.Broadcaster
+	retention       time.Duration    // How long deleted items are kept; zero keeps them forever
+	now             func() time.Time // Replaced in tests
+}
+
+func NewArchiveService(
+	archiveRepo repositories.ArchiveRepositoryInterface,
+	boardRepo repositories.BoardRepositoryInterface,
+	boardMemberRepo repositories.BoardMemberRepositoryInterface,
+	listRepo repositories.ListRepositoryInterface,
+	cardRepo repositories.CardRepositoryInterface,
+	hub realtime.Broadcaster,
+	retention time.Duration,
+) *ArchiveService {
+	return &ArchiveService{
+		archiveRepo:     archiveRepo,
+		boardRepo:       boardRepo,
+		boardMemberRepo: boardMemberRepo,
+		listRepo:        listRepo,
+		cardRepo:        cardRepo,
+		hub:             hub,
+		retention:       retention,
+		now:             time.Now,
+	}
+}
+
+// Retention is how long deleted items stay in the trash. Zero means forever.
+func (s *ArchiveService) Retention() time.Duration {
+	return s.retention
+}
+
+// ArchiveBoard archives the board. It disappears from board lists and becomes read-only.
+// Archiving an archived board does nothing.
+func (s *ArchiveService) ArchiveBoard(boardID, userID uint) (*models.Board, error) {
+	return s.setBoardArchived(boardID, userID, true)
+}
+
+func (s *ArchiveService) UnarchiveBoard(boardID, userID uint) (*models.Board, error) {
+	return s.setBoardArchived(boardID, userID, false)
+}
+
+func (s *ArchiveService) setBoardArchived(boardID, userID uint, archive bool) (*models.Board, error) {
+	board, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, boardID, userID, policy.ArchiveBoard)
+	if err != nil {
+		return nil, err
+	}
+	if (board.ArchivedAt != nil) == archive {
+		return board, nil
+	}
+
+	var archivedAt *time.Time
+	messageType := realtime.MessageTypeBoardUnarchived
+	if archive {
+		now := s.now()
+		archivedAt = &now
+		messageType = realtime.MessageTypeBoardArchived
+	}
+	if err := s.archiveRepo.SetBoardArchived(boardID, archivedAt); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBoardNotFound
+		}
+		return nil, err
+	}
+	updated, err := s.boardRepo.FindByID(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	broadcastMessage(s.hub, boardID, messageType, dto.MapBoardToResponse(updated, true, false), userID)
+	if archive {
+		disconnectAnonymousViewers(s.hub, boardID, realtime.AnyShareLink) // Archived boards aren't shown to them
+	}
+	return updated, nil
+}
+
+// GetArchivedBoards lists the archived boards the user owns or can use.
+func (s *ArchiveService) GetArchivedBoards(userID uint) ([]models.Board, error) {
+	boards, err := s.boardRepo.FindByOwnerOrMember(userID)
+	if err != nil {
+		return nil, err
+	}
+	return filterArchivedBoards(boards, true), nil
+}
+
+// ArchiveList hides the list, and the cards in it, from the board. It keeps its
+// rank, as do the other lists. Archiving an archived list does nothing.
+func (s *ArchiveService) ArchiveList(listID, userID uint) (*models.List, error) {
+	list, err := s.authorizeList(listID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if list.ArchivedAt != nil {
+		return list, nil
+	}
+	if err := s.archiveRepo.ArchiveList(listID, s.now()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrListNotFound
+		}
+		return nil, err
+	}
+	archived, err := s.listRepo.FindByID(listID)
+	if err != nil {
+		return nil, err
+	}
+
+	broadcastMessage(s.hub, archived.BoardID, realtime.MessageTypeListArchived,
+		realtime.ListBasicInfo{ID: listID, BoardID: archived.BoardID}, userID)
+	return archived, nil
+}
+
+// UnarchiveList puts the list back where it was on the board.
+func (s *ArchiveService) UnarchiveList(listID, userID uint) (*models.List, error) {
+	list, err := s.authorizeList(listID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if list.ArchivedAt == nil {
+		return list, nil
+	}
+	if err := s.archiveRepo.UnarchiveList(listID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrListNotFound
+		}
+		return nil, err
+	}
+	unarchived, err := s.listRepo.FindByID(listID)
+	if err != nil {
+		return nil, err
+	}
+
+	broadcastMessage(s.hub, unarchived.BoardID, realtime.MessageTypeListUnarchived, dto.MapListToResponse(unarchived, true), userID)
+	return unarchived, nil
+}
+
+// ArchiveCard hides the card from its list. It keeps its rank, as do the other
+// cards. Archiving an archived card does nothing.
+func (s *ArchiveService) ArchiveCard(cardID, userID uint) (*models.Card, error) {
+	card, boardID, err := s.authorizeCard(cardID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if card.ArchivedAt != nil {
+		return card, nil
+	}
+	if err := s.archiveRepo.ArchiveCard(cardID, s.now()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCardNotFound
+		}
+		return nil, err
+	}
+	archived, err := s.cardRepo.FindByID(cardID)
+	if err != nil {
+		return nil, err
+	}
+
+	broadcastMessage(s.hub, boardID, realtime.MessageTypeCardArchived,
+		realtime.CardBasicInfo{ID: cardID, ListID: archived.ListID, BoardID: boardID}, userID)
+	return archived, nil
+}
+
+// UnarchiveCard puts the card back where it was in its list.
+func (s *ArchiveService) UnarchiveCard(cardID, userID uint) (*models.Card, error) {
+	card, boardID, err := s.authorizeCard(cardID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if card.ArchivedAt == nil {
+		return card, nil
+	}
+	if err := s.archiveRepo.UnarchiveCard(cardID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCardNotFound
+		}
+		return nil, err
+	}
+	unarchived, err := s.cardRepo.FindByID(cardID)
+	if err != nil {
+		return nil, err
+	}
+
+	broadcastMessage(s.hub, boardID, realtime.MessageTypeCardUnarchived, dto.MapCardToResponse(unarchived, true), userID)
+	return unarchived, nil
+}
+
+// GetArchivedItems lists the board's archived lists and cards for anyone who can see the board.
+func (s *ArchiveService) GetArchivedItems(boardID, userID uint) (*BoardItems, error) {
+	if _, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, boardID, userID, policy.ViewBoard); err != nil {
+		return nil, err
+	}
+	lists, err := s.archiveRepo.FindArchivedLists(boardID)
+	if err != nil {
+		return nil, err
+	}
+	cards, err := s.archiveRepo.FindArchivedCards(boardID)
+	if err != nil {
+		return nil, err
+	}
+	return &BoardItems{Lists: lists, Cards: cards}, nil
+}
+
+// GetTrash lists the board's deleted lists and cards for its admins.
+func (s *ArchiveService) GetTrash(boardID, userID uint) (*BoardItems, error) {
+	if _, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, boardID, userID, policy.ManageTrash); err != nil {
+		return nil, err
+	}
+	lists, err := s.archiveRepo.FindDeletedLists(boardID)
+	if err != nil {
+		return nil, err
+	}
+	cards, err := s.archiveRepo.FindDeletedCards(boardID)
+	if err != nil {
+		return nil, err
+	}
+	return &BoardItems{Lists: lists, Cards: cards}, nil
+}
+
+// RestoreList takes a list, with its cards, out of the board's trash and puts it
+// back at its old position among the lists still on the board.
+func (s *ArchiveService) RestoreList(boardID, listID, userID uint) (*models.List, error) {
+	if _, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, boardID, userID, policy.ManageTrash); err != nil {
+		return nil, err
+	}
+	list, err := s.archiveRepo.FindDeletedList(listID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrListNotFound
+		}
+		return nil, err
+	}
+	if list.BoardID != boardID {
+		return nil, ErrListNotFound
+	}
+
+	if err := s.archiveRepo.RestoreList(listID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrListNotFound // Restored concurrently
+		}
+		return nil, err
+	}
+	restored, err := s.listRepo.FindByID(listID)
+	if err != nil {
+		return nil, err
+	}
+
+	broadcastMessage(s.hub, boardID, realtime.MessageTypeListRestored, dto.MapListToResponse(restored, true), userID)
+	return restored, nil
+}
+
+// RestoreCard takes a card out of the board's trash and puts it back at its old
+// position in its list. A card whose list is in the trash too comes back with the list.
+func (s *ArchiveService) RestoreCard(boardID, cardID, userID uint) (*models.Card, error) {
+	if _, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, boardID, userID, policy.ManageTrash); err != nil {
+		return nil, err
+	}
+	card, err := s.archiveRepo.FindDeletedCard(cardID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCardNotFound
+		}
+		return nil, err
+	}
+	if card.List.BoardID != boardID {
+		return nil, ErrCardNotFound
+	}
+	if card.List.DeletedAt.Valid {
+		return nil, fmt.Errorf("%w: the card's list is in the trash too, restore the list first", ErrInvalidInput)
+	}
+
+	if err := s.archiveRepo.RestoreCard(cardID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCardNotFound // Restored concurrently
+		}
+		return nil, err
+	}
+	restored, err := s.cardRepo.FindByID(cardID)
+	if err != nil {
+		return nil, err
+	}
+
+	broadcastMessage(s.hub, boardID, realtime.MessageTypeCardRestored, dto.MapCardToResponse(restored, true), userID)
+	return restored, nil
+}
+
+// GetDeletedBoards lists the boards the user deleted that are still in the trash.
+// Only owners delete boards, so only they see them here.
+func (s *ArchiveService) GetDeletedBoards(userID uint) ([]models.Board, error) {
+	return s.archiveRepo.FindDeletedBoards(userID)
+}
+
+// RestoreBoard takes one of the user's deleted boards out of the trash, with everything on it.
+func (s *ArchiveService) RestoreBoard(boardID, userID uint) (*models.Board, error) {
+	if err := s.archiveRepo.RestoreBoard(boardID, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBoardNotFound
+		}
+		return nil, err
+	}
+	return s.boardRepo.FindByID(boardID)
+}
+
+// PurgeExpired permanently deletes the boards, lists and cards that have been in
+// the trash for longer than the retention period, and returns how many there were.
+func (s *ArchiveService) PurgeExpired() (int64, error) {
+	if s.retention <= 0 {
+		return 0, nil // Kept forever
+	}
+	return s.archiveRepo.Purge(s.now().Add(-s.retention))
+}
+
+// RunRetentionJob calls PurgeExpired right away and then every interval until stop
+// is closed. It returns at once if retention is disabled. Run it in its own goroutine.
+func (s *ArchiveService) RunRetentionJob(interval time.Duration, stop <-chan struct{}) {
+	if s.retention <= 0 || interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		purged, err := s.PurgeExpired()
+		if err != nil {
+			log.Printf("ERROR [ArchiveService.RunRetentionJob]: Failed to purge the trash: %v", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d boards, lists and cards deleted more than %s ago", purged, s.retention)
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// authorizeList finds the list and checks the user may archive it.
+func (s *ArchiveService) authorizeList(listID, userID uint) (*models.List, error) {
+	list, err := s.listRepo.FindByID(listID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrListNotFound
+		}
+		return nil, err
+	}
+	if _, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, list.BoardID, userID, policy.ArchiveList); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// authorizeCard checks the user may archive the card, and finds it.
+func (s *ArchiveService) authorizeCard(cardID, userID uint) (*models.Card, uint, error) {
+	boardID, err := authorizeCardAction(s.cardRepo, s.listRepo, s.boardRepo, s.boardMemberRepo, cardID, userID, policy.ArchiveCard)
+	if err != nil {
+		return nil, 0, err
+	}
+	card, err := s.cardRepo.FindByID(cardID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, 0, ErrCardNotFound
+		}
+		return nil, 0, err
+	}
+	return card, boardID, nil
+}
@@ -0,0 +1,222 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/repositories"
+)
+
+// archiveTestEnv runs the archive service on SQLite next to the services that
+// create and delete what it archives and restores. Alice owns the board, Bob is a member.
+type archiveTestEnv struct {
+	archive *ArchiveService
+	boards  BoardServiceInterface
+	lists   *ListService
+	cards   CardServiceInterface
+	board   *models.Board
+	alice   uint
+	bob     uint
+}
+
+func newArchiveTestEnv(t *testing.T) *archiveTestEnv {
+	db := setupTestDB(t)
+	userRepo := repositories.NewUserRepository(db)
+	boardRepo := repositories.NewBoardRepository(db)
+	boardMemberRepo := repositories.NewBoardMemberRepository(db)
+	listRepo := repositories.NewListRepository(db)
+	cardRepo := repositories.NewCardRepository(db)
+
+	env := &archiveTestEnv{
+		archive: NewArchiveService(repositories.NewArchiveRepository(db), boardRepo, boardMemberRepo, listRepo, cardRepo, &MockHub{}, 24*time.Hour),
+		boards:  NewBoardService(boardRepo, userRepo, boardMemberRepo, &MockHub{}, false),
+		lists:   NewListService(listRepo, boardRepo, boardMemberRepo, &MockHub{}),
+		cards:   NewCardService(cardRepo, listRepo, boardRepo, boardMemberRepo, userRepo, &MockHub{}),
+	}
+	env.alice = createTestUser(t, userRepo, "alice")
+	env.bob = createTestUser(t, userRepo, "bob")
+	board := createTestBoard(t, env.boards, "Roadmap", "", env.alice, map[uint]models.BoardRole{env.bob: models.BoardRoleMember})
+	env.board = board
+	return env
+}
+
+func (env *archiveTestEnv) createList(t *testing.T, name string) *models.List {
+	t.Helper()
+	list, err := env.lists.CreateList(name, env.board.ID, env.alice, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return list
+}
+
+func (env *archiveTestEnv) listNames(t *testing.T) []string {
+	t.Helper()
+	lists, err := env.lists.GetListsByBoardID(env.board.ID, env.alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, len(lists))
+	for i, list := range lists {
+		names[i] = list.Name
+		if i > 0 {
+			assert.Less(t, lists[i-1].Position, list.Position, "sorted by distinct ranks")
+		}
+	}
+	return names
+}
+
+func TestArchiveService_ArchiveAndUnarchiveList(t *testing.T) {
+	env := newArchiveTestEnv(t)
+	env.createList(t, "To do")
+	doing := env.createList(t, "Doing")
+	env.createList(t, "Done")
+
+	archived, err := env.archive.ArchiveList(doing.ID, env.bob)
+	assert.NoError(t, err)
+	assert.NotNil(t, archived.ArchivedAt)
+	assert.Equal(t, []string{"To do", "Done"}, env.listNames(t))
+	items, err := env.archive.GetArchivedItems(env.board.ID, env.bob)
+	assert.NoError(t, err)
+	assert.Len(t, items.Lists, 1)
+
+	env.createList(t, "Later")
+	unarchived, err := env.archive.UnarchiveList(doing.ID, env.bob)
+	assert.NoError(t, err)
+	assert.Nil(t, unarchived.ArchivedAt)
+	assert.Equal(t, []string{"To do", "Doing", "Done", "Later"}, env.listNames(t))
+}
+
+func TestArchiveService_ArchiveCardNeedsAdminOrParticipant(t *testing.T) {
+	env := newArchiveTestEnv(t)
+	list := env.createList(t, "To do")
+	card, err := env.cards.CreateCard(list.ID, "Task", "", nil, nil, nil, nil, nil, env.alice)
+	assert.NoError(t, err)
+
+	_, err = env.archive.ArchiveCard(card.ID, env.bob)
+	assert.ErrorIs(t, err, ErrForbidden)
+	assignee := &env.bob
+	_, err = env.cards.UpdateCard(card.ID, nil, nil, nil, nil, &assignee, nil, nil, nil, env.alice)
+	assert.NoError(t, err)
+	_, err = env.archive.ArchiveCard(card.ID, env.bob)
+	assert.NoError(t, err, "members may archive cards they work on")
+
+	cards, err := env.cards.GetCardsByListID(list.ID, env.alice)
+	assert.NoError(t, err)
+	assert.Empty(t, cards)
+}
+
+func TestArchiveService_ArchivedBoardIsReadOnly(t *testing.T) {
+	env := newArchiveTestEnv(t)
+	list := env.createList(t, "To do")
+	card, err := env.cards.CreateCard(list.ID, "Task", "", nil, nil, nil, nil, nil, env.alice)
+	assert.NoError(t, err)
+
+	_, err = env.archive.ArchiveBoard(env.board.ID, env.bob)
+	assert.ErrorIs(t, err, ErrForbidden, "only admins archive boards")
+	_, err = env.archive.ArchiveBoard(env.board.ID, env.alice)
+	assert.NoError(t, err)
+
+	_, err = env.lists.CreateList("More", env.board.ID, env.alice, nil)
+	assert.ErrorIs(t, err, ErrBoardArchived)
+	_, err = env.cards.UpdateCard(card.ID, nil, strPtr("Changed"), nil, nil, nil, nil, nil, nil, env.alice)
+	assert.ErrorIs(t, err, ErrBoardArchived)
+	_, err = env.boards.GetBoardByID(env.board.ID, env.bob)
+	assert.NoError(t, err, "archived boards can still be viewed")
+
+	boards, err := env.boards.GetBoardsForUser(env.bob)
+	assert.NoError(t, err)
+	assert.Empty(t, boards)
+	boards, err = env.archive.GetArchivedBoards(env.bob)
+	assert.NoError(t, err)
+	assert.Len(t, boards, 1)
+
+	_, err = env.archive.UnarchiveBoard(env.board.ID, env.alice)
+	assert.NoError(t, err)
+	_, err = env.lists.CreateList("More", env.board.ID, env.alice, nil)
+	assert.NoError(t, err)
+}
+
+func TestArchiveService_TrashRestore(t *testing.T) {
+	env := newArchiveTestEnv(t)
+	todo := env.createList(t, "To do")
+	doing := env.createList(t, "Doing")
+	var cards []*models.Card
+	for _, title := range []string{"a", "b", "c"} {
+		card, err := env.cards.CreateCard(todo.ID, title, "", nil, nil, nil, nil, nil, env.alice)
+		assert.NoError(t, err)
+		cards = append(cards, card)
+	}
+
+	assert.NoError(t, env.cards.DeleteCard(cards[0].ID, env.alice))
+	assert.NoError(t, env.lists.DeleteList(doing.ID, env.alice))
+	_, err := env.archive.GetTrash(env.board.ID, env.bob)
+	assert.ErrorIs(t, err, ErrForbidden, "the trash is for admins")
+	trash, err := env.archive.GetTrash(env.board.ID, env.alice)
+	assert.NoError(t, err)
+	assert.Len(t, trash.Lists, 1)
+	assert.Len(t, trash.Cards, 1)
+
+	restored, err := env.archive.RestoreCard(env.board.ID, cards[0].ID, env.alice)
+	assert.NoError(t, err)
+	assert.Equal(t, cards[0].Position, restored.Position, "back at the top of its list")
+	remaining, err := env.cards.GetCardsByListID(todo.ID, env.alice)
+	assert.NoError(t, err)
+	if assert.Len(t, remaining, 3) {
+		assert.Equal(t, "a", remaining[0].Title)
+		assert.Equal(t, "b", remaining[1].Title)
+		assert.Equal(t, cards[2].Position, remaining[2].Position, "the others kept their ranks")
+	}
+
+	_, err = env.archive.RestoreList(env.board.ID+1, doing.ID, env.alice)
+	assert.ErrorIs(t, err, ErrBoardNotFound)
+	_, err = env.archive.RestoreList(env.board.ID, doing.ID, env.alice)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"To do", "Doing"}, env.listNames(t))
+	_, err = env.archive.RestoreList(env.board.ID, doing.ID, env.alice)
+	assert.ErrorIs(t, err, ErrListNotFound, "no longer in the trash")
+}
+
+func TestArchiveService_CardInDeletedListComesBackWithIt(t *testing.T) {
+	env := newArchiveTestEnv(t)
+	list := env.createList(t, "To do")
+	card, err := env.cards.CreateCard(list.ID, "Task", "", nil, nil, nil, nil, nil, env.alice)
+	assert.NoError(t, err)
+	assert.NoError(t, env.cards.DeleteCard(card.ID, env.alice))
+	assert.NoError(t, env.lists.DeleteList(list.ID, env.alice))
+
+	_, err = env.archive.RestoreCard(env.board.ID, card.ID, env.alice)
+	assert.ErrorIs(t, err, ErrInvalidInput, "restore the list first")
+	_, err = env.archive.RestoreList(env.board.ID, list.ID, env.alice)
+	assert.NoError(t, err)
+	_, err = env.archive.RestoreCard(env.board.ID, card.ID, env.alice)
+	assert.NoError(t, err)
+}
+
+func TestArchiveService_DeletedBoardsAndRetention(t *testing.T) {
+	env := newArchiveTestEnv(t)
+	env.createList(t, "To do")
+	assert.NoError(t, env.boards.DeleteBoard(env.board.ID, env.alice))
+
+	deleted, err := env.archive.GetDeletedBoards(env.alice)
+	assert.NoError(t, err)
+	assert.Len(t, deleted, 1)
+	_, err = env.archive.RestoreBoard(env.board.ID, env.bob)
+	assert.ErrorIs(t, err, ErrBoardNotFound, "only the owner restores a board")
+	_, err = env.archive.RestoreBoard(env.board.ID, env.alice)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"To do"}, env.listNames(t), "the board comes back with its lists")
+
+	assert.NoError(t, env.boards.DeleteBoard(env.board.ID, env.alice))
+	purged, err := env.archive.PurgeExpired()
+	assert.NoError(t, err)
+	assert.Zero(t, purged, "still within the retention period")
+
+	env.archive.now = func() time.Time { return time.Now().Add(25 * time.Hour) }
+	purged, err = env.archive.PurgeExpired()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), purged, "the board and its list")
+	_, err = env.archive.RestoreBoard(env.board.ID, env.alice)
+	assert.ErrorIs(t, err, ErrBoardNotFound)
+}
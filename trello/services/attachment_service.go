@@ -0,0 +1,355 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/zayyadi/trello/dto"
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/policy"
+	"github.com/zayyadi/trello/realtime"
+	"github.com/zayyadi/trello/repositories"
+	"github.com/zayyadi/trello/storage"
+	"github.com/zayyadi/trello/utils"
+
+	"gorm.io/gorm"
+)
+
+// Attachment defaults, used when the options leave them unset.
+const (
+	DefaultAttachmentMaxSize       = 10 << 20 // 10 MiB
+	DefaultAttachmentThumbnailSize = 320      // Pixels on the longer side
+)
+
+// DefaultAttachmentTypes are the file types accepted when AttachmentOptions.AllowedTypes is empty.
+var DefaultAttachmentTypes = []string{"image/*", "application/pdf", "text/plain", "application/zip"}
+
+// attachmentCleanupBatch is how many orphaned attachments PurgeOrphaned handles per query.
+const attachmentCleanupBatch = 100
+
+type AttachmentOptions struct {
+	MaxSize       int64    // Largest accepted file in bytes
+	AllowedTypes  []string // MIME types such as "application/pdf", or "image/*" for all images
+	ThumbnailSize int      // Longer side of image thumbnails in pixels
+}
+
+// AttachmentService manages the files attached to cards. The files are kept in a
+// storage.BlobStore; their type is worked out from their contents, not their name.
+// Adding and removing attachments counts as editing the card.
+type AttachmentService struct {
+	attachmentRepo  repositories.AttachmentRepositoryInterface
+	cardRepo        repositories.CardRepositoryInterface
+	listRepo        repositories.ListRepositoryInterface
+	boardRepo       repositories.BoardRepositoryInterface
+	boardMemberRepo repositories.BoardMemberRepositoryInterface
+	blobs           storage.BlobStore
+	hub             realtime.Broadcaster
+	opts            AttachmentOptions
+}
+
+func NewAttachmentService(
+	attachmentRepo repositories.AttachmentRepositoryInterface,
+	cardRepo repositories.CardRepositoryInterface,
+	listRepo repositories.ListRepositoryInterface,
+	boardRepo repositories.BoardRepositoryInterface,
+	boardMemberRepo repositories.BoardMemberRepositoryInterface,
+	blobs storage.BlobStore,
+	hub realtime.Broadcaster,
+	opts AttachmentOptions,
+) *AttachmentService {
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = DefaultAttachmentMaxSize
+	}
+	if len(opts.AllowedTypes) == 0 {
+		opts.AllowedTypes = DefaultAttachmentTypes
+	}
+	if opts.ThumbnailSize <= 0 {
+		opts.ThumbnailSize = DefaultAttachmentThumbnailSize
+	}
+	return &AttachmentService{
+		attachmentRepo:  attachmentRepo,
+		cardRepo:        cardRepo,
+		listRepo:        listRepo,
+		boardRepo:       boardRepo,
+		boardMemberRepo: boardMemberRepo,
+		blobs:           blobs,
+		hub:             hub,
+		opts:            opts,
+	}
+}
+
+// MaxSize is the largest file accepted, in bytes.
+func (s *AttachmentService) MaxSize() int64 {
+	return s.opts.MaxSize
+}
+
+// GetAttachments lists the card's attachments, newest first.
+func (s *AttachmentService) GetAttachments(cardID, userID uint) ([]models.Attachment, error) {
+	if _, err := s.authorize(cardID, userID, policy.ViewBoard); err != nil {
+		return nil, err
+	}
+	return s.attachmentRepo.FindByCardID(cardID)
+}
+
+// UploadAttachment stores the file read from body as a new attachment of the card,
+// with a thumbnail if it is an image we can scale. Files over the size limit fail
+// with ErrAttachmentTooLarge and types not allowed with ErrUnsupportedFileType.
+func (s *AttachmentService) UploadAttachment(cardID uint, filename string, body io.Reader, userID uint) (*models.Attachment, error) {
+	boardID, err := s.authorize(cardID, userID, policy.EditCard)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, s.opts.MaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > s.opts.MaxSize {
+		return nil, fmt.Errorf("%w: the limit is %d bytes", ErrAttachmentTooLarge, s.opts.MaxSize)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: the file is empty", ErrInvalidInput)
+	}
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	if !s.typeAllowed(contentType) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFileType, contentType)
+	}
+
+	token, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	attachment := &models.Attachment{
+		CardID:      cardID,
+		UploaderID:  userID,
+		Filename:    cleanFilename(filename),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		StorageKey:  fmt.Sprintf("cards/%d/%s", cardID, token),
+	}
+	if err := s.blobs.Put(attachment.StorageKey, bytes.NewReader(data), attachment.Size, contentType); err != nil {
+		return nil, err
+	}
+	if thumbnail, thumbnailType, err := utils.MakeThumbnail(data, s.opts.ThumbnailSize); err == nil {
+		thumbnailKey := attachment.StorageKey + "_thumb"
+		if err := s.blobs.Put(thumbnailKey, bytes.NewReader(thumbnail), int64(len(thumbnail)), thumbnailType); err != nil {
+			log.Printf("WARN [AttachmentService.UploadAttachment]: Failed to store thumbnail of %s: %v", attachment.StorageKey, err)
+		} else {
+			attachment.ThumbnailKey = &thumbnailKey
+		}
+	} // Files that aren't images we can decode simply have no thumbnail
+
+	if err := s.attachmentRepo.Create(attachment); err != nil {
+		s.deleteBlobs(attachment) // Nothing refers to them
+		return nil, err
+	}
+	created, err := s.attachmentRepo.FindByID(attachment.ID) // With the uploader
+	if err != nil {
+		return nil, err
+	}
+
+	broadcastMessage(s.hub, boardID, realtime.MessageTypeAttachmentCreated, dto.MapAttachmentToResponse(created), userID)
+	return created, nil
+}
+
+// OpenAttachment returns one of the card's attachments with its file, or its thumbnail
+// if thumbnail is set. The caller must close the returned reader.
+func (s *AttachmentService) OpenAttachment(cardID, attachmentID uint, thumbnail bool, userID uint) (*models.Attachment, io.ReadCloser, storage.BlobInfo, error) {
+	if _, err := s.authorize(cardID, userID, policy.ViewBoard); err != nil {
+		return nil, nil, storage.BlobInfo{}, err
+	}
+	attachment, err := s.findAttachment(cardID, attachmentID)
+	if err != nil {
+		return nil, nil, storage.BlobInfo{}, err
+	}
+	key := attachment.StorageKey
+	if thumbnail {
+		if attachment.ThumbnailKey == nil {
+			return nil, nil, storage.BlobInfo{}, fmt.Errorf("%w: the attachment has no thumbnail", ErrAttachmentNotFound)
+		}
+		key = *attachment.ThumbnailKey
+	}
+	body, info, err := s.blobs.Open(key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, storage.BlobInfo{}, fmt.Errorf("%w: its file is missing", ErrAttachmentNotFound)
+		}
+		return nil, nil, storage.BlobInfo{}, err
+	}
+	return attachment, body, info, nil
+}
+
+// DeleteAttachment removes one of the card's attachments, and its file unless a copy
+// of the card still uses it. If it was the card's cover, the card is left without one.
+func (s *AttachmentService) DeleteAttachment(cardID, attachmentID, userID uint) error {
+	boardID, err := s.authorize(cardID, userID, policy.EditCard)
+	if err != nil {
+		return err
+	}
+	attachment, err := s.findAttachment(cardID, attachmentID)
+	if err != nil {
+		return err
+	}
+	if err := s.attachmentRepo.Delete(attachmentID); err != nil {
+		return err
+	}
+	s.deleteBlobs(attachment)
+
+	broadcastMessage(s.hub, boardID, realtime.MessageTypeAttachmentDeleted,
+		realtime.AttachmentBasicInfo{ID: attachmentID, CardID: cardID, BoardID: boardID}, userID)
+	return nil
+}
+
+// SetCover makes one of the card's image attachments its cover, or removes the
+// cover if attachmentID is nil. It returns the updated card.
+func (s *AttachmentService) SetCover(cardID uint, attachmentID *uint, userID uint) (*models.Card, error) {
+	boardID, err := s.authorize(cardID, userID, policy.EditCard)
+	if err != nil {
+		return nil, err
+	}
+	if attachmentID != nil {
+		attachment, err := s.findAttachment(cardID, *attachmentID)
+		if err != nil {
+			return nil, err
+		}
+		if !attachment.IsImage() {
+			return nil, fmt.Errorf("%w: only image attachments can be a card's cover", ErrInvalidInput)
+		}
+	}
+	if err := s.attachmentRepo.SetCover(cardID, attachmentID); err != nil {
+		return nil, err
+	}
+	card, err := s.cardRepo.FindByID(cardID)
+	if err != nil {
+		return nil, err
+	}
+
+	broadcastMessage(s.hub, boardID, realtime.MessageTypeCardCoverUpdated,
+		realtime.CardCoverPayload{CardID: cardID, BoardID: boardID, CoverAttachmentID: attachmentID}, userID)
+	return card, nil
+}
+
+// PurgeOrphaned deletes the attachments of cards purged from the trash, with their
+// files, and returns how many there were.
+func (s *AttachmentService) PurgeOrphaned() (int, error) {
+	purged := 0
+	for {
+		attachments, err := s.attachmentRepo.FindOrphaned(attachmentCleanupBatch)
+		if err != nil {
+			return purged, err
+		}
+		for i := range attachments {
+			if err := s.attachmentRepo.Delete(attachments[i].ID); err != nil {
+				return purged, err
+			}
+			s.deleteBlobs(&attachments[i])
+			purged++
+		}
+		if len(attachments) < attachmentCleanupBatch {
+			return purged, nil
+		}
+	}
+}
+
+// RunCleanupJob calls PurgeOrphaned right away and then every interval until stop
+// is closed. Run it in its own goroutine.
+func (s *AttachmentService) RunCleanupJob(interval time.Duration, stop <-chan struct{}) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		purged, err := s.PurgeOrphaned()
+		if err != nil {
+			log.Printf("ERROR [AttachmentService.RunCleanupJob]: Failed to delete attachments of purged cards: %v", err)
+		} else if purged > 0 {
+			log.Printf("Deleted %d attachments of purged cards", purged)
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// authorize checks action against the card's board, and returns the board's ID.
+func (s *AttachmentService) authorize(cardID, userID uint, action policy.Action) (uint, error) {
+	return authorizeCardAction(s.cardRepo, s.listRepo, s.boardRepo, s.boardMemberRepo, cardID, userID, action)
+}
+
+// findAttachment finds one of the card's attachments, reporting other cards' attachments as not found.
+func (s *AttachmentService) findAttachment(cardID, attachmentID uint) (*models.Attachment, error) {
+	attachment, err := s.attachmentRepo.FindByID(attachmentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAttachmentNotFound
+		}
+		return nil, err
+	}
+	if attachment.CardID != cardID {
+		return nil, ErrAttachmentNotFound
+	}
+	return attachment, nil
+}
+
+// deleteBlobs deletes the attachment's file and thumbnail once no attachment uses
+// them any more. Failures are only logged; the attachment itself is already gone.
+func (s *AttachmentService) deleteBlobs(attachment *models.Attachment) {
+	keys := []string{attachment.StorageKey}
+	if attachment.ThumbnailKey != nil {
+		keys = append(keys, *attachment.ThumbnailKey)
+	}
+	for _, key := range keys {
+		used, err := s.attachmentRepo.IsBlobUsed(key)
+		if err == nil && !used {
+			err = s.blobs.Delete(key)
+		}
+		if err != nil {
+			log.Printf("WARN [AttachmentService.deleteBlobs]: Failed to delete blob %s: %v", key, err)
+		}
+	}
+}
+
+// typeAllowed checks contentType against the allowed types, where "image/*" allows every image.
+func (s *AttachmentService) typeAllowed(contentType string) bool {
+	for _, allowed := range s.opts.AllowedTypes {
+		if allowed == contentType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(allowed, "/*"); ok && strings.HasPrefix(contentType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanFilename keeps the last element of an uploaded file's name, without control
+// characters and at most 255 bytes long.
+func cleanFilename(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(name))
+	for len(name) > 255 {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	if name == "" || name == "." || name == "/" {
+		return "file"
+	}
+	return name
+}
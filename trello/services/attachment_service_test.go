@@ -0,0 +1,217 @@
+package services
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/realtime"
+	"github.com/zayyadi/trello/repositories"
+	"github.com/zayyadi/trello/storage"
+)
+
+// attachmentTestEnv runs the attachment service on SQLite with files kept in a
+// temporary directory. Alice owns a board with one card, Vera is a viewer.
+type attachmentTestEnv struct {
+	attachments *AttachmentService
+	cards       CardServiceInterface
+	copier      *BoardCopyService
+	archiveRepo repositories.ArchiveRepositoryInterface
+	listRepo    repositories.ListRepositoryInterface
+	blobs       *storage.LocalStore
+	messages    []string // Types of the messages broadcast
+	board       *models.Board
+	card        *models.Card
+	alice       uint
+	vera        uint
+}
+
+func newAttachmentTestEnv(t *testing.T) *attachmentTestEnv {
+	db := setupTestDB(t)
+	userRepo := repositories.NewUserRepository(db)
+	boardRepo := repositories.NewBoardRepository(db)
+	boardMemberRepo := repositories.NewBoardMemberRepository(db)
+	listRepo := repositories.NewListRepository(db)
+	cardRepo := repositories.NewCardRepository(db)
+
+	env := &attachmentTestEnv{blobs: storage.NewLocalStore(t.TempDir()), archiveRepo: repositories.NewArchiveRepository(db), listRepo: listRepo}
+	hub := &MockHub{SubmitFunc: func(msg *realtime.WebSocketMessage) { env.messages = append(env.messages, msg.Type) }}
+	env.attachments = NewAttachmentService(repositories.NewAttachmentRepository(db), cardRepo, listRepo, boardRepo, boardMemberRepo,
+		env.blobs, hub, AttachmentOptions{MaxSize: 64 << 10, ThumbnailSize: 16})
+	env.cards = NewCardService(cardRepo, listRepo, boardRepo, boardMemberRepo, userRepo, &MockHub{})
+	env.copier = NewBoardCopyService(repositories.NewBoardCopyRepository(db), boardRepo, boardMemberRepo, repositories.NewWorkspaceMemberRepository(db), &MockHub{})
+	boards := NewBoardService(boardRepo, userRepo, boardMemberRepo, &MockHub{}, false)
+	lists := NewListService(listRepo, boardRepo, boardMemberRepo, &MockHub{})
+	env.alice = createTestUser(t, userRepo, "alice")
+	env.vera = createTestUser(t, userRepo, "vera")
+	board := createTestBoard(t, boards, "Launch", "", env.alice, map[uint]models.BoardRole{env.vera: models.BoardRoleViewer})
+	list, err := lists.CreateList("To do", board.ID, env.alice, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env.card, err = env.cards.CreateCard(list.ID, "Press release", "", nil, nil, nil, nil, nil, env.alice); err != nil {
+		t.Fatal(err)
+	}
+	env.board = board
+	return env
+}
+
+func testPNG(t *testing.T, w, h int) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// blobExists says whether the store still has a blob under key.
+func (env *attachmentTestEnv) blobExists(key string) bool {
+	body, _, err := env.blobs.Open(key)
+	if err != nil {
+		return false
+	}
+	body.Close()
+	return true
+}
+
+func TestAttachmentService_UploadAndDownload(t *testing.T) {
+	env := newAttachmentTestEnv(t)
+
+	_, err := env.attachments.UploadAttachment(env.card.ID, "notes.txt", strings.NewReader("hello"), env.vera)
+	assert.ErrorIs(t, err, ErrForbidden, "viewers can't edit cards")
+
+	notes, err := env.attachments.UploadAttachment(env.card.ID, `C:\Users\alice\notes.txt`, strings.NewReader("hello"), env.alice)
+	assert.NoError(t, err)
+	assert.Equal(t, "notes.txt", notes.Filename, "only the last part of the name is kept")
+	assert.Equal(t, "text/plain", notes.ContentType, "worked out from the contents")
+	assert.Equal(t, int64(5), notes.Size)
+	assert.Nil(t, notes.ThumbnailKey)
+	assert.Equal(t, "alice", notes.Uploader.Username)
+	assert.Contains(t, env.messages, realtime.MessageTypeAttachmentCreated)
+
+	photo, err := env.attachments.UploadAttachment(env.card.ID, "photo.png", bytes.NewReader(testPNG(t, 64, 32)), env.alice)
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", photo.ContentType)
+	assert.NotNil(t, photo.ThumbnailKey, "images get a thumbnail")
+
+	// Viewers can list and download
+	attachments, err := env.attachments.GetAttachments(env.card.ID, env.vera)
+	assert.NoError(t, err)
+	assert.Len(t, attachments, 2)
+	_, body, info, err := env.attachments.OpenAttachment(env.card.ID, notes.ID, false, env.vera)
+	assert.NoError(t, err)
+	data, _ := io.ReadAll(body)
+	body.Close()
+	assert.Equal(t, "hello", string(data))
+	assert.Equal(t, "text/plain", info.ContentType)
+
+	_, body, _, err = env.attachments.OpenAttachment(env.card.ID, photo.ID, true, env.vera)
+	assert.NoError(t, err)
+	thumbnail, err := png.Decode(body)
+	body.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 16, 8), thumbnail.Bounds())
+
+	_, _, _, err = env.attachments.OpenAttachment(env.card.ID, notes.ID, true, env.alice)
+	assert.ErrorIs(t, err, ErrAttachmentNotFound, "only images have thumbnails")
+	_, _, _, err = env.attachments.OpenAttachment(env.card.ID+1, notes.ID, false, env.alice)
+	assert.Error(t, err, "attachments are only found through their own card")
+}
+
+func TestAttachmentService_Limits(t *testing.T) {
+	env := newAttachmentTestEnv(t)
+
+	_, err := env.attachments.UploadAttachment(env.card.ID, "big.txt", strings.NewReader(strings.Repeat("a", 64<<10+1)), env.alice)
+	assert.ErrorIs(t, err, ErrAttachmentTooLarge)
+	_, err = env.attachments.UploadAttachment(env.card.ID, "page.txt", strings.NewReader("<html><script>alert(1)</script></html>"), env.alice)
+	assert.ErrorIs(t, err, ErrUnsupportedFileType, "the name doesn't matter, the contents do")
+	_, err = env.attachments.UploadAttachment(env.card.ID, "empty.txt", strings.NewReader(""), env.alice)
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	attachments, err := env.attachments.GetAttachments(env.card.ID, env.alice)
+	assert.NoError(t, err)
+	assert.Empty(t, attachments)
+}
+
+func TestAttachmentService_CoverAndDelete(t *testing.T) {
+	env := newAttachmentTestEnv(t)
+	notes, err := env.attachments.UploadAttachment(env.card.ID, "notes.txt", strings.NewReader("hello"), env.alice)
+	assert.NoError(t, err)
+	photo, err := env.attachments.UploadAttachment(env.card.ID, "photo.png", bytes.NewReader(testPNG(t, 8, 8)), env.alice)
+	assert.NoError(t, err)
+
+	_, err = env.attachments.SetCover(env.card.ID, &notes.ID, env.alice)
+	assert.ErrorIs(t, err, ErrInvalidInput, "only images can be covers")
+	_, err = env.attachments.SetCover(env.card.ID, &photo.ID, env.vera)
+	assert.ErrorIs(t, err, ErrForbidden)
+	card, err := env.attachments.SetCover(env.card.ID, &photo.ID, env.alice)
+	assert.NoError(t, err)
+	assert.Equal(t, photo.ID, *card.CoverAttachmentID)
+	assert.Equal(t, "photo.png", card.CoverAttachment.Filename)
+	assert.Contains(t, env.messages, realtime.MessageTypeCardCoverUpdated)
+
+	// Deleting the cover image leaves the card without a cover, and removes the files
+	assert.NoError(t, env.attachments.DeleteAttachment(env.card.ID, photo.ID, env.alice))
+	card, err = env.cards.GetCardByID(env.card.ID, env.alice)
+	assert.NoError(t, err)
+	assert.Nil(t, card.CoverAttachmentID)
+	assert.False(t, env.blobExists(photo.StorageKey))
+	assert.False(t, env.blobExists(*photo.ThumbnailKey))
+	assert.Contains(t, env.messages, realtime.MessageTypeAttachmentDeleted)
+
+	assert.ErrorIs(t, env.attachments.DeleteAttachment(env.card.ID, photo.ID, env.alice), ErrAttachmentNotFound)
+	assert.True(t, env.blobExists(notes.StorageKey))
+}
+
+func TestAttachmentService_CopiesShareFiles(t *testing.T) {
+	env := newAttachmentTestEnv(t)
+	photo, err := env.attachments.UploadAttachment(env.card.ID, "photo.png", bytes.NewReader(testPNG(t, 8, 8)), env.alice)
+	assert.NoError(t, err)
+	_, err = env.attachments.SetCover(env.card.ID, &photo.ID, env.alice)
+	assert.NoError(t, err)
+
+	copied, err := env.copier.CopyBoard(env.board.ID, "Launch (copy)", repositories.BoardCopyOptions{Cards: true}, env.alice)
+	assert.NoError(t, err)
+	lists, err := env.listRepo.FindByBoardID(copied.ID)
+	assert.NoError(t, err)
+	copiedCard := lists[0].Cards[0]
+	if assert.NotNil(t, copiedCard.CoverAttachment, "the copy keeps its cover") {
+		assert.NotEqual(t, photo.ID, copiedCard.CoverAttachment.ID)
+		assert.Equal(t, copiedCard.ID, copiedCard.CoverAttachment.CardID)
+	}
+
+	// The copy still has the file after the original attachment is deleted
+	assert.NoError(t, env.attachments.DeleteAttachment(env.card.ID, photo.ID, env.alice))
+	assert.True(t, env.blobExists(photo.StorageKey))
+	_, body, _, err := env.attachments.OpenAttachment(copiedCard.ID, copiedCard.CoverAttachment.ID, false, env.alice)
+	assert.NoError(t, err)
+	body.Close()
+
+	assert.NoError(t, env.attachments.DeleteAttachment(copiedCard.ID, copiedCard.CoverAttachment.ID, env.alice))
+	assert.False(t, env.blobExists(photo.StorageKey), "deleted with the last attachment using it")
+}
+
+func TestAttachmentService_PurgeOrphaned(t *testing.T) {
+	env := newAttachmentTestEnv(t)
+	notes, err := env.attachments.UploadAttachment(env.card.ID, "notes.txt", strings.NewReader("hello"), env.alice)
+	assert.NoError(t, err)
+	assert.NoError(t, env.cards.DeleteCard(env.card.ID, env.alice))
+
+	purged, err := env.attachments.PurgeOrphaned()
+	assert.NoError(t, err)
+	assert.Zero(t, purged, "the card is only in the trash and may be restored")
+	assert.True(t, env.blobExists(notes.StorageKey))
+
+	_, err = env.archiveRepo.Purge(time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	purged, err = env.attachments.PurgeOrphaned()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	assert.False(t, env.blobExists(notes.StorageKey))
+}
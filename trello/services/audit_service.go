@@ -0,0 +1,55 @@
+package services
+
+import (
+	"log"
+	"strconv"
+
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/repositories"
+)
+
+const (
+	defaultAuditListLimit = 50
+	maxAuditListLimit     = 500
+)
+
+// AuditService records security-relevant events, both in the database and in the server log.
+type AuditService struct {
+	auditRepo repositories.AuditEventRepositoryInterface
+}
+
+func NewAuditService(auditRepo repositories.AuditEventRepositoryInterface) *AuditService {
+	return &AuditService{auditRepo: auditRepo}
+}
+
+// Record stores the event. A failure to store it is logged rather than returned,
+// so a broken audit log never blocks the action being audited. A nil service
+// only logs the event.
+func (s *AuditService) Record(event *models.AuditEvent) {
+	log.Printf("AUDIT %s: user=%s actor=%s email=%q ip=%q %s",
+		event.Type, formatOptionalID(event.UserID), formatOptionalID(event.ActorID), event.Email, event.IP, event.Detail)
+	if s == nil {
+		return
+	}
+	if err := s.auditRepo.Create(event); err != nil {
+		log.Printf("ERROR [Audit]: failed to store %s event: %v", event.Type, err)
+	}
+}
+
+// List returns the most recent events, optionally only those of one type or involving one user.
+func (s *AuditService) List(eventType models.AuditEventType, userID *uint, limit int) ([]models.AuditEvent, error) {
+	if limit <= 0 {
+		limit = defaultAuditListLimit
+	}
+	if limit > maxAuditListLimit {
+		limit = maxAuditListLimit
+	}
+	return s.auditRepo.List(eventType, userID, limit)
+}
+
+func formatOptionalID(id *uint) string {
+	if id == nil {
+		return "-"
+	}
+	return strconv.FormatUint(uint64(*id), 10)
+}
@@ -2,36 +2,124 @@ package services
 
 import (
 	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
 
+	"github.com/zayyadi/trello/mailer"
 	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/realtime"
 	"github.com/zayyadi/trello/repositories"
 	"github.com/zayyadi/trello/utils"
 	"gorm.io/gorm"
 )
 
+const (
+	DefaultAccessTokenTTL   = 15 * time.Minute
+	DefaultRefreshTokenTTL  = 30 * 24 * time.Hour
+	DefaultPasswordResetTTL = time.Hour
+	DefaultEmailVerifyTTL   = 48 * time.Hour
+	// How long a user has to enter their second factor after a correct password
+	DefaultTwoFactorChallengeTTL = 5 * time.Minute
+)
+
+// AuthOptions holds the settings AuthService needs besides its dependencies.
+// Zero durations fall back to the defaults above.
+type AuthOptions struct {
+	JWTSecretKey     string
+	AccessTokenTTL   time.Duration
+	RefreshTokenTTL  time.Duration
+	PasswordResetTTL time.Duration
+	EmailVerifyTTL   time.Duration
+	AppBaseURL       string // Frontend URL used to build links in emails
+	// RequireVerifiedEmail blocks login until the user has verified their email.
+	// Registration then no longer logs the user in.
+	RequireVerifiedEmail bool
+	// TwoFactorChallengeTTL is how long the challenge token from a two-factor login stays valid.
+	TwoFactorChallengeTTL time.Duration
+	// LoginThrottle locks out accounts and IPs after repeated failed logins. Nil disables it.
+	LoginThrottle *LoginThrottleService
+	// JWTKeys signs and verifies access tokens. When nil, JWTSecretKey is used as an HS256 secret.
+	JWTKeys *utils.JWTKeySet
+	// PersonalAccessTokens are revoked along with the sessions when the password is
+	// reset or changed, as whoever knew the old one may have created some. Nil skips them.
+	PersonalAccessTokens repositories.PersonalAccessTokenRepositoryInterface
+	// Hub closes the WebSocket connections of revoked sessions. Nil leaves them open
+	// until they drop.
+	Hub realtime.Broadcaster
+	// Invitations accepts the board invitations waiting for a new user's email address
+	// once the address is verified, rather than on Register. Nil disables it.
+	Invitations *InvitationService
+}
+
+// AuthTokens is the pair of credentials handed to a client after login or refresh.
+type AuthTokens struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    time.Time // Expiry of the access token
+}
+
 type AuthService struct {
-	userRepo     repositories.UserRepositoryInterface
-	jwtSecretKey string
+	userRepo         repositories.UserRepositoryInterface
+	refreshTokenRepo repositories.RefreshTokenRepositoryInterface
+	userTokenRepo    repositories.UserTokenRepositoryInterface
+	mailer           mailer.Mailer
+	opts             AuthOptions
 }
 
-func NewAuthService(userRepo repositories.UserRepositoryInterface, jwtSecretKey string) *AuthService {
-	return &AuthService{userRepo: userRepo, jwtSecretKey: jwtSecretKey}
+func NewAuthService(
+	userRepo repositories.UserRepositoryInterface,
+	refreshTokenRepo repositories.RefreshTokenRepositoryInterface,
+	userTokenRepo repositories.UserTokenRepositoryInterface,
+	mailSender mailer.Mailer,
+	opts AuthOptions,
+) *AuthService {
+	if opts.AccessTokenTTL <= 0 {
+		opts.AccessTokenTTL = DefaultAccessTokenTTL
+	}
+	if opts.RefreshTokenTTL <= 0 {
+		opts.RefreshTokenTTL = DefaultRefreshTokenTTL
+	}
+	if opts.PasswordResetTTL <= 0 {
+		opts.PasswordResetTTL = DefaultPasswordResetTTL
+	}
+	if opts.EmailVerifyTTL <= 0 {
+		opts.EmailVerifyTTL = DefaultEmailVerifyTTL
+	}
+	if opts.TwoFactorChallengeTTL <= 0 {
+		opts.TwoFactorChallengeTTL = DefaultTwoFactorChallengeTTL
+	}
+	if opts.JWTKeys == nil {
+		opts.JWTKeys = utils.NewHMACKeySet(opts.JWTSecretKey)
+	}
+	return &AuthService{
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		userTokenRepo:    userTokenRepo,
+		mailer:           mailSender,
+		opts:             opts,
+	}
 }
 
-func (s *AuthService) Register(username, email, password string) (*models.User, string, error) {
+func (s *AuthService) Register(username, email, password string) (*models.User, *AuthTokens, error) {
 	// Check if email or username already exists
 	_, err := s.userRepo.FindByEmail(email)
 	if err == nil { // nil error means user found
-		return nil, "", ErrEmailExists
+		return nil, nil, ErrEmailExists
 	} else if !errors.Is(err, gorm.ErrRecordNotFound) { // Other DB error
-		return nil, "", err
+		return nil, nil, err
+	}
+	_, err = s.userRepo.FindByUsername(username)
+	if err == nil {
+		return nil, nil, ErrUsernameExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, err
 	}
-	// Similar check for username if your model has unique username (it does)
-	// For brevity, assuming FindByEmail is the primary check for duplicates for now.
 
 	hashedPassword, err := utils.HashPassword(password)
 	if err != nil {
-		return nil, "", err
+		return nil, nil, err
 	}
 
 	user := &models.User{
@@ -41,16 +129,24 @@ func (s *AuthService) Register(username, email, password string) (*models.User,
 	}
 
 	if err := s.userRepo.Create(user); err != nil {
-		return nil, "", err
+		return nil, nil, err
+	}
+
+	if err := s.sendVerificationEmail(user); err != nil {
+		// The account exists; the user can ask for the email again
+		log.Printf("Failed to send verification email to user %d: %v", user.ID, err)
+	}
+	if s.opts.RequireVerifiedEmail {
+		return user, nil, nil // No session until the email is verified
 	}
 
-	token, err := utils.GenerateJWT(user.ID, s.jwtSecretKey)
+	tokens, err := s.startSession(user.ID)
 	if err != nil {
 		// If token generation fails during registration, return nil for user.
-		return nil, "", err
+		return nil, nil, err
 	}
 
-	return user, token, nil
+	return user, tokens, nil
 }
 
 func (s *AuthService) GetAllUsers() ([]models.User, error) {
@@ -61,25 +157,425 @@ func (s *AuthService) GetAllUsers() ([]models.User, error) {
 	return users, nil
 }
 
-func (s *AuthService) Login(email, password string) (*models.User, string, error) {
+// Login checks the user's password. clientIP is used to throttle password guessing
+// and may be empty. Users with two-factor auth get a *TwoFactorRequiredError instead of tokens.
+func (s *AuthService) Login(email, password, clientIP string) (*models.User, *AuthTokens, error) {
+	throttle := s.opts.LoginThrottle
+	if err := throttle.Check(email, clientIP); err != nil {
+		return nil, nil, err
+	}
+
 	user, err := s.userRepo.FindByEmail(email)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, "", ErrInvalidCredentials
+			if err := throttle.RecordFailure(email, clientIP, nil, "unknown email"); err != nil {
+				return nil, nil, err
+			}
+			return nil, nil, ErrInvalidCredentials
 		}
-		return nil, "", err
+		return nil, nil, err
 	}
 
 	if !utils.CheckPasswordHash(password, user.Password) {
-		return nil, "", ErrInvalidCredentials
+		if err := throttle.RecordFailure(email, clientIP, &user.ID, "wrong password"); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, ErrInvalidCredentials
+	}
+	if s.opts.RequireVerifiedEmail && !user.EmailVerified {
+		return nil, nil, ErrEmailNotVerified // Checked after the password so it doesn't reveal accounts
+	}
+	if user.TwoFactorEnabled {
+		// Not a success yet: failures are only cleared once the second factor checks out
+		return nil, nil, s.twoFactorChallenge(user)
+	}
+	if err := throttle.RecordSuccess(email, clientIP, user.ID); err != nil {
+		return nil, nil, err
 	}
 
-	token, err := utils.GenerateJWT(user.ID, s.jwtSecretKey)
+	tokens, err := s.startSession(user.ID)
 	if err != nil {
 		// Return the user object even if token generation fails,
 		// as authentication (email/password check) itself was successful.
-		return user, "", err
+		return user, nil, err
+	}
+
+	return user, tokens, nil
+}
+
+// Refresh exchanges a refresh token for a new access/refresh token pair.
+// Refresh tokens are single use: the presented token is revoked and replaced.
+// Presenting an already used token is treated as theft and ends the whole session.
+func (s *AuthService) Refresh(refreshToken string) (*AuthTokens, error) {
+	stored, err := s.refreshTokenRepo.FindByHash(utils.HashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	if stored.RevokedAt != nil {
+		// Token reuse: someone is replaying a rotated token
+		return nil, s.endReusedSession(stored.UserID, stored.SessionID)
+	}
+	if !stored.IsActive(time.Now()) {
+		return nil, ErrInvalidRefreshToken // Expired
+	}
+
+	if err := s.refreshTokenRepo.Revoke(stored.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Rotated by a concurrent refresh since we read it: reused all the same
+			return nil, s.endReusedSession(stored.UserID, stored.SessionID)
+		}
+		return nil, err
+	}
+	return s.issueTokens(stored.UserID, stored.SessionID)
+}
+
+// endReusedSession revokes the session of a refresh token presented twice.
+func (s *AuthService) endReusedSession(userID uint, sessionID string) error {
+	if err := s.revokeSession(userID, sessionID); err != nil {
+		return err
+	}
+	return ErrInvalidRefreshToken
+}
+
+// revokeSession revokes one of the user's sessions and closes the WebSocket
+// connections opened with it.
+func (s *AuthService) revokeSession(userID uint, sessionID string) error {
+	if err := s.refreshTokenRepo.RevokeSession(sessionID); err != nil {
+		return err
+	}
+	disconnectSession(s.opts.Hub, userID, sessionID)
+	return nil
+}
+
+// Logout revokes the session the refresh token belongs to.
+// If allSessions is true, every session of the token's owner is revoked instead.
+func (s *AuthService) Logout(refreshToken string, allSessions bool) error {
+	stored, err := s.refreshTokenRepo.FindByHash(utils.HashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidRefreshToken
+		}
+		return err
+	}
+	if allSessions {
+		return s.RevokeAllSessions(stored.UserID)
+	}
+	return s.revokeSession(stored.UserID, stored.SessionID)
+}
+
+// RevokeAllSessions signs a user out everywhere, e.g. when their access must be
+// cut off, and closes their WebSocket connections.
+func (s *AuthService) RevokeAllSessions(userID uint) error {
+	if err := s.refreshTokenRepo.RevokeAllForUser(userID); err != nil {
+		return err
+	}
+	disconnectUser(s.opts.Hub, userID, realtime.AnyBoard)
+	return nil
+}
+
+// RequestPasswordReset emails a single-use reset link to the account with this email.
+// Unknown emails are silently ignored so the endpoint cannot be used to probe for accounts.
+func (s *AuthService) RequestPasswordReset(email string) error {
+	user, err := s.userRepo.FindByEmail(email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	// Only the most recent link should work
+	if err := s.userTokenRepo.DeleteForUser(user.ID, models.TokenPurposePasswordReset); err != nil {
+		return err
+	}
+	token, err := s.createUserToken(user.ID, models.TokenPurposePasswordReset, s.opts.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", strings.TrimRight(s.opts.AppBaseURL, "/"), token)
+	return s.mailer.Send(mailer.Message{
+		To:      []string{user.Email},
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone asked to reset the password for your account.\n"+
+			"Use the link below to choose a new one. It expires in %s.\n\n%s\n\n"+
+			"If you didn't ask for this, you can ignore this email.\n",
+			user.Username, s.opts.PasswordResetTTL, link),
+	})
+}
+
+// ResetPassword sets a new password using a token from RequestPasswordReset.
+// The token is consumed and all of the user's existing sessions and personal
+// access tokens are revoked.
+func (s *AuthService) ResetPassword(token, newPassword string) error {
+	stored, err := s.userTokenRepo.FindByHash(models.TokenPurposePasswordReset, utils.HashToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	if !stored.IsUsable(time.Now()) {
+		return ErrInvalidResetToken
+	}
+
+	user, err := s.userRepo.FindByID(stored.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken // Account deleted since the link was sent
+		}
+		return err
 	}
 
-	return user, token, nil
+	if err := s.userTokenRepo.MarkUsed(stored.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken // Redeemed concurrently
+		}
+		return err
+	}
+
+	hashedPassword, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+	user.Password = hashedPassword
+	if !user.EmailVerified {
+		markEmailVerified(user) // Following the emailed link proves they own the address
+	}
+	if err := s.userRepo.Update(user); err != nil {
+		return err
+	}
+	// Whoever knew the old password should not stay logged in
+	return s.revokeAllCredentials(user.ID)
+}
+
+// VerifyEmail marks the user's email as verified using a token from their verification email.
+func (s *AuthService) VerifyEmail(token string) (*models.User, error) {
+	stored, err := s.userTokenRepo.FindByHash(models.TokenPurposeEmailVerification, utils.HashToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidVerifyToken
+		}
+		return nil, err
+	}
+	if !stored.IsUsable(time.Now()) {
+		return nil, ErrInvalidVerifyToken
+	}
+
+	user, err := s.userRepo.FindByID(stored.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidVerifyToken
+		}
+		return nil, err
+	}
+	if err := s.userTokenRepo.MarkUsed(stored.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidVerifyToken
+		}
+		return nil, err
+	}
+
+	markEmailVerified(user)
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+	s.acceptPendingInvitations(user) // Held back at registration until the address was proven
+	return user, nil
+}
+
+// ResendVerificationEmail sends a fresh verification link.
+// Unknown or already verified emails are silently ignored.
+func (s *AuthService) ResendVerificationEmail(email string) error {
+	user, err := s.userRepo.FindByEmail(email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.EmailVerified {
+		return nil
+	}
+	return s.sendVerificationEmail(user)
+}
+
+// ValidateAccessToken parses an access token and checks that its session has not been revoked.
+func (s *AuthService) ValidateAccessToken(tokenString string) (*models.Claims, error) {
+	claims, err := utils.ValidateJWT(tokenString, s.opts.JWTKeys)
+	if err != nil {
+		return nil, err
+	}
+	if claims.SessionID == "" {
+		return nil, ErrSessionRevoked // Tokens issued before sessions existed cannot be revoked, so reject them
+	}
+	active, err := s.refreshTokenRepo.IsSessionActive(claims.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if !active {
+		return nil, ErrSessionRevoked
+	}
+	return claims, nil
+}
+
+// JWKS returns the public keys access tokens can be verified with.
+func (s *AuthService) JWKS() utils.JWKS {
+	return s.opts.JWTKeys.JWKS()
+}
+
+// IsAdmin reports whether the user is a site administrator.
+func (s *AuthService) IsAdmin(userID uint) (bool, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return user.IsAdmin, nil
+}
+
+// GrantAdmin makes the users with these emails site administrators.
+// Emails without an account are logged and skipped.
+func (s *AuthService) GrantAdmin(emails []string) error {
+	for _, email := range emails {
+		user, err := s.userRepo.FindByEmail(email)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Printf("WARN: cannot grant admin to %q: no such user", email)
+				continue
+			}
+			return err
+		}
+		if user.IsAdmin {
+			continue
+		}
+		user.IsAdmin = true
+		if err := s.userRepo.Update(user); err != nil {
+			return err
+		}
+		log.Printf("Granted admin to user %d (%s)", user.ID, user.Email)
+	}
+	return nil
+}
+
+// twoFactorChallenge starts the second step of a login for a user with two-factor auth.
+// The outstanding challenge (if any) is replaced, and returned inside a *TwoFactorRequiredError.
+func (s *AuthService) twoFactorChallenge(user *models.User) error {
+	if err := s.userTokenRepo.DeleteForUser(user.ID, models.TokenPurposeTwoFactorChallenge); err != nil {
+		return err
+	}
+	token, err := s.createUserToken(user.ID, models.TokenPurposeTwoFactorChallenge, s.opts.TwoFactorChallengeTTL)
+	if err != nil {
+		return err
+	}
+	return &TwoFactorRequiredError{
+		ChallengeToken: token,
+		ExpiresAt:      time.Now().Add(s.opts.TwoFactorChallengeTTL),
+	}
+}
+
+// startSession opens a new login session for the user and issues its first tokens.
+func (s *AuthService) startSession(userID uint) (*AuthTokens, error) {
+	sessionID, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	return s.issueTokens(userID, sessionID)
+}
+
+// issueTokens creates an access token and a stored refresh token for the session.
+func (s *AuthService) issueTokens(userID uint, sessionID string) (*AuthTokens, error) {
+	accessToken, err := utils.GenerateJWT(userID, sessionID, s.opts.AccessTokenTTL, s.opts.JWTKeys)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.refreshTokenRepo.Create(&models.RefreshToken{
+		UserID:    userID,
+		SessionID: sessionID,
+		TokenHash: utils.HashToken(refreshToken),
+		ExpiresAt: now.Add(s.opts.RefreshTokenTTL),
+	}); err != nil {
+		return nil, err
+	}
+
+	return &AuthTokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresAt:    now.Add(s.opts.AccessTokenTTL),
+	}, nil
+}
+
+// createUserToken stores a new emailed token for the user and returns its raw value.
+func (s *AuthService) createUserToken(userID uint, purpose models.TokenPurpose, ttl time.Duration) (string, error) {
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+	if err := s.userTokenRepo.Create(&models.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	}); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// revokeAllCredentials revokes every session and personal access token of the
+// user, after their password changed.
+func (s *AuthService) revokeAllCredentials(userID uint) error {
+	if err := s.RevokeAllSessions(userID); err != nil {
+		return err
+	}
+	if s.opts.PersonalAccessTokens == nil {
+		return nil
+	}
+	return s.opts.PersonalAccessTokens.RevokeAllForUser(userID)
+}
+
+// acceptPendingInvitations adds a newly verified user to the boards they were
+// invited to before they had an account. Failures don't stop the verification.
+func (s *AuthService) acceptPendingInvitations(user *models.User) {
+	if err := s.opts.Invitations.AcceptPendingInvitations(user); err != nil {
+		log.Printf("Failed to accept pending board invitations for user %d: %v", user.ID, err)
+	}
+}
+
+// sendVerificationEmail replaces any outstanding verification link with a new one and emails it.
+func (s *AuthService) sendVerificationEmail(user *models.User) error {
+	if err := s.userTokenRepo.DeleteForUser(user.ID, models.TokenPurposeEmailVerification); err != nil {
+		return err
+	}
+	token, err := s.createUserToken(user.ID, models.TokenPurposeEmailVerification, s.opts.EmailVerifyTTL)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", strings.TrimRight(s.opts.AppBaseURL, "/"), token)
+	return s.mailer.Send(mailer.Message{
+		To:      []string{user.Email},
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm this is your email address by opening the link below.\n"+
+			"It expires in %s.\n\n%s\n",
+			user.Username, s.opts.EmailVerifyTTL, link),
+	})
+}
+
+func markEmailVerified(user *models.User) {
+	now := time.Now()
+	user.EmailVerified = true
+	user.EmailVerifiedAt = &now
 }
@@ -2,9 +2,13 @@ package services
 
 import (
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/zayyadi/trello/mailer"
 	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/realtime"
 	"github.com/zayyadi/trello/repositories"
 	"github.com/zayyadi/trello/utils"
 
@@ -14,15 +18,18 @@ import (
 
 // MockUserRepository is a mock implementation of UserRepositoryInterface
 type MockUserRepository struct {
-	CreateFunc      func(user *models.User) error
-	FindByEmailFunc func(email string) (*models.User, error)
-	FindByIDFunc    func(id uint) (*models.User, error)
-	FindAllFunc     func() ([]models.User, error) // Add FindAllFunc
+	CreateFunc         func(user *models.User) error
+	FindByEmailFunc    func(email string) (*models.User, error)
+	FindByUsernameFunc func(username string) (*models.User, error)
+	FindByIDFunc       func(id uint) (*models.User, error)
+	FindAllFunc        func() ([]models.User, error) // Add FindAllFunc
+	UpdateFunc         func(user *models.User) error
 
 	// Store calls if needed for assertions
 	CreateCalledWith      *models.User
 	FindByEmailCalledWith string
 	FindByIDCalledWith    uint
+	UpdateCalledWith      *models.User
 }
 
 func (m *MockUserRepository) Create(user *models.User) error {
@@ -43,6 +50,13 @@ func (m *MockUserRepository) FindByEmail(email string) (*models.User, error) {
 	return nil, gorm.ErrRecordNotFound
 }
 
+func (m *MockUserRepository) FindByUsername(username string) (*models.User, error) {
+	if m.FindByUsernameFunc != nil {
+		return m.FindByUsernameFunc(username)
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
 func (m *MockUserRepository) FindByID(id uint) (*models.User, error) {
 	m.FindByIDCalledWith = id // Store the passed ID
 	if m.FindByIDFunc != nil {
@@ -59,12 +73,156 @@ func (m *MockUserRepository) FindAll() ([]models.User, error) {
 	return nil, nil // Default behavior: return empty slice, no error
 }
 
+func (m *MockUserRepository) Update(user *models.User) error {
+	m.UpdateCalledWith = user
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(user)
+	}
+	return nil
+}
+
 // Ensure MockUserRepository implements UserRepositoryInterface
 var _ repositories.UserRepositoryInterface = (*MockUserRepository)(nil)
 
+// MockRefreshTokenRepository is an in-memory implementation of RefreshTokenRepositoryInterface
+type MockRefreshTokenRepository struct {
+	Tokens []*models.RefreshToken
+
+	CreateErr               error
+	RevokeSessionCalledWith string
+	RevokeAllCalledWithUser uint
+}
+
+func (m *MockRefreshTokenRepository) Create(token *models.RefreshToken) error {
+	if m.CreateErr != nil {
+		return m.CreateErr
+	}
+	token.ID = uint(len(m.Tokens) + 1)
+	m.Tokens = append(m.Tokens, token)
+	return nil
+}
+
+func (m *MockRefreshTokenRepository) FindByHash(tokenHash string) (*models.RefreshToken, error) {
+	for _, t := range m.Tokens {
+		if t.TokenHash == tokenHash {
+			return t, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *MockRefreshTokenRepository) revokeWhere(match func(t *models.RefreshToken) bool) int {
+	now := time.Now()
+	revoked := 0
+	for _, t := range m.Tokens {
+		if t.RevokedAt == nil && match(t) {
+			t.RevokedAt = &now
+			revoked++
+		}
+	}
+	return revoked
+}
+
+func (m *MockRefreshTokenRepository) Revoke(id uint) error {
+	if m.revokeWhere(func(t *models.RefreshToken) bool { return t.ID == id }) == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (m *MockRefreshTokenRepository) RevokeSession(sessionID string) error {
+	m.RevokeSessionCalledWith = sessionID
+	m.revokeWhere(func(t *models.RefreshToken) bool { return t.SessionID == sessionID })
+	return nil
+}
+
+func (m *MockRefreshTokenRepository) RevokeAllForUser(userID uint) error {
+	m.RevokeAllCalledWithUser = userID
+	m.revokeWhere(func(t *models.RefreshToken) bool { return t.UserID == userID })
+	return nil
+}
+
+func (m *MockRefreshTokenRepository) IsSessionActive(sessionID string) (bool, error) {
+	for _, t := range m.Tokens {
+		if t.SessionID == sessionID && t.IsActive(time.Now()) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+var _ repositories.RefreshTokenRepositoryInterface = (*MockRefreshTokenRepository)(nil)
+
+// MockUserTokenRepository is an in-memory implementation of UserTokenRepositoryInterface
+type MockUserTokenRepository struct {
+	Tokens []*models.UserToken
+}
+
+func (m *MockUserTokenRepository) Create(token *models.UserToken) error {
+	token.ID = uint(len(m.Tokens) + 1)
+	m.Tokens = append(m.Tokens, token)
+	return nil
+}
+
+func (m *MockUserTokenRepository) FindByHash(purpose models.TokenPurpose, tokenHash string) (*models.UserToken, error) {
+	for _, t := range m.Tokens {
+		if t.Purpose == purpose && t.TokenHash == tokenHash {
+			return t, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *MockUserTokenRepository) MarkUsed(id uint) error {
+	for _, t := range m.Tokens {
+		if t.ID == id && t.UsedAt == nil {
+			now := time.Now()
+			t.UsedAt = &now
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (m *MockUserTokenRepository) IncrementAttempts(id uint) error {
+	for _, t := range m.Tokens {
+		if t.ID == id {
+			t.Attempts++
+		}
+	}
+	return nil
+}
+
+func (m *MockUserTokenRepository) DeleteForUser(userID uint, purpose models.TokenPurpose) error {
+	kept := m.Tokens[:0]
+	for _, t := range m.Tokens {
+		if t.UserID != userID || t.Purpose != purpose {
+			kept = append(kept, t)
+		}
+	}
+	m.Tokens = kept
+	return nil
+}
+
+var _ repositories.UserTokenRepositoryInterface = (*MockUserTokenRepository)(nil)
+
+// MockMailer records sent messages instead of delivering them
+type MockMailer struct {
+	Sent    []mailer.Message
+	SendErr error
+}
+
+func (m *MockMailer) Send(msg mailer.Message) error {
+	if m.SendErr != nil {
+		return m.SendErr
+	}
+	m.Sent = append(m.Sent, msg)
+	return nil
+}
+
 func TestAuthService_Register_EmailExists(t *testing.T) {
 	mockRepo := &MockUserRepository{}
-	authService := NewAuthService(mockRepo, "test-secret")
+	authService := NewAuthService(mockRepo, &MockRefreshTokenRepository{}, &MockUserTokenRepository{}, &MockMailer{}, AuthOptions{JWTSecretKey: "test-secret"})
 
 	// Configure mock responses
 	existingUser := &models.User{Model: gorm.Model{ID: 1}, Username: "existinguser", Email: "test@example.com"}
@@ -92,9 +250,22 @@ func TestAuthService_Register_EmailExists(t *testing.T) {
 	assert.Nil(t, mockRepo.CreateCalledWith)
 }
 
+func TestAuthService_Register_UsernameExists(t *testing.T) {
+	mockRepo := &MockUserRepository{}
+	authService := NewAuthService(mockRepo, &MockRefreshTokenRepository{}, &MockUserTokenRepository{}, &MockMailer{}, AuthOptions{JWTSecretKey: "test-secret"})
+	inMemoryUsers(mockRepo, &models.User{Model: gorm.Model{ID: 1}, Username: "existinguser", Email: "test@example.com"})
+
+	user, tokens, err := authService.Register("existinguser", "other@example.com", "password123")
+
+	assert.Equal(t, ErrUsernameExists, err)
+	assert.Nil(t, user)
+	assert.Nil(t, tokens)
+	assert.Nil(t, mockRepo.CreateCalledWith)
+}
+
 func TestAuthService_Register_CreateUserError(t *testing.T) {
 	mockRepo := &MockUserRepository{}
-	authService := NewAuthService(mockRepo, "test-secret")
+	authService := NewAuthService(mockRepo, &MockRefreshTokenRepository{}, &MockUserTokenRepository{}, &MockMailer{}, AuthOptions{JWTSecretKey: "test-secret"})
 
 	// Configure mock responses
 	mockRepo.FindByEmailFunc = func(email string) (*models.User, error) {
@@ -127,7 +298,7 @@ func TestAuthService_Register_CreateUserError(t *testing.T) {
 
 func TestAuthService_Register_Success(t *testing.T) {
 	mockRepo := &MockUserRepository{}
-	authService := NewAuthService(mockRepo, "test-secret")
+	authService := NewAuthService(mockRepo, &MockRefreshTokenRepository{}, &MockUserTokenRepository{}, &MockMailer{}, AuthOptions{JWTSecretKey: "test-secret"})
 
 	// Configure mock responses
 	mockRepo.FindByEmailFunc = func(email string) (*models.User, error) {
@@ -172,7 +343,7 @@ func TestAuthService_Register_Success(t *testing.T) {
 /*
 func TestAuthService_Register_PasswordHashingError(t *testing.T) {
 	mockRepo := &MockUserRepository{}
-	authService := NewAuthService(mockRepo, "test-secret")
+	authService := NewAuthService(mockRepo, &MockRefreshTokenRepository{}, &MockUserTokenRepository{}, &MockMailer{}, AuthOptions{JWTSecretKey: "test-secret"})
 
 	mockRepo.FindByEmailFunc = func(email string) (*models.User, error) {
 		return nil, gorm.ErrRecordNotFound
@@ -205,7 +376,7 @@ func TestAuthService_Register_PasswordHashingError(t *testing.T) {
 func TestAuthService_Register_TokenGenerationError(t *testing.T) {
 	mockRepo := &MockUserRepository{}
 	// Use the special secret key to force GenerateJWT to fail
-	authService := NewAuthService(mockRepo, "FORCE_JWT_ERROR_FOR_TEST")
+	authService := NewAuthService(mockRepo, &MockRefreshTokenRepository{}, &MockUserTokenRepository{}, &MockMailer{}, AuthOptions{JWTSecretKey: "FORCE_JWT_ERROR_FOR_TEST"})
 
 	mockRepo.FindByEmailFunc = func(email string) (*models.User, error) {
 		return nil, gorm.ErrRecordNotFound
@@ -230,7 +401,7 @@ func TestAuthService_Register_TokenGenerationError(t *testing.T) {
 
 func TestAuthService_Login_Success(t *testing.T) {
 	mockRepo := &MockUserRepository{}
-	authService := NewAuthService(mockRepo, "test-secret-login")
+	authService := NewAuthService(mockRepo, &MockRefreshTokenRepository{}, &MockUserTokenRepository{}, &MockMailer{}, AuthOptions{JWTSecretKey: "test-secret-login"})
 
 	testPassword := "password123"
 	hashedTestPassword, _ := utils.HashPassword(testPassword)
@@ -249,7 +420,7 @@ func TestAuthService_Login_Success(t *testing.T) {
 		return nil, gorm.ErrRecordNotFound
 	}
 
-	user, token, err := authService.Login(expectedUser.Email, testPassword)
+	user, token, err := authService.Login(expectedUser.Email, testPassword, "")
 
 	assert.NoError(t, err)
 	assert.NotNil(t, user)
@@ -262,7 +433,7 @@ func TestAuthService_Login_Success(t *testing.T) {
 
 func TestAuthService_Login_UserNotFound(t *testing.T) {
 	mockRepo := &MockUserRepository{}
-	authService := NewAuthService(mockRepo, "test-secret-login")
+	authService := NewAuthService(mockRepo, &MockRefreshTokenRepository{}, &MockUserTokenRepository{}, &MockMailer{}, AuthOptions{JWTSecretKey: "test-secret-login"})
 
 	mockRepo.FindByEmailFunc = func(email string) (*models.User, error) {
 		return nil, gorm.ErrRecordNotFound // Simulate user not found
@@ -271,7 +442,7 @@ func TestAuthService_Login_UserNotFound(t *testing.T) {
 	email := "nonexistent@example.com"
 	password := "password123"
 
-	user, token, err := authService.Login(email, password)
+	user, token, err := authService.Login(email, password, "")
 
 	assert.Error(t, err)
 	assert.Equal(t, ErrInvalidCredentials, err)
@@ -283,7 +454,7 @@ func TestAuthService_Login_UserNotFound(t *testing.T) {
 
 func TestAuthService_Login_IncorrectPassword(t *testing.T) {
 	mockRepo := &MockUserRepository{}
-	authService := NewAuthService(mockRepo, "test-secret-login")
+	authService := NewAuthService(mockRepo, &MockRefreshTokenRepository{}, &MockUserTokenRepository{}, &MockMailer{}, AuthOptions{JWTSecretKey: "test-secret-login"})
 
 	correctPassword := "correctPassword"
 	incorrectPassword := "incorrectPassword"
@@ -303,7 +474,7 @@ func TestAuthService_Login_IncorrectPassword(t *testing.T) {
 		return nil, gorm.ErrRecordNotFound
 	}
 
-	user, token, err := authService.Login(existingUser.Email, incorrectPassword)
+	user, token, err := authService.Login(existingUser.Email, incorrectPassword, "")
 
 	assert.Error(t, err)
 	assert.Equal(t, ErrInvalidCredentials, err)
@@ -317,7 +488,7 @@ func TestAuthService_Login_IncorrectPassword(t *testing.T) {
 func TestAuthService_Login_TokenGenerationError(t *testing.T) {
 	mockRepo := &MockUserRepository{}
 	// Use the special secret key to force GenerateJWT to fail
-	authService := NewAuthService(mockRepo, "FORCE_JWT_ERROR_FOR_TEST")
+	authService := NewAuthService(mockRepo, &MockRefreshTokenRepository{}, &MockUserTokenRepository{}, &MockMailer{}, AuthOptions{JWTSecretKey: "FORCE_JWT_ERROR_FOR_TEST"})
 
 	testPassword := "password123"
 	hashedTestPassword, _ := utils.HashPassword(testPassword)
@@ -336,7 +507,7 @@ func TestAuthService_Login_TokenGenerationError(t *testing.T) {
 		return nil, gorm.ErrRecordNotFound
 	}
 
-	user, token, err := authService.Login(existingUser.Email, testPassword)
+	user, token, err := authService.Login(existingUser.Email, testPassword, "")
 
 	assert.Error(t, err) // Expect an error from GenerateJWT
 	// User object might be available as FindByEmail and CheckPasswordHash succeeded
@@ -345,3 +516,388 @@ func TestAuthService_Login_TokenGenerationError(t *testing.T) {
 	// We might want to check for a specific error type if GenerateJWT returns one
 	// For example: assert.Equal(t, utils.ErrTokenGeneration, err)
 }
+
+// loginForTest logs in a freshly created user and returns the issued tokens.
+func loginForTest(t *testing.T, authService *AuthService, mockRepo *MockUserRepository) *AuthTokens {
+	hashedPassword, _ := utils.HashPassword("password123")
+	mockRepo.FindByEmailFunc = func(email string) (*models.User, error) {
+		return &models.User{Model: gorm.Model{ID: 7}, Email: email, Password: hashedPassword}, nil
+	}
+	_, tokens, err := authService.Login("login@example.com", "password123", "")
+	if err != nil {
+		t.Fatalf("login failed: %v", err)
+	}
+	return tokens
+}
+
+func TestAuthService_Login_IssuesRefreshToken(t *testing.T) {
+	mockRepo := &MockUserRepository{}
+	mockTokenRepo := &MockRefreshTokenRepository{}
+	authService := NewAuthService(mockRepo, mockTokenRepo, &MockUserTokenRepository{}, &MockMailer{}, AuthOptions{JWTSecretKey: "test-secret", AccessTokenTTL: time.Minute, RefreshTokenTTL: time.Hour})
+
+	tokens := loginForTest(t, authService, mockRepo)
+
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.NotEmpty(t, tokens.RefreshToken)
+	assert.Len(t, mockTokenRepo.Tokens, 1)
+	stored := mockTokenRepo.Tokens[0]
+	assert.Equal(t, uint(7), stored.UserID)
+	assert.Equal(t, utils.HashToken(tokens.RefreshToken), stored.TokenHash)
+	assert.NotEqual(t, tokens.RefreshToken, stored.TokenHash, "raw refresh token must not be stored")
+
+	claims, err := authService.ValidateAccessToken(tokens.AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(7), claims.UserID)
+	assert.Equal(t, stored.SessionID, claims.SessionID)
+}
+
+func TestAuthService_Refresh_RotatesToken(t *testing.T) {
+	mockRepo := &MockUserRepository{}
+	mockTokenRepo := &MockRefreshTokenRepository{}
+	authService := NewAuthService(mockRepo, mockTokenRepo, &MockUserTokenRepository{}, &MockMailer{}, AuthOptions{JWTSecretKey: "test-secret", AccessTokenTTL: time.Minute, RefreshTokenTTL: time.Hour})
+	tokens := loginForTest(t, authService, mockRepo)
+
+	newTokens, err := authService.Refresh(tokens.RefreshToken)
+
+	assert.NoError(t, err)
+	assert.NotEqual(t, tokens.RefreshToken, newTokens.RefreshToken)
+	assert.Len(t, mockTokenRepo.Tokens, 2)
+	assert.NotNil(t, mockTokenRepo.Tokens[0].RevokedAt, "old refresh token should be revoked")
+	assert.Nil(t, mockTokenRepo.Tokens[1].RevokedAt)
+	assert.Equal(t, mockTokenRepo.Tokens[0].SessionID, mockTokenRepo.Tokens[1].SessionID)
+
+	// Access tokens of the session remain valid after rotation
+	_, err = authService.ValidateAccessToken(tokens.AccessToken)
+	assert.NoError(t, err)
+}
+
+// userDisconnectRecordingHub records the users whose WebSocket clients are dropped.
+type userDisconnectRecordingHub struct {
+	MockHub
+	users    [][2]uint // User ID and board ID
+	sessions []string
+}
+
+func (h *userDisconnectRecordingHub) DisconnectUser(userID, boardID uint) {
+	h.users = append(h.users, [2]uint{userID, boardID})
+}
+
+func (h *userDisconnectRecordingHub) DisconnectSession(userID uint, sessionID string) {
+	h.sessions = append(h.sessions, sessionID)
+}
+
+func TestAuthService_Refresh_ReuseRevokesSession(t *testing.T) {
+	mockRepo := &MockUserRepository{}
+	mockTokenRepo := &MockRefreshTokenRepository{}
+	hub := &userDisconnectRecordingHub{}
+	authService := NewAuthService(mockRepo, mockTokenRepo, &MockUserTokenRepository{}, &MockMailer{}, AuthOptions{JWTSecretKey: "test-secret", AccessTokenTTL: time.Minute, RefreshTokenTTL: time.Hour, Hub: hub})
+	tokens := loginForTest(t, authService, mockRepo)
+
+	newTokens, err := authService.Refresh(tokens.RefreshToken)
+	assert.NoError(t, err)
+
+	// Replaying the rotated token ends the session
+	_, err = authService.Refresh(tokens.RefreshToken)
+	assert.Equal(t, ErrInvalidRefreshToken, err)
+	assert.Equal(t, mockTokenRepo.Tokens[0].SessionID, mockTokenRepo.RevokeSessionCalledWith)
+	assert.Equal(t, []string{mockTokenRepo.Tokens[0].SessionID}, hub.sessions, "the session's WebSockets are closed")
+
+	_, err = authService.Refresh(newTokens.RefreshToken)
+	assert.Equal(t, ErrInvalidRefreshToken, err)
+	_, err = authService.ValidateAccessToken(newTokens.AccessToken)
+	assert.Equal(t, ErrSessionRevoked, err)
+}
+
+// staleRefreshTokenRepository hands out tokens as they were before being revoked,
+// like a refresh that read its token just before a concurrent one rotated it.
+type staleRefreshTokenRepository struct {
+	*MockRefreshTokenRepository
+}
+
+func (m staleRefreshTokenRepository) FindByHash(tokenHash string) (*models.RefreshToken, error) {
+	token, err := m.MockRefreshTokenRepository.FindByHash(tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	stale := *token
+	stale.RevokedAt = nil
+	return &stale, nil
+}
+
+func TestAuthService_Refresh_ConcurrentReuseRevokesSession(t *testing.T) {
+	mockRepo := &MockUserRepository{}
+	mockTokenRepo := &MockRefreshTokenRepository{}
+	authService := NewAuthService(mockRepo, staleRefreshTokenRepository{mockTokenRepo}, &MockUserTokenRepository{}, &MockMailer{}, AuthOptions{JWTSecretKey: "test-secret", AccessTokenTTL: time.Minute, RefreshTokenTTL: time.Hour})
+	tokens := loginForTest(t, authService, mockRepo)
+
+	newTokens, err := authService.Refresh(tokens.RefreshToken)
+	assert.NoError(t, err)
+	_, err = authService.Refresh(tokens.RefreshToken)
+	assert.Equal(t, ErrInvalidRefreshToken, err, "only one of two refreshes gets a token pair")
+	assert.Equal(t, mockTokenRepo.Tokens[0].SessionID, mockTokenRepo.RevokeSessionCalledWith)
+	_, err = authService.ValidateAccessToken(newTokens.AccessToken)
+	assert.Equal(t, ErrSessionRevoked, err)
+}
+
+func TestAuthService_Refresh_Invalid(t *testing.T) {
+	mockRepo := &MockUserRepository{}
+	mockTokenRepo := &MockRefreshTokenRepository{}
+	authService := NewAuthService(mockRepo, mockTokenRepo, &MockUserTokenRepository{}, &MockMailer{}, AuthOptions{JWTSecretKey: "test-secret", AccessTokenTTL: time.Minute, RefreshTokenTTL: time.Hour})
+
+	mockTokenRepo.Tokens = append(mockTokenRepo.Tokens, &models.RefreshToken{
+		Model:     gorm.Model{ID: 1},
+		UserID:    7,
+		SessionID: "expired-session",
+		TokenHash: utils.HashToken("expired-token"),
+		ExpiresAt: time.Now().Add(-time.Minute),
+	})
+
+	tokens, err := authService.Refresh("expired-token")
+	assert.Equal(t, ErrInvalidRefreshToken, err)
+	assert.Nil(t, tokens)
+
+	tokens, err = authService.Refresh("unknown-token")
+	assert.Equal(t, ErrInvalidRefreshToken, err)
+	assert.Nil(t, tokens)
+}
+
+func TestAuthService_Logout_RevokesSession(t *testing.T) {
+	mockRepo := &MockUserRepository{}
+	mockTokenRepo := &MockRefreshTokenRepository{}
+	hub := &userDisconnectRecordingHub{}
+	authService := NewAuthService(mockRepo, mockTokenRepo, &MockUserTokenRepository{}, &MockMailer{}, AuthOptions{JWTSecretKey: "test-secret", AccessTokenTTL: time.Minute, RefreshTokenTTL: time.Hour, Hub: hub})
+	tokens := loginForTest(t, authService, mockRepo)
+	otherTokens := loginForTest(t, authService, mockRepo) // Second device
+
+	err := authService.Logout(tokens.RefreshToken, false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{mockTokenRepo.Tokens[0].SessionID}, hub.sessions, "the session's WebSockets are closed")
+	assert.Empty(t, hub.users)
+	_, err = authService.ValidateAccessToken(tokens.AccessToken)
+	assert.Equal(t, ErrSessionRevoked, err)
+	_, err = authService.Refresh(tokens.RefreshToken)
+	assert.Equal(t, ErrInvalidRefreshToken, err)
+
+	// Other sessions are unaffected
+	_, err = authService.ValidateAccessToken(otherTokens.AccessToken)
+	assert.NoError(t, err)
+}
+
+func TestAuthService_Logout_AllSessions(t *testing.T) {
+	mockRepo := &MockUserRepository{}
+	mockTokenRepo := &MockRefreshTokenRepository{}
+	hub := &userDisconnectRecordingHub{}
+	authService := NewAuthService(mockRepo, mockTokenRepo, &MockUserTokenRepository{}, &MockMailer{}, AuthOptions{JWTSecretKey: "test-secret", AccessTokenTTL: time.Minute, RefreshTokenTTL: time.Hour, Hub: hub})
+	tokens := loginForTest(t, authService, mockRepo)
+	otherTokens := loginForTest(t, authService, mockRepo)
+
+	err := authService.Logout(tokens.RefreshToken, true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(7), mockTokenRepo.RevokeAllCalledWithUser)
+	assert.Equal(t, [][2]uint{{7, realtime.AnyBoard}}, hub.users, "all of the user's WebSockets are closed")
+	_, err = authService.ValidateAccessToken(otherTokens.AccessToken)
+	assert.Equal(t, ErrSessionRevoked, err)
+}
+
+func TestAuthService_ValidateAccessToken_RejectsTokenWithoutSession(t *testing.T) {
+	authService := NewAuthService(&MockUserRepository{}, &MockRefreshTokenRepository{}, &MockUserTokenRepository{}, &MockMailer{}, AuthOptions{JWTSecretKey: "test-secret"})
+	token, err := utils.GenerateJWT(7, "", time.Minute, utils.NewHMACKeySet("test-secret"))
+	assert.NoError(t, err)
+
+	claims, err := authService.ValidateAccessToken(token)
+
+	assert.Equal(t, ErrSessionRevoked, err)
+	assert.Nil(t, claims)
+}
+
+func TestAuthService_PasswordReset_Success(t *testing.T) {
+	mockRepo := &MockUserRepository{}
+	mockTokenRepo := &MockRefreshTokenRepository{}
+	mockUserTokenRepo := &MockUserTokenRepository{}
+	mockMailer := &MockMailer{}
+	patRepo := &MockPersonalAccessTokenRepository{}
+	authService := NewAuthService(mockRepo, mockTokenRepo, mockUserTokenRepo, mockMailer, AuthOptions{JWTSecretKey: "test-secret", AppBaseURL: "https://app.example.com/", PersonalAccessTokens: patRepo})
+
+	session := loginForTest(t, authService, mockRepo)
+	assert.NoError(t, patRepo.Create(&models.PersonalAccessToken{UserID: 7, Name: "deploy script"}))
+	user := &models.User{Model: gorm.Model{ID: 7}, Username: "resetuser", Email: "reset@example.com"}
+	mockRepo.FindByEmailFunc = func(email string) (*models.User, error) { return user, nil }
+	mockRepo.FindByIDFunc = func(id uint) (*models.User, error) { return user, nil }
+
+	err := authService.RequestPasswordReset(user.Email)
+	assert.NoError(t, err)
+	assert.Len(t, mockMailer.Sent, 1)
+	assert.Equal(t, []string{user.Email}, mockMailer.Sent[0].To)
+	assert.Contains(t, mockMailer.Sent[0].Body, "https://app.example.com/reset-password?token=")
+
+	token := tokenFromMail(t, mockMailer.Sent[0], "/reset-password")
+	assert.NotEqual(t, token, mockUserTokenRepo.Tokens[0].TokenHash, "raw reset token must not be stored")
+
+	err = authService.ResetPassword(token, "newPassword123")
+	assert.NoError(t, err)
+	assert.NotNil(t, mockRepo.UpdateCalledWith)
+	assert.True(t, utils.CheckPasswordHash("newPassword123", mockRepo.UpdateCalledWith.Password))
+	assert.Equal(t, user.ID, mockTokenRepo.RevokeAllCalledWithUser)
+	_, err = authService.ValidateAccessToken(session.AccessToken)
+	assert.Equal(t, ErrSessionRevoked, err)
+	assert.NotNil(t, patRepo.Tokens[0].RevokedAt, "personal access tokens are revoked too")
+
+	// Tokens are single use
+	err = authService.ResetPassword(token, "anotherPassword")
+	assert.Equal(t, ErrInvalidResetToken, err)
+}
+
+func TestAuthService_RequestPasswordReset_UnknownEmail(t *testing.T) {
+	mockRepo := &MockUserRepository{} // FindByEmail defaults to not found
+	mockUserTokenRepo := &MockUserTokenRepository{}
+	mockMailer := &MockMailer{}
+	authService := NewAuthService(mockRepo, &MockRefreshTokenRepository{}, mockUserTokenRepo, mockMailer, AuthOptions{JWTSecretKey: "test-secret"})
+
+	err := authService.RequestPasswordReset("nobody@example.com")
+
+	assert.NoError(t, err, "unknown emails must not be revealed")
+	assert.Empty(t, mockMailer.Sent)
+	assert.Empty(t, mockUserTokenRepo.Tokens)
+}
+
+func TestAuthService_RequestPasswordReset_InvalidatesOlderLinks(t *testing.T) {
+	mockRepo := &MockUserRepository{}
+	mockMailer := &MockMailer{}
+	authService := NewAuthService(mockRepo, &MockRefreshTokenRepository{}, &MockUserTokenRepository{}, mockMailer, AuthOptions{JWTSecretKey: "test-secret"})
+
+	user := &models.User{Model: gorm.Model{ID: 7}, Email: "reset@example.com"}
+	mockRepo.FindByEmailFunc = func(email string) (*models.User, error) { return user, nil }
+	mockRepo.FindByIDFunc = func(id uint) (*models.User, error) { return user, nil }
+
+	assert.NoError(t, authService.RequestPasswordReset(user.Email))
+	assert.NoError(t, authService.RequestPasswordReset(user.Email))
+	oldToken := tokenFromMail(t, mockMailer.Sent[0], "/reset-password")
+	newToken := tokenFromMail(t, mockMailer.Sent[1], "/reset-password")
+
+	assert.Equal(t, ErrInvalidResetToken, authService.ResetPassword(oldToken, "newPassword123"))
+	assert.NoError(t, authService.ResetPassword(newToken, "newPassword123"))
+}
+
+func TestAuthService_ResetPassword_Expired(t *testing.T) {
+	mockRepo := &MockUserRepository{}
+	mockUserTokenRepo := &MockUserTokenRepository{}
+	authService := NewAuthService(mockRepo, &MockRefreshTokenRepository{}, mockUserTokenRepo, &MockMailer{}, AuthOptions{JWTSecretKey: "test-secret"})
+
+	mockUserTokenRepo.Tokens = append(mockUserTokenRepo.Tokens, &models.UserToken{
+		Model:     gorm.Model{ID: 1},
+		UserID:    7,
+		Purpose:   models.TokenPurposePasswordReset,
+		TokenHash: utils.HashToken("expired-token"),
+		ExpiresAt: time.Now().Add(-time.Minute),
+	})
+
+	err := authService.ResetPassword("expired-token", "newPassword123")
+
+	assert.Equal(t, ErrInvalidResetToken, err)
+	assert.Nil(t, mockRepo.UpdateCalledWith)
+}
+
+// tokenFromMail extracts the raw token from the first link containing path in a sent email.
+func tokenFromMail(t *testing.T, msg mailer.Message, path string) string {
+	marker := path + "?token="
+	i := strings.Index(msg.Body, marker)
+	if i < 0 {
+		t.Fatalf("%s link not found in mail body: %q", path, msg.Body)
+	}
+	return strings.Fields(msg.Body[i+len(marker):])[0]
+}
+
+// registerForTest registers a user against an in-memory user store and returns the stored user.
+func registerForTest(t *testing.T, authService *AuthService, mockRepo *MockUserRepository) (*models.User, *AuthTokens) {
+	var stored *models.User
+	mockRepo.CreateFunc = func(user *models.User) error {
+		user.ID = 7
+		stored = user
+		return nil
+	}
+	mockRepo.FindByEmailFunc = func(email string) (*models.User, error) {
+		if stored != nil && stored.Email == email {
+			return stored, nil
+		}
+		return nil, gorm.ErrRecordNotFound
+	}
+	mockRepo.FindByIDFunc = func(id uint) (*models.User, error) { return stored, nil }
+
+	user, tokens, err := authService.Register("verifyuser", "verify@example.com", "password123")
+	if err != nil {
+		t.Fatalf("register failed: %v", err)
+	}
+	return user, tokens
+}
+
+func TestAuthService_Register_SendsVerificationEmail(t *testing.T) {
+	mockRepo := &MockUserRepository{}
+	mockMailer := &MockMailer{}
+	authService := NewAuthService(mockRepo, &MockRefreshTokenRepository{}, &MockUserTokenRepository{}, mockMailer, AuthOptions{JWTSecretKey: "test-secret"})
+
+	user, tokens := registerForTest(t, authService, mockRepo)
+
+	assert.False(t, user.EmailVerified)
+	assert.NotNil(t, tokens, "login is not blocked unless RequireVerifiedEmail is set")
+	assert.Len(t, mockMailer.Sent, 1)
+	assert.Equal(t, []string{"verify@example.com"}, mockMailer.Sent[0].To)
+
+	verified, err := authService.VerifyEmail(tokenFromMail(t, mockMailer.Sent[0], "/verify-email"))
+	assert.NoError(t, err)
+	assert.True(t, verified.EmailVerified)
+	assert.NotNil(t, verified.EmailVerifiedAt)
+	assert.Equal(t, verified, mockRepo.UpdateCalledWith)
+
+	// Tokens are single use
+	_, err = authService.VerifyEmail(tokenFromMail(t, mockMailer.Sent[0], "/verify-email"))
+	assert.Equal(t, ErrInvalidVerifyToken, err)
+}
+
+func TestAuthService_RequireVerifiedEmail_BlocksLogin(t *testing.T) {
+	mockRepo := &MockUserRepository{}
+	mockMailer := &MockMailer{}
+	authService := NewAuthService(mockRepo, &MockRefreshTokenRepository{}, &MockUserTokenRepository{}, mockMailer, AuthOptions{JWTSecretKey: "test-secret", RequireVerifiedEmail: true})
+
+	user, tokens := registerForTest(t, authService, mockRepo)
+	assert.NotNil(t, user)
+	assert.Nil(t, tokens, "registration must not log in unverified users")
+
+	loggedIn, tokens, err := authService.Login("verify@example.com", "password123", "")
+	assert.Equal(t, ErrEmailNotVerified, err)
+	assert.Nil(t, loggedIn)
+	assert.Nil(t, tokens)
+
+	// Wrong password still reports invalid credentials, not the verification state
+	_, _, err = authService.Login("verify@example.com", "wrongPassword", "")
+	assert.Equal(t, ErrInvalidCredentials, err)
+
+	_, err = authService.VerifyEmail(tokenFromMail(t, mockMailer.Sent[0], "/verify-email"))
+	assert.NoError(t, err)
+
+	_, tokens, err = authService.Login("verify@example.com", "password123", "")
+	assert.NoError(t, err)
+	assert.NotNil(t, tokens)
+}
+
+func TestAuthService_ResendVerificationEmail(t *testing.T) {
+	mockRepo := &MockUserRepository{}
+	mockMailer := &MockMailer{}
+	authService := NewAuthService(mockRepo, &MockRefreshTokenRepository{}, &MockUserTokenRepository{}, mockMailer, AuthOptions{JWTSecretKey: "test-secret"})
+	registerForTest(t, authService, mockRepo)
+
+	assert.NoError(t, authService.ResendVerificationEmail("verify@example.com"))
+	assert.Len(t, mockMailer.Sent, 2)
+
+	// Only the newest link works
+	_, err := authService.VerifyEmail(tokenFromMail(t, mockMailer.Sent[0], "/verify-email"))
+	assert.Equal(t, ErrInvalidVerifyToken, err)
+	_, err = authService.VerifyEmail(tokenFromMail(t, mockMailer.Sent[1], "/verify-email"))
+	assert.NoError(t, err)
+
+	// Verified and unknown addresses get no mail
+	assert.NoError(t, authService.ResendVerificationEmail("verify@example.com"))
+	assert.NoError(t, authService.ResendVerificationEmail("nobody@example.com"))
+	assert.Len(t, mockMailer.Sent, 2)
+}
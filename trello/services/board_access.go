@@ -0,0 +1,146 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/policy"
+	"github.com/zayyadi/trello/repositories"
+
+	"gorm.io/gorm"
+)
+
+// resolveBoardSubject loads the board and works out userID's relationship to it.
+// The board owner is always treated as an admin, regardless of their membership row.
+// On a workspace-visible or public board, members of the workspace get the role the policy
+// derives from their workspace role, unless their own board role is higher.
+// Users with no relationship to the board get ErrForbidden.
+func resolveBoardSubject(
+	boardRepo repositories.BoardRepositoryInterface,
+	boardMemberRepo repositories.BoardMemberRepositoryInterface,
+	boardID, userID uint,
+) (*models.Board, policy.Subject, error) {
+	board, err := boardRepo.FindByID(boardID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, policy.Subject{}, ErrBoardNotFound
+		}
+		return nil, policy.Subject{}, err
+	}
+	if board.OwnerID == userID {
+		return board, policy.Subject{UserID: userID, Role: models.BoardRoleAdmin, IsOwner: true}, nil
+	}
+	role, err := boardMemberRepo.GetRole(boardID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		role = "" // Not a member in their own right
+	} else if err != nil {
+		return nil, policy.Subject{}, err
+	}
+	if board.WorkspaceID != nil && board.Visibility.SharedWithWorkspace() {
+		workspaceRole, err := boardMemberRepo.GetWorkspaceRole(boardID, userID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, policy.Subject{}, err
+		}
+		if derived := policy.WorkspaceBoardRole(workspaceRole); derived != "" && !role.AtLeast(derived) {
+			role = derived
+		}
+	}
+	if role == "" {
+		return nil, policy.Subject{}, ErrForbidden // Not the owner, a member or in the board's workspace
+	}
+	return board, policy.Subject{UserID: userID, Role: role}, nil
+}
+
+// archivedBoardActions are the actions still possible on an archived board.
+// Everything else fails with ErrBoardArchived until the board is unarchived.
+var archivedBoardActions = map[policy.Action]bool{
+	policy.ViewBoard:         true,
+	policy.ArchiveBoard:      true, // To unarchive it
+	policy.DeleteBoard:       true,
+	policy.ManageMembers:     true,
+	policy.TransferOwnership: true,
+	policy.CopyBoard:         true,
+	policy.ShareBoard:        true, // Sharing only lets people look
+}
+
+// authorizeBoardAction resolves userID's relationship to the board and checks
+// it against the central policy. It fails with ErrForbidden if action is not allowed,
+// and with ErrBoardArchived if the board is archived and action would change its contents.
+func authorizeBoardAction(
+	boardRepo repositories.BoardRepositoryInterface,
+	boardMemberRepo repositories.BoardMemberRepositoryInterface,
+	boardID, userID uint,
+	action policy.Action,
+) (*models.Board, policy.Subject, error) {
+	board, subject, err := resolveBoardSubject(boardRepo, boardMemberRepo, boardID, userID)
+	if err != nil {
+		return nil, policy.Subject{}, err
+	}
+	if !policy.Can(subject, action) {
+		return nil, policy.Subject{}, ErrForbidden
+	}
+	if board.ArchivedAt != nil && !archivedBoardActions[action] {
+		return nil, policy.Subject{}, ErrBoardArchived
+	}
+	return board, subject, nil
+}
+
+// ensureBoardWritable fails with ErrBoardArchived if the board is archived. It is for
+// services that authorize with a read action and apply the policy to changes themselves.
+func ensureBoardWritable(boardRepo repositories.BoardRepositoryInterface, boardID uint) error {
+	board, err := boardRepo.FindByID(boardID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrBoardNotFound
+		}
+		return err
+	}
+	if board.ArchivedAt != nil {
+		return ErrBoardArchived
+	}
+	return nil
+}
+
+// authorizeCardAction finds the board the card is on and checks action against the
+// policy like authorizeBoardAction, counting the user's part in the card: its
+// collaborators and assignee get the lower role card actions allow them.
+// It returns the card's board ID.
+func authorizeCardAction(
+	cardRepo repositories.CardRepositoryInterface,
+	listRepo repositories.ListRepositoryInterface,
+	boardRepo repositories.BoardRepositoryInterface,
+	boardMemberRepo repositories.BoardMemberRepositoryInterface,
+	cardID, userID uint,
+	action policy.Action,
+) (uint, error) {
+	listID, err := cardRepo.GetListIDByCardID(cardID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, ErrCardNotFound
+		}
+		return 0, err
+	}
+	boardID, err := listRepo.GetBoardIDByListID(listID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, ErrListNotFound
+		}
+		return 0, err
+	}
+	board, subject, err := resolveBoardSubject(boardRepo, boardMemberRepo, boardID, userID)
+	if err != nil {
+		return 0, err
+	}
+	participant, err := cardRepo.IsUserCollaboratorOrAssignee(cardID, userID)
+	if err != nil {
+		return 0, err
+	}
+	subject.IsCardParticipant = participant
+	if !policy.Can(subject, action) {
+		return 0, ErrForbidden
+	}
+	if board.ArchivedAt != nil && !archivedBoardActions[action] {
+		return 0, ErrBoardArchived
+	}
+	return boardID, nil
+}
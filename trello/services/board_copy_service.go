@@ -0,0 +1,151 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zayyadi/trello/dto"
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/policy"
+	"github.com/zayyadi/trello/realtime"
+	"github.com/zayyadi/trello/repositories"
+
+	"gorm.io/gorm"
+)
+
+// BoardCopyService copies boards into new boards owned by the caller, and manages
+// templates: boards marked to be copied by anyone who can see them.
+type BoardCopyService struct {
+	copyRepo            repositories.BoardCopyRepositoryInterface
+	boardRepo           repositories.BoardRepositoryInterface
+	boardMemberRepo     repositories.BoardMemberRepositoryInterface
+	workspaceMemberRepo repositories.WorkspaceMemberRepositoryInterface
+	hub                 realtime.Broadcaster
+}
+
+func NewBoardCopyService(
+	copyRepo repositories.BoardCopyRepositoryInterface,
+	boardRepo repositories.BoardRepositoryInterface,
+	boardMemberRepo repositories.BoardMemberRepositoryInterface,
+	workspaceMemberRepo repositories.WorkspaceMemberRepositoryInterface,
+	hub realtime.Broadcaster,
+) *BoardCopyService {
+	return &BoardCopyService{
+		copyRepo:            copyRepo,
+		boardRepo:           boardRepo,
+		boardMemberRepo:     boardMemberRepo,
+		workspaceMemberRepo: workspaceMemberRepo,
+		hub:                 hub,
+	}
+}
+
+// CopyBoard creates a private board owned by the caller with the source board's
+// lists, and optionally its cards with their collaborators and comments. Anyone who
+// can see the source board may copy it. The copy stays in the source board's
+// workspace if the caller may create boards there, and is never a template itself.
+// An empty name defaults to the template's name, or "<name> (copy)" for other boards.
+func (s *BoardCopyService) CopyBoard(sourceID uint, name string, opts repositories.BoardCopyOptions, userID uint) (*models.Board, error) {
+	source, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, sourceID, userID, policy.CopyBoard)
+	if err != nil {
+		return nil, err
+	}
+	if !opts.Cards && (opts.Collaborators || opts.Comments) {
+		return nil, fmt.Errorf("%w: collaborators and comments can only be copied with the cards", ErrInvalidInput)
+	}
+	if name == "" {
+		name = source.Name
+		if !source.IsTemplate {
+			name += " (copy)"
+		}
+	}
+
+	board := &models.Board{
+		Name:        name,
+		Description: source.Description,
+		OwnerID:     userID,
+		Visibility:  models.BoardVisibilityPrivate,
+	}
+	if source.WorkspaceID != nil {
+		inWorkspace, err := s.canCreateInWorkspace(*source.WorkspaceID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if inWorkspace {
+			board.WorkspaceID = source.WorkspaceID
+		}
+	}
+	if err := s.copyRepo.Copy(sourceID, board, opts); err != nil {
+		return nil, err
+	}
+	createdBoard, err := s.boardRepo.FindByID(board.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	broadcastMessage(
+		s.hub,
+		createdBoard.ID,
+		realtime.MessageTypeBoardCreated,
+		dto.MapBoardToResponse(createdBoard, true, false),
+		userID,
+	)
+	return createdBoard, nil
+}
+
+// SetTemplate marks the board as a template, or makes it a normal board again.
+// Admins (including the owner) may change it.
+func (s *BoardCopyService) SetTemplate(boardID uint, isTemplate bool, userID uint) (*models.Board, error) {
+	board, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, boardID, userID, policy.UpdateBoard)
+	if err != nil {
+		return nil, err
+	}
+	if board.IsTemplate == isTemplate {
+		return board, nil
+	}
+
+	board.IsTemplate = isTemplate
+	if err := s.boardRepo.Update(board); err != nil {
+		return nil, err
+	}
+	updatedBoard, err := s.boardRepo.FindByID(board.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	broadcastMessage(
+		s.hub,
+		updatedBoard.ID,
+		realtime.MessageTypeBoardUpdated,
+		dto.MapBoardToResponse(updatedBoard, true, false),
+		userID,
+	)
+	return updatedBoard, nil
+}
+
+// GetTemplates lists the templates the user can see and so start boards from,
+// leaving out archived ones.
+func (s *BoardCopyService) GetTemplates(userID uint) ([]models.Board, error) {
+	boards, err := s.boardRepo.FindByOwnerOrMember(userID)
+	if err != nil {
+		return nil, err
+	}
+	templates := make([]models.Board, 0, len(boards))
+	for _, board := range filterArchivedBoards(boards, false) {
+		if board.IsTemplate {
+			templates = append(templates, board)
+		}
+	}
+	return templates, nil
+}
+
+// canCreateInWorkspace reports whether the user may create boards in the workspace.
+func (s *BoardCopyService) canCreateInWorkspace(workspaceID, userID uint) (bool, error) {
+	role, err := s.workspaceMemberRepo.GetRole(workspaceID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return policy.CanInWorkspace(policy.WorkspaceSubject{UserID: userID, Role: role}, policy.CreateWorkspaceBoard), nil
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/repositories"
+	"gorm.io/gorm"
+)
+
+// boardCopyTestEnv runs the copy service on SQLite. Alice owns a board with two
+// lists, Bob is a member and Carol is not on the board.
+type boardCopyTestEnv struct {
+	db     *gorm.DB
+	copier *BoardCopyService
+	boards BoardServiceInterface
+	lists  *ListService
+	cards  CardServiceInterface
+	board  *models.Board
+	alice  uint
+	bob    uint
+	carol  uint
+}
+
+func newBoardCopyTestEnv(t *testing.T) *boardCopyTestEnv {
+	db := setupTestDB(t)
+	userRepo := repositories.NewUserRepository(db)
+	boardRepo := repositories.NewBoardRepository(db)
+	boardMemberRepo := repositories.NewBoardMemberRepository(db)
+	listRepo := repositories.NewListRepository(db)
+	cardRepo := repositories.NewCardRepository(db)
+
+	env := &boardCopyTestEnv{
+		db:     db,
+		copier: NewBoardCopyService(repositories.NewBoardCopyRepository(db), boardRepo, boardMemberRepo, repositories.NewWorkspaceMemberRepository(db), &MockHub{}),
+		boards: NewBoardService(boardRepo, userRepo, boardMemberRepo, &MockHub{}, false),
+		lists:  NewListService(listRepo, boardRepo, boardMemberRepo, &MockHub{}),
+		cards:  NewCardService(cardRepo, listRepo, boardRepo, boardMemberRepo, userRepo, &MockHub{}),
+	}
+	env.alice = createTestUser(t, userRepo, "alice")
+	env.bob = createTestUser(t, userRepo, "bob")
+	env.carol = createTestUser(t, userRepo, "carol")
+	board := createTestBoard(t, env.boards, "Sprint", "Two weeks", env.alice, map[uint]models.BoardRole{env.bob: models.BoardRoleViewer})
+	env.board = board
+	return env
+}
+
+func (env *boardCopyTestEnv) createList(t *testing.T, name string) *models.List {
+	t.Helper()
+	list, err := env.lists.CreateList(name, env.board.ID, env.alice, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return list
+}
+
+func TestBoardCopyService_CopyBoardWithCards(t *testing.T) {
+	env := newBoardCopyTestEnv(t)
+	todo := env.createList(t, "To do")
+	env.createList(t, "Done")
+	old := env.createList(t, "Old")
+	assert.NoError(t, env.db.Model(old).Update("archived_at", gorm.Expr("CURRENT_TIMESTAMP")).Error)
+	task, err := env.cards.CreateCard(todo.ID, "Plan", "Sprint planning", nil, nil, &env.alice, nil, nil, env.alice)
+	assert.NoError(t, err)
+	_, err = env.cards.CreateCard(todo.ID, "Review", "", nil, nil, nil, nil, nil, env.alice)
+	assert.NoError(t, err)
+	assert.NoError(t, env.db.Create(&models.CardCollaborator{CardID: task.ID, UserID: env.carol}).Error)
+	assert.NoError(t, env.db.Create(&models.Comment{CardID: task.ID, UserID: env.carol, Content: "Agenda?"}).Error)
+
+	opts := repositories.BoardCopyOptions{Cards: true, Collaborators: true, Comments: true}
+	copied, err := env.copier.CopyBoard(env.board.ID, "", opts, env.bob)
+	assert.NoError(t, err, "viewers may copy what they can see")
+	assert.Equal(t, "Sprint (copy)", copied.Name)
+	assert.Equal(t, "Two weeks", copied.Description)
+	assert.Equal(t, env.bob, copied.OwnerID)
+	assert.Equal(t, models.BoardVisibilityPrivate, copied.Visibility)
+
+	lists, err := env.lists.GetListsByBoardID(copied.ID, env.bob)
+	assert.NoError(t, err)
+	if !assert.Len(t, lists, 2, "archived lists stay behind") {
+		return
+	}
+	assert.Equal(t, "To do", lists[0].Name)
+	assert.Less(t, lists[0].Position, lists[1].Position)
+	cards, err := env.cards.GetCardsByListID(lists[0].ID, env.bob)
+	assert.NoError(t, err)
+	if !assert.Len(t, cards, 2) {
+		return
+	}
+	assert.Equal(t, "Plan", cards[0].Title)
+	assert.Nil(t, cards[0].AssignedUserID, "Alice isn't on Bob's copy")
+	assert.Empty(t, cards[0].Collaborators)
+	assert.NotEqual(t, task.ID, cards[0].ID)
+
+	var comments []models.Comment
+	assert.NoError(t, env.db.Where("card_id = ?", cards[0].ID).Find(&comments).Error)
+	if assert.Len(t, comments, 1) {
+		assert.Equal(t, env.carol, comments[0].UserID)
+	}
+	for _, userID := range []uint{env.alice, env.carol} {
+		_, err := env.boards.GetBoardByID(copied.ID, userID)
+		assert.ErrorIs(t, err, ErrForbidden, "people on the copied cards don't join the copy")
+	}
+
+	// People on the copy stay on their cards
+	mine, err := env.copier.CopyBoard(env.board.ID, "Mine", opts, env.alice)
+	assert.NoError(t, err)
+	lists, err = env.lists.GetListsByBoardID(mine.ID, env.alice)
+	assert.NoError(t, err)
+	cards, err = env.cards.GetCardsByListID(lists[0].ID, env.alice)
+	assert.NoError(t, err)
+	if assert.Len(t, cards, 2) {
+		assert.Equal(t, &env.alice, cards[0].AssignedUserID)
+		assert.Empty(t, cards[0].Collaborators)
+	}
+
+	_, err = env.copier.CopyBoard(env.board.ID, "Mine", opts, env.carol)
+	assert.ErrorIs(t, err, ErrForbidden, "only people who can see the board copy it")
+}
+
+func TestBoardCopyService_ListsOnly(t *testing.T) {
+	env := newBoardCopyTestEnv(t)
+	todo := env.createList(t, "To do")
+	_, err := env.cards.CreateCard(todo.ID, "Plan", "", nil, nil, nil, nil, nil, env.alice)
+	assert.NoError(t, err)
+
+	_, err = env.copier.CopyBoard(env.board.ID, "", repositories.BoardCopyOptions{Comments: true}, env.alice)
+	assert.ErrorIs(t, err, ErrInvalidInput, "comments need cards")
+	copied, err := env.copier.CopyBoard(env.board.ID, "Next sprint", repositories.BoardCopyOptions{}, env.alice)
+	assert.NoError(t, err)
+	assert.Equal(t, "Next sprint", copied.Name)
+
+	lists, err := env.lists.GetListsByBoardID(copied.ID, env.alice)
+	assert.NoError(t, err)
+	if assert.Len(t, lists, 1) {
+		cards, err := env.cards.GetCardsByListID(lists[0].ID, env.alice)
+		assert.NoError(t, err)
+		assert.Empty(t, cards)
+	}
+}
+
+func TestBoardCopyService_Templates(t *testing.T) {
+	env := newBoardCopyTestEnv(t)
+	env.createList(t, "Backlog")
+
+	_, err := env.copier.SetTemplate(env.board.ID, true, env.bob)
+	assert.ErrorIs(t, err, ErrForbidden, "only admins make templates")
+	template, err := env.copier.SetTemplate(env.board.ID, true, env.alice)
+	assert.NoError(t, err)
+	assert.True(t, template.IsTemplate)
+
+	templates, err := env.copier.GetTemplates(env.bob)
+	assert.NoError(t, err)
+	assert.Len(t, templates, 1)
+	templates, err = env.copier.GetTemplates(env.carol)
+	assert.NoError(t, err)
+	assert.Empty(t, templates)
+
+	board, err := env.copier.CopyBoard(env.board.ID, "", repositories.BoardCopyOptions{}, env.bob)
+	assert.NoError(t, err)
+	assert.Equal(t, "Sprint", board.Name, "boards started from a template take its name")
+	assert.False(t, board.IsTemplate)
+	templates, err = env.copier.GetTemplates(env.bob)
+	assert.NoError(t, err)
+	assert.Len(t, templates, 1, "the new board is not a template")
+}
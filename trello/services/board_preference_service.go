@@ -0,0 +1,167 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/policy"
+	"github.com/zayyadi/trello/repositories"
+)
+
+const (
+	defaultRecentBoardsLimit = 10
+	maxRecentBoardsLimit     = 50
+)
+
+// UserBoard is a board together with the current user's own settings for it.
+// Preference is the zero value if the user never starred, placed or opened the board.
+type UserBoard struct {
+	Board      models.Board
+	Preference models.BoardPreference
+}
+
+// BoardPreferenceService keeps each user's own board settings: starred boards, the
+// order of their board list and when they last opened each board.
+type BoardPreferenceService struct {
+	prefRepo        repositories.BoardPreferenceRepositoryInterface
+	boardRepo       repositories.BoardRepositoryInterface
+	boardMemberRepo repositories.BoardMemberRepositoryInterface
+	now             func() time.Time // Replaced in tests
+}
+
+func NewBoardPreferenceService(
+	prefRepo repositories.BoardPreferenceRepositoryInterface,
+	boardRepo repositories.BoardRepositoryInterface,
+	boardMemberRepo repositories.BoardMemberRepositoryInterface,
+) *BoardPreferenceService {
+	return &BoardPreferenceService{
+		prefRepo:        prefRepo,
+		boardRepo:       boardRepo,
+		boardMemberRepo: boardMemberRepo,
+		now:             time.Now,
+	}
+}
+
+// GetBoardsForUser lists the user's boards, leaving out archived ones. Starred
+// boards come first, then boards in the order the user put them, then the most
+// recently viewed, and the rest by name.
+func (s *BoardPreferenceService) GetBoardsForUser(userID uint) ([]UserBoard, error) {
+	boards, err := s.userBoards(userID)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(boards, func(i, j int) bool {
+		a, b := boards[i].Preference, boards[j].Preference
+		if a.Starred != b.Starred {
+			return a.Starred
+		}
+		if (a.Position == nil) != (b.Position == nil) {
+			return a.Position != nil
+		}
+		if a.Position != nil && *a.Position != *b.Position {
+			return *a.Position < *b.Position
+		}
+		if (a.LastViewedAt == nil) != (b.LastViewedAt == nil) {
+			return a.LastViewedAt != nil
+		}
+		if a.LastViewedAt != nil && !a.LastViewedAt.Equal(*b.LastViewedAt) {
+			return a.LastViewedAt.After(*b.LastViewedAt)
+		}
+		return strings.ToLower(boards[i].Board.Name) < strings.ToLower(boards[j].Board.Name)
+	})
+	return boards, nil
+}
+
+// GetRecentBoards lists up to limit of the user's boards they opened, most recently
+// viewed first. A limit of zero or less uses the default.
+func (s *BoardPreferenceService) GetRecentBoards(userID uint, limit int) ([]UserBoard, error) {
+	if limit <= 0 {
+		limit = defaultRecentBoardsLimit
+	}
+	if limit > maxRecentBoardsLimit {
+		limit = maxRecentBoardsLimit
+	}
+	boards, err := s.userBoards(userID)
+	if err != nil {
+		return nil, err
+	}
+	recent := make([]UserBoard, 0, len(boards))
+	for _, board := range boards {
+		if board.Preference.LastViewedAt != nil {
+			recent = append(recent, board)
+		}
+	}
+	sort.SliceStable(recent, func(i, j int) bool {
+		return recent[i].Preference.LastViewedAt.After(*recent[j].Preference.LastViewedAt)
+	})
+	if len(recent) > limit {
+		recent = recent[:limit]
+	}
+	return recent, nil
+}
+
+// SetStarred stars or unstars a board the user can see.
+func (s *BoardPreferenceService) SetStarred(boardID uint, starred bool, userID uint) error {
+	if _, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, boardID, userID, policy.ViewBoard); err != nil {
+		return err
+	}
+	return s.prefRepo.SetStarred(userID, boardID, starred)
+}
+
+// RecordView notes that the user just opened a board they can see.
+func (s *BoardPreferenceService) RecordView(boardID, userID uint) error {
+	if _, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, boardID, userID, policy.ViewBoard); err != nil {
+		return err
+	}
+	return s.prefRepo.SetLastViewed(userID, boardID, s.now())
+}
+
+// SetBoardOrder puts boardIDs first, in that order, in the user's board list. Each
+// must be one of the user's unarchived boards; boards left out lose their place.
+func (s *BoardPreferenceService) SetBoardOrder(boardIDs []uint, userID uint) error {
+	boards, err := s.userBoards(userID)
+	if err != nil {
+		return err
+	}
+	visible := make(map[uint]bool, len(boards))
+	for _, board := range boards {
+		visible[board.Board.ID] = true
+	}
+	seen := make(map[uint]bool, len(boardIDs))
+	for _, boardID := range boardIDs {
+		if !visible[boardID] {
+			return fmt.Errorf("%w: board %d is not one of your boards", ErrInvalidInput, boardID)
+		}
+		if seen[boardID] {
+			return fmt.Errorf("%w: board %d is listed more than once", ErrInvalidInput, boardID)
+		}
+		seen[boardID] = true
+	}
+	return s.prefRepo.SetOrder(userID, boardIDs)
+}
+
+// userBoards returns the user's unarchived boards with their preferences.
+func (s *BoardPreferenceService) userBoards(userID uint) ([]UserBoard, error) {
+	boards, err := s.boardRepo.FindByOwnerOrMember(userID)
+	if err != nil {
+		return nil, err
+	}
+	prefs, err := s.prefRepo.FindByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	byBoard := make(map[uint]models.BoardPreference, len(prefs))
+	for _, pref := range prefs {
+		byBoard[pref.BoardID] = pref
+	}
+
+	active := filterArchivedBoards(boards, false)
+	userBoards := make([]UserBoard, len(active))
+	for i, board := range active {
+		userBoards[i] = UserBoard{Board: board, Preference: byBoard[board.ID]}
+	}
+	return userBoards, nil
+}
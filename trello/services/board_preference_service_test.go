@@ -0,0 +1,123 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zayyadi/trello/repositories"
+)
+
+// boardPreferenceTestEnv runs the preference service on SQLite. Alice owns the
+// boards Alpha, Beta and Gamma; Bob is not on any of them.
+type boardPreferenceTestEnv struct {
+	prefs  *BoardPreferenceService
+	boards BoardServiceInterface
+	alpha  uint
+	beta   uint
+	gamma  uint
+	alice  uint
+	bob    uint
+}
+
+func newBoardPreferenceTestEnv(t *testing.T) *boardPreferenceTestEnv {
+	db := setupTestDB(t)
+	userRepo := repositories.NewUserRepository(db)
+	boardRepo := repositories.NewBoardRepository(db)
+	boardMemberRepo := repositories.NewBoardMemberRepository(db)
+
+	env := &boardPreferenceTestEnv{
+		prefs:  NewBoardPreferenceService(repositories.NewBoardPreferenceRepository(db), boardRepo, boardMemberRepo),
+		boards: NewBoardService(boardRepo, userRepo, boardMemberRepo, &MockHub{}, false),
+	}
+	env.alice = createTestUser(t, userRepo, "alice")
+	env.bob = createTestUser(t, userRepo, "bob")
+	env.gamma = createTestBoard(t, env.boards, "Gamma", "", env.alice, nil).ID
+	env.alpha = createTestBoard(t, env.boards, "alpha", "", env.alice, nil).ID
+	env.beta = createTestBoard(t, env.boards, "Beta", "", env.alice, nil).ID
+	return env
+}
+
+// boardIDs returns the IDs of the boards in order.
+func boardIDs(boards []UserBoard) []uint {
+	ids := make([]uint, len(boards))
+	for i, board := range boards {
+		ids[i] = board.Board.ID
+	}
+	return ids
+}
+
+func TestBoardPreferenceService_GetBoardsForUserOrder(t *testing.T) {
+	env := newBoardPreferenceTestEnv(t)
+
+	boards, err := env.prefs.GetBoardsForUser(env.alice)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{env.alpha, env.beta, env.gamma}, boardIDs(boards), "by name to begin with")
+
+	start := time.Now()
+	env.prefs.now = func() time.Time { return start }
+	assert.NoError(t, env.prefs.RecordView(env.beta, env.alice))
+	env.prefs.now = func() time.Time { return start.Add(time.Minute) }
+	assert.NoError(t, env.prefs.RecordView(env.gamma, env.alice))
+	boards, err = env.prefs.GetBoardsForUser(env.alice)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{env.gamma, env.beta, env.alpha}, boardIDs(boards), "recently viewed first")
+
+	assert.NoError(t, env.prefs.SetBoardOrder([]uint{env.alpha, env.beta}, env.alice))
+	boards, err = env.prefs.GetBoardsForUser(env.alice)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{env.alpha, env.beta, env.gamma}, boardIDs(boards), "the user's own order before recency")
+	if assert.NotNil(t, boards[1].Preference.Position) {
+		assert.Equal(t, 2, *boards[1].Preference.Position)
+	}
+
+	assert.NoError(t, env.prefs.SetStarred(env.gamma, true, env.alice))
+	boards, err = env.prefs.GetBoardsForUser(env.alice)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{env.gamma, env.alpha, env.beta}, boardIDs(boards), "starred first")
+	assert.True(t, boards[0].Preference.Starred)
+	assert.NotNil(t, boards[0].Preference.LastViewedAt, "starring keeps the other settings")
+
+	assert.NoError(t, env.prefs.SetStarred(env.gamma, false, env.alice))
+	assert.NoError(t, env.prefs.SetBoardOrder(nil, env.alice))
+	boards, err = env.prefs.GetBoardsForUser(env.alice)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{env.gamma, env.beta, env.alpha}, boardIDs(boards), "back to recency")
+	assert.False(t, boards[0].Preference.Starred)
+	assert.Nil(t, boards[1].Preference.Position)
+}
+
+func TestBoardPreferenceService_GetRecentBoards(t *testing.T) {
+	env := newBoardPreferenceTestEnv(t)
+	start := time.Now()
+	for i, boardID := range []uint{env.alpha, env.gamma, env.beta} {
+		env.prefs.now = func() time.Time { return start.Add(time.Duration(i) * time.Minute) }
+		assert.NoError(t, env.prefs.RecordView(boardID, env.alice))
+	}
+
+	boards, err := env.prefs.GetRecentBoards(env.alice, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{env.beta, env.gamma}, boardIDs(boards))
+
+	assert.NoError(t, env.boards.DeleteBoard(env.beta, env.alice))
+	boards, err = env.prefs.GetRecentBoards(env.alice, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{env.gamma, env.alpha}, boardIDs(boards), "deleted boards drop out")
+
+	boards, err = env.prefs.GetRecentBoards(env.bob, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, boards)
+}
+
+func TestBoardPreferenceService_OnlyTheirOwnBoards(t *testing.T) {
+	env := newBoardPreferenceTestEnv(t)
+
+	assert.ErrorIs(t, env.prefs.SetStarred(env.alpha, true, env.bob), ErrForbidden)
+	assert.ErrorIs(t, env.prefs.RecordView(env.alpha, env.bob), ErrForbidden)
+	assert.ErrorIs(t, env.prefs.SetBoardOrder([]uint{env.alpha}, env.bob), ErrInvalidInput)
+	assert.ErrorIs(t, env.prefs.SetBoardOrder([]uint{env.alpha, env.alpha}, env.alice), ErrInvalidInput, "duplicates")
+
+	boards, err := env.prefs.GetBoardsForUser(env.bob)
+	assert.NoError(t, err)
+	assert.Empty(t, boards)
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/zayyadi/trello/repositories"
+
+	"gorm.io/gorm"
+)
+
+// BoardResolver finds the board that a list or card belongs to,
+// e.g. to check a request against a board-scoped access token.
+type BoardResolver struct {
+	listRepo repositories.ListRepositoryInterface
+	cardRepo repositories.CardRepositoryInterface
+}
+
+func NewBoardResolver(listRepo repositories.ListRepositoryInterface, cardRepo repositories.CardRepositoryInterface) *BoardResolver {
+	return &BoardResolver{listRepo: listRepo, cardRepo: cardRepo}
+}
+
+func (r *BoardResolver) BoardIDForList(listID uint) (uint, error) {
+	list, err := r.listRepo.FindByID(listID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, ErrListNotFound
+		}
+		return 0, err
+	}
+	return list.BoardID, nil
+}
+
+func (r *BoardResolver) BoardIDForCard(cardID uint) (uint, error) {
+	card, err := r.cardRepo.FindByID(cardID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, ErrCardNotFound
+		}
+		return 0, err
+	}
+	return r.BoardIDForList(card.ListID)
+}
@@ -2,20 +2,24 @@ package services
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/zayyadi/trello/dto" // Changed import
 	"github.com/zayyadi/trello/models"
-	"github.com/zayyadi/trello/repositories"
+	"github.com/zayyadi/trello/policy"
 	"github.com/zayyadi/trello/realtime"
+	"github.com/zayyadi/trello/repositories"
 
 	"gorm.io/gorm"
 )
 
 type BoardService struct {
-	boardRepo       repositories.BoardRepositoryInterface
-	userRepo        repositories.UserRepositoryInterface
-	boardMemberRepo repositories.BoardMemberRepositoryInterface
-	hub             *realtime.Hub
+	boardRepo            repositories.BoardRepositoryInterface
+	userRepo             repositories.UserRepositoryInterface
+	boardMemberRepo      repositories.BoardMemberRepositoryInterface
+	hub                  realtime.Broadcaster
+	requireVerifiedEmail bool
+	audit                *AuditService // Records site admins acting on boards they don't own
 }
 
 // BoardServiceInterface defines methods for board service (including IsUserMemberOfBoard)
@@ -26,24 +30,42 @@ type BoardServiceInterface interface {
 	GetBoardsForUser(userID uint) ([]models.Board, error)
 	UpdateBoard(boardID uint, name, description *string, userID uint) (*models.Board, error)
 	DeleteBoard(boardID, userID uint) error
-	AddMemberToBoard(boardID uint, email *string, memberUserID *uint, currentUserID uint) (*models.BoardMember, error)
+	AddMemberToBoard(boardID uint, email *string, memberUserID *uint, role models.BoardRole, currentUserID uint) (*models.BoardMember, error)
 	RemoveMemberFromBoard(boardID, memberUserID, currentUserID uint) error
+	UpdateMemberRole(boardID, memberUserID uint, role models.BoardRole, currentUserID uint) (*models.BoardMember, error)
 	GetBoardMembers(boardID, currentUserID uint) ([]models.BoardMember, error)
 	IsUserMemberOfBoard(userID uint, boardID uint) (bool, error) // New method
+	UpdateVisibility(boardID uint, visibility models.BoardVisibility, userID uint) (*models.Board, error)
+	TransferOwnership(boardID, newOwnerID, currentUserID uint) (*models.Board, error)
 }
 
 func NewBoardService(
 	boardRepo repositories.BoardRepositoryInterface,
 	userRepo repositories.UserRepositoryInterface,
 	boardMemberRepo repositories.BoardMemberRepositoryInterface,
-	hub *realtime.Hub,
+	hub realtime.Broadcaster,
+	requireVerifiedEmail bool, // Refuse to add users who haven't verified their email
+	opts ...BoardServiceOption,
 ) BoardServiceInterface { // Return interface type
-	return &BoardService{
-		boardRepo:       boardRepo,
-		userRepo:        userRepo,
-		boardMemberRepo: boardMemberRepo,
-		hub:             hub,
+	s := &BoardService{
+		boardRepo:            boardRepo,
+		userRepo:             userRepo,
+		boardMemberRepo:      boardMemberRepo,
+		hub:                  hub,
+		requireVerifiedEmail: requireVerifiedEmail,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
+}
+
+// BoardServiceOption sets an optional dependency of BoardService.
+type BoardServiceOption func(*BoardService)
+
+// WithAuditService makes BoardService record site admins acting on boards they don't own.
+func WithAuditService(audit *AuditService) BoardServiceOption {
+	return func(s *BoardService) { s.audit = audit }
 }
 
 func (s *BoardService) CreateBoard(name, description string, ownerID uint) (*models.Board, error) {
@@ -51,13 +73,14 @@ func (s *BoardService) CreateBoard(name, description string, ownerID uint) (*mod
 		Name:        name,
 		Description: description,
 		OwnerID:     ownerID,
+		Visibility:  models.BoardVisibilityPrivate,
 	}
 	var err error // Declare err once
 	if err = s.boardRepo.Create(board); err != nil {
 		return nil, err
 	}
-	// Add owner as a member automatically
-	if err = s.boardMemberRepo.AddMember(&models.BoardMember{BoardID: board.ID, UserID: ownerID}); err != nil {
+	// Add owner as an admin member automatically
+	if err = s.boardMemberRepo.AddMember(&models.BoardMember{BoardID: board.ID, UserID: ownerID, Role: models.BoardRoleAdmin}); err != nil {
 		// Log error but don't fail board creation if member addition fails
 		// Or, implement rollback for board creation if member addition is critical
 		return nil, err
@@ -81,41 +104,37 @@ func (s *BoardService) CreateBoard(name, description string, ownerID uint) (*mod
 }
 
 func (s *BoardService) GetBoardByID(boardID, userID uint) (*models.Board, error) {
-	board, err := s.boardRepo.FindByID(boardID)
+	board, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, boardID, userID, policy.ViewBoard)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrBoardNotFound
-		}
 		return nil, err
 	}
-	if board.OwnerID == userID {
-		return board, nil
-	}
-	isMember, err := s.boardMemberRepo.IsMember(boardID, userID)
-	if err != nil || !isMember {
-		return nil, ErrForbidden
-	}
 	return board, nil
 }
 
+// GetBoardsForUser lists the user's boards, leaving out archived ones.
 func (s *BoardService) GetBoardsForUser(userID uint) ([]models.Board, error) {
 	boards, err := s.boardRepo.FindByOwnerOrMember(userID)
 	if err != nil {
 		return nil, err
 	}
-	return boards, nil
+	return filterArchivedBoards(boards, false), nil
 }
 
-func (s *BoardService) UpdateBoard(boardID uint, name, description *string, userID uint) (*models.Board, error) {
-	board, err := s.boardRepo.FindByID(boardID)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrBoardNotFound
+// filterArchivedBoards keeps the boards that are archived, or those that aren't.
+func filterArchivedBoards(boards []models.Board, archived bool) []models.Board {
+	kept := make([]models.Board, 0, len(boards))
+	for _, board := range boards {
+		if (board.ArchivedAt != nil) == archived {
+			kept = append(kept, board)
 		}
-		return nil, err
 	}
-	if board.OwnerID != userID {
-		return nil, ErrForbidden // Only owner can update board details
+	return kept
+}
+
+func (s *BoardService) UpdateBoard(boardID uint, name, description *string, userID uint) (*models.Board, error) {
+	board, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, boardID, userID, policy.UpdateBoard)
+	if err != nil {
+		return nil, err // Only admins (including the owner) can update board details
 	}
 
 	if name != nil {
@@ -146,18 +165,49 @@ func (s *BoardService) UpdateBoard(boardID uint, name, description *string, user
 	return updatedBoard, nil
 }
 
-func (s *BoardService) DeleteBoard(boardID, userID uint) error {
-	board, err := s.boardRepo.FindByID(boardID)
+// UpdateVisibility changes who can see the board. Workspace visibility needs the
+// board to be in a workspace; admins (including the owner) may change it.
+// Anonymous viewers of a public board are disconnected when it stops being public.
+func (s *BoardService) UpdateVisibility(boardID uint, visibility models.BoardVisibility, userID uint) (*models.Board, error) {
+	board, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, boardID, userID, policy.UpdateBoard)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ErrBoardNotFound
-		}
-		return err
+		return nil, err
+	}
+	if !visibility.IsValid() {
+		return nil, fmt.Errorf("%w: visibility must be one of: private, workspace, public", ErrInvalidInput)
+	}
+	if visibility == models.BoardVisibilityWorkspace && board.WorkspaceID == nil {
+		return nil, fmt.Errorf("%w: the board is not in a workspace", ErrInvalidInput)
+	}
+
+	wasPublic := board.Visibility == models.BoardVisibilityPublic
+	board.Visibility = visibility
+	if err := s.boardRepo.Update(board); err != nil {
+		return nil, err
 	}
-	if board.OwnerID != userID {
-		return ErrForbidden // Only owner can delete board
+	updatedBoard, err := s.boardRepo.FindByID(board.ID)
+	if err != nil {
+		return nil, err
 	}
-	err = s.boardRepo.Delete(boardID)
+
+	broadcastMessage(
+		s.hub,
+		updatedBoard.ID,
+		realtime.MessageTypeBoardUpdated,
+		dto.MapBoardToResponse(updatedBoard, true, false),
+		userID,
+	)
+	if wasPublic && visibility != models.BoardVisibilityPublic {
+		disconnectAnonymousViewers(s.hub, boardID, 0)
+	}
+	return updatedBoard, nil
+}
+
+func (s *BoardService) DeleteBoard(boardID, userID uint) error {
+	if _, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, boardID, userID, policy.DeleteBoard); err != nil {
+		return err // Only owner can delete board
+	}
+	err := s.boardRepo.Delete(boardID)
 	if err == nil {
 		// Broadcast board deletion
 		broadcastMessage(
@@ -167,44 +217,44 @@ func (s *BoardService) DeleteBoard(boardID, userID uint) error {
 			realtime.BoardBasicInfo{ID: boardID}, // Simple payload for deletion
 			userID,
 		)
+		disconnectAnonymousViewers(s.hub, boardID, realtime.AnyShareLink) // Share links die with the board too
 	}
 	return err
 }
 
-func (s *BoardService) AddMemberToBoard(boardID uint, email *string, memberUserID *uint, currentUserID uint) (*models.BoardMember, error) {
-	board, err := s.boardRepo.FindByID(boardID)
+// AddMemberToBoard adds a user to the board straight away, without asking them.
+// The API doesn't expose it: people are invited through InvitationService and
+// join once they accept.
+func (s *BoardService) AddMemberToBoard(boardID uint, email *string, memberUserID *uint, role models.BoardRole, currentUserID uint) (*models.BoardMember, error) {
+	board, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, boardID, currentUserID, policy.ManageMembers)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrBoardNotFound
-		}
-		return nil, err
+		return nil, err // Only admins can add members
+	}
+	if role == "" {
+		role = models.BoardRoleMember
 	}
-	if board.OwnerID != currentUserID {
-		return nil, ErrForbidden // Only owner can add members
+	if !role.IsValid() {
+		return nil, ErrInvalidBoardRole
 	}
 
-	var targetUserID uint
+	var targetUser *models.User
 	if email != nil && *email != "" {
-		user, err := s.userRepo.FindByEmail(*email)
-		if err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return nil, ErrUserNotFound
-			}
-			return nil, err
-		}
-		targetUserID = user.ID
+		targetUser, err = s.userRepo.FindByEmail(*email)
 	} else if memberUserID != nil && *memberUserID != 0 {
-		user, err := s.userRepo.FindByID(*memberUserID)
-		if err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return nil, ErrUserNotFound
-			}
-			return nil, err
-		}
-		targetUserID = user.ID
+		targetUser, err = s.userRepo.FindByID(*memberUserID)
 	} else {
 		return nil, errors.New("Either email or userID must be provided")
 	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if s.requireVerifiedEmail && !targetUser.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+	targetUserID := targetUser.ID
 
 	if targetUserID == board.OwnerID {
 		return nil, ErrUserAlreadyMember // Owner is implicitly a member
@@ -221,6 +271,7 @@ func (s *BoardService) AddMemberToBoard(boardID uint, email *string, memberUserI
 	boardMember := &models.BoardMember{
 		BoardID: boardID,
 		UserID:  targetUserID,
+		Role:    role,
 	}
 	if err = s.boardMemberRepo.AddMember(boardMember); err != nil {
 		return nil, err
@@ -242,15 +293,9 @@ func (s *BoardService) AddMemberToBoard(boardID uint, email *string, memberUserI
 }
 
 func (s *BoardService) RemoveMemberFromBoard(boardID, memberUserID, currentUserID uint) error {
-	board, err := s.boardRepo.FindByID(boardID)
+	board, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, boardID, currentUserID, policy.ManageMembers)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ErrBoardNotFound
-		}
-		return err
-	}
-	if board.OwnerID != currentUserID {
-		return ErrForbidden // Only owner can remove members
+		return err // Only admins can remove members
 	}
 	if memberUserID == board.OwnerID {
 		return ErrCannotRemoveOwner // Cannot remove the owner
@@ -274,50 +319,144 @@ func (s *BoardService) RemoveMemberFromBoard(boardID, memberUserID, currentUserI
 			realtime.BoardMemberPayload{BoardID: boardID, UserID: memberUserID}, // Simple payload
 			currentUserID,
 		)
+		disconnectFormerViewer(s.hub, s.boardRepo, s.boardMemberRepo, boardID, memberUserID)
 	}
 	return err
 }
 
-func (s *BoardService) GetBoardMembers(boardID, currentUserID uint) ([]models.BoardMember, error) {
-	board, err := s.boardRepo.FindByID(boardID)
+// UpdateMemberRole changes the role of an existing board member.
+// Only admins may change roles, and the owner's role is fixed.
+func (s *BoardService) UpdateMemberRole(boardID, memberUserID uint, role models.BoardRole, currentUserID uint) (*models.BoardMember, error) {
+	board, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, boardID, currentUserID, policy.ManageMembers)
 	if err != nil {
+		return nil, err
+	}
+	if !role.IsValid() {
+		return nil, ErrInvalidBoardRole
+	}
+	if memberUserID == board.OwnerID {
+		return nil, ErrCannotChangeOwner
+	}
+
+	if err := s.boardMemberRepo.UpdateRole(boardID, memberUserID, role); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrBoardNotFound
+			return nil, ErrBoardMemberNotFound
 		}
 		return nil, err
 	}
-	if board.OwnerID != currentUserID {
-		isMember, err := s.boardMemberRepo.IsMember(boardID, currentUserID)
-		if err != nil || !isMember {
-			return nil, ErrForbidden // Only owner or member can view members
+	updatedMember, err := s.boardMemberRepo.FindByBoardIDAndUserID(boardID, memberUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Broadcast role change
+	broadcastMessage(
+		s.hub,
+		boardID,
+		realtime.MessageTypeBoardMemberRoleUpdated,
+		dto.MapBoardMemberToResponse(updatedMember),
+		currentUserID,
+	)
+	return updatedMember, nil
+}
+
+// TransferOwnership hands the board to one of its existing members. The owner may
+// do this, and so may site admins, e.g. for the boards of someone who has left.
+// The new owner becomes an admin; the previous owner stays on the board as an admin member.
+func (s *BoardService) TransferOwnership(boardID, newOwnerID, currentUserID uint) (*models.Board, error) {
+	board, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, boardID, currentUserID, policy.TransferOwnership)
+	if errors.Is(err, ErrForbidden) {
+		board, err = s.boardForSiteAdmin(boardID, currentUserID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if newOwnerID == board.OwnerID {
+		return nil, ErrAlreadyBoardOwner
+	}
+
+	isMember, err := s.boardMemberRepo.IsMember(boardID, newOwnerID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, ErrNewOwnerNotMember
+	}
+
+	previousOwnerID := board.OwnerID
+	if err := s.boardRepo.TransferOwnership(boardID, newOwnerID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBoardNotFound
 		}
+		return nil, err
 	}
-	members, err := s.boardMemberRepo.FindMembersByBoardID(boardID)
+	if currentUserID != previousOwnerID {
+		s.audit.Record(&models.AuditEvent{
+			Type:    models.AuditEventBoardTransferred,
+			ActorID: &currentUserID,
+			UserID:  &previousOwnerID,
+			Detail:  fmt.Sprintf("board=%d new_owner=%d", boardID, newOwnerID),
+		})
+	}
+	updatedBoard, err := s.boardRepo.FindByID(boardID)
 	if err != nil {
 		return nil, err
 	}
-	return members, nil
+
+	// Broadcast ownership change
+	broadcastMessage(
+		s.hub,
+		boardID,
+		realtime.MessageTypeBoardOwnerChanged,
+		realtime.BoardOwnerChangedPayload{BoardID: boardID, PreviousOwnerID: previousOwnerID, NewOwnerID: newOwnerID},
+		currentUserID,
+	)
+	return updatedBoard, nil
 }
 
-// IsUserMemberOfBoard checks if a user is the owner or an explicit member of the board.
-func (s *BoardService) IsUserMemberOfBoard(userID uint, boardID uint) (bool, error) {
+// boardForSiteAdmin loads the board for a site admin acting outside the board's own
+// permissions. Anyone else gets ErrForbidden.
+func (s *BoardService) boardForSiteAdmin(boardID, userID uint) (*models.Board, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrForbidden
+		}
+		return nil, err
+	}
+	if !user.IsAdmin {
+		return nil, ErrForbidden
+	}
 	board, err := s.boardRepo.FindByID(boardID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return false, ErrBoardNotFound // Or just return false, nil if board not found means not a member
+			return nil, ErrBoardNotFound
 		}
-		return false, err
+		return nil, err
 	}
+	return board, nil
+}
 
-	// Check if the user is the owner of the board
-	if board.OwnerID == userID {
-		return true, nil
+func (s *BoardService) GetBoardMembers(boardID, currentUserID uint) ([]models.BoardMember, error) {
+	if _, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, boardID, currentUserID, policy.ViewBoard); err != nil {
+		return nil, err // Any member can view members
 	}
+	members, err := s.boardMemberRepo.FindMembersByBoardID(boardID)
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
 
-	// Check if the user is listed in the board_members table
-	isMember, err := s.boardMemberRepo.IsMember(boardID, userID)
+// IsUserMemberOfBoard checks if a user is the owner or an explicit member of the board,
+// i.e. whether the policy lets them view it.
+func (s *BoardService) IsUserMemberOfBoard(userID uint, boardID uint) (bool, error) {
+	_, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, boardID, userID, policy.ViewBoard)
 	if err != nil {
-		return false, err
+		if errors.Is(err, ErrForbidden) {
+			return false, nil
+		}
+		return false, err // Includes ErrBoardNotFound
 	}
-	return isMember, nil
+	return true, nil
 }
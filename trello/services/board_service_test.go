@@ -19,6 +19,8 @@ type MockBoardRepository struct {
 	UpdateFunc              func(board *models.Board) error
 	DeleteFunc              func(id uint) error
 	IsOwnerFunc             func(boardID uint, userID uint) (bool, error)
+	FindByWorkspaceFunc     func(workspaceID uint, userID uint) ([]models.Board, error)
+	TransferOwnershipFunc   func(boardID uint, newOwnerID uint) error
 
 	// Store calls
 	CreateCalledWith              *models.Board
@@ -74,6 +76,20 @@ func (m *MockBoardRepository) IsOwner(boardID uint, userID uint) (bool, error) {
 	return false, errors.New("IsOwnerFunc not implemented")
 }
 
+func (m *MockBoardRepository) FindByWorkspace(workspaceID uint, userID uint) ([]models.Board, error) {
+	if m.FindByWorkspaceFunc != nil {
+		return m.FindByWorkspaceFunc(workspaceID, userID)
+	}
+	return nil, errors.New("FindByWorkspaceFunc not implemented")
+}
+
+func (m *MockBoardRepository) TransferOwnership(boardID uint, newOwnerID uint) error {
+	if m.TransferOwnershipFunc != nil {
+		return m.TransferOwnershipFunc(boardID, newOwnerID)
+	}
+	return nil
+}
+
 var _ repositories.BoardRepositoryInterface = (*MockBoardRepository)(nil)
 
 // MockBoardMemberRepository is a mock implementation of BoardMemberRepositoryInterface
@@ -83,6 +99,9 @@ type MockBoardMemberRepository struct {
 	IsMemberFunc               func(boardID uint, userID uint) (bool, error)
 	FindMembersByBoardIDFunc   func(boardID uint) ([]models.BoardMember, error)
 	FindByBoardIDAndUserIDFunc func(boardID uint, userID uint) (*models.BoardMember, error)
+	GetRoleFunc                func(boardID uint, userID uint) (models.BoardRole, error)
+	UpdateRoleFunc             func(boardID uint, userID uint, role models.BoardRole) error
+	GetWorkspaceRoleFunc       func(boardID uint, userID uint) (models.WorkspaceRole, error)
 
 	// Store calls
 	AddMemberCalledWithMember               *models.BoardMember
@@ -93,6 +112,9 @@ type MockBoardMemberRepository struct {
 	FindMembersByBoardIDCalledWith          uint
 	FindByBoardIDAndUserIDCalledWithBoardID uint
 	FindByBoardIDAndUserIDCalledWithUserID  uint
+	GetRoleCalledWithBoardID                uint
+	GetRoleCalledWithUserID                 uint
+	UpdateRoleCalledWithRole                models.BoardRole
 }
 
 func (m *MockBoardMemberRepository) AddMember(member *models.BoardMember) error {
@@ -133,6 +155,27 @@ func (m *MockBoardMemberRepository) FindByBoardIDAndUserID(boardID uint, userID
 	}
 	return nil, errors.New("FindByBoardIDAndUserIDFunc not implemented")
 }
+func (m *MockBoardMemberRepository) GetRole(boardID uint, userID uint) (models.BoardRole, error) {
+	m.GetRoleCalledWithBoardID = boardID
+	m.GetRoleCalledWithUserID = userID
+	if m.GetRoleFunc != nil {
+		return m.GetRoleFunc(boardID, userID)
+	}
+	return "", errors.New("GetRoleFunc not implemented")
+}
+func (m *MockBoardMemberRepository) UpdateRole(boardID uint, userID uint, role models.BoardRole) error {
+	m.UpdateRoleCalledWithRole = role
+	if m.UpdateRoleFunc != nil {
+		return m.UpdateRoleFunc(boardID, userID, role)
+	}
+	return nil
+}
+func (m *MockBoardMemberRepository) GetWorkspaceRole(boardID uint, userID uint) (models.WorkspaceRole, error) {
+	if m.GetWorkspaceRoleFunc != nil {
+		return m.GetWorkspaceRoleFunc(boardID, userID)
+	}
+	return "", gorm.ErrRecordNotFound // Not in a workspace unless a test says otherwise
+}
 
 var _ repositories.BoardMemberRepositoryInterface = (*MockBoardMemberRepository)(nil)
 
@@ -163,6 +206,9 @@ func (m *MockUserRepositoryForBoardService) FindByEmail(email string) (*models.U
 	}
 	return nil, errors.New("FindByEmailFunc not implemented")
 }
+func (m *MockUserRepositoryForBoardService) FindByUsername(username string) (*models.User, error) {
+	return nil, errors.New("FindByUsername not implemented by MockUserRepositoryForBoardService")
+}
 func (m *MockUserRepositoryForBoardService) FindByID(id uint) (*models.User, error) {
 	m.FindByIDCalledWith = id
 	if m.FindByIDFunc != nil {
@@ -178,6 +224,10 @@ func (m *MockUserRepositoryForBoardService) FindAll() ([]models.User, error) {
 	return nil, nil // Default behavior: return empty slice, no error
 }
 
+func (m *MockUserRepositoryForBoardService) Update(user *models.User) error {
+	return errors.New("Update not implemented by MockUserRepositoryForBoardService")
+}
+
 // MockHub is a mock implementation of realtime.Hub for testing purposes
 type MockHub struct {
 	SubmitFunc func(msg *realtime.WebSocketMessage)
@@ -206,7 +256,7 @@ func TestBoardService_CreateBoard_Success(t *testing.T) {
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 	mockHub := &MockHub{} // Initialize mock hub
 
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub, false)
 
 	ownerID := uint(1)
 	boardName := "Test Board"
@@ -265,7 +315,7 @@ func TestBoardService_CreateBoard_ErrOnBoardCreate(t *testing.T) {
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 	mockHub := &MockHub{} // Initialize mock hub
 
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub, false)
 
 	ownerID := uint(1)
 	boardName := "Test Board"
@@ -302,7 +352,7 @@ func TestBoardService_CreateBoard_ErrOnAddOwnerAsMember(t *testing.T) {
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 	mockHub := &MockHub{} // Initialize mock hub
 
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub, false)
 
 	ownerID := uint(1)
 	boardName := "Test Board"
@@ -340,7 +390,7 @@ func TestBoardService_GetBoardByID_SuccessAsOwner(t *testing.T) {
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 	mockHub := &MockHub{} // Initialize mock hub
 
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub, false)
 
 	boardID := uint(1)
 	ownerID := uint(10)
@@ -380,7 +430,7 @@ func TestBoardService_GetBoardByID_SuccessAsMember(t *testing.T) {
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 	mockHub := &MockHub{} // Initialize mock hub
 
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub, false)
 
 	boardID := uint(1)
 	boardOwnerID := uint(10)
@@ -398,10 +448,10 @@ func TestBoardService_GetBoardByID_SuccessAsMember(t *testing.T) {
 		return expectedBoard, nil
 	}
 
-	mockBoardMemberRepo.IsMemberFunc = func(bID uint, uID uint) (bool, error) {
+	mockBoardMemberRepo.GetRoleFunc = func(bID uint, uID uint) (models.BoardRole, error) {
 		assert.Equal(t, boardID, bID)
 		assert.Equal(t, currentUserID, uID)
-		return true, nil
+		return models.BoardRoleMember, nil
 	}
 
 	board, err := boardService.GetBoardByID(boardID, currentUserID)
@@ -412,22 +462,22 @@ func TestBoardService_GetBoardByID_SuccessAsMember(t *testing.T) {
 	assert.Equal(t, expectedBoard.Name, board.Name)
 
 	assert.Equal(t, boardID, mockBoardRepo.FindByIDCalledWith)
-	assert.Equal(t, boardID, mockBoardMemberRepo.IsMemberCalledWithBoardID)
-	assert.Equal(t, currentUserID, mockBoardMemberRepo.IsMemberCalledWithUserID)
+	assert.Equal(t, boardID, mockBoardMemberRepo.GetRoleCalledWithBoardID)
+	assert.Equal(t, currentUserID, mockBoardMemberRepo.GetRoleCalledWithUserID)
 }
 
-func TestBoardService_GetBoardByID_IsMemberError(t *testing.T) {
+func TestBoardService_GetBoardByID_GetRoleError(t *testing.T) {
 	mockBoardRepo := &MockBoardRepository{}
 	mockUserRepo := &MockUserRepositoryForBoardService{}
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 	mockHub := &MockHub{} // Initialize mock hub
 
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub, false)
 
 	boardID := uint(1)
 	boardOwnerID := uint(10)
 	currentUserID := uint(20)
-	expectedError := errors.New("DB error on IsMember")
+	expectedError := errors.New("DB error on GetRole")
 
 	foundBoard := &models.Board{
 		Model:   gorm.Model{ID: boardID},
@@ -440,21 +490,21 @@ func TestBoardService_GetBoardByID_IsMemberError(t *testing.T) {
 		return foundBoard, nil
 	}
 
-	mockBoardMemberRepo.IsMemberFunc = func(bID uint, uID uint) (bool, error) {
+	mockBoardMemberRepo.GetRoleFunc = func(bID uint, uID uint) (models.BoardRole, error) {
 		assert.Equal(t, boardID, bID)
 		assert.Equal(t, currentUserID, uID)
-		return false, expectedError
+		return "", expectedError
 	}
 
 	board, err := boardService.GetBoardByID(boardID, currentUserID)
 
 	assert.Error(t, err)
-	assert.Equal(t, ErrForbidden, err)
+	assert.Equal(t, expectedError, err)
 	assert.Nil(t, board)
 
 	assert.Equal(t, boardID, mockBoardRepo.FindByIDCalledWith)
-	assert.Equal(t, boardID, mockBoardMemberRepo.IsMemberCalledWithBoardID)
-	assert.Equal(t, currentUserID, mockBoardMemberRepo.IsMemberCalledWithUserID)
+	assert.Equal(t, boardID, mockBoardMemberRepo.GetRoleCalledWithBoardID)
+	assert.Equal(t, currentUserID, mockBoardMemberRepo.GetRoleCalledWithUserID)
 }
 
 func TestBoardService_GetBoardsForUser_Success(t *testing.T) {
@@ -463,7 +513,7 @@ func TestBoardService_GetBoardsForUser_Success(t *testing.T) {
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 	mockHub := &MockHub{} // Initialize mock hub
 
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub, false)
 
 	userID := uint(1)
 	expectedBoards := []models.Board{
@@ -496,7 +546,7 @@ func TestBoardService_GetBoardsForUser_NoBoards(t *testing.T) {
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 	mockHub := &MockHub{} // Initialize mock hub
 
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub, false)
 
 	userID := uint(1)
 	expectedBoards := []models.Board{}
@@ -520,7 +570,7 @@ func TestBoardService_GetBoardsForUser_Error(t *testing.T) {
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 	mockHub := &MockHub{} // Initialize mock hub
 
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub, false)
 
 	userID := uint(1)
 	expectedError := errors.New("DB error FindByOwnerOrMember")
@@ -544,7 +594,7 @@ func TestBoardService_UpdateBoard_Success(t *testing.T) {
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 	mockHub := &MockHub{} // Initialize mock hub
 
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub, false)
 
 	boardID := uint(1)
 	currentUserID := uint(10)
@@ -595,7 +645,7 @@ func TestBoardService_UpdateBoard_BoardNotFound(t *testing.T) {
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 	mockHub := &MockHub{} // Initialize mock hub
 
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub, false)
 
 	boardID := uint(1)
 	currentUserID := uint(10)
@@ -627,7 +677,7 @@ func TestBoardService_UpdateBoard_Forbidden(t *testing.T) {
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 	mockHub := &MockHub{} // Initialize mock hub
 
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub, false)
 
 	boardID := uint(1)
 	ownerID := uint(5)
@@ -645,8 +695,11 @@ func TestBoardService_UpdateBoard_Forbidden(t *testing.T) {
 		return foundBoard, nil
 	}
 
+	mockBoardMemberRepo.GetRoleFunc = func(bID uint, uID uint) (models.BoardRole, error) {
+		return models.BoardRoleMember, nil // Plain members cannot manage the board
+	}
 	mockBoardRepo.UpdateFunc = func(board *models.Board) error {
-		t.Error("Update should not be called if user is not owner")
+		t.Error("Update should not be called if user is not an admin")
 		return nil
 	}
 
@@ -666,7 +719,7 @@ func TestBoardService_UpdateBoard_ErrOnUpdate(t *testing.T) {
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 	mockHub := &MockHub{} // Initialize mock hub
 
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub, false)
 
 	boardID := uint(1)
 	currentUserID := uint(10)
@@ -711,7 +764,7 @@ func TestBoardService_UpdateBoard_ErrOnFinalFindByID(t *testing.T) {
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 	mockHub := &MockHub{} // Initialize mock hub
 
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub, false)
 
 	boardID := uint(1)
 	currentUserID := uint(10)
@@ -756,7 +809,7 @@ func TestBoardService_DeleteBoard_Success(t *testing.T) {
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 	mockHub := &MockHub{} // Initialize mock hub
 
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub, false)
 
 	boardID := uint(1)
 	currentUserID := uint(10)
@@ -790,7 +843,7 @@ func TestBoardService_DeleteBoard_BoardNotFound(t *testing.T) {
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 	mockHub := &MockHub{} // Initialize mock hub
 
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub, false)
 
 	boardID := uint(1)
 	currentUserID := uint(10)
@@ -819,7 +872,7 @@ func TestBoardService_DeleteBoard_Forbidden(t *testing.T) {
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 	mockHub := &MockHub{} // Initialize mock hub
 
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub, false)
 
 	boardID := uint(1)
 	ownerID := uint(5)
@@ -835,6 +888,9 @@ func TestBoardService_DeleteBoard_Forbidden(t *testing.T) {
 		assert.Equal(t, boardID, id)
 		return foundBoard, nil
 	}
+	mockBoardMemberRepo.GetRoleFunc = func(bID uint, uID uint) (models.BoardRole, error) {
+		return models.BoardRoleAdmin, nil // Even admins cannot delete a board they don't own
+	}
 
 	mockBoardRepo.DeleteFunc = func(id uint) error {
 		t.Error("Delete should not be called if user is not owner")
@@ -855,7 +911,7 @@ func TestBoardService_DeleteBoard_ErrOnDelete(t *testing.T) {
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 	mockHub := &MockHub{} // Initialize mock hub
 
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub, false)
 
 	boardID := uint(1)
 	currentUserID := uint(10)
@@ -891,7 +947,7 @@ func TestBoardService_DeleteBoard_ErrOnFindByID(t *testing.T) {
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 	mockHub := &MockHub{} // Initialize mock hub
 
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub, false)
 
 	boardID := uint(1)
 	currentUserID := uint(10)
@@ -921,7 +977,7 @@ func TestBoardService_AddMemberToBoard_SuccessByEmail(t *testing.T) {
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 	mockHub := &MockHub{} // Initialize mock hub
 
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub, false)
 
 	boardID := uint(1)
 	ownerID := uint(10)
@@ -958,7 +1014,7 @@ func TestBoardService_AddMemberToBoard_SuccessByEmail(t *testing.T) {
 		return addedMemberWithUser, nil
 	}
 
-	newMember, err := boardService.AddMemberToBoard(boardID, &targetUserEmail, nil, ownerID)
+	newMember, err := boardService.AddMemberToBoard(boardID, &targetUserEmail, nil, "", ownerID)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, newMember)
@@ -976,13 +1032,40 @@ func TestBoardService_AddMemberToBoard_SuccessByEmail(t *testing.T) {
 	assert.Equal(t, targetUserID, mockBoardMemberRepo.FindByBoardIDAndUserIDCalledWithUserID)
 }
 
+func TestBoardService_AddMemberToBoard_UnverifiedEmail(t *testing.T) {
+	mockBoardRepo := &MockBoardRepository{}
+	mockUserRepo := &MockUserRepositoryForBoardService{}
+	mockBoardMemberRepo := &MockBoardMemberRepository{}
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, &MockHub{}, true)
+
+	boardID := uint(1)
+	ownerID := uint(10)
+	targetUserEmail := "unverified@example.com"
+
+	mockBoardRepo.FindByIDFunc = func(id uint) (*models.Board, error) {
+		return &models.Board{Model: gorm.Model{ID: boardID}, OwnerID: ownerID}, nil
+	}
+	mockUserRepo.FindByEmailFunc = func(email string) (*models.User, error) {
+		return &models.User{Model: gorm.Model{ID: 20}, Email: email, EmailVerified: false}, nil
+	}
+	mockBoardMemberRepo.AddMemberFunc = func(member *models.BoardMember) error {
+		t.Error("AddMember should not be called for unverified users")
+		return nil
+	}
+
+	newMember, err := boardService.AddMemberToBoard(boardID, &targetUserEmail, nil, "", ownerID)
+
+	assert.Equal(t, ErrEmailNotVerified, err)
+	assert.Nil(t, newMember)
+}
+
 func TestBoardService_AddMemberToBoard_SuccessByUserID(t *testing.T) {
 	mockBoardRepo := &MockBoardRepository{}
 	mockUserRepo := &MockUserRepositoryForBoardService{}
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 	mockHub := &MockHub{} // Initialize mock hub
 
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub, false)
 
 	boardID := uint(1)
 	ownerID := uint(10)
@@ -1011,7 +1094,7 @@ func TestBoardService_AddMemberToBoard_SuccessByUserID(t *testing.T) {
 		return addedMemberWithUser, nil
 	}
 
-	newMember, err := boardService.AddMemberToBoard(boardID, nil, &targetUserID, ownerID)
+	newMember, err := boardService.AddMemberToBoard(boardID, nil, &targetUserID, "", ownerID)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, newMember)
@@ -1030,7 +1113,7 @@ func TestBoardService_AddMemberToBoard_BoardNotFound(t *testing.T) {
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 	mockHub := &MockHub{} // Initialize mock hub
 
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub, false)
 
 	boardID := uint(1)
 	ownerID := uint(10)
@@ -1049,7 +1132,7 @@ func TestBoardService_AddMemberToBoard_BoardNotFound(t *testing.T) {
 		return false, nil
 	}
 
-	newMember, err := boardService.AddMemberToBoard(boardID, &targetUserEmail, nil, ownerID)
+	newMember, err := boardService.AddMemberToBoard(boardID, &targetUserEmail, nil, "", ownerID)
 
 	assert.Error(t, err)
 	assert.Equal(t, ErrBoardNotFound, err)
@@ -1063,7 +1146,7 @@ func TestBoardService_AddMemberToBoard_Forbidden(t *testing.T) {
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 	mockHub := &MockHub{} // Initialize mock hub
 
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub, false)
 
 	boardID := uint(1)
 	actualOwnerID := uint(5)
@@ -1075,12 +1158,15 @@ func TestBoardService_AddMemberToBoard_Forbidden(t *testing.T) {
 	mockBoardRepo.FindByIDFunc = func(id uint) (*models.Board, error) {
 		return boardToReturn, nil
 	}
+	mockBoardMemberRepo.GetRoleFunc = func(bID uint, uID uint) (models.BoardRole, error) {
+		return models.BoardRoleMember, nil // Plain members cannot manage the board
+	}
 	mockUserRepo.FindByEmailFunc = func(email string) (*models.User, error) {
 		t.Error("UserRepo.FindByEmailFunc should not be called in Forbidden case")
 		return nil, nil
 	}
 
-	newMember, err := boardService.AddMemberToBoard(boardID, &targetUserEmail, nil, currentUserID)
+	newMember, err := boardService.AddMemberToBoard(boardID, &targetUserEmail, nil, "", currentUserID)
 
 	assert.Error(t, err)
 	assert.Equal(t, ErrForbidden, err)
@@ -1095,7 +1181,7 @@ func TestBoardService_AddMemberToBoard_UserToAddNotFoundByEmail(t *testing.T) {
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 	mockHub := &MockHub{} // Initialize mock hub
 
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub, false)
 
 	boardID := uint(1)
 	ownerID := uint(10)
@@ -1111,7 +1197,7 @@ func TestBoardService_AddMemberToBoard_UserToAddNotFoundByEmail(t *testing.T) {
 		return nil, gorm.ErrRecordNotFound
 	}
 
-	newMember, err := boardService.AddMemberToBoard(boardID, &targetUserEmail, nil, ownerID)
+	newMember, err := boardService.AddMemberToBoard(boardID, &targetUserEmail, nil, "", ownerID)
 
 	assert.Error(t, err)
 	assert.Equal(t, ErrUserNotFound, err)
@@ -1125,7 +1211,7 @@ func TestBoardService_AddMemberToBoard_UserToAddNotFoundByID(t *testing.T) {
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 	mockHub := &MockHub{} // Initialize mock hub
 
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub, false)
 
 	boardID := uint(1)
 	ownerID := uint(10)
@@ -1141,7 +1227,7 @@ func TestBoardService_AddMemberToBoard_UserToAddNotFoundByID(t *testing.T) {
 		return nil, gorm.ErrRecordNotFound
 	}
 
-	newMember, err := boardService.AddMemberToBoard(boardID, nil, &targetUserID, ownerID)
+	newMember, err := boardService.AddMemberToBoard(boardID, nil, &targetUserID, "", ownerID)
 
 	assert.Error(t, err)
 	assert.Equal(t, ErrUserNotFound, err)
@@ -1155,7 +1241,7 @@ func TestBoardService_AddMemberToBoard_UserAlreadyMember(t *testing.T) {
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 	mockHub := &MockHub{} // Initialize mock hub
 
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub, false)
 
 	boardID := uint(1)
 	ownerID := uint(10)
@@ -1178,7 +1264,7 @@ func TestBoardService_AddMemberToBoard_UserAlreadyMember(t *testing.T) {
 		return nil
 	}
 
-	newMember, err := boardService.AddMemberToBoard(boardID, &targetUserEmail, nil, ownerID)
+	newMember, err := boardService.AddMemberToBoard(boardID, &targetUserEmail, nil, "", ownerID)
 
 	assert.Error(t, err)
 	assert.Equal(t, ErrUserAlreadyMember, err)
@@ -1194,7 +1280,7 @@ func TestBoardService_AddMemberToBoard_OwnerCannotBeAddedAsMember(t *testing.T)
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 	mockHub := &MockHub{} // Initialize mock hub
 
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub, false)
 
 	boardID := uint(1)
 	ownerID := uint(10)
@@ -1215,7 +1301,7 @@ func TestBoardService_AddMemberToBoard_OwnerCannotBeAddedAsMember(t *testing.T)
 		return nil
 	}
 
-	newMember, err := boardService.AddMemberToBoard(boardID, &targetUserEmail, nil, ownerID)
+	newMember, err := boardService.AddMemberToBoard(boardID, &targetUserEmail, nil, "", ownerID)
 
 	assert.Error(t, err)
 	assert.Equal(t, ErrUserAlreadyMember, err)
@@ -1228,7 +1314,7 @@ func TestBoardService_AddMemberToBoard_InvalidInput(t *testing.T) {
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 	mockHub := &MockHub{} // Initialize mock hub
 
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub, false)
 
 	boardID := uint(1)
 	ownerID := uint(10)
@@ -1238,7 +1324,7 @@ func TestBoardService_AddMemberToBoard_InvalidInput(t *testing.T) {
 		return boardToReturn, nil
 	}
 
-	newMember, err := boardService.AddMemberToBoard(boardID, nil, nil, ownerID)
+	newMember, err := boardService.AddMemberToBoard(boardID, nil, nil, "", ownerID)
 
 	assert.Error(t, err)
 	assert.Equal(t, "Either email or userID must be provided", err.Error())
@@ -1246,7 +1332,7 @@ func TestBoardService_AddMemberToBoard_InvalidInput(t *testing.T) {
 
 	emptyEmail := ""
 	zeroID := uint(0)
-	newMember, err = boardService.AddMemberToBoard(boardID, &emptyEmail, &zeroID, ownerID)
+	newMember, err = boardService.AddMemberToBoard(boardID, &emptyEmail, &zeroID, "", ownerID)
 	assert.Error(t, err)
 	assert.Equal(t, "Either email or userID must be provided", err.Error())
 	assert.Nil(t, newMember)
@@ -1257,7 +1343,7 @@ func TestBoardService_AddMemberToBoard_ErrOnUserFindByEmail(t *testing.T) {
 	mockUserRepo := &MockUserRepositoryForBoardService{}
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 	mockHub := &MockHub{} // Initialize mock hub
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub, false)
 
 	boardID := uint(1)
 	ownerID := uint(10)
@@ -1271,7 +1357,7 @@ func TestBoardService_AddMemberToBoard_ErrOnUserFindByEmail(t *testing.T) {
 		return nil, expectedError
 	}
 
-	newMember, err := boardService.AddMemberToBoard(boardID, &targetUserEmail, nil, ownerID)
+	newMember, err := boardService.AddMemberToBoard(boardID, &targetUserEmail, nil, "", ownerID)
 
 	assert.Error(t, err)
 	assert.Equal(t, expectedError, err)
@@ -1284,7 +1370,7 @@ func TestBoardService_AddMemberToBoard_ErrOnUserFindByID(t *testing.T) {
 	mockUserRepo := &MockUserRepositoryForBoardService{}
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 	mockHub := &MockHub{} // Initialize mock hub
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub, false)
 
 	boardID := uint(1)
 	ownerID := uint(10)
@@ -1298,7 +1384,7 @@ func TestBoardService_AddMemberToBoard_ErrOnUserFindByID(t *testing.T) {
 		return nil, expectedError
 	}
 
-	newMember, err := boardService.AddMemberToBoard(boardID, nil, &targetUserID, ownerID)
+	newMember, err := boardService.AddMemberToBoard(boardID, nil, &targetUserID, "", ownerID)
 
 	assert.Error(t, err)
 	assert.Equal(t, expectedError, err)
@@ -1311,7 +1397,7 @@ func TestBoardService_AddMemberToBoard_ErrOnIsMember(t *testing.T) {
 	mockUserRepo := &MockUserRepositoryForBoardService{}
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 	mockHub := &MockHub{} // Initialize mock hub
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub, false)
 
 	boardID := uint(1)
 	ownerID := uint(10)
@@ -1328,7 +1414,7 @@ func TestBoardService_AddMemberToBoard_ErrOnIsMember(t *testing.T) {
 		return false, expectedError
 	}
 
-	newMember, err := boardService.AddMemberToBoard(boardID, &targetUserEmail, nil, ownerID)
+	newMember, err := boardService.AddMemberToBoard(boardID, &targetUserEmail, nil, "", ownerID)
 
 	assert.Error(t, err)
 	assert.Equal(t, expectedError, err)
@@ -1342,7 +1428,7 @@ func TestBoardService_AddMemberToBoard_ErrOnAddMember(t *testing.T) {
 	mockUserRepo := &MockUserRepositoryForBoardService{}
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 	mockHub := &MockHub{} // Initialize mock hub
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub, false)
 
 	boardID := uint(1)
 	ownerID := uint(10)
@@ -1364,7 +1450,7 @@ func TestBoardService_AddMemberToBoard_ErrOnAddMember(t *testing.T) {
 		return nil, nil
 	}
 
-	newMember, err := boardService.AddMemberToBoard(boardID, &targetUserEmail, nil, ownerID)
+	newMember, err := boardService.AddMemberToBoard(boardID, &targetUserEmail, nil, "", ownerID)
 
 	assert.Error(t, err)
 	assert.Equal(t, expectedError, err)
@@ -1377,7 +1463,7 @@ func TestBoardService_AddMemberToBoard_ErrOnFinalFindByBoardIDAndUserID(t *testi
 	mockUserRepo := &MockUserRepositoryForBoardService{}
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 	mockHub := &MockHub{} // Initialize mock hub
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub, false)
 
 	boardID := uint(1)
 	ownerID := uint(10)
@@ -1396,7 +1482,7 @@ func TestBoardService_AddMemberToBoard_ErrOnFinalFindByBoardIDAndUserID(t *testi
 		return nil, expectedError
 	}
 
-	newMember, err := boardService.AddMemberToBoard(boardID, &targetUserEmail, nil, ownerID)
+	newMember, err := boardService.AddMemberToBoard(boardID, &targetUserEmail, nil, "", ownerID)
 
 	assert.Error(t, err)
 	assert.Equal(t, expectedError, err)
@@ -1410,7 +1496,7 @@ func TestBoardService_RemoveMemberFromBoard_Success(t *testing.T) {
 	mockUserRepo := &MockUserRepositoryForBoardService{}
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 	mockHub := &MockHub{} // Initialize mock hub
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub, false)
 
 	boardID := uint(1)
 	ownerID := uint(10)
@@ -1448,7 +1534,7 @@ func TestBoardService_RemoveMemberFromBoard_BoardNotFound(t *testing.T) {
 	mockUserRepo := &MockUserRepositoryForBoardService{}
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 	mockHub := &MockHub{} // Initialize mock hub
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, mockHub, false)
 
 	boardID := uint(1)
 	ownerID := uint(10)
@@ -1477,7 +1563,7 @@ func TestBoardService_RemoveMemberFromBoard_Forbidden(t *testing.T) {
 	mockBoardRepo := &MockBoardRepository{}
 	mockUserRepo := &MockUserRepositoryForBoardService{}
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, &MockHub{}, false)
 
 	boardID := uint(1)
 	actualOwnerID := uint(5)
@@ -1489,8 +1575,11 @@ func TestBoardService_RemoveMemberFromBoard_Forbidden(t *testing.T) {
 	mockBoardRepo.FindByIDFunc = func(id uint) (*models.Board, error) {
 		return boardToReturn, nil
 	}
+	mockBoardMemberRepo.GetRoleFunc = func(bID uint, uID uint) (models.BoardRole, error) {
+		return models.BoardRoleMember, nil // Plain members cannot manage the board
+	}
 	mockBoardMemberRepo.IsMemberFunc = func(bID uint, uID uint) (bool, error) {
-		t.Error("IsMember should not be called if user is not owner")
+		t.Error("IsMember should not be called if user is not an admin")
 		return false, nil
 	}
 
@@ -1505,7 +1594,7 @@ func TestBoardService_RemoveMemberFromBoard_CannotRemoveOwner(t *testing.T) {
 	mockBoardRepo := &MockBoardRepository{}
 	mockUserRepo := &MockUserRepositoryForBoardService{}
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, &MockHub{}, false)
 
 	boardID := uint(1)
 	ownerID := uint(10)
@@ -1531,7 +1620,7 @@ func TestBoardService_RemoveMemberFromBoard_MemberNotFound(t *testing.T) {
 	mockBoardRepo := &MockBoardRepository{}
 	mockUserRepo := &MockUserRepositoryForBoardService{}
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, &MockHub{}, false)
 
 	boardID := uint(1)
 	ownerID := uint(10)
@@ -1560,7 +1649,7 @@ func TestBoardService_RemoveMemberFromBoard_ErrOnIsMember(t *testing.T) {
 	mockBoardRepo := &MockBoardRepository{}
 	mockUserRepo := &MockUserRepositoryForBoardService{}
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, &MockHub{}, false)
 
 	boardID := uint(1)
 	ownerID := uint(10)
@@ -1588,7 +1677,7 @@ func TestBoardService_RemoveMemberFromBoard_ErrOnRemoveMember(t *testing.T) {
 	mockBoardRepo := &MockBoardRepository{}
 	mockUserRepo := &MockUserRepositoryForBoardService{}
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, &MockHub{}, false)
 
 	boardID := uint(1)
 	ownerID := uint(10)
@@ -1611,11 +1700,139 @@ func TestBoardService_RemoveMemberFromBoard_ErrOnRemoveMember(t *testing.T) {
 	assert.Equal(t, memberToRemoveID, mockBoardMemberRepo.RemoveMemberCalledWithUserID)
 }
 
+func TestBoardService_UpdateMemberRole_Success(t *testing.T) {
+	mockBoardRepo := &MockBoardRepository{}
+	mockUserRepo := &MockUserRepositoryForBoardService{}
+	mockBoardMemberRepo := &MockBoardMemberRepository{}
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, &MockHub{}, false)
+
+	boardID := uint(1)
+	ownerID := uint(10)
+	memberID := uint(20)
+
+	mockBoardRepo.FindByIDFunc = func(id uint) (*models.Board, error) {
+		return &models.Board{Model: gorm.Model{ID: boardID}, OwnerID: ownerID}, nil
+	}
+	mockBoardMemberRepo.FindByBoardIDAndUserIDFunc = func(bID uint, uID uint) (*models.BoardMember, error) {
+		assert.Equal(t, boardID, bID)
+		assert.Equal(t, memberID, uID)
+		return &models.BoardMember{BoardID: bID, UserID: uID, Role: models.BoardRoleViewer}, nil
+	}
+
+	member, err := boardService.UpdateMemberRole(boardID, memberID, models.BoardRoleViewer, ownerID)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, member)
+	assert.Equal(t, models.BoardRoleViewer, member.Role)
+	assert.Equal(t, models.BoardRoleViewer, mockBoardMemberRepo.UpdateRoleCalledWithRole)
+}
+
+func TestBoardService_UpdateMemberRole_ByAdminMember(t *testing.T) {
+	mockBoardRepo := &MockBoardRepository{}
+	mockUserRepo := &MockUserRepositoryForBoardService{}
+	mockBoardMemberRepo := &MockBoardMemberRepository{}
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, &MockHub{}, false)
+
+	boardID := uint(1)
+	adminID := uint(15)
+	memberID := uint(20)
+
+	mockBoardRepo.FindByIDFunc = func(id uint) (*models.Board, error) {
+		return &models.Board{Model: gorm.Model{ID: boardID}, OwnerID: uint(10)}, nil
+	}
+	mockBoardMemberRepo.GetRoleFunc = func(bID uint, uID uint) (models.BoardRole, error) {
+		return models.BoardRoleAdmin, nil
+	}
+	mockBoardMemberRepo.FindByBoardIDAndUserIDFunc = func(bID uint, uID uint) (*models.BoardMember, error) {
+		return &models.BoardMember{BoardID: bID, UserID: uID, Role: models.BoardRoleAdmin}, nil
+	}
+
+	member, err := boardService.UpdateMemberRole(boardID, memberID, models.BoardRoleAdmin, adminID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.BoardRoleAdmin, member.Role)
+	assert.Equal(t, adminID, mockBoardMemberRepo.GetRoleCalledWithUserID)
+}
+
+func TestBoardService_UpdateMemberRole_Forbidden(t *testing.T) {
+	mockBoardRepo := &MockBoardRepository{}
+	mockUserRepo := &MockUserRepositoryForBoardService{}
+	mockBoardMemberRepo := &MockBoardMemberRepository{}
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, &MockHub{}, false)
+
+	mockBoardRepo.FindByIDFunc = func(id uint) (*models.Board, error) {
+		return &models.Board{Model: gorm.Model{ID: 1}, OwnerID: 10}, nil
+	}
+	mockBoardMemberRepo.GetRoleFunc = func(bID uint, uID uint) (models.BoardRole, error) {
+		return models.BoardRoleMember, nil // Plain members cannot change roles
+	}
+	mockBoardMemberRepo.UpdateRoleFunc = func(bID uint, uID uint, role models.BoardRole) error {
+		t.Error("UpdateRole should not be called if user is not an admin")
+		return nil
+	}
+
+	member, err := boardService.UpdateMemberRole(1, 20, models.BoardRoleAdmin, 15)
+
+	assert.Nil(t, member)
+	assert.Equal(t, ErrForbidden, err)
+}
+
+func TestBoardService_UpdateMemberRole_InvalidRole(t *testing.T) {
+	mockBoardRepo := &MockBoardRepository{}
+	mockUserRepo := &MockUserRepositoryForBoardService{}
+	mockBoardMemberRepo := &MockBoardMemberRepository{}
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, &MockHub{}, false)
+
+	mockBoardRepo.FindByIDFunc = func(id uint) (*models.Board, error) {
+		return &models.Board{Model: gorm.Model{ID: 1}, OwnerID: 10}, nil
+	}
+
+	member, err := boardService.UpdateMemberRole(1, 20, models.BoardRole("superuser"), 10)
+
+	assert.Nil(t, member)
+	assert.Equal(t, ErrInvalidBoardRole, err)
+}
+
+func TestBoardService_UpdateMemberRole_CannotChangeOwner(t *testing.T) {
+	mockBoardRepo := &MockBoardRepository{}
+	mockUserRepo := &MockUserRepositoryForBoardService{}
+	mockBoardMemberRepo := &MockBoardMemberRepository{}
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, &MockHub{}, false)
+
+	mockBoardRepo.FindByIDFunc = func(id uint) (*models.Board, error) {
+		return &models.Board{Model: gorm.Model{ID: 1}, OwnerID: 10}, nil
+	}
+
+	member, err := boardService.UpdateMemberRole(1, 10, models.BoardRoleViewer, 10)
+
+	assert.Nil(t, member)
+	assert.Equal(t, ErrCannotChangeOwner, err)
+}
+
+func TestBoardService_UpdateMemberRole_MemberNotFound(t *testing.T) {
+	mockBoardRepo := &MockBoardRepository{}
+	mockUserRepo := &MockUserRepositoryForBoardService{}
+	mockBoardMemberRepo := &MockBoardMemberRepository{}
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, &MockHub{}, false)
+
+	mockBoardRepo.FindByIDFunc = func(id uint) (*models.Board, error) {
+		return &models.Board{Model: gorm.Model{ID: 1}, OwnerID: 10}, nil
+	}
+	mockBoardMemberRepo.UpdateRoleFunc = func(bID uint, uID uint, role models.BoardRole) error {
+		return gorm.ErrRecordNotFound
+	}
+
+	member, err := boardService.UpdateMemberRole(1, 20, models.BoardRoleViewer, 10)
+
+	assert.Nil(t, member)
+	assert.Equal(t, ErrBoardMemberNotFound, err)
+}
+
 func TestBoardService_GetBoardMembers_SuccessAsOwner(t *testing.T) {
 	mockBoardRepo := &MockBoardRepository{}
 	mockUserRepo := &MockUserRepositoryForBoardService{}
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, &MockHub{}, false)
 
 	boardID := uint(1)
 	ownerID := uint(10)
@@ -1648,7 +1865,7 @@ func TestBoardService_GetBoardMembers_SuccessAsMember(t *testing.T) {
 	mockBoardRepo := &MockBoardRepository{}
 	mockUserRepo := &MockUserRepositoryForBoardService{}
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, &MockHub{}, false)
 
 	boardID := uint(1)
 	actualOwnerID := uint(5)
@@ -1661,10 +1878,10 @@ func TestBoardService_GetBoardMembers_SuccessAsMember(t *testing.T) {
 	}
 
 	mockBoardRepo.FindByIDFunc = func(id uint) (*models.Board, error) { return boardToReturn, nil }
-	mockBoardMemberRepo.IsMemberFunc = func(bID uint, uID uint) (bool, error) {
+	mockBoardMemberRepo.GetRoleFunc = func(bID uint, uID uint) (models.BoardRole, error) {
 		assert.Equal(t, boardID, bID)
 		assert.Equal(t, currentUserID, uID)
-		return true, nil
+		return models.BoardRoleMember, nil
 	}
 	mockBoardMemberRepo.FindMembersByBoardIDFunc = func(bID uint) ([]models.BoardMember, error) {
 		assert.Equal(t, boardID, bID)
@@ -1677,15 +1894,15 @@ func TestBoardService_GetBoardMembers_SuccessAsMember(t *testing.T) {
 	assert.Len(t, members, 2)
 	assert.Equal(t, expectedMembers, members)
 	assert.Equal(t, boardID, mockBoardMemberRepo.FindMembersByBoardIDCalledWith)
-	assert.Equal(t, boardID, mockBoardMemberRepo.IsMemberCalledWithBoardID)
-	assert.Equal(t, currentUserID, mockBoardMemberRepo.IsMemberCalledWithUserID)
+	assert.Equal(t, boardID, mockBoardMemberRepo.GetRoleCalledWithBoardID)
+	assert.Equal(t, currentUserID, mockBoardMemberRepo.GetRoleCalledWithUserID)
 }
 
 func TestBoardService_GetBoardMembers_BoardNotFound(t *testing.T) {
 	mockBoardRepo := &MockBoardRepository{}
 	mockUserRepo := &MockUserRepositoryForBoardService{}
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, &MockHub{}, false)
 
 	boardID := uint(1)
 	currentUserID := uint(10)
@@ -1714,7 +1931,7 @@ func TestBoardService_GetBoardMembers_Forbidden(t *testing.T) {
 	mockBoardRepo := &MockBoardRepository{}
 	mockUserRepo := &MockUserRepositoryForBoardService{}
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, &MockHub{}, false)
 
 	boardID := uint(1)
 	actualOwnerID := uint(5)
@@ -1723,10 +1940,10 @@ func TestBoardService_GetBoardMembers_Forbidden(t *testing.T) {
 	boardToReturn := &models.Board{Model: gorm.Model{ID: boardID}, OwnerID: actualOwnerID}
 
 	mockBoardRepo.FindByIDFunc = func(id uint) (*models.Board, error) { return boardToReturn, nil }
-	mockBoardMemberRepo.IsMemberFunc = func(bID uint, uID uint) (bool, error) {
+	mockBoardMemberRepo.GetRoleFunc = func(bID uint, uID uint) (models.BoardRole, error) {
 		assert.Equal(t, boardID, bID)
 		assert.Equal(t, currentUserID, uID)
-		return false, nil
+		return "", gorm.ErrRecordNotFound
 	}
 	mockBoardMemberRepo.FindMembersByBoardIDFunc = func(bID uint) ([]models.BoardMember, error) {
 		t.Error("FindMembersByBoardID should not be called if user is forbidden")
@@ -1740,32 +1957,32 @@ func TestBoardService_GetBoardMembers_Forbidden(t *testing.T) {
 	assert.Nil(t, members)
 }
 
-func TestBoardService_GetBoardMembers_ErrOnIsMember(t *testing.T) {
+func TestBoardService_GetBoardMembers_ErrOnGetRole(t *testing.T) {
 	mockBoardRepo := &MockBoardRepository{}
 	mockUserRepo := &MockUserRepositoryForBoardService{}
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, &MockHub{}, false)
 
 	boardID := uint(1)
 	actualOwnerID := uint(5)
 	currentUserID := uint(10)
-	expectedError := errors.New("DB error on IsMember")
+	expectedError := errors.New("DB error on GetRole")
 
 	boardToReturn := &models.Board{Model: gorm.Model{ID: boardID}, OwnerID: actualOwnerID}
 
 	mockBoardRepo.FindByIDFunc = func(id uint) (*models.Board, error) { return boardToReturn, nil }
-	mockBoardMemberRepo.IsMemberFunc = func(bID uint, uID uint) (bool, error) {
-		return false, expectedError
+	mockBoardMemberRepo.GetRoleFunc = func(bID uint, uID uint) (models.BoardRole, error) {
+		return "", expectedError
 	}
 	mockBoardMemberRepo.FindMembersByBoardIDFunc = func(bID uint) ([]models.BoardMember, error) {
-		t.Error("FindMembersByBoardID should not be called if IsMember errors")
+		t.Error("FindMembersByBoardID should not be called if GetRole errors")
 		return nil, nil
 	}
 
 	members, err := boardService.GetBoardMembers(boardID, currentUserID)
 
 	assert.Error(t, err)
-	assert.Equal(t, ErrForbidden, err)
+	assert.Equal(t, expectedError, err)
 	assert.Nil(t, members)
 }
 
@@ -1773,7 +1990,7 @@ func TestBoardService_GetBoardMembers_ErrOnFindMembersByBoardID(t *testing.T) {
 	mockBoardRepo := &MockBoardRepository{}
 	mockUserRepo := &MockUserRepositoryForBoardService{}
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, &MockHub{}, false)
 
 	boardID := uint(1)
 	ownerID := uint(10)
@@ -1799,7 +2016,7 @@ func TestBoardService_GetBoardByID_BoardNotFound(t *testing.T) {
 	mockUserRepo := &MockUserRepositoryForBoardService{}
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, &MockHub{}, false)
 
 	boardID := uint(1)
 	userID := uint(10)
@@ -1829,7 +2046,7 @@ func TestBoardService_GetBoardByID_Forbidden(t *testing.T) {
 	mockUserRepo := &MockUserRepositoryForBoardService{}
 	mockBoardMemberRepo := &MockBoardMemberRepository{}
 
-	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo)
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, &MockHub{}, false)
 
 	boardID := uint(1)
 	boardOwnerID := uint(10)
@@ -1846,10 +2063,10 @@ func TestBoardService_GetBoardByID_Forbidden(t *testing.T) {
 		return foundBoard, nil
 	}
 
-	mockBoardMemberRepo.IsMemberFunc = func(bID uint, uID uint) (bool, error) {
+	mockBoardMemberRepo.GetRoleFunc = func(bID uint, uID uint) (models.BoardRole, error) {
 		assert.Equal(t, boardID, bID)
 		assert.Equal(t, currentUserID, uID)
-		return false, nil
+		return "", gorm.ErrRecordNotFound
 	}
 
 	board, err := boardService.GetBoardByID(boardID, currentUserID)
@@ -1859,6 +2076,123 @@ func TestBoardService_GetBoardByID_Forbidden(t *testing.T) {
 	assert.Nil(t, board)
 
 	assert.Equal(t, boardID, mockBoardRepo.FindByIDCalledWith)
-	assert.Equal(t, boardID, mockBoardMemberRepo.IsMemberCalledWithBoardID)
-	assert.Equal(t, currentUserID, mockBoardMemberRepo.IsMemberCalledWithUserID)
+	assert.Equal(t, boardID, mockBoardMemberRepo.GetRoleCalledWithBoardID)
+	assert.Equal(t, currentUserID, mockBoardMemberRepo.GetRoleCalledWithUserID)
+}
+
+func TestBoardService_TransferOwnership_ByOwner(t *testing.T) {
+	mockBoardRepo := &MockBoardRepository{}
+	mockUserRepo := &MockUserRepositoryForBoardService{}
+	mockBoardMemberRepo := &MockBoardMemberRepository{}
+	var broadcast *realtime.WebSocketMessage
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, &MockHub{SubmitFunc: func(msg *realtime.WebSocketMessage) { broadcast = msg }}, false)
+
+	boardID := uint(1)
+	ownerID := uint(10)
+	memberID := uint(20)
+	owner := ownerID
+
+	mockBoardRepo.FindByIDFunc = func(id uint) (*models.Board, error) {
+		return &models.Board{Model: gorm.Model{ID: boardID}, OwnerID: owner}, nil
+	}
+	mockBoardMemberRepo.IsMemberFunc = func(bID uint, uID uint) (bool, error) {
+		return uID == memberID, nil
+	}
+	mockBoardRepo.TransferOwnershipFunc = func(bID uint, newOwnerID uint) error {
+		assert.Equal(t, boardID, bID)
+		owner = newOwnerID
+		return nil
+	}
+
+	board, err := boardService.TransferOwnership(boardID, memberID, ownerID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, memberID, board.OwnerID)
+	assert.Zero(t, mockBoardMemberRepo.RemoveMemberCalledWithBoardID, "the old owner stays a member")
+	if assert.NotNil(t, broadcast) {
+		assert.Equal(t, realtime.MessageTypeBoardOwnerChanged, broadcast.Type)
+		assert.Equal(t, realtime.BoardOwnerChangedPayload{BoardID: boardID, PreviousOwnerID: ownerID, NewOwnerID: memberID}, broadcast.Payload)
+	}
+}
+
+func TestBoardService_TransferOwnership_BySiteAdmin(t *testing.T) {
+	mockBoardRepo := &MockBoardRepository{}
+	mockUserRepo := &MockUserRepositoryForBoardService{}
+	mockBoardMemberRepo := &MockBoardMemberRepository{}
+	auditRepo := &MockAuditEventRepository{}
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, &MockHub{}, false, WithAuditService(NewAuditService(auditRepo)))
+
+	siteAdminID := uint(99) // Not on the board at all
+	mockBoardRepo.FindByIDFunc = func(id uint) (*models.Board, error) {
+		return &models.Board{Model: gorm.Model{ID: 1}, OwnerID: 10}, nil
+	}
+	mockBoardMemberRepo.GetRoleFunc = func(bID uint, uID uint) (models.BoardRole, error) {
+		return "", gorm.ErrRecordNotFound
+	}
+	mockUserRepo.FindByIDFunc = func(id uint) (*models.User, error) {
+		return &models.User{Model: gorm.Model{ID: id}, IsAdmin: id == siteAdminID}, nil
+	}
+	mockBoardMemberRepo.IsMemberFunc = func(bID uint, uID uint) (bool, error) { return true, nil }
+	transferred := false
+	mockBoardRepo.TransferOwnershipFunc = func(bID uint, newOwnerID uint) error {
+		transferred = true
+		return nil
+	}
+
+	_, err := boardService.TransferOwnership(1, 20, siteAdminID)
+	assert.NoError(t, err)
+	assert.True(t, transferred)
+	if assert.Len(t, auditRepo.Events, 1, "taking over someone else's board is audited") {
+		assert.Equal(t, models.AuditEventBoardTransferred, auditRepo.Events[0].Type)
+		assert.Equal(t, siteAdminID, *auditRepo.Events[0].ActorID)
+		assert.Equal(t, uint(10), *auditRepo.Events[0].UserID)
+	}
+
+	transferred = false
+	_, err = boardService.TransferOwnership(1, 20, 30)
+	assert.Equal(t, ErrForbidden, err, "other outsiders can't")
+	assert.False(t, transferred)
+}
+
+func TestBoardService_TransferOwnership_BoardAdminForbidden(t *testing.T) {
+	mockBoardRepo := &MockBoardRepository{}
+	mockUserRepo := &MockUserRepositoryForBoardService{}
+	mockBoardMemberRepo := &MockBoardMemberRepository{}
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, &MockHub{}, false)
+
+	mockBoardRepo.FindByIDFunc = func(id uint) (*models.Board, error) {
+		return &models.Board{Model: gorm.Model{ID: 1}, OwnerID: 10}, nil
+	}
+	mockBoardMemberRepo.GetRoleFunc = func(bID uint, uID uint) (models.BoardRole, error) {
+		return models.BoardRoleAdmin, nil
+	}
+	mockUserRepo.FindByIDFunc = func(id uint) (*models.User, error) {
+		return &models.User{Model: gorm.Model{ID: id}}, nil
+	}
+	mockBoardRepo.TransferOwnershipFunc = func(bID uint, newOwnerID uint) error {
+		t.Error("TransferOwnership should not be called for a board admin")
+		return nil
+	}
+
+	board, err := boardService.TransferOwnership(1, 15, 15)
+
+	assert.Nil(t, board)
+	assert.Equal(t, ErrForbidden, err)
+}
+
+func TestBoardService_TransferOwnership_InvalidTarget(t *testing.T) {
+	mockBoardRepo := &MockBoardRepository{}
+	mockUserRepo := &MockUserRepositoryForBoardService{}
+	mockBoardMemberRepo := &MockBoardMemberRepository{}
+	boardService := NewBoardService(mockBoardRepo, mockUserRepo, mockBoardMemberRepo, &MockHub{}, false)
+
+	mockBoardRepo.FindByIDFunc = func(id uint) (*models.Board, error) {
+		return &models.Board{Model: gorm.Model{ID: 1}, OwnerID: 10}, nil
+	}
+	mockBoardMemberRepo.IsMemberFunc = func(bID uint, uID uint) (bool, error) { return false, nil }
+
+	_, err := boardService.TransferOwnership(1, 10, 10)
+	assert.Equal(t, ErrAlreadyBoardOwner, err)
+	_, err = boardService.TransferOwnership(1, 30, 10)
+	assert.Equal(t, ErrNewOwnerNotMember, err)
 }
@@ -1,17 +1,20 @@
 package services
 
 import (
+	"errors"
 	"log"
 
-	"github.com/zayyadi/trello/dto" // Use new dto package for mappers
+	"github.com/zayyadi/trello/dto"    // Use new dto package for mappers
 	"github.com/zayyadi/trello/models" // Keep models import
+	"github.com/zayyadi/trello/policy"
 	"github.com/zayyadi/trello/realtime"
+	"github.com/zayyadi/trello/repositories"
 )
 
 // broadcastMessage constructs a WebSocketMessage and sends it to the hub.
 // userID is optional and can be used to prevent sending messages back to the originating user.
 func broadcastMessage(
-	hub *realtime.Hub,
+	hub realtime.Broadcaster,
 	boardID uint,
 	messageType string,
 	payload interface{},
@@ -66,3 +69,43 @@ func MapCardToPayload(card *models.Card) dto.CardResponse {
 	// Decide if user details should be included.
 	return dto.MapCardToResponse(card, true)
 }
+
+// disconnectAnonymousViewers drops the board's anonymous viewers that came through
+// the share link with shareLinkID, or because the board is public if it is zero,
+// or all of them for realtime.AnyShareLink. Hubs that don't track anonymous viewers are left alone.
+func disconnectAnonymousViewers(hub realtime.Broadcaster, boardID, shareLinkID uint) {
+	if disconnecter, ok := hub.(realtime.AnonymousDisconnecter); ok {
+		disconnecter.DisconnectAnonymous(boardID, shareLinkID)
+	}
+}
+
+// disconnectUser drops the user's clients on the board, or on every board for
+// realtime.AnyBoard. Hubs that don't track users are left alone.
+func disconnectUser(hub realtime.Broadcaster, userID, boardID uint) {
+	if disconnecter, ok := hub.(realtime.UserDisconnecter); ok {
+		disconnecter.DisconnectUser(userID, boardID)
+	}
+}
+
+// disconnectSession drops the clients the user opened with an access token of the session.
+func disconnectSession(hub realtime.Broadcaster, userID uint, sessionID string) {
+	if disconnecter, ok := hub.(realtime.UserDisconnecter); ok {
+		disconnecter.DisconnectSession(userID, sessionID)
+	}
+}
+
+// disconnectFormerViewer drops the user's clients on the board unless they can
+// still view it, e.g. through the board's workspace after leaving the board.
+func disconnectFormerViewer(
+	hub realtime.Broadcaster,
+	boardRepo repositories.BoardRepositoryInterface,
+	boardMemberRepo repositories.BoardMemberRepositoryInterface,
+	boardID, userID uint,
+) {
+	_, _, err := authorizeBoardAction(boardRepo, boardMemberRepo, boardID, userID, policy.ViewBoard)
+	if errors.Is(err, ErrForbidden) || errors.Is(err, ErrBoardNotFound) {
+		disconnectUser(hub, userID, boardID)
+	} else if err != nil {
+		log.Printf("ERROR: Failed to check whether user %d can still view board %d: %v", userID, boardID, err)
+	}
+}
@@ -3,12 +3,14 @@ package services
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/zayyadi/trello/dto" // Changed import
 	"github.com/zayyadi/trello/models"
-	"github.com/zayyadi/trello/repositories"
+	"github.com/zayyadi/trello/policy"
 	"github.com/zayyadi/trello/realtime"
+	"github.com/zayyadi/trello/repositories"
 
 	"gorm.io/gorm"
 )
@@ -21,6 +23,7 @@ type CardServiceInterface interface {
 	UpdateCard(cardID uint, title, description *string, newPosition *uint, dueDate *time.Time, assignedUserID **uint, supervisorID **uint, status *models.CardStatus, color *string, currentUserID uint) (*models.Card, error)
 	DeleteCard(cardID uint, currentUserID uint) error
 	MoveCard(cardID uint, targetListID uint, newPosition uint, currentUserID uint) (*models.Card, error)
+	ReorderCards(listID uint, cardIDs []uint, currentUserID uint) ([]models.Card, error)
 	AddCollaboratorToCard(cardID uint, currentUserID uint, targetUserEmail string, targetUserIDInput *uint) (*models.User, error)
 	RemoveCollaboratorFromCard(cardID uint, currentUserID uint, targetUserID uint) error
 	GetCardCollaborators(cardID uint, currentUserID uint) ([]models.User, error)
@@ -32,7 +35,7 @@ type CardService struct {
 	boardRepo       repositories.BoardRepositoryInterface // For permission checks
 	boardMemberRepo repositories.BoardMemberRepositoryInterface
 	userRepo        repositories.UserRepositoryInterface // Added for collaborator methods
-	hub             *realtime.Hub
+	hub             realtime.Broadcaster
 }
 
 func NewCardService(
@@ -41,7 +44,7 @@ func NewCardService(
 	boardRepo repositories.BoardRepositoryInterface,
 	boardMemberRepo repositories.BoardMemberRepositoryInterface,
 	userRepo repositories.UserRepositoryInterface, // Added
-	hub *realtime.Hub,
+	hub realtime.Broadcaster,
 ) CardServiceInterface { // Return interface type
 	return &CardService{
 		cardRepo:        cardRepo,
@@ -53,51 +56,44 @@ func NewCardService(
 	}
 }
 
-// Helper to check board access via list
-func (s *CardService) checkAccessViaList(userID, listID uint) (uint, error) {
+// Helper to check board access via list. The policy must allow action on the list's board.
+func (s *CardService) checkAccessViaList(userID, listID uint, action policy.Action) (uint, policy.Subject, error) { // Returns boardID, subject
 	boardID, err := s.listRepo.GetBoardIDByListID(listID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return 0, ErrListNotFound
+			return 0, policy.Subject{}, ErrListNotFound
 		}
-		return 0, err
+		return 0, policy.Subject{}, err
 	}
 
-	board, err := s.boardRepo.FindByID(boardID)
+	_, subject, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, boardID, userID, action)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return 0, ErrBoardNotFound
-		}
-		return 0, err
+		return 0, policy.Subject{}, err
 	}
-	if board.OwnerID == userID {
-		return boardID, nil
-	}
-	isMember, err := s.boardMemberRepo.IsMember(boardID, userID)
-	if err != nil || !isMember {
-		return 0, ErrForbidden
-	}
-	return boardID, nil
+	return boardID, subject, nil
 }
 
 // Helper to check board access via card
-func (s *CardService) checkAccessViaCard(userID, cardID uint) (uint, uint, error) { // Returns boardID, listID
+func (s *CardService) checkAccessViaCard(userID, cardID uint, action policy.Action) (uint, uint, policy.Subject, error) { // Returns boardID, listID, subject
 	listID, err := s.cardRepo.GetListIDByCardID(cardID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return 0, 0, ErrCardNotFound
+			return 0, 0, policy.Subject{}, ErrCardNotFound
 		}
-		return 0, 0, err
+		return 0, 0, policy.Subject{}, err
 	}
-	boardID, err := s.checkAccessViaList(userID, listID)
-	return boardID, listID, err
+	boardID, subject, err := s.checkAccessViaList(userID, listID, action)
+	return boardID, listID, subject, err
 }
 
 func (s *CardService) CreateCard(listID uint, title, description string, position *uint, dueDate *time.Time, assignedUserID *uint, supervisorID *uint, color *string, currentUserID uint) (*models.Card, error) {
-	boardID, err := s.checkAccessViaList(currentUserID, listID)
+	boardID, _, err := s.checkAccessViaList(currentUserID, listID, policy.CreateCard)
 	if err != nil {
 		return nil, err
 	}
+	if position != nil && *position < 1 {
+		return nil, ErrPositionOutOfBound
+	}
 
 	card := &models.Card{
 		ListID:         listID,
@@ -109,14 +105,14 @@ func (s *CardService) CreateCard(listID uint, title, description string, positio
 		Status:         models.StatusToDo, // Default status
 		Color:          color,             // Add color
 	}
-	// Position handling will be done by the repository's Create method typically
-	if position != nil { // If a specific position is requested by client (less common for create)
-		card.Position = *position // Note: This might need more complex logic if repo doesn't handle reordering on create
-	}
-
-	if err := s.cardRepo.Create(card); err != nil {
+	if err := s.cardRepo.Create(card); err != nil { // Appended to the list
 		return nil, err
 	}
+	if position != nil { // A specific position was requested by the client
+		if err := s.cardRepo.MoveCard(card.ID, listID, *position); err != nil {
+			return nil, err
+		}
+	}
 
 	createdCard, err := s.cardRepo.FindByID(card.ID) // Will now preload supervisor too
 	if err != nil {
@@ -136,34 +132,14 @@ func (s *CardService) CreateCard(listID uint, title, description string, positio
 }
 
 func (s *CardService) GetCardByID(cardID uint, currentUserID uint) (*models.Card, error) {
-	boardID, _, err := s.checkAccessViaCard(currentUserID, cardID)
-	if err != nil {
-		return nil, err
+	if _, _, _, err := s.checkAccessViaCard(currentUserID, cardID, policy.ViewBoard); err != nil {
+		return nil, err // Anyone who can see the board can see its cards
 	}
-
-	board, err := s.boardRepo.FindByID(boardID)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrBoardNotFound
-		}
-		return nil, err
-	}
-
-	isOwner := (board.OwnerID == currentUserID)
-	isCollaboratorOrAssignee, collabErr := s.cardRepo.IsUserCollaboratorOrAssignee(cardID, currentUserID)
-	if collabErr != nil && !errors.Is(collabErr, gorm.ErrRecordNotFound) { // Allow if card not found for IsCollaboratorOrAssignee if it implies no relations
-		return nil, collabErr
-	}
-
-	if !isOwner && !isCollaboratorOrAssignee {
-		return nil, ErrForbidden
-	}
-
 	return s.cardRepo.FindByID(cardID)
 }
 
 func (s *CardService) GetCardsByListID(listID uint, currentUserID uint) ([]models.Card, error) {
-	if _, err := s.checkAccessViaList(currentUserID, listID); err != nil {
+	if _, _, err := s.checkAccessViaList(currentUserID, listID, policy.ViewBoard); err != nil {
 		return nil, err
 	}
 	return s.cardRepo.FindByListID(listID)
@@ -180,62 +156,62 @@ func (s *CardService) UpdateCard(
 	color *string, // Add color
 	currentUserID uint,
 ) (*models.Card, error) {
-	boardID, listID, err := s.checkAccessViaCard(currentUserID, cardID)
+	boardID, listID, subject, err := s.checkAccessViaCard(currentUserID, cardID, policy.ViewBoard)
 	if err != nil {
 		return nil, err
 	}
 
-	board, err := s.boardRepo.FindByID(boardID)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrBoardNotFound
-		}
-		return nil, err
-	}
-
 	card, err := s.cardRepo.FindByID(cardID)
 	if err != nil {
 		return nil, ErrCardNotFound
 	}
 
-	isOwner := (board.OwnerID == currentUserID)
 	isCollaboratorOrAssignee, collabErr := s.cardRepo.IsUserCollaboratorOrAssignee(cardID, currentUserID)
 	if collabErr != nil && !errors.Is(collabErr, gorm.ErrRecordNotFound) {
 		return nil, collabErr // Propagate actual DB errors
 	}
+	subject.IsCardParticipant = isCollaboratorOrAssignee
+	canEdit := policy.Can(subject, policy.EditCard)
+	canRename := policy.Can(subject, policy.RenameCard)
+	if !canEdit && !canRename {
+		return nil, ErrPermissionDenied // e.g. viewers, or members not working on this card
+	}
+	if err := ensureBoardWritable(s.boardRepo, boardID); err != nil {
+		return nil, err // Access was checked with ViewBoard, which archived boards allow
+	}
 
 	if title != nil {
-		if !isOwner {
+		if !canRename {
 			return nil, ErrPermissionDenied
 		}
 		card.Title = *title
 	}
 	if description != nil {
-		if !isOwner && !isCollaboratorOrAssignee {
+		if !canEdit {
 			return nil, ErrPermissionDenied
 		}
 		card.Description = *description
 	}
 	if dueDate != nil { // No double pointer, direct update or keep old
-		if !isOwner && !isCollaboratorOrAssignee {
+		if !canEdit {
 			return nil, ErrPermissionDenied
 		}
 		card.DueDate = dueDate
 	}
 	if assignedUserID != nil {
-		if !isOwner && !isCollaboratorOrAssignee {
+		if !canEdit {
 			return nil, ErrPermissionDenied
 		}
 		card.AssignedUserID = *assignedUserID
 	}
 	if supervisorID != nil { // New field
-		if !isOwner && !isCollaboratorOrAssignee {
+		if !canEdit {
 			return nil, ErrPermissionDenied
 		}
 		card.SupervisorID = *supervisorID
 	}
 	if status != nil { // New field
-		if !isOwner && !isCollaboratorOrAssignee {
+		if !canEdit {
 			return nil, ErrPermissionDenied
 		}
 		// Basic validation for status
@@ -247,7 +223,7 @@ func (s *CardService) UpdateCard(
 		}
 	}
 	if color != nil { // Add color update
-		if !isOwner && !isCollaboratorOrAssignee {
+		if !canEdit {
 			return nil, ErrPermissionDenied
 		}
 		if *color == "" { // Allow clearing the color
@@ -257,40 +233,20 @@ func (s *CardService) UpdateCard(
 		}
 	}
 
-	// Handle position update within the same list
-	if newPosition != nil && card.Position != *newPosition {
-		if !isOwner && !isCollaboratorOrAssignee {
+	// Handle position update within the same list; only this card's rank changes
+	if newPosition != nil {
+		if !canEdit {
 			return nil, ErrPermissionDenied
 		}
-		currentPosition := card.Position
-		targetPosition := *newPosition
-
-		err = s.cardRepo.PerformTransaction(func(tx *gorm.DB) error {
-			// Adjust positions of other cards
-			if targetPosition < currentPosition { // Moving up
-				if err := tx.Model(&models.Card{}).
-					Where("list_id = ? AND position >= ? AND position < ?", listID, targetPosition, currentPosition).
-					Where("id != ?", cardID). // Exclude the card being moved
-					Update("position", gorm.Expr("position + 1")).Error; err != nil {
-					return err
-				}
-			} else { // Moving down
-				if err := tx.Model(&models.Card{}).
-					Where("list_id = ? AND position > ? AND position <= ?", listID, currentPosition, targetPosition).
-					Where("id != ?", cardID). // Exclude the card being moved
-					Update("position", gorm.Expr("position - 1")).Error; err != nil {
-					return err
-				}
-			}
-			// Update the current card's position and other fields
-			card.Position = targetPosition
-			return tx.Save(card).Error
-		})
-		if err != nil {
-			return nil, err
+		if *newPosition < 1 {
+			return nil, ErrPositionOutOfBound
 		}
-	} else { // No position change, or only other fields (including color) updated
-		if err := s.cardRepo.Update(card); err != nil {
+	}
+	if err := s.cardRepo.Update(card); err != nil {
+		return nil, err
+	}
+	if newPosition != nil {
+		if err := s.cardRepo.MoveCard(cardID, listID, *newPosition); err != nil {
 			return nil, err
 		}
 	}
@@ -316,52 +272,26 @@ func (s *CardService) UpdateCard(
 		} else { // Assigned or changed assignee
 			// We might want to include more assignee details in the payload here
 			assigneePayload := struct {
-				CardID    uint  `json:"cardId"`
-				UserID    uint  `json:"userId"`
-				BoardID   uint  `json:"boardId"`
-				ListID    uint  `json:"listId"`
+				CardID  uint `json:"cardId"`
+				UserID  uint `json:"userId"`
+				BoardID uint `json:"boardId"`
+				ListID  uint `json:"listId"`
 			}{cardID, **assignedUserID, boardID, listID}
 			broadcastMessage(s.hub, boardID, realtime.MessageTypeCardAssigned, assigneePayload, currentUserID)
 		}
 	}
 
-
 	return updatedCard, nil
 }
 
 func (s *CardService) DeleteCard(cardID uint, currentUserID uint) error {
-	boardID, listID, err := s.checkAccessViaCard(currentUserID, cardID)
-	if err != nil {
-		return err
-	}
-
-	board, err := s.boardRepo.FindByID(boardID)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ErrBoardNotFound
-		}
-		return err
-	}
-
-	if board.OwnerID != currentUserID {
-		return ErrForbidden
-	}
-
-	card, err := s.cardRepo.FindByID(cardID)
+	boardID, listID, _, err := s.checkAccessViaCard(currentUserID, cardID, policy.DeleteCard)
 	if err != nil {
-		return ErrCardNotFound
+		return err // Only admins can delete cards
 	}
 
-	err = s.cardRepo.PerformTransaction(func(tx *gorm.DB) error {
-		// Shift positions of subsequent cards in the same list
-		if err := tx.Model(&models.Card{}).
-			Where("list_id = ? AND position > ?", listID, card.Position).
-			Update("position", gorm.Expr("position - 1")).Error; err != nil {
-			return err
-		}
-		// Delete the card
-		return tx.Delete(&models.Card{}, cardID).Error
-	})
+	// The other cards keep their ranks, and so does this one if it's restored
+	err = s.cardRepo.Delete(cardID)
 
 	if err == nil {
 		// Broadcast card deletion
@@ -377,24 +307,21 @@ func (s *CardService) DeleteCard(cardID uint, currentUserID uint) error {
 }
 
 func (s *CardService) MoveCard(cardID uint, targetListID uint, newPosition uint, currentUserID uint) (*models.Card, error) {
-	boardID, originalListID, err := s.checkAccessViaCard(currentUserID, cardID)
+	boardID, originalListID, _, err := s.checkAccessViaCard(currentUserID, cardID, policy.MoveCard)
 	if err != nil {
 		return nil, err
 	}
-	targetBoardID, err := s.checkAccessViaList(currentUserID, targetListID) // Check access to target list
+	targetBoardID, _, err := s.checkAccessViaList(currentUserID, targetListID, policy.MoveCard) // Check access to target list
 	if err != nil {
 		return nil, err
 	}
-
-	if boardID != targetBoardID {
-		// Moving card across boards is not supported by this message structure easily,
-		// as broadcasting is per-board. This implies an error or more complex handling needed.
-		// For now, assume targetListID is on the same board.
-		// If they can differ, the broadcasting logic needs to be revisited (e.g., two messages).
-		return nil, errors.New("moving card to a different board is not directly supported for simple broadcast")
+	if targetBoardID != boardID {
+		// Taking the card off its board is as good as deleting it there
+		if _, _, err := s.checkAccessViaList(currentUserID, originalListID, policy.DeleteCard); err != nil {
+			return nil, err
+		}
 	}
 
-
 	card, err := s.cardRepo.FindByID(cardID)
 	if err != nil {
 		return nil, ErrCardNotFound
@@ -407,7 +334,11 @@ func (s *CardService) MoveCard(cardID uint, targetListID uint, newPosition uint,
 	}
 	// More complex validation: newPosition <= max cards in target list + 1
 
-	err = s.cardRepo.MoveCard(cardID, originalListID, targetListID, newPosition)
+	if boardID != targetBoardID {
+		return s.moveCardToBoard(card, boardID, targetBoardID, targetListID, newPosition, currentUserID)
+	}
+
+	err = s.cardRepo.MoveCard(cardID, targetListID, newPosition)
 	if err != nil {
 		return nil, err
 	}
@@ -425,7 +356,6 @@ func (s *CardService) MoveCard(cardID uint, targetListID uint, newPosition uint,
 		OldPosition: originalPosition,
 		NewPosition: movedCard.Position, // Use the final position from the moved card
 		BoardID:     boardID,
-		// UpdatedCards could be populated here if the MoveCard repo method returned them
 	}
 	broadcastMessage(
 		s.hub,
@@ -438,23 +368,102 @@ func (s *CardService) MoveCard(cardID uint, targetListID uint, newPosition uint,
 	return movedCard, nil
 }
 
-// AddCollaboratorToCard adds a user as a collaborator to a card.
-func (s *CardService) AddCollaboratorToCard(cardID uint, currentUserID uint, targetUserEmail string, targetUserIDInput *uint) (*models.User, error) {
-	boardID, _, err := s.checkAccessViaCard(currentUserID, cardID)
+// ReorderCards puts the list's cards in the given order, which must name each
+// of its active cards once. Archived cards stay out of the order.
+func (s *CardService) ReorderCards(listID uint, cardIDs []uint, currentUserID uint) ([]models.Card, error) {
+	boardID, _, err := s.checkAccessViaList(currentUserID, listID, policy.MoveCard)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.cardRepo.Reorder(listID, cardIDs); err != nil {
+		if errors.Is(err, repositories.ErrOrderMismatch) {
+			return nil, fmt.Errorf("%w: the new order must list each of the list's cards exactly once", ErrInvalidInput)
+		}
+		return nil, err
+	}
+	cards, err := s.cardRepo.FindByListID(listID)
 	if err != nil {
 		return nil, err
 	}
 
-	board, err := s.boardRepo.FindByID(boardID) // Note: This was the line with the error, ensure 'err' is properly assigned.
+	payload := realtime.CardsReorderedPayload{ListID: listID, BoardID: boardID, CardIDs: make([]uint, len(cards)), Positions: make([]string, len(cards))}
+	for i, card := range cards {
+		payload.CardIDs[i], payload.Positions[i] = card.ID, card.Position
+	}
+	broadcastMessage(s.hub, boardID, realtime.MessageTypeCardsReordered, payload, currentUserID)
+	return cards, nil
+}
+
+// moveCardToBoard moves a card to a list on another board. Everyone on the card
+// who can't see the target board is taken off it, so the card doesn't point
+// them at a board they aren't on. Clients of the old board see the card removed
+// and those of the target board see it created.
+func (s *CardService) moveCardToBoard(card *models.Card, boardID, targetBoardID, targetListID, newPosition, currentUserID uint) (*models.Card, error) {
+	removedUserIDs, err := s.usersOutsideBoard(card, targetBoardID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.cardRepo.MoveCardToBoard(repositories.CardBoardMove{
+		CardID:         card.ID,
+		NewListID:      targetListID,
+		NewPosition:    newPosition,
+		TargetBoardID:  targetBoardID,
+		RemovedUserIDs: removedUserIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	movedCard, err := s.cardRepo.FindByID(card.ID)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrBoardNotFound
-		}
 		return nil, err
 	}
 
-	if board.OwnerID != currentUserID {
-		return nil, ErrForbidden
+	broadcastMessage(s.hub, boardID, realtime.MessageTypeCardDeleted,
+		realtime.CardBasicInfo{ID: card.ID, ListID: card.ListID, BoardID: boardID}, currentUserID)
+	broadcastMessage(s.hub, targetBoardID, realtime.MessageTypeCardCreated, dto.MapCardToResponse(movedCard, true), currentUserID)
+	return movedCard, nil
+}
+
+// usersOutsideBoard returns the card's assignee, supervisor, collaborators and
+// checklist item assignees who can't see the board.
+func (s *CardService) usersOutsideBoard(card *models.Card, boardID uint) ([]uint, error) {
+	var userIDs []uint
+	if card.AssignedUserID != nil {
+		userIDs = append(userIDs, *card.AssignedUserID)
+	}
+	if card.SupervisorID != nil {
+		userIDs = append(userIDs, *card.SupervisorID)
+	}
+	for _, collaborator := range card.Collaborators {
+		userIDs = append(userIDs, collaborator.ID)
+	}
+	for _, checklist := range card.Checklists {
+		for _, item := range checklist.Items {
+			if item.AssignedUserID != nil {
+				userIDs = append(userIDs, *item.AssignedUserID)
+			}
+		}
+	}
+	slices.Sort(userIDs)
+
+	var outside []uint
+	for _, userID := range slices.Compact(userIDs) {
+		if _, _, err := resolveBoardSubject(s.boardRepo, s.boardMemberRepo, boardID, userID); errors.Is(err, ErrForbidden) {
+			outside = append(outside, userID)
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	return outside, nil
+}
+
+// AddCollaboratorToCard adds a user as a collaborator to a card.
+func (s *CardService) AddCollaboratorToCard(cardID uint, currentUserID uint, targetUserEmail string, targetUserIDInput *uint) (*models.User, error) {
+	boardID, _, _, err := s.checkAccessViaCard(currentUserID, cardID, policy.ManageCollaborators)
+	if err != nil {
+		return nil, err // Only admins can manage collaborators
 	}
 
 	var targetUser *models.User
@@ -509,21 +518,9 @@ func (s *CardService) AddCollaboratorToCard(cardID uint, currentUserID uint, tar
 
 // RemoveCollaboratorFromCard removes a collaborator from a card.
 func (s *CardService) RemoveCollaboratorFromCard(cardID uint, currentUserID uint, targetUserID uint) error {
-	boardID, _, err := s.checkAccessViaCard(currentUserID, cardID)
-	if err != nil {
-		return err // Ensure current user has access to the card's board
-	}
-
-	board, err := s.boardRepo.FindByID(boardID)
+	boardID, _, _, err := s.checkAccessViaCard(currentUserID, cardID, policy.ManageCollaborators)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ErrBoardNotFound // Return specific error
-		}
-		return err
-	}
-
-	if board.OwnerID != currentUserID {
-		return ErrForbidden
+		return err // Only admins can manage collaborators
 	}
 
 	// Check if target user is actually a collaborator
@@ -562,7 +559,7 @@ func (s *CardService) RemoveCollaboratorFromCard(cardID uint, currentUserID uint
 
 // GetCardCollaborators retrieves all collaborators for a card.
 func (s *CardService) GetCardCollaborators(cardID uint, currentUserID uint) ([]models.User, error) {
-	if _, _, err := s.checkAccessViaCard(currentUserID, cardID); err != nil {
+	if _, _, _, err := s.checkAccessViaCard(currentUserID, cardID, policy.ViewBoard); err != nil {
 		return nil, err // Ensure current user has access
 	}
 	return s.cardRepo.GetCollaboratorsByCardID(cardID)
@@ -0,0 +1,338 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zayyadi/trello/dto"
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/policy"
+	"github.com/zayyadi/trello/realtime"
+	"github.com/zayyadi/trello/repositories"
+
+	"gorm.io/gorm"
+)
+
+// ChecklistItemUpdate holds the changes to a checklist item. Nil fields are left as they are.
+type ChecklistItemUpdate struct {
+	Content        *string
+	Completed      *bool
+	AssignedUserID *uint // 0 unassigns the item
+	DueDate        *time.Time
+	ClearDueDate   bool
+}
+
+// ChecklistService manages the checklists on cards and their items. Changing
+// them counts as editing the card, so card participants may work on their own cards.
+type ChecklistService struct {
+	checklistRepo   repositories.ChecklistRepositoryInterface
+	cardRepo        repositories.CardRepositoryInterface
+	listRepo        repositories.ListRepositoryInterface
+	boardRepo       repositories.BoardRepositoryInterface
+	boardMemberRepo repositories.BoardMemberRepositoryInterface
+	hub             realtime.Broadcaster
+	now             func() time.Time // Replaced in tests
+}
+
+func NewChecklistService(
+	checklistRepo repositories.ChecklistRepositoryInterface,
+	cardRepo repositories.CardRepositoryInterface,
+	listRepo repositories.ListRepositoryInterface,
+	boardRepo repositories.BoardRepositoryInterface,
+	boardMemberRepo repositories.BoardMemberRepositoryInterface,
+	hub realtime.Broadcaster,
+) *ChecklistService {
+	return &ChecklistService{
+		checklistRepo:   checklistRepo,
+		cardRepo:        cardRepo,
+		listRepo:        listRepo,
+		boardRepo:       boardRepo,
+		boardMemberRepo: boardMemberRepo,
+		hub:             hub,
+		now:             time.Now,
+	}
+}
+
+// GetChecklists lists the card's checklists with their items.
+func (s *ChecklistService) GetChecklists(cardID, userID uint) ([]models.Checklist, error) {
+	if _, err := s.authorize(cardID, userID, policy.ViewBoard); err != nil {
+		return nil, err
+	}
+	return s.checklistRepo.FindByCardID(cardID)
+}
+
+// CreateChecklist adds an empty checklist at the end of the card's checklists.
+func (s *ChecklistService) CreateChecklist(cardID uint, title string, userID uint) (*models.Checklist, error) {
+	boardID, err := s.authorize(cardID, userID, policy.EditCard)
+	if err != nil {
+		return nil, err
+	}
+	checklist := &models.Checklist{CardID: cardID, Title: title}
+	if err := s.checklistRepo.Create(checklist); err != nil {
+		return nil, err
+	}
+
+	broadcastMessage(s.hub, boardID, realtime.MessageTypeChecklistCreated, dto.MapChecklistToResponse(checklist), userID)
+	return checklist, nil
+}
+
+// UpdateChecklist renames one of the card's checklists.
+func (s *ChecklistService) UpdateChecklist(cardID, checklistID uint, title string, userID uint) (*models.Checklist, error) {
+	boardID, err := s.authorize(cardID, userID, policy.EditCard)
+	if err != nil {
+		return nil, err
+	}
+	checklist, err := s.findChecklist(cardID, checklistID)
+	if err != nil {
+		return nil, err
+	}
+	checklist.Title = title
+	if err := s.checklistRepo.Update(checklist); err != nil {
+		return nil, err
+	}
+
+	broadcastMessage(s.hub, boardID, realtime.MessageTypeChecklistUpdated, dto.MapChecklistToResponse(checklist), userID)
+	return checklist, nil
+}
+
+// DeleteChecklist removes one of the card's checklists with its items.
+func (s *ChecklistService) DeleteChecklist(cardID, checklistID, userID uint) error {
+	boardID, err := s.authorize(cardID, userID, policy.EditCard)
+	if err != nil {
+		return err
+	}
+	if _, err := s.findChecklist(cardID, checklistID); err != nil {
+		return err
+	}
+	if err := s.checklistRepo.Delete(checklistID); err != nil {
+		return err
+	}
+
+	broadcastMessage(s.hub, boardID, realtime.MessageTypeChecklistDeleted,
+		realtime.ChecklistBasicInfo{ID: checklistID, CardID: cardID, BoardID: boardID}, userID)
+	return nil
+}
+
+// ReorderChecklists puts the card's checklists in the given order. checklistIDs
+// must list each of them exactly once.
+func (s *ChecklistService) ReorderChecklists(cardID uint, checklistIDs []uint, userID uint) ([]models.Checklist, error) {
+	boardID, err := s.authorize(cardID, userID, policy.EditCard)
+	if err != nil {
+		return nil, err
+	}
+	checklists, err := s.checklistRepo.FindByCardID(cardID)
+	if err != nil {
+		return nil, err
+	}
+	current := make([]uint, len(checklists))
+	for i, checklist := range checklists {
+		current[i] = checklist.ID
+	}
+	if err := checkSameIDs(current, checklistIDs, "checklists"); err != nil {
+		return nil, err
+	}
+	if err := s.checklistRepo.Reorder(cardID, checklistIDs); err != nil {
+		return nil, err
+	}
+
+	broadcastMessage(s.hub, boardID, realtime.MessageTypeChecklistsReordered,
+		realtime.ChecklistsReorderedPayload{CardID: cardID, BoardID: boardID, ChecklistIDs: checklistIDs}, userID)
+	return s.checklistRepo.FindByCardID(cardID)
+}
+
+// CreateItem adds an item at the end of one of the card's checklists. The
+// assignee, if any, must be able to see the board.
+func (s *ChecklistService) CreateItem(cardID, checklistID uint, content string, assignedUserID *uint, dueDate *time.Time, userID uint) (*models.ChecklistItem, error) {
+	boardID, err := s.authorize(cardID, userID, policy.EditCard)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.findChecklist(cardID, checklistID); err != nil {
+		return nil, err
+	}
+	if assignedUserID != nil {
+		if err := s.checkAssignee(boardID, *assignedUserID); err != nil {
+			return nil, err
+		}
+	}
+
+	item := &models.ChecklistItem{
+		ChecklistID:    checklistID,
+		Content:        content,
+		AssignedUserID: assignedUserID,
+		DueDate:        dueDate,
+	}
+	if err := s.checklistRepo.CreateItem(item); err != nil {
+		return nil, err
+	}
+	createdItem, err := s.checklistRepo.FindItemByID(item.ID) // With the assignee
+	if err != nil {
+		return nil, err
+	}
+
+	broadcastMessage(s.hub, boardID, realtime.MessageTypeChecklistItemCreated, dto.MapChecklistItemToResponse(createdItem), userID)
+	return createdItem, nil
+}
+
+// UpdateItem changes an item of one of the card's checklists: its text, whether
+// it is done, its assignee or its due date.
+func (s *ChecklistService) UpdateItem(cardID, checklistID, itemID uint, update ChecklistItemUpdate, userID uint) (*models.ChecklistItem, error) {
+	boardID, err := s.authorize(cardID, userID, policy.EditCard)
+	if err != nil {
+		return nil, err
+	}
+	item, err := s.findItem(cardID, checklistID, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Content != nil {
+		item.Content = *update.Content
+	}
+	if update.Completed != nil && *update.Completed != item.Completed {
+		item.Completed = *update.Completed
+		item.CompletedAt = nil
+		if item.Completed {
+			now := s.now()
+			item.CompletedAt = &now
+		}
+	}
+	if update.AssignedUserID != nil {
+		if *update.AssignedUserID == 0 {
+			item.AssignedUserID = nil
+		} else {
+			if err := s.checkAssignee(boardID, *update.AssignedUserID); err != nil {
+				return nil, err
+			}
+			item.AssignedUserID = update.AssignedUserID
+		}
+	}
+	if update.ClearDueDate {
+		item.DueDate = nil
+	} else if update.DueDate != nil {
+		item.DueDate = update.DueDate
+	}
+	if err := s.checklistRepo.UpdateItem(item); err != nil {
+		return nil, err
+	}
+	updatedItem, err := s.checklistRepo.FindItemByID(item.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	broadcastMessage(s.hub, boardID, realtime.MessageTypeChecklistItemUpdated, dto.MapChecklistItemToResponse(updatedItem), userID)
+	return updatedItem, nil
+}
+
+// DeleteItem removes an item from one of the card's checklists.
+func (s *ChecklistService) DeleteItem(cardID, checklistID, itemID, userID uint) error {
+	boardID, err := s.authorize(cardID, userID, policy.EditCard)
+	if err != nil {
+		return err
+	}
+	if _, err := s.findItem(cardID, checklistID, itemID); err != nil {
+		return err
+	}
+	if err := s.checklistRepo.DeleteItem(itemID); err != nil {
+		return err
+	}
+
+	broadcastMessage(s.hub, boardID, realtime.MessageTypeChecklistItemDeleted,
+		realtime.ChecklistItemBasicInfo{ID: itemID, ChecklistID: checklistID, CardID: cardID, BoardID: boardID}, userID)
+	return nil
+}
+
+// ReorderItems puts a checklist's items in the given order. itemIDs must list
+// each of them exactly once.
+func (s *ChecklistService) ReorderItems(cardID, checklistID uint, itemIDs []uint, userID uint) (*models.Checklist, error) {
+	boardID, err := s.authorize(cardID, userID, policy.EditCard)
+	if err != nil {
+		return nil, err
+	}
+	checklist, err := s.findChecklist(cardID, checklistID)
+	if err != nil {
+		return nil, err
+	}
+	current := make([]uint, len(checklist.Items))
+	for i, item := range checklist.Items {
+		current[i] = item.ID
+	}
+	if err := checkSameIDs(current, itemIDs, "checklist items"); err != nil {
+		return nil, err
+	}
+	if err := s.checklistRepo.ReorderItems(checklistID, itemIDs); err != nil {
+		return nil, err
+	}
+
+	broadcastMessage(s.hub, boardID, realtime.MessageTypeChecklistItemsReordered,
+		realtime.ChecklistItemsReorderedPayload{ChecklistID: checklistID, CardID: cardID, BoardID: boardID, ItemIDs: itemIDs}, userID)
+	return s.checklistRepo.FindByID(checklistID)
+}
+
+// authorize checks action against the card's board, and returns the board's ID.
+func (s *ChecklistService) authorize(cardID, userID uint, action policy.Action) (uint, error) {
+	return authorizeCardAction(s.cardRepo, s.listRepo, s.boardRepo, s.boardMemberRepo, cardID, userID, action)
+}
+
+// findChecklist finds one of the card's checklists, reporting other cards' checklists as not found.
+func (s *ChecklistService) findChecklist(cardID, checklistID uint) (*models.Checklist, error) {
+	checklist, err := s.checklistRepo.FindByID(checklistID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrChecklistNotFound
+		}
+		return nil, err
+	}
+	if checklist.CardID != cardID {
+		return nil, ErrChecklistNotFound
+	}
+	return checklist, nil
+}
+
+// findItem finds an item of one of the card's checklists.
+func (s *ChecklistService) findItem(cardID, checklistID, itemID uint) (*models.ChecklistItem, error) {
+	if _, err := s.findChecklist(cardID, checklistID); err != nil {
+		return nil, err
+	}
+	item, err := s.checklistRepo.FindItemByID(itemID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrChecklistItemNotFound
+		}
+		return nil, err
+	}
+	if item.ChecklistID != checklistID {
+		return nil, ErrChecklistItemNotFound
+	}
+	return item, nil
+}
+
+// checkAssignee makes sure items are only assigned to people who can see the board.
+func (s *ChecklistService) checkAssignee(boardID, assigneeID uint) error {
+	if _, _, err := resolveBoardSubject(s.boardRepo, s.boardMemberRepo, boardID, assigneeID); err != nil {
+		if errors.Is(err, ErrForbidden) {
+			return fmt.Errorf("%w: checklist items can only be assigned to people on the board", ErrInvalidInput)
+		}
+		return err
+	}
+	return nil
+}
+
+// checkSameIDs makes sure a new order lists each of the current IDs exactly once.
+func checkSameIDs(current, ordered []uint, what string) error {
+	if len(ordered) != len(current) {
+		return fmt.Errorf("%w: the new order must list all %d %s", ErrInvalidInput, len(current), what)
+	}
+	remaining := make(map[uint]bool, len(current))
+	for _, id := range current {
+		remaining[id] = true
+	}
+	for _, id := range ordered {
+		if !remaining[id] {
+			return fmt.Errorf("%w: %d is not one of the %s, or is listed twice", ErrInvalidInput, id, what)
+		}
+		delete(remaining, id)
+	}
+	return nil
+}
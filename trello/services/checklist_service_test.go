@@ -0,0 +1,150 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/realtime"
+	"github.com/zayyadi/trello/repositories"
+)
+
+// checklistTestEnv runs the checklist service on SQLite. Alice owns a board with
+// one card, Vera is a viewer and Carol is not on the board.
+type checklistTestEnv struct {
+	checklists *ChecklistService
+	lists      *ListService
+	cards      CardServiceInterface
+	copier     *BoardCopyService
+	messages   []string // Types of the messages broadcast
+	board      *models.Board
+	card       *models.Card
+	alice      uint
+	vera       uint
+	carol      uint
+}
+
+func newChecklistTestEnv(t *testing.T) *checklistTestEnv {
+	db := setupTestDB(t)
+	userRepo := repositories.NewUserRepository(db)
+	boardRepo := repositories.NewBoardRepository(db)
+	boardMemberRepo := repositories.NewBoardMemberRepository(db)
+	listRepo := repositories.NewListRepository(db)
+	cardRepo := repositories.NewCardRepository(db)
+
+	env := &checklistTestEnv{}
+	hub := &MockHub{SubmitFunc: func(msg *realtime.WebSocketMessage) { env.messages = append(env.messages, msg.Type) }}
+	env.checklists = NewChecklistService(repositories.NewChecklistRepository(db), cardRepo, listRepo, boardRepo, boardMemberRepo, hub)
+	env.lists = NewListService(listRepo, boardRepo, boardMemberRepo, &MockHub{})
+	env.cards = NewCardService(cardRepo, listRepo, boardRepo, boardMemberRepo, userRepo, &MockHub{})
+	env.copier = NewBoardCopyService(repositories.NewBoardCopyRepository(db), boardRepo, boardMemberRepo, repositories.NewWorkspaceMemberRepository(db), &MockHub{})
+	boards := NewBoardService(boardRepo, userRepo, boardMemberRepo, &MockHub{}, false)
+	env.alice = createTestUser(t, userRepo, "alice")
+	env.vera = createTestUser(t, userRepo, "vera")
+	env.carol = createTestUser(t, userRepo, "carol")
+	board := createTestBoard(t, boards, "Launch", "", env.alice, map[uint]models.BoardRole{env.vera: models.BoardRoleViewer})
+	list, err := env.lists.CreateList("To do", board.ID, env.alice, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env.card, err = env.cards.CreateCard(list.ID, "Press release", "", nil, nil, nil, nil, nil, env.alice); err != nil {
+		t.Fatal(err)
+	}
+	env.board = board
+	return env
+}
+
+func TestChecklistService_Checklists(t *testing.T) {
+	env := newChecklistTestEnv(t)
+
+	_, err := env.checklists.CreateChecklist(env.card.ID, "Definition of done", env.vera)
+	assert.ErrorIs(t, err, ErrForbidden, "viewers can't edit cards")
+	done, err := env.checklists.CreateChecklist(env.card.ID, "Definition of done", env.alice)
+	assert.NoError(t, err)
+	review, err := env.checklists.CreateChecklist(env.card.ID, "Review", env.alice)
+	assert.NoError(t, err)
+	assert.Equal(t, done.Position+1, review.Position)
+
+	renamed, err := env.checklists.UpdateChecklist(env.card.ID, done.ID, "Done when", env.alice)
+	assert.NoError(t, err)
+	assert.Equal(t, "Done when", renamed.Title)
+	_, err = env.checklists.UpdateChecklist(env.card.ID+1, done.ID, "Elsewhere", env.alice)
+	assert.Error(t, err, "the checklist is looked up on its own card")
+
+	_, err = env.checklists.ReorderChecklists(env.card.ID, []uint{review.ID}, env.alice)
+	assert.ErrorIs(t, err, ErrInvalidInput, "every checklist must be listed")
+	_, err = env.checklists.ReorderChecklists(env.card.ID, []uint{review.ID, review.ID}, env.alice)
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	checklists, err := env.checklists.ReorderChecklists(env.card.ID, []uint{review.ID, done.ID}, env.alice)
+	assert.NoError(t, err)
+	if assert.Len(t, checklists, 2) {
+		assert.Equal(t, review.ID, checklists[0].ID)
+	}
+
+	checklists, err = env.checklists.GetChecklists(env.card.ID, env.vera)
+	assert.NoError(t, err, "viewers see checklists")
+	assert.Len(t, checklists, 2)
+	_, err = env.checklists.GetChecklists(env.card.ID, env.carol)
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	assert.NoError(t, env.checklists.DeleteChecklist(env.card.ID, review.ID, env.alice))
+	assert.ErrorIs(t, env.checklists.DeleteChecklist(env.card.ID, review.ID, env.alice), ErrChecklistNotFound)
+	assert.Equal(t, []string{
+		realtime.MessageTypeChecklistCreated, realtime.MessageTypeChecklistCreated, realtime.MessageTypeChecklistUpdated,
+		realtime.MessageTypeChecklistsReordered, realtime.MessageTypeChecklistDeleted,
+	}, env.messages)
+}
+
+func TestChecklistService_Items(t *testing.T) {
+	env := newChecklistTestEnv(t)
+	checklist, err := env.checklists.CreateChecklist(env.card.ID, "Steps", env.alice)
+	assert.NoError(t, err)
+
+	_, err = env.checklists.CreateItem(env.card.ID, checklist.ID, "Draft", &env.carol, nil, env.alice)
+	assert.ErrorIs(t, err, ErrInvalidInput, "assignees must be on the board")
+	draft, err := env.checklists.CreateItem(env.card.ID, checklist.ID, "Draft", &env.vera, nil, env.alice)
+	assert.NoError(t, err)
+	if assert.NotNil(t, draft.AssignedUser) {
+		assert.Equal(t, "vera", draft.AssignedUser.Username)
+	}
+	send, err := env.checklists.CreateItem(env.card.ID, checklist.ID, "Send", nil, nil, env.alice)
+	assert.NoError(t, err)
+
+	completedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	env.checklists.now = func() time.Time { return completedAt }
+	completed, unassign := true, uint(0)
+	item, err := env.checklists.UpdateItem(env.card.ID, checklist.ID, draft.ID, ChecklistItemUpdate{Completed: &completed, AssignedUserID: &unassign}, env.alice)
+	assert.NoError(t, err)
+	assert.True(t, item.Completed)
+	if assert.NotNil(t, item.CompletedAt) {
+		assert.True(t, completedAt.Equal(*item.CompletedAt))
+	}
+	assert.Nil(t, item.AssignedUserID)
+
+	card, err := env.cards.GetCardByID(env.card.ID, env.vera)
+	assert.NoError(t, err)
+	done, total := models.ChecklistProgress(card.Checklists)
+	assert.Equal(t, [2]int{1, 2}, [2]int{done, total}, "cards come with their checklists")
+
+	reordered, err := env.checklists.ReorderItems(env.card.ID, checklist.ID, []uint{send.ID, draft.ID}, env.alice)
+	assert.NoError(t, err)
+	if assert.Len(t, reordered.Items, 2) {
+		assert.Equal(t, send.ID, reordered.Items[0].ID)
+	}
+
+	copied, err := env.copier.CopyBoard(env.board.ID, "", repositories.BoardCopyOptions{Cards: true}, env.alice)
+	assert.NoError(t, err)
+	lists, err := env.lists.GetListsByBoardID(copied.ID, env.alice)
+	assert.NoError(t, err)
+	if assert.Len(t, lists, 1) && assert.Len(t, lists[0].Cards, 1) {
+		done, total = models.ChecklistProgress(lists[0].Cards[0].Checklists)
+		assert.Equal(t, [2]int{1, 2}, [2]int{done, total}, "copies keep their checklists as they stand")
+	}
+
+	assert.NoError(t, env.checklists.DeleteItem(env.card.ID, checklist.ID, send.ID, env.alice))
+	assert.ErrorIs(t, env.checklists.DeleteItem(env.card.ID, checklist.ID, send.ID, env.alice), ErrChecklistItemNotFound)
+	assert.NoError(t, env.checklists.DeleteChecklist(env.card.ID, checklist.ID, env.alice))
+	_, err = env.checklists.UpdateItem(env.card.ID, checklist.ID, draft.ID, ChecklistItemUpdate{Completed: &completed}, env.alice)
+	assert.ErrorIs(t, err, ErrChecklistNotFound, "items go with their checklist")
+}
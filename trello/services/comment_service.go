@@ -4,6 +4,7 @@ import (
 	"errors"
 
 	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/policy"
 	"github.com/zayyadi/trello/repositories"
 	"gorm.io/gorm"
 )
@@ -40,8 +41,8 @@ func NewCommentService(
 	}
 }
 
-// Helper function to check if a user has access to the board a card belongs to.
-func (s *CommentService) checkCardBoardAccess(userID uint, cardID uint) error {
+// Helper function to check the policy allows the user to perform action on the board a card belongs to.
+func (s *CommentService) checkCardBoardAccess(userID uint, cardID uint, action policy.Action) error {
 	listID, err := s.cardRepo.GetListIDByCardID(cardID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -58,33 +59,14 @@ func (s *CommentService) checkCardBoardAccess(userID uint, cardID uint) error {
 		return err
 	}
 
-	board, err := s.boardRepo.FindByID(boardID)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ErrBoardNotFound
-		}
-		return err
-	}
-
-	if board.OwnerID == userID {
-		return nil // User is the owner of the board
-	}
-
-	isMember, err := s.boardMemberRepo.IsMember(boardID, userID)
-	if err != nil {
-		return err // DB error during IsMember check
-	}
-	if !isMember {
-		return ErrForbidden // User is not owner and not a member
-	}
-
-	return nil // User has access
+	_, _, err = authorizeBoardAction(s.boardRepo, s.boardMemberRepo, boardID, userID, action)
+	return err
 }
 
 // CreateComment creates a new comment on a card.
 func (s *CommentService) CreateComment(cardID uint, userID uint, content string) (*models.Comment, error) {
-	if err := s.checkCardBoardAccess(userID, cardID); err != nil {
-		return nil, err
+	if err := s.checkCardBoardAccess(userID, cardID, policy.CreateComment); err != nil {
+		return nil, err // Viewers cannot comment
 	}
 
 	if content == "" {
@@ -122,7 +104,7 @@ func (s *CommentService) CreateComment(cardID uint, userID uint, content string)
 
 // GetCommentsByCardID retrieves all comments for a given card.
 func (s *CommentService) GetCommentsByCardID(cardID uint, userID uint) ([]models.Comment, error) {
-	if err := s.checkCardBoardAccess(userID, cardID); err != nil {
+	if err := s.checkCardBoardAccess(userID, cardID, policy.ViewBoard); err != nil {
 		return nil, err
 	}
 
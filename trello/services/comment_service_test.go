@@ -85,13 +85,10 @@ func (m *MockCardRepositoryForCommentService) Delete(id uint) error {
 func (m *MockCardRepositoryForCommentService) PerformTransaction(fn func(tx *gorm.DB) error) error {
 	return errors.New("not implemented")
 }
-func (m *MockCardRepositoryForCommentService) MoveCard(cardID, oldListID, newListID uint, newPosition uint) error {
+func (m *MockCardRepositoryForCommentService) MoveCard(cardID, listID uint, position uint) error {
 	return errors.New("not implemented")
 }
-func (m *MockCardRepositoryForCommentService) GetMaxPosition(listID uint) (uint, error) {
-	return 0, errors.New("not implemented")
-}
-func (m *MockCardRepositoryForCommentService) ShiftPositions(listID uint, startPosition uint, shiftAmount int, excludedCardID *uint) error {
+func (m *MockCardRepositoryForCommentService) MoveCardToBoard(move repositories.CardBoardMove) error {
 	return errors.New("not implemented")
 }
 
@@ -136,9 +133,6 @@ func (m *MockListRepositoryForCommentService) Update(list *models.List) error {
 func (m *MockListRepositoryForCommentService) Delete(id uint) error {
 	return errors.New("not implemented")
 }
-func (m *MockListRepositoryForCommentService) GetMaxPosition(boardID uint) (uint, error) {
-	return 0, errors.New("not implemented")
-}
 func (m *MockListRepositoryForCommentService) GetDB() *gorm.DB { return nil }
 func (m *MockListRepositoryForCommentService) PerformTransaction(fn func(tx *gorm.DB) error) error { return errors.New("not implemented") }
 
@@ -175,6 +169,7 @@ func (m *MockBoardRepositoryForCommentService) IsOwner(boardID uint, userID uint
 type MockBoardMemberRepositoryForCommentService struct {
 	repositories.BoardMemberRepositoryInterface
 	IsMemberFunc func(boardID uint, userID uint) (bool, error)
+	GetRoleFunc  func(boardID uint, userID uint) (models.BoardRole, error)
 }
 
 func (m *MockBoardMemberRepositoryForCommentService) IsMember(boardID uint, userID uint) (bool, error) {
@@ -195,6 +190,12 @@ func (m *MockBoardMemberRepositoryForCommentService) FindMembersByBoardID(boardI
 func (m *MockBoardMemberRepositoryForCommentService) FindByBoardIDAndUserID(boardID uint, userID uint) (*models.BoardMember, error) {
 	return nil, errors.New("not implemented")
 }
+func (m *MockBoardMemberRepositoryForCommentService) GetRole(boardID uint, userID uint) (models.BoardRole, error) {
+	if m.GetRoleFunc != nil {
+		return m.GetRoleFunc(boardID, userID)
+	}
+	return "", errors.New("GetRoleFunc on MockBoardMemberRepositoryForCommentService not implemented")
+}
 
 func TestCommentService_CreateComment_Success(t *testing.T) {
 	mockCommentRepo := &MockCommentRepository{}
@@ -345,10 +346,10 @@ func TestCommentService_CreateComment_PermissionDenied_Forbidden(t *testing.T) {
 	mockBoardRepo.FindByIDFunc = func(bID uint) (*models.Board, error) {
 		return &models.Board{Model: gorm.Model{ID: boardID}, OwnerID: actualOwnerID}, nil
 	}
-	mockBoardMemberRepo.IsMemberFunc = func(bID uint, uID uint) (bool, error) {
+	mockBoardMemberRepo.GetRoleFunc = func(bID uint, uID uint) (models.BoardRole, error) {
 		assert.Equal(t, boardID, bID)
 		assert.Equal(t, userID, uID)
-		return false, nil
+		return "", gorm.ErrRecordNotFound
 	}
 
 	comment, err := commentService.CreateComment(cardID, userID, content)
@@ -358,6 +359,37 @@ func TestCommentService_CreateComment_PermissionDenied_Forbidden(t *testing.T) {
 	assert.Nil(t, comment)
 }
 
+func TestCommentService_CreateComment_PermissionDenied_Viewer(t *testing.T) {
+	mockCommentRepo := &MockCommentRepository{}
+	mockCardRepo := &MockCardRepositoryForCommentService{}
+	mockListRepo := &MockListRepositoryForCommentService{}
+	mockBoardRepo := &MockBoardRepositoryForCommentService{}
+	mockBoardMemberRepo := &MockBoardMemberRepositoryForCommentService{}
+
+	commentService := NewCommentService(
+		mockCommentRepo, mockCardRepo, mockListRepo, mockBoardRepo, mockBoardMemberRepo,
+	)
+
+	cardID := uint(1)
+	userID := uint(2)
+	boardID := uint(3)
+	listID := uint(4)
+
+	mockCardRepo.GetListIDByCardIDFunc = func(cID uint) (uint, error) { return listID, nil }
+	mockListRepo.GetBoardIDByListIDFunc = func(lID uint) (uint, error) { return boardID, nil }
+	mockBoardRepo.FindByIDFunc = func(bID uint) (*models.Board, error) {
+		return &models.Board{Model: gorm.Model{ID: boardID}, OwnerID: uint(5)}, nil
+	}
+	mockBoardMemberRepo.GetRoleFunc = func(bID uint, uID uint) (models.BoardRole, error) {
+		return models.BoardRoleViewer, nil // Viewers have read-only access
+	}
+
+	comment, err := commentService.CreateComment(cardID, userID, "Test comment")
+
+	assert.Equal(t, ErrForbidden, err)
+	assert.Nil(t, comment)
+}
+
 func TestCommentService_GetCommentsByCardID_Success(t *testing.T) {
 	mockCommentRepo := &MockCommentRepository{}
 	mockCardRepo := &MockCardRepositoryForCommentService{}
@@ -454,8 +486,8 @@ func TestCommentService_GetCommentsByCardID_PermissionDenied_Forbidden(t *testin
 	mockBoardRepo.FindByIDFunc = func(bID uint) (*models.Board, error) {
 		return &models.Board{Model: gorm.Model{ID: boardID}, OwnerID: actualOwnerID}, nil
 	}
-	mockBoardMemberRepo.IsMemberFunc = func(bID uint, uID uint) (bool, error) {
-		return false, nil
+	mockBoardMemberRepo.GetRoleFunc = func(bID uint, uID uint) (models.BoardRole, error) {
+		return "", gorm.ErrRecordNotFound
 	}
 
 	var findByCardIDCalled bool
@@ -0,0 +1,517 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/zayyadi/trello/dto"
+	"github.com/zayyadi/trello/mailer"
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/policy"
+	"github.com/zayyadi/trello/realtime"
+	"github.com/zayyadi/trello/repositories"
+	"github.com/zayyadi/trello/utils"
+
+	"gorm.io/gorm"
+)
+
+// DefaultInvitationTTL is how long an emailed board invitation can be accepted.
+const DefaultInvitationTTL = 7 * 24 * time.Hour
+
+// InvitationOptions holds the settings InvitationService needs besides its dependencies.
+type InvitationOptions struct {
+	InvitationTTL        time.Duration // Zero falls back to DefaultInvitationTTL
+	AppBaseURL           string        // Frontend URL used to build invitation links
+	RequireVerifiedEmail bool          // Refuse to add users who haven't verified their email
+}
+
+// InvitationService invites people to boards, either by email or through a
+// shareable link. Nobody joins a board without accepting, and invitations may
+// go to addresses with no account yet.
+type InvitationService struct {
+	invitationRepo  repositories.BoardInvitationRepositoryInterface
+	inviteLinkRepo  repositories.BoardInviteLinkRepositoryInterface
+	boardRepo       repositories.BoardRepositoryInterface
+	boardMemberRepo repositories.BoardMemberRepositoryInterface
+	userRepo        repositories.UserRepositoryInterface
+	mailer          mailer.Mailer
+	hub             realtime.Broadcaster
+	opts            InvitationOptions
+	now             func() time.Time // Replaced in tests
+}
+
+func NewInvitationService(
+	invitationRepo repositories.BoardInvitationRepositoryInterface,
+	inviteLinkRepo repositories.BoardInviteLinkRepositoryInterface,
+	boardRepo repositories.BoardRepositoryInterface,
+	boardMemberRepo repositories.BoardMemberRepositoryInterface,
+	userRepo repositories.UserRepositoryInterface,
+	mailSender mailer.Mailer,
+	hub realtime.Broadcaster,
+	opts InvitationOptions,
+) *InvitationService {
+	if opts.InvitationTTL <= 0 {
+		opts.InvitationTTL = DefaultInvitationTTL
+	}
+	return &InvitationService{
+		invitationRepo:  invitationRepo,
+		inviteLinkRepo:  inviteLinkRepo,
+		boardRepo:       boardRepo,
+		boardMemberRepo: boardMemberRepo,
+		userRepo:        userRepo,
+		mailer:          mailSender,
+		hub:             hub,
+		opts:            opts,
+		now:             time.Now,
+	}
+}
+
+// InviteToBoard emails an invitation to join the board with role (member by default).
+// Inviting the same address again replaces the earlier invitation, so it can be used to resend one.
+func (s *InvitationService) InviteToBoard(boardID uint, email string, role models.BoardRole, inviterID uint) (*models.BoardInvitation, error) {
+	board, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, boardID, inviterID, policy.ManageMembers)
+	if err != nil {
+		return nil, err // Only admins can invite
+	}
+	if role == "" {
+		role = models.BoardRoleMember
+	}
+	if !role.IsValid() {
+		return nil, ErrInvalidBoardRole
+	}
+	email = normalizeInvitationEmail(email)
+	if email == "" {
+		return nil, fmt.Errorf("%w: email is required", ErrInvalidInput)
+	}
+
+	// People already on the board don't need an invitation
+	existing, err := s.userRepo.FindByEmail(email)
+	if err == nil {
+		if existing.ID == board.OwnerID {
+			return nil, ErrUserAlreadyMember
+		}
+		isMember, err := s.boardMemberRepo.IsMember(boardID, existing.ID)
+		if err != nil {
+			return nil, err
+		}
+		if isMember {
+			return nil, ErrUserAlreadyMember
+		}
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if err := s.invitationRepo.RevokePending(boardID, email); err != nil {
+		return nil, err
+	}
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	invitation := &models.BoardInvitation{
+		BoardID:   boardID,
+		InviterID: inviterID,
+		Email:     email,
+		Role:      role,
+		TokenHash: utils.HashToken(token),
+		Status:    models.InvitationStatusPending,
+		ExpiresAt: s.now().Add(s.opts.InvitationTTL),
+	}
+	if err := s.invitationRepo.Create(invitation); err != nil {
+		return nil, err
+	}
+	created, err := s.invitationRepo.FindByID(invitation.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.sendInvitationEmail(created, board, token, existing != nil); err != nil {
+		// The invitation stands: registered users see it in the app, and it can be resent
+		log.Printf("Failed to send board invitation %d: %v", created.ID, err)
+	}
+	return created, nil
+}
+
+// InviteMember invites someone to the board by email address or, for people who
+// already have an account, by user ID. Board admins can't add people directly:
+// they join once they accept.
+func (s *InvitationService) InviteMember(boardID uint, email *string, memberUserID *uint, role models.BoardRole, inviterID uint) (*models.BoardInvitation, error) {
+	if memberUserID == nil {
+		if email == nil {
+			return nil, fmt.Errorf("%w: email or userID is required", ErrInvalidInput)
+		}
+		return s.InviteToBoard(boardID, *email, role, inviterID)
+	}
+	if _, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, boardID, inviterID, policy.ManageMembers); err != nil {
+		return nil, err // Don't tell outsiders whether the user exists
+	}
+	user, err := s.findUser(*memberUserID)
+	if err != nil {
+		return nil, err
+	}
+	return s.InviteToBoard(boardID, user.Email, role, inviterID)
+}
+
+// GetBoardInvitations lists the board's pending invitations for its admins.
+func (s *InvitationService) GetBoardInvitations(boardID, userID uint) ([]models.BoardInvitation, error) {
+	if _, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, boardID, userID, policy.ManageMembers); err != nil {
+		return nil, err
+	}
+	return s.invitationRepo.FindPendingByBoard(boardID)
+}
+
+// RevokeInvitation withdraws a pending invitation so it can no longer be accepted.
+func (s *InvitationService) RevokeInvitation(boardID, invitationID, userID uint) error {
+	if _, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, boardID, userID, policy.ManageMembers); err != nil {
+		return err
+	}
+	invitation, err := s.findInvitation(invitationID)
+	if err != nil {
+		return err
+	}
+	if invitation.BoardID != boardID {
+		return ErrInvitationNotFound
+	}
+	if err := s.invitationRepo.Respond(invitation.ID, models.InvitationStatusRevoked, nil); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidInvitation
+		}
+		return err
+	}
+	return nil
+}
+
+// GetMyInvitations lists the pending invitations sent to the user's email
+// address, once they have verified it.
+func (s *InvitationService) GetMyInvitations(userID uint) ([]models.BoardInvitation, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+	return s.invitationRepo.FindPendingByEmail(normalizeInvitationEmail(user.Email))
+}
+
+// AcceptInvitation accepts one of the invitations sent to the user's email address.
+func (s *InvitationService) AcceptInvitation(invitationID, userID uint) (*models.BoardMember, error) {
+	invitation, user, err := s.ownInvitation(invitationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.accept(invitation, user)
+}
+
+// DeclineInvitation declines one of the invitations sent to the user's email address.
+func (s *InvitationService) DeclineInvitation(invitationID, userID uint) error {
+	invitation, _, err := s.ownInvitation(invitationID, userID)
+	if err != nil {
+		return err
+	}
+	return s.decline(invitation)
+}
+
+// AcceptInvitationToken accepts the invitation with the token from an invitation email.
+// Having the token proves the email was received, so the user's own address
+// doesn't have to match the one invited (e.g. they signed up with a personal address).
+func (s *InvitationService) AcceptInvitationToken(token string, userID uint) (*models.BoardMember, error) {
+	invitation, err := s.findInvitationByToken(token)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.accept(invitation, user)
+}
+
+// DeclineInvitationToken declines the invitation with the token from an invitation email.
+func (s *InvitationService) DeclineInvitationToken(token string) error {
+	invitation, err := s.findInvitationByToken(token)
+	if err != nil {
+		return err
+	}
+	return s.decline(invitation)
+}
+
+// AcceptPendingInvitations accepts every pending invitation sent to a newly
+// registered user's email address, once they have verified it: anyone can sign
+// up with an address, so registering alone doesn't prove the invitation reached
+// them. Invitations that can't be accepted (e.g. the board was deleted) are
+// logged and skipped. Safe to call on a nil service.
+func (s *InvitationService) AcceptPendingInvitations(user *models.User) error {
+	if s == nil || !user.EmailVerified {
+		return nil
+	}
+	invitations, err := s.invitationRepo.FindPendingByEmail(normalizeInvitationEmail(user.Email))
+	if err != nil {
+		return err
+	}
+	for i := range invitations {
+		if _, err := s.accept(&invitations[i], user); err != nil {
+			log.Printf("Could not accept board invitation %d for new user %d: %v", invitations[i].ID, user.ID, err)
+		}
+	}
+	return nil
+}
+
+// CreateInviteLink creates a shareable link for joining the board with role (member
+// by default). maxUses limits how many people can join with it (nil for no limit)
+// and expiresIn how long it works (zero for no expiry). The raw token is only returned here.
+func (s *InvitationService) CreateInviteLink(boardID uint, role models.BoardRole, maxUses *int, expiresIn time.Duration, userID uint) (*models.BoardInviteLink, string, error) {
+	if _, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, boardID, userID, policy.ManageMembers); err != nil {
+		return nil, "", err
+	}
+	if role == "" {
+		role = models.BoardRoleMember
+	}
+	if !role.IsValid() {
+		return nil, "", ErrInvalidBoardRole
+	}
+	if maxUses != nil && *maxUses < 1 {
+		return nil, "", fmt.Errorf("%w: maxUses must be at least 1", ErrInvalidInput)
+	}
+	if expiresIn < 0 {
+		return nil, "", fmt.Errorf("%w: expiry must not be negative", ErrInvalidInput)
+	}
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+	link := &models.BoardInviteLink{
+		BoardID:     boardID,
+		CreatedByID: userID,
+		Role:        role,
+		TokenHash:   utils.HashToken(token),
+		MaxUses:     maxUses,
+	}
+	if expiresIn > 0 {
+		expiresAt := s.now().Add(expiresIn)
+		link.ExpiresAt = &expiresAt
+	}
+	if err := s.inviteLinkRepo.Create(link); err != nil {
+		return nil, "", err
+	}
+	return link, token, nil
+}
+
+// InviteLinkURL is the frontend address to share for an invite link token.
+func (s *InvitationService) InviteLinkURL(token string) string {
+	return fmt.Sprintf("%s/join-board?token=%s", strings.TrimRight(s.opts.AppBaseURL, "/"), token)
+}
+
+// GetInviteLinks lists the board's unrevoked invite links for its admins.
+func (s *InvitationService) GetInviteLinks(boardID, userID uint) ([]models.BoardInviteLink, error) {
+	if _, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, boardID, userID, policy.ManageMembers); err != nil {
+		return nil, err
+	}
+	return s.inviteLinkRepo.FindByBoard(boardID)
+}
+
+func (s *InvitationService) RevokeInviteLink(boardID, linkID, userID uint) error {
+	if _, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, boardID, userID, policy.ManageMembers); err != nil {
+		return err
+	}
+	if err := s.inviteLinkRepo.Revoke(linkID, boardID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInviteLinkNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// JoinWithInviteLink adds the user to the link's board with the link's role.
+// Users already on the board get ErrUserAlreadyMember and don't use up the link.
+func (s *InvitationService) JoinWithInviteLink(token string, userID uint) (*models.BoardMember, error) {
+	link, err := s.inviteLinkRepo.FindByHash(utils.HashToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidInviteLink
+		}
+		return nil, err
+	}
+	if !link.IsActive(s.now()) {
+		return nil, ErrInvalidInviteLink
+	}
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkCanJoin(link.BoardID, user); err != nil {
+		return nil, err
+	}
+
+	if err := s.inviteLinkRepo.Use(link.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidInviteLink // Used up or revoked meanwhile
+		}
+		return nil, err
+	}
+	return s.addMember(link.BoardID, user.ID, link.Role)
+}
+
+// accept adds the user to the invitation's board. A user who is already on the
+// board keeps their current role, and the invitation is still marked accepted.
+func (s *InvitationService) accept(invitation *models.BoardInvitation, user *models.User) (*models.BoardMember, error) {
+	if !invitation.IsPending(s.now()) {
+		return nil, ErrInvalidInvitation
+	}
+	err := s.checkCanJoin(invitation.BoardID, user)
+	alreadyMember := errors.Is(err, ErrUserAlreadyMember)
+	if err != nil && !alreadyMember {
+		return nil, err
+	}
+
+	if err := s.invitationRepo.Respond(invitation.ID, models.InvitationStatusAccepted, &user.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidInvitation // Answered concurrently
+		}
+		return nil, err
+	}
+	if alreadyMember {
+		return s.boardMemberRepo.FindByBoardIDAndUserID(invitation.BoardID, user.ID) // The owner has a member row too
+	}
+	return s.addMember(invitation.BoardID, user.ID, invitation.Role)
+}
+
+func (s *InvitationService) decline(invitation *models.BoardInvitation) error {
+	if !invitation.IsPending(s.now()) {
+		return ErrInvalidInvitation
+	}
+	if err := s.invitationRepo.Respond(invitation.ID, models.InvitationStatusDeclined, nil); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidInvitation
+		}
+		return err
+	}
+	return nil
+}
+
+// checkCanJoin makes sure the board still exists and the user may be added to it.
+// It returns ErrUserAlreadyMember for the owner and existing members.
+func (s *InvitationService) checkCanJoin(boardID uint, user *models.User) error {
+	board, err := s.boardRepo.FindByID(boardID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrBoardNotFound
+		}
+		return err
+	}
+	if s.opts.RequireVerifiedEmail && !user.EmailVerified {
+		return ErrEmailNotVerified
+	}
+	if user.ID == board.OwnerID {
+		return ErrUserAlreadyMember
+	}
+	isMember, err := s.boardMemberRepo.IsMember(boardID, user.ID)
+	if err != nil {
+		return err
+	}
+	if isMember {
+		return ErrUserAlreadyMember
+	}
+	return nil
+}
+
+// addMember adds the user to the board and tells the board's clients. The new
+// member is the actor, as they accepted the invitation themselves.
+func (s *InvitationService) addMember(boardID, userID uint, role models.BoardRole) (*models.BoardMember, error) {
+	if err := s.boardMemberRepo.AddMember(&models.BoardMember{BoardID: boardID, UserID: userID, Role: role}); err != nil {
+		return nil, err
+	}
+	member, err := s.boardMemberRepo.FindByBoardIDAndUserID(boardID, userID) // Fetch with user preloaded
+	if err != nil {
+		return nil, err
+	}
+	broadcastMessage(
+		s.hub,
+		boardID,
+		realtime.MessageTypeBoardMemberAdded,
+		dto.MapBoardMemberToResponse(member),
+		userID,
+	)
+	return member, nil
+}
+
+// ownInvitation finds an invitation sent to the user's email address, which they
+// must have verified. Invitations to other addresses are reported as not found.
+func (s *InvitationService) ownInvitation(invitationID, userID uint) (*models.BoardInvitation, *models.User, error) {
+	invitation, err := s.findInvitation(invitationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if invitation.Email != normalizeInvitationEmail(user.Email) {
+		return nil, nil, ErrInvitationNotFound
+	}
+	if !user.EmailVerified {
+		return nil, nil, ErrEmailNotVerified // Anyone can sign up with the invited address
+	}
+	return invitation, user, nil
+}
+
+func (s *InvitationService) findInvitation(invitationID uint) (*models.BoardInvitation, error) {
+	invitation, err := s.invitationRepo.FindByID(invitationID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvitationNotFound
+		}
+		return nil, err
+	}
+	return invitation, nil
+}
+
+func (s *InvitationService) findInvitationByToken(token string) (*models.BoardInvitation, error) {
+	invitation, err := s.invitationRepo.FindByHash(utils.HashToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidInvitation
+		}
+		return nil, err
+	}
+	return invitation, nil
+}
+
+func (s *InvitationService) findUser(userID uint) (*models.User, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// sendInvitationEmail emails the invitation link. People without an account are
+// told they join automatically once they sign up with this address.
+func (s *InvitationService) sendInvitationEmail(invitation *models.BoardInvitation, board *models.Board, token string, hasAccount bool) error {
+	baseURL := strings.TrimRight(s.opts.AppBaseURL, "/")
+	link := fmt.Sprintf("%s/invitations/accept?token=%s", baseURL, token)
+	next := "Open the link below to accept or decline."
+	if !hasAccount {
+		next = fmt.Sprintf("Sign up at %s/register with this email address to join automatically,\nor open the link below after signing up with another one.", baseURL)
+	}
+	return s.mailer.Send(mailer.Message{
+		To:      []string{invitation.Email},
+		Subject: fmt.Sprintf("%s invited you to the board %q", invitation.Inviter.Username, board.Name),
+		Body: fmt.Sprintf("Hi,\n\n%s invited you to join the board %q as %s.\n%s\n"+
+			"The invitation expires in %s.\n\n%s\n",
+			invitation.Inviter.Username, board.Name, invitation.Role, next, s.opts.InvitationTTL, link),
+	})
+}
+
+// normalizeInvitationEmail is the form invitation addresses are stored and matched in.
+func normalizeInvitationEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/repositories"
+	"gorm.io/gorm"
+)
+
+var invitationTokenPattern = regexp.MustCompile(`token=(\S+)`)
+
+// invitationTestEnv runs the invitation service on SQLite, with alice owning a board.
+type invitationTestEnv struct {
+	db          *gorm.DB
+	invitations *InvitationService
+	boards      BoardServiceInterface
+	mail        *MockMailer
+	board       *models.Board
+	alice       uint // Board owner
+	bob         uint
+	carol       uint
+}
+
+func newInvitationTestEnv(t *testing.T) *invitationTestEnv {
+	db := setupTestDB(t)
+	userRepo := repositories.NewUserRepository(db)
+	boardRepo := repositories.NewBoardRepository(db)
+	boardMemberRepo := repositories.NewBoardMemberRepository(db)
+
+	env := &invitationTestEnv{db: db, mail: &MockMailer{}}
+	env.invitations = NewInvitationService(repositories.NewBoardInvitationRepository(db), repositories.NewBoardInviteLinkRepository(db),
+		boardRepo, boardMemberRepo, userRepo, env.mail, &MockHub{}, InvitationOptions{AppBaseURL: "http://app.test"})
+	env.boards = NewBoardService(boardRepo, userRepo, boardMemberRepo, &MockHub{}, false)
+	env.alice = createTestUser(t, userRepo, "alice")
+	env.bob = createTestUser(t, userRepo, "bob")
+	env.carol = createTestUser(t, userRepo, "carol")
+	// Invitations sent by email are only seen by users who verified their address
+	if err := db.Model(&models.User{}).Where("id IN ?", []uint{env.alice, env.bob, env.carol}).
+		Update("email_verified", true).Error; err != nil {
+		t.Fatal(err)
+	}
+	env.board = createTestBoard(t, env.boards, "Roadmap", "", env.alice, nil)
+	return env
+}
+
+// lastToken returns the token from the most recent invitation email.
+func (env *invitationTestEnv) lastToken(t *testing.T) string {
+	t.Helper()
+	if len(env.mail.Sent) == 0 {
+		t.Fatal("no invitation email sent")
+	}
+	match := invitationTokenPattern.FindStringSubmatch(env.mail.Sent[len(env.mail.Sent)-1].Body)
+	if match == nil {
+		t.Fatal("invitation email has no token")
+	}
+	return match[1]
+}
+
+func (env *invitationTestEnv) role(t *testing.T, userID uint) models.BoardRole {
+	t.Helper()
+	member, err := repositories.NewBoardMemberRepository(env.db).FindByBoardIDAndUserID(env.board.ID, userID)
+	if err != nil {
+		return ""
+	}
+	return member.Role
+}
+
+func TestInvitationService_InviteAndAcceptWithToken(t *testing.T) {
+	env := newInvitationTestEnv(t)
+
+	invitation, err := env.invitations.InviteToBoard(env.board.ID, " Bob@Example.com ", models.BoardRoleViewer, env.alice)
+	assert.NoError(t, err)
+	assert.Equal(t, "bob@example.com", invitation.Email)
+	assert.Equal(t, models.InvitationStatusPending, invitation.Status)
+	if assert.Len(t, env.mail.Sent, 1) {
+		assert.Equal(t, []string{"bob@example.com"}, env.mail.Sent[0].To)
+	}
+	assert.Empty(t, env.role(t, env.bob), "nobody joins before accepting")
+
+	mine, err := env.invitations.GetMyInvitations(env.bob)
+	assert.NoError(t, err)
+	if assert.Len(t, mine, 1) {
+		assert.Equal(t, "Roadmap", mine[0].Board.Name)
+	}
+
+	member, err := env.invitations.AcceptInvitationToken(env.lastToken(t), env.bob)
+	assert.NoError(t, err)
+	assert.Equal(t, models.BoardRoleViewer, member.Role)
+	assert.Equal(t, models.BoardRoleViewer, env.role(t, env.bob))
+
+	_, err = env.invitations.AcceptInvitationToken(env.lastToken(t), env.carol)
+	assert.ErrorIs(t, err, ErrInvalidInvitation, "an invitation is answered once")
+	_, err = env.invitations.InviteToBoard(env.board.ID, "bob@example.com", "", env.alice)
+	assert.ErrorIs(t, err, ErrUserAlreadyMember)
+	_, err = env.invitations.InviteToBoard(env.board.ID, "dave@example.com", "", env.bob)
+	assert.ErrorIs(t, err, ErrForbidden, "viewers can't invite")
+}
+
+func TestInvitationService_InviteMember(t *testing.T) {
+	env := newInvitationTestEnv(t)
+
+	invitation, err := env.invitations.InviteMember(env.board.ID, nil, &env.bob, models.BoardRoleViewer, env.alice)
+	assert.NoError(t, err)
+	assert.Equal(t, "bob@example.com", invitation.Email, "users are invited at their address")
+	assert.Empty(t, env.role(t, env.bob), "nobody is added without accepting")
+	_, err = env.invitations.AcceptInvitation(invitation.ID, env.bob)
+	assert.NoError(t, err)
+	assert.Equal(t, models.BoardRoleViewer, env.role(t, env.bob))
+
+	email := "dave@example.com"
+	invitation, err = env.invitations.InviteMember(env.board.ID, &email, nil, "", env.alice)
+	assert.NoError(t, err)
+	assert.Equal(t, models.BoardRoleMember, invitation.Role)
+	missing := uint(999)
+	_, err = env.invitations.InviteMember(env.board.ID, nil, &missing, "", env.alice)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+	_, err = env.invitations.InviteMember(env.board.ID, nil, &env.carol, "", env.bob)
+	assert.ErrorIs(t, err, ErrForbidden, "viewers can't invite")
+}
+
+func TestInvitationService_DeclineAndOwnership(t *testing.T) {
+	env := newInvitationTestEnv(t)
+	invitation, err := env.invitations.InviteToBoard(env.board.ID, "bob@example.com", "", env.alice)
+	assert.NoError(t, err)
+
+	_, err = env.invitations.AcceptInvitation(invitation.ID, env.carol)
+	assert.ErrorIs(t, err, ErrInvitationNotFound, "invitations by ID are only for their addressee")
+
+	// Nor for someone who signed up with the address without verifying it
+	assert.NoError(t, env.db.Model(&models.User{}).Where("id = ?", env.bob).Update("email_verified", false).Error)
+	_, err = env.invitations.AcceptInvitation(invitation.ID, env.bob)
+	assert.ErrorIs(t, err, ErrEmailNotVerified)
+	_, err = env.invitations.GetMyInvitations(env.bob)
+	assert.ErrorIs(t, err, ErrEmailNotVerified)
+	assert.NoError(t, env.db.Model(&models.User{}).Where("id = ?", env.bob).Update("email_verified", true).Error)
+
+	assert.NoError(t, env.invitations.DeclineInvitation(invitation.ID, env.bob))
+	_, err = env.invitations.AcceptInvitation(invitation.ID, env.bob)
+	assert.ErrorIs(t, err, ErrInvalidInvitation)
+	assert.Empty(t, env.role(t, env.bob))
+	mine, err := env.invitations.GetMyInvitations(env.bob)
+	assert.NoError(t, err)
+	assert.Empty(t, mine)
+}
+
+func TestInvitationService_ExpiredRevokedAndReplaced(t *testing.T) {
+	env := newInvitationTestEnv(t)
+
+	first, err := env.invitations.InviteToBoard(env.board.ID, "bob@example.com", "", env.alice)
+	assert.NoError(t, err)
+	firstToken := env.lastToken(t)
+	_, err = env.invitations.InviteToBoard(env.board.ID, "bob@example.com", "", env.alice)
+	assert.NoError(t, err)
+	_, err = env.invitations.AcceptInvitationToken(firstToken, env.bob)
+	assert.ErrorIs(t, err, ErrInvalidInvitation, "inviting again replaces the earlier invitation")
+	assert.ErrorIs(t, env.invitations.RevokeInvitation(env.board.ID, first.ID, env.alice), ErrInvalidInvitation)
+
+	env.invitations.now = func() time.Time { return time.Now().Add(DefaultInvitationTTL + time.Hour) }
+	_, err = env.invitations.AcceptInvitationToken(env.lastToken(t), env.bob)
+	assert.ErrorIs(t, err, ErrInvalidInvitation, "expired")
+
+	env.invitations.now = time.Now
+	pending, err := env.invitations.GetBoardInvitations(env.board.ID, env.alice)
+	assert.NoError(t, err)
+	if assert.Len(t, pending, 1) {
+		assert.NoError(t, env.invitations.RevokeInvitation(env.board.ID, pending[0].ID, env.alice))
+	}
+	_, err = env.invitations.AcceptInvitationToken(env.lastToken(t), env.bob)
+	assert.ErrorIs(t, err, ErrInvalidInvitation, "revoked")
+}
+
+func TestInvitationService_AcceptedOnRegister(t *testing.T) {
+	for _, requireVerified := range []bool{false, true} {
+		env := newInvitationTestEnv(t)
+		_, err := env.invitations.InviteToBoard(env.board.ID, "dave@example.com", models.BoardRoleAdmin, env.alice)
+		assert.NoError(t, err)
+		assert.Contains(t, env.mail.Sent[0].Body, "Sign up", "people without an account are told to sign up")
+
+		env.invitations.opts.RequireVerifiedEmail = requireVerified
+		auth := NewAuthService(repositories.NewUserRepository(env.db), repositories.NewRefreshTokenRepository(env.db), repositories.NewUserTokenRepository(env.db),
+			env.mail, AuthOptions{JWTSecretKey: "test-secret", RequireVerifiedEmail: requireVerified, Invitations: env.invitations})
+		dave, _, err := auth.Register("dave", "Dave@example.com", "password123")
+		if !assert.NoError(t, err) {
+			continue
+		}
+
+		assert.Empty(t, env.role(t, dave.ID), "not before the address is verified")
+		_, err = auth.VerifyEmail(invitationTokenPattern.FindStringSubmatch(env.mail.Sent[len(env.mail.Sent)-1].Body)[1])
+		assert.NoError(t, err)
+		assert.Equal(t, models.BoardRoleAdmin, env.role(t, dave.ID), "requireVerified=%v", requireVerified)
+	}
+}
+
+func TestInvitationService_InviteLinks(t *testing.T) {
+	env := newInvitationTestEnv(t)
+	maxUses := 1
+	link, token, err := env.invitations.CreateInviteLink(env.board.ID, "", &maxUses, 0, env.alice)
+	assert.NoError(t, err)
+	assert.Equal(t, models.BoardRoleMember, link.Role)
+	assert.Equal(t, "http://app.test/join-board?token="+token, env.invitations.InviteLinkURL(token))
+
+	_, err = env.invitations.JoinWithInviteLink(token, env.alice)
+	assert.ErrorIs(t, err, ErrUserAlreadyMember, "members don't use up a link")
+	member, err := env.invitations.JoinWithInviteLink(token, env.bob)
+	assert.NoError(t, err)
+	assert.Equal(t, models.BoardRoleMember, member.Role)
+	_, err = env.invitations.JoinWithInviteLink(token, env.carol)
+	assert.ErrorIs(t, err, ErrInvalidInviteLink, "used up")
+
+	viewerLink, viewerToken, err := env.invitations.CreateInviteLink(env.board.ID, models.BoardRoleViewer, nil, time.Hour, env.alice)
+	assert.NoError(t, err)
+	assert.NoError(t, env.invitations.RevokeInviteLink(env.board.ID, viewerLink.ID, env.alice))
+	_, err = env.invitations.JoinWithInviteLink(viewerToken, env.carol)
+	assert.ErrorIs(t, err, ErrInvalidInviteLink, "revoked")
+	assert.ErrorIs(t, env.invitations.RevokeInviteLink(env.board.ID, viewerLink.ID, env.alice), ErrInviteLinkNotFound)
+
+	zero := 0
+	_, _, err = env.invitations.CreateInviteLink(env.board.ID, "", &zero, 0, env.alice)
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	_, _, err = env.invitations.CreateInviteLink(env.board.ID, "", nil, 0, env.bob)
+	assert.ErrorIs(t, err, ErrForbidden, "only admins create links")
+
+	links, err := env.invitations.GetInviteLinks(env.board.ID, env.alice)
+	assert.NoError(t, err)
+	if assert.Len(t, links, 1) {
+		assert.Equal(t, 1, links[0].Uses)
+	}
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/zayyadi/trello/dto"
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/policy"
+	"github.com/zayyadi/trello/realtime"
+	"github.com/zayyadi/trello/repositories"
+
+	"gorm.io/gorm"
+)
+
+const maxLabelNameLength = 50
+
+// labelColorPattern matches the hex colors labels can have, like #61bd4f.
+var labelColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// LabelService manages each board's labels and which of its cards carry them.
+type LabelService struct {
+	labelRepo       repositories.LabelRepositoryInterface
+	cardRepo        repositories.CardRepositoryInterface
+	listRepo        repositories.ListRepositoryInterface
+	boardRepo       repositories.BoardRepositoryInterface
+	boardMemberRepo repositories.BoardMemberRepositoryInterface
+	hub             realtime.Broadcaster
+}
+
+func NewLabelService(
+	labelRepo repositories.LabelRepositoryInterface,
+	cardRepo repositories.CardRepositoryInterface,
+	listRepo repositories.ListRepositoryInterface,
+	boardRepo repositories.BoardRepositoryInterface,
+	boardMemberRepo repositories.BoardMemberRepositoryInterface,
+	hub realtime.Broadcaster,
+) *LabelService {
+	return &LabelService{
+		labelRepo:       labelRepo,
+		cardRepo:        cardRepo,
+		listRepo:        listRepo,
+		boardRepo:       boardRepo,
+		boardMemberRepo: boardMemberRepo,
+		hub:             hub,
+	}
+}
+
+// GetLabels lists the board's labels for anyone who can see the board.
+func (s *LabelService) GetLabels(boardID, userID uint) ([]models.Label, error) {
+	if _, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, boardID, userID, policy.ViewBoard); err != nil {
+		return nil, err
+	}
+	return s.labelRepo.FindByBoardID(boardID)
+}
+
+// CreateLabel adds a label to the board. The name may be empty for a color-only label.
+func (s *LabelService) CreateLabel(boardID uint, name, color string, userID uint) (*models.Label, error) {
+	if _, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, boardID, userID, policy.ManageLabels); err != nil {
+		return nil, err
+	}
+	label := &models.Label{BoardID: boardID}
+	if err := setLabelFields(label, &name, &color); err != nil {
+		return nil, err
+	}
+	if err := s.labelRepo.Create(label); err != nil {
+		return nil, err
+	}
+
+	broadcastMessage(s.hub, boardID, realtime.MessageTypeLabelCreated, dto.MapLabelToResponse(label), userID)
+	return label, nil
+}
+
+// UpdateLabel renames or recolors one of the board's labels. Nil fields are left as they are.
+func (s *LabelService) UpdateLabel(boardID, labelID uint, name, color *string, userID uint) (*models.Label, error) {
+	if _, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, boardID, userID, policy.ManageLabels); err != nil {
+		return nil, err
+	}
+	label, err := s.findBoardLabel(boardID, labelID)
+	if err != nil {
+		return nil, err
+	}
+	if err := setLabelFields(label, name, color); err != nil {
+		return nil, err
+	}
+	if err := s.labelRepo.Update(label); err != nil {
+		return nil, err
+	}
+
+	broadcastMessage(s.hub, boardID, realtime.MessageTypeLabelUpdated, dto.MapLabelToResponse(label), userID)
+	return label, nil
+}
+
+// DeleteLabel removes one of the board's labels, taking it off every card that has it.
+func (s *LabelService) DeleteLabel(boardID, labelID, userID uint) error {
+	if _, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, boardID, userID, policy.ManageLabels); err != nil {
+		return err
+	}
+	if _, err := s.findBoardLabel(boardID, labelID); err != nil {
+		return err
+	}
+	if err := s.labelRepo.Delete(labelID); err != nil {
+		return err
+	}
+
+	broadcastMessage(s.hub, boardID, realtime.MessageTypeLabelDeleted, realtime.LabelBasicInfo{ID: labelID, BoardID: boardID}, userID)
+	return nil
+}
+
+// AddLabelToCard puts one of the board's labels on a card. Adding a label the
+// card already has succeeds without broadcasting anything.
+func (s *LabelService) AddLabelToCard(cardID, labelID, userID uint) error {
+	boardID, err := authorizeCardAction(s.cardRepo, s.listRepo, s.boardRepo, s.boardMemberRepo, cardID, userID, policy.EditCard)
+	if err != nil {
+		return err // Card participants may label their own cards, as with other card edits
+	}
+	if _, err := s.findBoardLabel(boardID, labelID); err != nil {
+		return err // Labels of other boards can't be used
+	}
+
+	hasLabel, err := s.labelRepo.HasLabel(cardID, labelID)
+	if err != nil {
+		return err
+	}
+	if hasLabel {
+		return nil
+	}
+	if err := s.labelRepo.AddToCard(cardID, labelID); err != nil {
+		return err
+	}
+
+	broadcastMessage(s.hub, boardID, realtime.MessageTypeCardLabelAdded,
+		realtime.CardLabelPayload{CardID: cardID, LabelID: labelID, BoardID: boardID}, userID)
+	return nil
+}
+
+// RemoveLabelFromCard takes a label off a card.
+func (s *LabelService) RemoveLabelFromCard(cardID, labelID, userID uint) error {
+	boardID, err := authorizeCardAction(s.cardRepo, s.listRepo, s.boardRepo, s.boardMemberRepo, cardID, userID, policy.EditCard)
+	if err != nil {
+		return err
+	}
+	if err := s.labelRepo.RemoveFromCard(cardID, labelID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrLabelNotOnCard
+		}
+		return err
+	}
+
+	broadcastMessage(s.hub, boardID, realtime.MessageTypeCardLabelRemoved,
+		realtime.CardLabelPayload{CardID: cardID, LabelID: labelID, BoardID: boardID}, userID)
+	return nil
+}
+
+// findBoardLabel finds a label, reporting labels of other boards as not found.
+func (s *LabelService) findBoardLabel(boardID, labelID uint) (*models.Label, error) {
+	label, err := s.labelRepo.FindByID(labelID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLabelNotFound
+		}
+		return nil, err
+	}
+	if label.BoardID != boardID {
+		return nil, ErrLabelNotFound
+	}
+	return label, nil
+}
+
+// setLabelFields validates and applies a new name and color. Nil fields are left as they are.
+func setLabelFields(label *models.Label, name, color *string) error {
+	if name != nil {
+		trimmed := strings.TrimSpace(*name)
+		if len([]rune(trimmed)) > maxLabelNameLength {
+			return fmt.Errorf("%w: label names can be at most %d characters", ErrInvalidInput, maxLabelNameLength)
+		}
+		label.Name = trimmed
+	}
+	if color != nil {
+		if !labelColorPattern.MatchString(*color) {
+			return fmt.Errorf("%w: label color must be a hex color like #61bd4f", ErrInvalidInput)
+		}
+		label.Color = strings.ToLower(*color)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/realtime"
+	"github.com/zayyadi/trello/repositories"
+)
+
+// labelTestEnv runs the label service on SQLite. Alice owns a board with one
+// card, Bob is a member and Vera a viewer.
+type labelTestEnv struct {
+	labels   *LabelService
+	cards    CardServiceInterface
+	boards   BoardServiceInterface
+	copier   *BoardCopyService
+	messages []string // Types of the messages broadcast
+	board    *models.Board
+	card     *models.Card
+	alice    uint
+	bob      uint
+	vera     uint
+}
+
+func newLabelTestEnv(t *testing.T) *labelTestEnv {
+	db := setupTestDB(t)
+	userRepo := repositories.NewUserRepository(db)
+	boardRepo := repositories.NewBoardRepository(db)
+	boardMemberRepo := repositories.NewBoardMemberRepository(db)
+	listRepo := repositories.NewListRepository(db)
+	cardRepo := repositories.NewCardRepository(db)
+
+	env := &labelTestEnv{}
+	hub := &MockHub{SubmitFunc: func(msg *realtime.WebSocketMessage) { env.messages = append(env.messages, msg.Type) }}
+	env.labels = NewLabelService(repositories.NewLabelRepository(db), cardRepo, listRepo, boardRepo, boardMemberRepo, hub)
+	env.cards = NewCardService(cardRepo, listRepo, boardRepo, boardMemberRepo, userRepo, &MockHub{})
+	env.boards = NewBoardService(boardRepo, userRepo, boardMemberRepo, &MockHub{}, false)
+	env.copier = NewBoardCopyService(repositories.NewBoardCopyRepository(db), boardRepo, boardMemberRepo, repositories.NewWorkspaceMemberRepository(db), &MockHub{})
+	env.alice = createTestUser(t, userRepo, "alice")
+	env.bob = createTestUser(t, userRepo, "bob")
+	env.vera = createTestUser(t, userRepo, "vera")
+	board := createTestBoard(t, env.boards, "Launch", "", env.alice,
+		map[uint]models.BoardRole{env.bob: models.BoardRoleMember, env.vera: models.BoardRoleViewer})
+	list, err := NewListService(listRepo, boardRepo, boardMemberRepo, &MockHub{}).CreateList("To do", board.ID, env.alice, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env.card, err = env.cards.CreateCard(list.ID, "Press release", "", nil, nil, nil, nil, nil, env.alice); err != nil {
+		t.Fatal(err)
+	}
+	env.board = board
+	return env
+}
+
+func TestLabelService_ManageLabels(t *testing.T) {
+	env := newLabelTestEnv(t)
+
+	_, err := env.labels.CreateLabel(env.board.ID, "Urgent", "#EB5A46", env.vera)
+	assert.ErrorIs(t, err, ErrForbidden, "viewers can't create labels")
+	_, err = env.labels.CreateLabel(env.board.ID, "Urgent", "red", env.bob)
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	label, err := env.labels.CreateLabel(env.board.ID, " Urgent ", "#EB5A46", env.bob)
+	assert.NoError(t, err)
+	assert.Equal(t, "Urgent", label.Name)
+	assert.Equal(t, "#eb5a46", label.Color)
+
+	name := "Blocker"
+	updated, err := env.labels.UpdateLabel(env.board.ID, label.ID, &name, nil, env.bob)
+	assert.NoError(t, err)
+	assert.Equal(t, "Blocker", updated.Name)
+	assert.Equal(t, "#eb5a46", updated.Color, "the color is kept")
+	_, err = env.labels.UpdateLabel(env.board.ID+1, label.ID, &name, nil, env.alice)
+	assert.Error(t, err, "the label is looked up on its own board")
+
+	labels, err := env.labels.GetLabels(env.board.ID, env.vera)
+	assert.NoError(t, err)
+	assert.Len(t, labels, 1)
+
+	assert.NoError(t, env.labels.DeleteLabel(env.board.ID, label.ID, env.bob))
+	assert.ErrorIs(t, env.labels.DeleteLabel(env.board.ID, label.ID, env.bob), ErrLabelNotFound)
+	assert.Equal(t, []string{realtime.MessageTypeLabelCreated, realtime.MessageTypeLabelUpdated, realtime.MessageTypeLabelDeleted}, env.messages)
+}
+
+func TestLabelService_CardLabels(t *testing.T) {
+	env := newLabelTestEnv(t)
+	label, err := env.labels.CreateLabel(env.board.ID, "Design", "#61bd4f", env.alice)
+	assert.NoError(t, err)
+	env.messages = nil
+
+	assert.ErrorIs(t, env.labels.AddLabelToCard(env.card.ID, label.ID, env.bob), ErrForbidden, "members only label their own cards")
+	assert.NoError(t, env.labels.AddLabelToCard(env.card.ID, label.ID, env.alice))
+	assert.NoError(t, env.labels.AddLabelToCard(env.card.ID, label.ID, env.alice), "adding it twice is fine")
+	card, err := env.cards.GetCardByID(env.card.ID, env.vera)
+	assert.NoError(t, err)
+	if assert.Len(t, card.Labels, 1) {
+		assert.Equal(t, "Design", card.Labels[0].Name)
+	}
+
+	other, err := env.boards.CreateBoard("Other", "", env.alice)
+	assert.NoError(t, err)
+	foreign, err := env.labels.CreateLabel(other.ID, "Elsewhere", "#0079bf", env.alice)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, env.labels.AddLabelToCard(env.card.ID, foreign.ID, env.alice), ErrLabelNotFound, "labels of other boards")
+
+	copied, err := env.copier.CopyBoard(env.board.ID, "", repositories.BoardCopyOptions{Cards: true}, env.alice)
+	assert.NoError(t, err)
+	copiedLabels, err := env.labels.GetLabels(copied.ID, env.alice)
+	assert.NoError(t, err)
+	if assert.Len(t, copiedLabels, 1) {
+		assert.NotEqual(t, label.ID, copiedLabels[0].ID, "copies get labels of their own")
+	}
+
+	assert.NoError(t, env.labels.RemoveLabelFromCard(env.card.ID, label.ID, env.alice))
+	assert.ErrorIs(t, env.labels.RemoveLabelFromCard(env.card.ID, label.ID, env.alice), ErrLabelNotOnCard)
+	assert.Equal(t, []string{realtime.MessageTypeCardLabelAdded, realtime.MessageTypeLabelCreated, realtime.MessageTypeCardLabelRemoved}, env.messages)
+
+	assert.NoError(t, env.labels.AddLabelToCard(env.card.ID, label.ID, env.alice))
+	assert.NoError(t, env.labels.DeleteLabel(env.board.ID, label.ID, env.alice))
+	card, err = env.cards.GetCardByID(env.card.ID, env.alice)
+	assert.NoError(t, err)
+	assert.Empty(t, card.Labels, "deleting a label takes it off its cards")
+}
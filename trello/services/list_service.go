@@ -2,11 +2,13 @@ package services
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/zayyadi/trello/dto" // Changed import
 	"github.com/zayyadi/trello/models"
-	"github.com/zayyadi/trello/repositories"
+	"github.com/zayyadi/trello/policy"
 	"github.com/zayyadi/trello/realtime"
+	"github.com/zayyadi/trello/repositories"
 
 	"gorm.io/gorm"
 )
@@ -15,14 +17,14 @@ type ListService struct {
 	listRepo        repositories.ListRepositoryInterface
 	boardRepo       repositories.BoardRepositoryInterface // For permission checks via BoardService logic
 	boardMemberRepo repositories.BoardMemberRepositoryInterface
-	hub             *realtime.Hub
+	hub             realtime.Broadcaster
 }
 
 func NewListService(
 	listRepo repositories.ListRepositoryInterface,
 	boardRepo repositories.BoardRepositoryInterface,
 	boardMemberRepo repositories.BoardMemberRepositoryInterface,
-	hub *realtime.Hub,
+	hub realtime.Broadcaster,
 ) *ListService {
 	return &ListService{
 		listRepo:        listRepo,
@@ -32,27 +34,14 @@ func NewListService(
 	}
 }
 
-// Helper to check board access
-func (s *ListService) checkBoardAccess(userID, boardID uint) error {
-	board, err := s.boardRepo.FindByID(boardID)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ErrBoardNotFound
-		}
-		return err
-	}
-	if board.OwnerID == userID {
-		return nil
-	}
-	isMember, err := s.boardMemberRepo.IsMember(boardID, userID)
-	if err != nil || !isMember {
-		return ErrForbidden
-	}
-	return nil
+// Helper to check the policy allows the user to perform action on the board
+func (s *ListService) checkBoardAccess(userID, boardID uint, action policy.Action) error {
+	_, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, boardID, userID, action)
+	return err
 }
 
 func (s *ListService) CreateList(name string, boardID uint, userID uint, position *uint) (*models.List, error) {
-	if err := s.checkBoardAccess(userID, boardID); err != nil {
+	if err := s.checkBoardAccess(userID, boardID, policy.CreateList); err != nil {
 		return nil, err
 	}
 
@@ -61,31 +50,13 @@ func (s *ListService) CreateList(name string, boardID uint, userID uint, positio
 		BoardID: boardID,
 	}
 
-	// If position is provided, try to use it. Otherwise, repo handles appending.
-	// More complex position management (inserting at specific spot and shifting others)
-	// would require more logic here or in the repository.
-	// For now, repository's Create appends. If a specific position is given,
-	// we might need to update other lists' positions.
-	// This simplified version will let the repo handle default appending.
-	// If `position` is specified, it should be handled with care.
-	// For now, let's assume `Create` in repo appends.
-	// A more robust solution would be:
-	// 1. If position is nil, append.
-	// 2. If position is not nil, shift other lists >= position, then insert.
-	// This is simplified: Repo appends, UpdateList can change position.
-
-	if err := s.listRepo.Create(list); err != nil {
+	if err := s.listRepo.Create(list); err != nil { // Appended to the board
 		return nil, err
 	}
-
-	// If an explicit position was requested and is different from the one set by Create (appended)
-	if position != nil && list.Position != *position {
-		// This indicates a need for reordering logic not yet fully implemented here for create.
-		// For now, we'll just proceed with the appended position.
-		// A full implementation would adjust other lists.
-		// Let's assume client handles reordering via UpdateList after creation if needed.
-		// Or, the repo's Create needs to be smarter about initial position.
-		// For simplicity, the repo's Create method sets it to max + 1.
+	if position != nil { // A specific position was requested by the client
+		if err := s.listRepo.Move(list.ID, max(*position, 1)); err != nil {
+			return nil, err
+		}
 	}
 
 	createdList, err := s.listRepo.FindByID(list.ID) // Fetch with details
@@ -106,7 +77,7 @@ func (s *ListService) CreateList(name string, boardID uint, userID uint, positio
 }
 
 func (s *ListService) GetListsByBoardID(boardID uint, userID uint) ([]models.List, error) {
-	if err := s.checkBoardAccess(userID, boardID); err != nil {
+	if err := s.checkBoardAccess(userID, boardID, policy.ViewBoard); err != nil {
 		return nil, err
 	}
 	return s.listRepo.FindByBoardID(boardID)
@@ -120,7 +91,7 @@ func (s *ListService) GetListByID(listID uint, userID uint) (*models.List, error
 		}
 		return nil, err
 	}
-	if err := s.checkBoardAccess(userID, list.BoardID); err != nil {
+	if err := s.checkBoardAccess(userID, list.BoardID, policy.ViewBoard); err != nil {
 		return nil, err
 	}
 	return list, nil
@@ -134,7 +105,7 @@ func (s *ListService) UpdateList(listID uint, name *string, newPosition *uint, u
 		}
 		return nil, err
 	}
-	if err := s.checkBoardAccess(userID, list.BoardID); err != nil {
+	if err := s.checkBoardAccess(userID, list.BoardID, policy.UpdateList); err != nil {
 		return nil, err
 	}
 
@@ -142,58 +113,14 @@ func (s *ListService) UpdateList(listID uint, name *string, newPosition *uint, u
 		list.Name = *name
 	}
 
-	// Handle position update carefully
-	if newPosition != nil && list.Position != *newPosition {
-		// This requires reordering other lists on the same board.
-		// 1. Get all lists for the board.
-		// 2. Remove the current list from its old position.
-		// 3. Insert it at the new position.
-		// 4. Update positions of affected lists.
-		// This is complex. A simpler approach for now: just update the position field.
-		// The client or a separate reorder endpoint would handle complex reordering.
-		// For this PUT, let's assume it's a direct update.
-		// If more robust reordering is needed, a dedicated service method is better.
-
-		// Simplified reordering: adjust positions of affected lists.
-		// This is a basic example and might need refinement for edge cases or performance.
-		err = s.listRepo.PerformTransaction(func(tx *gorm.DB) error { // Use PerformTransaction
-			oldPosition := list.Position
-			targetPosition := *newPosition
-
-			// Clamp targetPosition to valid range
-			maxPos, _ := s.listRepo.GetMaxPosition(list.BoardID)
-			if targetPosition < 1 {
-				targetPosition = 1
-			}
-			if targetPosition > maxPos {
-				targetPosition = maxPos
-			}
-
-			if targetPosition < oldPosition { // Moving up (to a smaller position number)
-				// Increment position of lists between newPosition and oldPosition (exclusive of oldPosition)
-				if err := tx.Model(&models.List{}).
-					Where("board_id = ? AND position >= ? AND position < ?", list.BoardID, targetPosition, oldPosition).
-					Update("position", gorm.Expr("position + 1")).Error; err != nil {
-					return err
-				}
-			} else if targetPosition > oldPosition { // Moving down (to a larger position number)
-				// Decrement position of lists between oldPosition and newPosition (exclusive of oldPosition)
-				if err := tx.Model(&models.List{}).
-					Where("board_id = ? AND position > ? AND position <= ?", list.BoardID, oldPosition, targetPosition).
-					Update("position", gorm.Expr("position - 1")).Error; err != nil {
-					return err
-				}
-			}
-			// Set the new position for the current list
-			list.Position = targetPosition
-			return tx.Save(list).Error
-		})
-
-		if err != nil {
+	if name != nil {
+		if err := s.listRepo.Update(list); err != nil {
 			return nil, err
 		}
-	} else if name != nil { // Only name updated, no position change
-		if err := s.listRepo.Update(list); err != nil {
+	}
+	// Only this list's rank changes; a position past the end puts it last
+	if newPosition != nil {
+		if err := s.listRepo.Move(listID, max(*newPosition, 1)); err != nil {
 			return nil, err
 		}
 	}
@@ -215,6 +142,31 @@ func (s *ListService) UpdateList(listID uint, name *string, newPosition *uint, u
 	return updatedList, nil
 }
 
+// ReorderLists puts the board's lists in the given order, which must name each
+// of its active lists once. Archived lists stay out of the order.
+func (s *ListService) ReorderLists(boardID uint, listIDs []uint, userID uint) ([]models.List, error) {
+	if err := s.checkBoardAccess(userID, boardID, policy.UpdateList); err != nil {
+		return nil, err
+	}
+	if err := s.listRepo.Reorder(boardID, listIDs); err != nil {
+		if errors.Is(err, repositories.ErrOrderMismatch) {
+			return nil, fmt.Errorf("%w: the new order must list each of the board's lists exactly once", ErrInvalidInput)
+		}
+		return nil, err
+	}
+	lists, err := s.listRepo.FindByBoardID(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := realtime.ListsReorderedPayload{BoardID: boardID, ListIDs: make([]uint, len(lists)), Positions: make([]string, len(lists))}
+	for i, list := range lists {
+		payload.ListIDs[i], payload.Positions[i] = list.ID, list.Position
+	}
+	broadcastMessage(s.hub, boardID, realtime.MessageTypeListsReordered, payload, userID)
+	return lists, nil
+}
+
 func (s *ListService) DeleteList(listID uint, userID uint) error {
 	list, err := s.listRepo.FindByID(listID)
 	if err != nil {
@@ -223,45 +175,20 @@ func (s *ListService) DeleteList(listID uint, userID uint) error {
 		}
 		return err
 	}
-	if err := s.checkBoardAccess(userID, list.BoardID); err != nil {
+	if err := s.checkBoardAccess(userID, list.BoardID, policy.DeleteList); err != nil {
 		return err
 	}
 
-	// Before deleting the list, adjust positions of subsequent lists
-	err = s.listRepo.PerformTransaction(func(tx *gorm.DB) error { // Use PerformTransaction
-		// Decrement position of lists that were after the deleted list
-		if err := tx.Model(&models.List{}).
-			Where("board_id = ? AND position > ?", list.BoardID, list.Position).
-			Update("position", gorm.Expr("position - 1")).Error; err != nil {
-			return err
-		}
-		// Now delete the list. The actual deletion from listRepo.Delete should not also be in a transaction.
-		// The Delete method of the repository should just perform the delete operation.
-		// The transaction is managed by the service here.
-		// However, the current s.listRepo.Delete(listID) might be problematic if it also starts a tx.
-		// For now, assuming listRepo.Delete is a simple delete.
-		// A better way might be to have a version of Delete that accepts a `tx *gorm.DB`.
-		// For this refactor, let's assume s.listRepo.Delete is fine to be called here.
-		// The GORM `tx` object is passed to repository methods that need to be part of this specific transaction.
-		// So, if listRepo.Delete needs to be part of *this* transaction, it needs to accept `tx`.
-		// Let's assume `s.listRepo.Delete` is a simple operation for now.
-		// If `s.listRepo.Delete` itself starts a new transaction, this won't be a single atomic one.
-		// For this refactor, we'll keep it as is, and it implies `listRepo.Delete` does not manage its own tx.
-		// We need the list's BoardID for broadcasting before it's deleted, or ensure it's part of the info passed to Delete.
-		// The current list variable holds the details.
-		boardID := list.BoardID
-		err := s.listRepo.Delete(listID) // Assuming this is the actual delete operation
-		if err == nil {
-			// Broadcast list deletion
-			broadcastMessage(
-				s.hub,
-				boardID, // Use the boardID captured before deletion
-				realtime.MessageTypeListDeleted,
-				realtime.ListBasicInfo{ID: listID, BoardID: boardID}, // Simple payload
-				userID,
-			)
-		}
+	// The other lists keep their ranks, and so does this one if it's restored
+	if err := s.listRepo.Delete(listID); err != nil {
 		return err
-	})
-	return err
+	}
+	broadcastMessage(
+		s.hub,
+		list.BoardID,
+		realtime.MessageTypeListDeleted,
+		realtime.ListBasicInfo{ID: listID, BoardID: list.BoardID}, // Simple payload
+		userID,
+	)
+	return nil
 }
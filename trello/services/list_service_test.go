@@ -3,9 +3,11 @@ package services
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/realtime"
 	"github.com/zayyadi/trello/repositories"
 	"gorm.io/gorm"
 	// No longer importing sqlite or logger here
@@ -33,16 +35,17 @@ type MockListRepository struct {
 	FindByBoardIDFunc      func(boardID uint) ([]models.List, error)
 	UpdateFunc             func(list *models.List) error
 	DeleteFunc             func(id uint) error
-	GetMaxPositionFunc     func(boardID uint) (uint, error)
+	MoveFunc               func(listID uint, position uint) error
 	GetBoardIDByListIDFunc func(listID uint) (uint, error)
 	PerformTransactionFunc func(fn func(tx *gorm.DB) error) error
+	ReorderFunc            func(boardID uint, listIDs []uint) error
 
 	CreateCalledWithList         *models.List
 	FindByIDCalledWithID         uint
 	FindByBoardIDCalledWithID    uint
 	UpdateCalledWithList         *models.List
 	DeleteCalledWithID           uint
-	GetMaxPositionCalledWithID   uint
+	MoveCalledWith               []uint // List ID and position
 	GetBoardIDByListIDCalledWith uint
 }
 
@@ -56,13 +59,8 @@ func (m *MockListRepository) GetBoardIDByListID(listID uint) (uint, error) {
 
 func (m *MockListRepository) Create(list *models.List) error {
 	m.CreateCalledWithList = list
-	if list.Position == 0 {
-		if m.GetMaxPositionFunc != nil {
-			maxPos, _ := m.GetMaxPosition(list.BoardID)
-			list.Position = maxPos + 1
-		} else {
-			list.Position = 1
-		}
+	if list.Position == "" {
+		list.Position = "i"
 	}
 	if m.CreateFunc != nil {
 		return m.CreateFunc(list)
@@ -97,12 +95,12 @@ func (m *MockListRepository) Delete(id uint) error {
 	}
 	return nil
 }
-func (m *MockListRepository) GetMaxPosition(boardID uint) (uint, error) {
-	m.GetMaxPositionCalledWithID = boardID
-	if m.GetMaxPositionFunc != nil {
-		return m.GetMaxPositionFunc(boardID)
+func (m *MockListRepository) Move(listID uint, position uint) error {
+	m.MoveCalledWith = []uint{listID, position}
+	if m.MoveFunc != nil {
+		return m.MoveFunc(listID, position)
 	}
-	return 0, errors.New("GetMaxPositionFunc not implemented")
+	return errors.New("MoveFunc not implemented")
 }
 func (m *MockListRepository) PerformTransaction(fn func(tx *gorm.DB) error) error {
 	if m.PerformTransactionFunc != nil {
@@ -110,6 +108,12 @@ func (m *MockListRepository) PerformTransaction(fn func(tx *gorm.DB) error) erro
 	}
 	return fn(&gorm.DB{})
 }
+func (m *MockListRepository) Reorder(boardID uint, listIDs []uint) error {
+	if m.ReorderFunc != nil {
+		return m.ReorderFunc(boardID, listIDs)
+	}
+	return errors.New("ReorderFunc not implemented")
+}
 var _ repositories.ListRepositoryInterface = (*MockListRepository)(nil)
 
 
@@ -137,6 +141,7 @@ func (m *MockBoardRepositoryForListService) Delete(id uint) error { return error
 type MockBoardMemberRepositoryForListService struct {
 	repositories.BoardMemberRepositoryInterface
 	IsMemberFunc                                func(boardID uint, userID uint) (bool, error)
+	GetRoleFunc                                 func(boardID uint, userID uint) (models.BoardRole, error)
 }
 func (m *MockBoardMemberRepositoryForListService) IsMember(boardID uint, userID uint) (bool, error) {
 	if m.IsMemberFunc != nil { return m.IsMemberFunc(boardID, userID) }
@@ -146,6 +151,10 @@ func (m *MockBoardMemberRepositoryForListService) AddMember(member *models.Board
 func (m *MockBoardMemberRepositoryForListService) RemoveMember(boardID uint, userID uint) error { return errors.New("not implemented") }
 func (m *MockBoardMemberRepositoryForListService) FindMembersByBoardID(boardID uint) ([]models.BoardMember, error) { return nil, errors.New("not implemented") }
 func (m *MockBoardMemberRepositoryForListService) FindByBoardIDAndUserID(boardID uint, userID uint) (*models.BoardMember, error) { return nil, errors.New("not implemented") }
+func (m *MockBoardMemberRepositoryForListService) GetRole(boardID uint, userID uint) (models.BoardRole, error) {
+	if m.GetRoleFunc != nil { return m.GetRoleFunc(boardID, userID) }
+	return "", errors.New("GetRoleFunc on MockBoardMemberRepositoryForListService not implemented")
+}
 
 
 // TestListService_CreateList_Success and other tests remain the same until UpdateList/DeleteList tests
@@ -155,7 +164,7 @@ func TestListService_CreateList_Success(t *testing.T) {
 	mockBoardRepo := &MockBoardRepositoryForListService{}
 	mockBoardMemberRepo := &MockBoardMemberRepositoryForListService{}
 
-	listService := NewListService(mockListRepo, mockBoardRepo, mockBoardMemberRepo)
+	listService := NewListService(mockListRepo, mockBoardRepo, mockBoardMemberRepo, &MockHub{})
 
 	userID := uint(1)
 	boardID := uint(10)
@@ -165,15 +174,12 @@ func TestListService_CreateList_Success(t *testing.T) {
 		assert.Equal(t, boardID, id)
 		return &models.Board{Model: gorm.Model{ID: boardID}, OwnerID: userID}, nil
 	}
-	createdList := &models.List{Model: gorm.Model{ID: 100}, Name: listName, BoardID: boardID, Position: 1}
+	createdList := &models.List{Model: gorm.Model{ID: 100}, Name: listName, BoardID: boardID, Position: "i"}
 	mockListRepo.CreateFunc = func(list *models.List) error {
 		list.ID = createdList.ID
 		list.Position = createdList.Position
 		return nil
 	}
-	mockListRepo.GetMaxPositionFunc = func(bID uint) (uint, error) {
-		return 0, nil
-	}
 	mockListRepo.FindByIDFunc = func(id uint) (*models.List, error) {
 		return createdList, nil
 	}
@@ -187,14 +193,36 @@ func TestListService_CreateList_PermissionDenied_NotOwnerNotMember(t *testing.T)
 	mockListRepo := &MockListRepository{}
 	mockBoardRepo := &MockBoardRepositoryForListService{}
 	mockBoardMemberRepo := &MockBoardMemberRepositoryForListService{}
-	listService := NewListService(mockListRepo, mockBoardRepo, mockBoardMemberRepo)
+	listService := NewListService(mockListRepo, mockBoardRepo, mockBoardMemberRepo, &MockHub{})
+	userID, boardID, ownerID, listName := uint(1), uint(10), uint(2), "New List"
+
+	mockBoardRepo.FindByIDFunc = func(id uint) (*models.Board, error) {
+		return &models.Board{Model: gorm.Model{ID: boardID}, OwnerID: ownerID}, nil
+	}
+	mockBoardMemberRepo.GetRoleFunc = func(bID uint, uID uint) (models.BoardRole, error) {
+		return "", gorm.ErrRecordNotFound
+	}
+	mockListRepo.CreateFunc = func(list *models.List) error {
+		t.Error("listRepo.Create should not be called")
+		return nil
+	}
+	list, err := listService.CreateList(listName, boardID, userID, nil)
+	assert.ErrorIs(t, err, ErrForbidden)
+	assert.Nil(t, list)
+}
+
+func TestListService_CreateList_PermissionDenied_Viewer(t *testing.T) {
+	mockListRepo := &MockListRepository{}
+	mockBoardRepo := &MockBoardRepositoryForListService{}
+	mockBoardMemberRepo := &MockBoardMemberRepositoryForListService{}
+	listService := NewListService(mockListRepo, mockBoardRepo, mockBoardMemberRepo, &MockHub{})
 	userID, boardID, ownerID, listName := uint(1), uint(10), uint(2), "New List"
 
 	mockBoardRepo.FindByIDFunc = func(id uint) (*models.Board, error) {
 		return &models.Board{Model: gorm.Model{ID: boardID}, OwnerID: ownerID}, nil
 	}
-	mockBoardMemberRepo.IsMemberFunc = func(bID uint, uID uint) (bool, error) {
-		return false, nil
+	mockBoardMemberRepo.GetRoleFunc = func(bID uint, uID uint) (models.BoardRole, error) {
+		return models.BoardRoleViewer, nil // Viewers have read-only access
 	}
 	mockListRepo.CreateFunc = func(list *models.List) error {
 		t.Error("listRepo.Create should not be called")
@@ -209,7 +237,7 @@ func TestListService_CreateList_BoardNotFound(t *testing.T) {
 	mockListRepo := &MockListRepository{}
 	mockBoardRepo := &MockBoardRepositoryForListService{}
 	mockBoardMemberRepo := &MockBoardMemberRepositoryForListService{}
-	listService := NewListService(mockListRepo, mockBoardRepo, mockBoardMemberRepo)
+	listService := NewListService(mockListRepo, mockBoardRepo, mockBoardMemberRepo, &MockHub{})
 	userID, boardID, listName := uint(1), uint(10), "New List"
 
 	mockBoardRepo.FindByIDFunc = func(id uint) (*models.Board, error) {
@@ -228,14 +256,13 @@ func TestListService_CreateList_ErrOnCreate(t *testing.T) {
 	mockListRepo := &MockListRepository{}
 	mockBoardRepo := &MockBoardRepositoryForListService{}
 	mockBoardMemberRepo := &MockBoardMemberRepositoryForListService{}
-	listService := NewListService(mockListRepo, mockBoardRepo, mockBoardMemberRepo)
+	listService := NewListService(mockListRepo, mockBoardRepo, mockBoardMemberRepo, &MockHub{})
 	userID, boardID, listName, expectedError := uint(1), uint(10), "New List", errors.New("DB error")
 
 	mockBoardRepo.FindByIDFunc = func(id uint) (*models.Board, error) {
 		return &models.Board{Model: gorm.Model{ID: boardID}, OwnerID: userID}, nil
 	}
 	mockListRepo.CreateFunc = func(list *models.List) error { return expectedError }
-	mockListRepo.GetMaxPositionFunc = func(bID uint) (uint, error) { return 0, nil }
 
 	list, err := listService.CreateList(listName, boardID, userID, nil)
 	assert.ErrorIs(t, err, expectedError)
@@ -246,7 +273,7 @@ func TestListService_CreateList_ErrOnFinalFindByID(t *testing.T) {
 	mockListRepo := &MockListRepository{}
 	mockBoardRepo := &MockBoardRepositoryForListService{}
 	mockBoardMemberRepo := &MockBoardMemberRepositoryForListService{}
-	listService := NewListService(mockListRepo, mockBoardRepo, mockBoardMemberRepo)
+	listService := NewListService(mockListRepo, mockBoardRepo, mockBoardMemberRepo, &MockHub{})
 	userID, boardID, listName, expectedError := uint(1), uint(10), "New List", errors.New("DB error")
 	createdListID := uint(100)
 
@@ -254,7 +281,6 @@ func TestListService_CreateList_ErrOnFinalFindByID(t *testing.T) {
 		return &models.Board{Model: gorm.Model{ID: boardID}, OwnerID: userID}, nil
 	}
 	mockListRepo.CreateFunc = func(list *models.List) error { list.ID = createdListID; return nil }
-	mockListRepo.GetMaxPositionFunc = func(bID uint) (uint, error) { return 0, nil }
 	mockListRepo.FindByIDFunc = func(id uint) (*models.List, error) { return nil, expectedError }
 
 	list, err := listService.CreateList(listName, boardID, userID, nil)
@@ -266,7 +292,7 @@ func TestListService_GetListsByBoardID_Success(t *testing.T) {
 	mockListRepo := &MockListRepository{}
 	mockBoardRepo := &MockBoardRepositoryForListService{}
 	mockBoardMemberRepo := &MockBoardMemberRepositoryForListService{}
-	listService := NewListService(mockListRepo, mockBoardRepo, mockBoardMemberRepo)
+	listService := NewListService(mockListRepo, mockBoardRepo, mockBoardMemberRepo, &MockHub{})
 	userID, boardID := uint(1), uint(10)
 	expectedLists := []models.List{{Model: gorm.Model{ID:1}}}
 
@@ -282,11 +308,11 @@ func TestListService_GetListsByBoardID_PermissionDenied(t *testing.T) {
 	mockListRepo := &MockListRepository{}
 	mockBoardRepo := &MockBoardRepositoryForListService{}
 	mockBoardMemberRepo := &MockBoardMemberRepositoryForListService{}
-	listService := NewListService(mockListRepo, mockBoardRepo, mockBoardMemberRepo)
+	listService := NewListService(mockListRepo, mockBoardRepo, mockBoardMemberRepo, &MockHub{})
 	userID, boardID, ownerID := uint(1), uint(10), uint(2)
 
 	mockBoardRepo.FindByIDFunc = func(id uint) (*models.Board, error) { return &models.Board{Model: gorm.Model{ID: boardID}, OwnerID: ownerID}, nil }
-	mockBoardMemberRepo.IsMemberFunc = func(bID uint, uID uint) (bool, error) { return false, nil }
+	mockBoardMemberRepo.GetRoleFunc = func(bID uint, uID uint) (models.BoardRole, error) { return "", gorm.ErrRecordNotFound }
 
 	lists, err := listService.GetListsByBoardID(boardID, userID)
 	assert.ErrorIs(t, err, ErrForbidden)
@@ -297,7 +323,7 @@ func TestListService_GetListsByBoardID_RepoError(t *testing.T) {
 	mockListRepo := &MockListRepository{}
 	mockBoardRepo := &MockBoardRepositoryForListService{}
 	mockBoardMemberRepo := &MockBoardMemberRepositoryForListService{}
-	listService := NewListService(mockListRepo, mockBoardRepo, mockBoardMemberRepo)
+	listService := NewListService(mockListRepo, mockBoardRepo, mockBoardMemberRepo, &MockHub{})
 	userID, boardID, expectedError := uint(1), uint(10), errors.New("DB error")
 
 	mockBoardRepo.FindByIDFunc = func(id uint) (*models.Board, error) { return &models.Board{Model: gorm.Model{ID: boardID}, OwnerID: userID}, nil }
@@ -312,7 +338,7 @@ func TestListService_GetListByID_Success(t *testing.T) {
 	mockListRepo := &MockListRepository{}
 	mockBoardRepo := &MockBoardRepositoryForListService{}
 	mockBoardMemberRepo := &MockBoardMemberRepositoryForListService{}
-	listService := NewListService(mockListRepo, mockBoardRepo, mockBoardMemberRepo)
+	listService := NewListService(mockListRepo, mockBoardRepo, mockBoardMemberRepo, &MockHub{})
 	userID, boardID, listID := uint(1), uint(10), uint(100)
 	expectedList := &models.List{Model: gorm.Model{ID: listID}, BoardID: boardID}
 
@@ -329,7 +355,7 @@ func TestListService_GetListByID_ListNotFound(t *testing.T) {
 	mockListRepo := &MockListRepository{}
 	mockBoardRepo := &MockBoardRepositoryForListService{}
 	mockBoardMemberRepo := &MockBoardMemberRepositoryForListService{}
-	listService := NewListService(mockListRepo, mockBoardRepo, mockBoardMemberRepo)
+	listService := NewListService(mockListRepo, mockBoardRepo, mockBoardMemberRepo, &MockHub{})
 	userID, listID := uint(1), uint(100)
 
 	mockListRepo.FindByIDFunc = func(id uint) (*models.List, error) { return nil, gorm.ErrRecordNotFound }
@@ -343,37 +369,33 @@ func TestListService_GetListByID_PermissionDenied(t *testing.T) {
 	mockListRepo := &MockListRepository{}
 	mockBoardRepo := &MockBoardRepositoryForListService{}
 	mockBoardMemberRepo := &MockBoardMemberRepositoryForListService{}
-	listService := NewListService(mockListRepo, mockBoardRepo, mockBoardMemberRepo)
+	listService := NewListService(mockListRepo, mockBoardRepo, mockBoardMemberRepo, &MockHub{})
 	userID, boardID, listID, actualOwnerID := uint(1), uint(10), uint(100), uint(2)
 	foundList := &models.List{Model: gorm.Model{ID: listID}, BoardID: boardID}
 
 	mockListRepo.FindByIDFunc = func(id uint) (*models.List, error) { return foundList, nil }
 	mockListRepo.GetBoardIDByListIDFunc = func(lID uint) (uint, error) { return boardID, nil }
 	mockBoardRepo.FindByIDFunc = func(id uint) (*models.Board, error) { return &models.Board{Model: gorm.Model{ID: boardID}, OwnerID: actualOwnerID}, nil }
-	mockBoardMemberRepo.IsMemberFunc = func(bID uint, uID uint) (bool, error) { return false, nil }
+	mockBoardMemberRepo.GetRoleFunc = func(bID uint, uID uint) (models.BoardRole, error) { return "", gorm.ErrRecordNotFound }
 
 	list, err := listService.GetListByID(listID, userID)
 	assert.ErrorIs(t, err, ErrForbidden)
 	assert.Nil(t, list)
 }
 
-func TestListService_UpdateList_TransactionError(t *testing.T) {
+func TestListService_UpdateList_MoveError(t *testing.T) {
 	mockListRepo := &MockListRepository{}
 	mockBoardRepo := &MockBoardRepositoryForListService{}
 	mockBoardMemberRepo := &MockBoardMemberRepositoryForListService{}
-	listService := NewListService(mockListRepo, mockBoardRepo, mockBoardMemberRepo)
-	userID, listID, boardID, originalPosition, newPosition := uint(1), uint(100), uint(10), uint(1), uint(2)
-	expectedError := errors.New("transaction failed")
-	originalList := &models.List{Model: gorm.Model{ID: listID}, Name: "Test List", BoardID: boardID, Position: originalPosition}
+	listService := NewListService(mockListRepo, mockBoardRepo, mockBoardMemberRepo, &MockHub{})
+	userID, listID, boardID, newPosition := uint(1), uint(100), uint(10), uint(2)
+	expectedError := errors.New("move failed")
+	originalList := &models.List{Model: gorm.Model{ID: listID}, Name: "Test List", BoardID: boardID, Position: "i"}
 
 	mockListRepo.FindByIDFunc = func(id uint) (*models.List, error) { listCopy := *originalList; return &listCopy, nil }
 	mockListRepo.GetBoardIDByListIDFunc = func(lID uint) (uint, error) { return boardID, nil }
 	mockBoardRepo.FindByIDFunc = func(id uint) (*models.Board, error) { return &models.Board{Model: gorm.Model{ID: boardID}, OwnerID: userID}, nil }
-	mockListRepo.GetMaxPositionFunc = func(bID uint) (uint, error) { return 3, nil }
-
-	mockListRepo.PerformTransactionFunc = func(fn func(tx *gorm.DB) error) error {
-		return expectedError
-	}
+	mockListRepo.MoveFunc = func(lID uint, position uint) error { return expectedError }
 
 	list, err := listService.UpdateList(listID, nil, &newPosition, userID)
 	assert.ErrorIs(t, err, expectedError)
@@ -384,17 +406,17 @@ func TestListService_DeleteList_Success(t *testing.T) {
 	mockListRepo := &MockListRepository{}
 	mockBoardRepo := &MockBoardRepositoryForListService{}
 	mockBoardMemberRepo := &MockBoardMemberRepositoryForListService{}
-	listService := NewListService(mockListRepo, mockBoardRepo, mockBoardMemberRepo)
+	listService := NewListService(mockListRepo, mockBoardRepo, mockBoardMemberRepo, &MockHub{})
 	userID, listID, boardID := uint(1), uint(100), uint(10)
-	listToDelete := &models.List{Model: gorm.Model{ID: listID}, BoardID: boardID, Position: 1}
+	listToDelete := &models.List{Model: gorm.Model{ID: listID}, BoardID: boardID, Position: "i"}
 
 	mockListRepo.FindByIDFunc = func(id uint) (*models.List, error) { return listToDelete, nil }
 	mockListRepo.GetBoardIDByListIDFunc = func(lID uint) (uint, error) { return boardID, nil }
 	mockBoardRepo.FindByIDFunc = func(id uint) (*models.Board, error) { return &models.Board{Model: gorm.Model{ID: boardID}, OwnerID: userID}, nil }
 
-	dbForTx := setupTestDB(t)
 	mockListRepo.PerformTransactionFunc = func(fn func(tx *gorm.DB) error) error {
-		return fn(dbForTx)
+		t.Error("the other lists keep their ranks, so no transaction is needed")
+		return nil
 	}
 	deleteCalledOnRepo := false
 	mockListRepo.DeleteFunc = func(id uint) error { deleteCalledOnRepo = true; return nil }
@@ -408,10 +430,10 @@ func TestListService_UpdateList_SuccessNameChange(t *testing.T) {
 	mockListRepo := &MockListRepository{}
 	mockBoardRepo := &MockBoardRepositoryForListService{}
 	mockBoardMemberRepo := &MockBoardMemberRepositoryForListService{}
-	listService := NewListService(mockListRepo, mockBoardRepo, mockBoardMemberRepo)
+	listService := NewListService(mockListRepo, mockBoardRepo, mockBoardMemberRepo, &MockHub{})
 	userID, boardID, listID, originalName, newName := uint(1), uint(10), uint(100), "Original", "Updated"
-	originalList := &models.List{Model: gorm.Model{ID: listID}, Name: originalName, BoardID: boardID, Position: 1}
-	updatedList := &models.List{Model: gorm.Model{ID: listID}, Name: newName, BoardID: boardID, Position: 1}
+	originalList := &models.List{Model: gorm.Model{ID: listID}, Name: originalName, BoardID: boardID, Position: "i"}
+	updatedList := &models.List{Model: gorm.Model{ID: listID}, Name: newName, BoardID: boardID, Position: "i"}
 
 	var findByIdCallCount int
 	mockListRepo.FindByIDFunc = func(id uint) (*models.List, error) {
@@ -433,37 +455,33 @@ func TestListService_UpdateList_SuccessPositionChange(t *testing.T) {
 	mockListRepo := &MockListRepository{}
 	mockBoardRepo := &MockBoardRepositoryForListService{}
 	mockBoardMemberRepo := &MockBoardMemberRepositoryForListService{}
-	listService := NewListService(mockListRepo, mockBoardRepo, mockBoardMemberRepo)
-	userID, boardID, listID, originalPosition, newPos := uint(1), uint(10), uint(100), uint(1), uint(2)
-	originalList := &models.List{Model: gorm.Model{ID: listID}, Name: "Test List", BoardID: boardID, Position: originalPosition}
-	listAfterTxSave := &models.List{Model: gorm.Model{ID: listID}, Name: "Test List", BoardID: boardID, Position: newPos}
+	listService := NewListService(mockListRepo, mockBoardRepo, mockBoardMemberRepo, &MockHub{})
+	userID, boardID, listID, newPos := uint(1), uint(10), uint(100), uint(2)
+	originalList := &models.List{Model: gorm.Model{ID: listID}, Name: "Test List", BoardID: boardID, Position: "9"}
+	listAfterMove := &models.List{Model: gorm.Model{ID: listID}, Name: "Test List", BoardID: boardID, Position: "r"}
 
 	var initialFindByIDCalled bool
 	mockListRepo.FindByIDFunc = func(id uint) (*models.List, error) {
 		if !initialFindByIDCalled { initialFindByIDCalled = true; listCopy := *originalList; return &listCopy, nil }
-		return listAfterTxSave, nil
+		return listAfterMove, nil
 	}
 	mockListRepo.GetBoardIDByListIDFunc = func(lID uint) (uint, error) { return boardID, nil }
 	mockBoardRepo.FindByIDFunc = func(id uint) (*models.Board, error) { return &models.Board{Model: gorm.Model{ID: boardID}, OwnerID: userID}, nil }
-	mockListRepo.GetMaxPositionFunc = func(bID uint) (uint, error) { return 3, nil }
-
-	dbForTx := setupTestDB(t)
-	mockListRepo.PerformTransactionFunc = func(fn func(tx *gorm.DB) error) error {
-		return fn(dbForTx)
-	}
+	mockListRepo.MoveFunc = func(lID uint, position uint) error { return nil }
 	mockListRepo.UpdateFunc = func(l *models.List) error { t.Error("listRepo.Update should not be called"); return nil }
 
 	list, err := listService.UpdateList(listID, nil, &newPos, userID)
 	assert.NoError(t, err)
 	assert.NotNil(t, list)
-	assert.Equal(t, newPos, list.Position)
+	assert.Equal(t, []uint{listID, newPos}, mockListRepo.MoveCalledWith, "only the moved list is touched")
+	assert.Equal(t, "r", list.Position)
 }
 
 func TestListService_UpdateList_ListNotFound(t *testing.T) {
 	mockListRepo := &MockListRepository{}
 	mockBoardRepo := &MockBoardRepositoryForListService{}
 	mockBoardMemberRepo := &MockBoardMemberRepositoryForListService{}
-	listService := NewListService(mockListRepo, mockBoardRepo, mockBoardMemberRepo)
+	listService := NewListService(mockListRepo, mockBoardRepo, mockBoardMemberRepo, &MockHub{})
 	userID, listID, newName := uint(1), uint(100), "New Name"
 
 	mockListRepo.FindByIDFunc = func(id uint) (*models.List, error) { return nil, gorm.ErrRecordNotFound }
@@ -477,16 +495,67 @@ func TestListService_UpdateList_PermissionDenied(t *testing.T) {
 	mockListRepo := &MockListRepository{}
 	mockBoardRepo := &MockBoardRepositoryForListService{}
 	mockBoardMemberRepo := &MockBoardMemberRepositoryForListService{}
-	listService := NewListService(mockListRepo, mockBoardRepo, mockBoardMemberRepo)
+	listService := NewListService(mockListRepo, mockBoardRepo, mockBoardMemberRepo, &MockHub{})
 	userID, listID, boardID, actualOwnerID, newName := uint(1), uint(100), uint(10), uint(2), "New Name"
 	foundList := &models.List{Model: gorm.Model{ID: listID}, BoardID: boardID}
 
 	mockListRepo.FindByIDFunc = func(id uint) (*models.List, error) { return foundList, nil }
 	mockListRepo.GetBoardIDByListIDFunc = func(lID uint) (uint, error) { return boardID, nil }
 	mockBoardRepo.FindByIDFunc = func(id uint) (*models.Board, error) { return &models.Board{Model: gorm.Model{ID: boardID}, OwnerID: actualOwnerID}, nil }
-	mockBoardMemberRepo.IsMemberFunc = func(bID uint, uID uint) (bool, error) { return false, nil }
+	mockBoardMemberRepo.GetRoleFunc = func(bID uint, uID uint) (models.BoardRole, error) { return "", gorm.ErrRecordNotFound }
 
 	list, err := listService.UpdateList(listID, &newName, nil, userID)
 	assert.ErrorIs(t, err, ErrForbidden)
 	assert.Nil(t, list)
 }
+
+func TestListService_ReorderLists(t *testing.T) {
+	db := setupTestDB(t)
+	userRepo := repositories.NewUserRepository(db)
+	boardRepo := repositories.NewBoardRepository(db)
+	boardMemberRepo := repositories.NewBoardMemberRepository(db)
+	listRepo := repositories.NewListRepository(db)
+
+	var messages []string
+	hub := &MockHub{SubmitFunc: func(msg *realtime.WebSocketMessage) { messages = append(messages, msg.Type) }}
+	lists := NewListService(listRepo, boardRepo, boardMemberRepo, hub)
+	boards := NewBoardService(boardRepo, userRepo, boardMemberRepo, &MockHub{}, false)
+	alice := &models.User{Username: "alice", Email: "alice@example.com", Password: "x"}
+	vera := &models.User{Username: "vera", Email: "vera@example.com", Password: "x"}
+	assert.NoError(t, userRepo.Create(alice))
+	assert.NoError(t, userRepo.Create(vera))
+	board, err := boards.CreateBoard("Launch", "", alice.ID)
+	assert.NoError(t, err)
+	_, err = boards.AddMemberToBoard(board.ID, nil, &vera.ID, models.BoardRoleViewer, alice.ID)
+	assert.NoError(t, err)
+	var ids []uint
+	for _, name := range []string{"To do", "Doing", "Done", "Someday"} {
+		list, err := lists.CreateList(name, board.ID, alice.ID, nil)
+		assert.NoError(t, err)
+		ids = append(ids, list.ID)
+	}
+	assert.NoError(t, repositories.NewArchiveRepository(db).ArchiveList(ids[3], time.Now()))
+	active := ids[:3]
+
+	_, err = lists.ReorderLists(board.ID, []uint{active[2], active[1], active[0]}, vera.ID)
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = lists.ReorderLists(board.ID, []uint{active[2], active[1]}, alice.ID)
+	assert.ErrorIs(t, err, ErrInvalidInput, "every list must be named")
+	_, err = lists.ReorderLists(board.ID, []uint{active[2], active[1], active[1]}, alice.ID)
+	assert.ErrorIs(t, err, ErrInvalidInput, "each list only once")
+	_, err = lists.ReorderLists(board.ID, []uint{active[2], active[1], ids[3]}, alice.ID)
+	assert.ErrorIs(t, err, ErrInvalidInput, "archived lists are out of the order")
+
+	messages = nil
+	reordered, err := lists.ReorderLists(board.ID, []uint{active[2], active[0], active[1]}, alice.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, reordered, 3) {
+		for i, id := range []uint{active[2], active[0], active[1]} {
+			assert.Equal(t, id, reordered[i].ID)
+			if i > 0 {
+				assert.Less(t, reordered[i-1].Position, reordered[i].Position)
+			}
+		}
+	}
+	assert.Equal(t, []string{realtime.MessageTypeListsReordered}, messages)
+}
@@ -0,0 +1,218 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/repositories"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultMaxAccountLoginFailures = 5
+	DefaultMaxIPLoginFailures      = 20
+	DefaultLoginLockout            = time.Minute // First lockout; doubles with every further failure
+	DefaultMaxLoginLockout         = time.Hour
+	DefaultLoginFailureWindow      = 24 * time.Hour
+)
+
+// LoginThrottleOptions configures LoginThrottleService. Zero values fall back to the defaults above.
+type LoginThrottleOptions struct {
+	MaxAccountFailures int           // Failures for one account before it is locked
+	MaxIPFailures      int           // Failures from one IP address, across accounts, before it is locked
+	BaseLockout        time.Duration // Length of the first lockout
+	MaxLockout         time.Duration // Cap on the doubling lockout
+	// FailureWindow is how long after the last failure the counter starts over.
+	// It should be longer than MaxLockout, or the backoff never grows past it.
+	FailureWindow time.Duration
+}
+
+// LoginThrottleService protects logins against password guessing. It counts failed
+// logins per account and per client IP, and once either passes its limit refuses
+// logins for a lockout period that doubles with every further failure.
+// A nil *LoginThrottleService is valid and never throttles.
+type LoginThrottleService struct {
+	throttleRepo repositories.LoginThrottleRepositoryInterface
+	audit        *AuditService
+	opts         LoginThrottleOptions
+	now          func() time.Time
+}
+
+func NewLoginThrottleService(
+	throttleRepo repositories.LoginThrottleRepositoryInterface,
+	audit *AuditService,
+	opts LoginThrottleOptions,
+) *LoginThrottleService {
+	if opts.MaxAccountFailures <= 0 {
+		opts.MaxAccountFailures = DefaultMaxAccountLoginFailures
+	}
+	if opts.MaxIPFailures <= 0 {
+		opts.MaxIPFailures = DefaultMaxIPLoginFailures
+	}
+	if opts.BaseLockout <= 0 {
+		opts.BaseLockout = DefaultLoginLockout
+	}
+	if opts.MaxLockout <= 0 {
+		opts.MaxLockout = DefaultMaxLoginLockout
+	}
+	if opts.FailureWindow <= 0 {
+		opts.FailureWindow = DefaultLoginFailureWindow
+	}
+	return &LoginThrottleService{
+		throttleRepo: throttleRepo,
+		audit:        audit,
+		opts:         opts,
+		now:          time.Now,
+	}
+}
+
+// Check returns a *LoginLockedError if logins for this email or from this IP are locked.
+// It is called before the password is checked, so a locked account stays locked
+// even for the right password.
+func (s *LoginThrottleService) Check(email, ip string) error {
+	if s == nil {
+		return nil
+	}
+	now := s.now()
+	var retryAfter time.Duration
+	for _, target := range s.targets(email, ip) {
+		throttle, err := s.throttleRepo.Find(target.scope, target.key)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return err
+		}
+		if throttle.IsLocked(now) && throttle.LockedUntil.Sub(now) > retryAfter {
+			retryAfter = throttle.LockedUntil.Sub(now)
+		}
+	}
+	if retryAfter > 0 {
+		// Only logged, not stored: a locked-out attacker could otherwise fill the audit table
+		log.Printf("AUDIT login.blocked: email=%q ip=%q retryAfter=%s", normalizeLoginEmail(email), ip, retryAfter.Round(time.Second))
+		return &LoginLockedError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// RecordFailure counts a failed login and locks the account or IP once it is over its limit.
+// userID is nil when the email does not belong to an account.
+func (s *LoginThrottleService) RecordFailure(email, ip string, userID *uint, reason string) error {
+	if s == nil {
+		return nil
+	}
+	now := s.now()
+	s.audit.Record(&models.AuditEvent{
+		Type: models.AuditEventLoginFailed, UserID: userID, Email: normalizeLoginEmail(email), IP: ip, Detail: reason,
+	})
+
+	for _, target := range s.targets(email, ip) {
+		throttle, err := s.throttleRepo.RecordFailure(target.scope, target.key, now, s.opts.FailureWindow)
+		if err != nil {
+			return err
+		}
+		if throttle.Failures < target.limit {
+			continue
+		}
+		lockout := s.lockoutFor(throttle.Failures - target.limit)
+		if err := s.throttleRepo.Lock(target.scope, target.key, now.Add(lockout)); err != nil {
+			return err
+		}
+		s.audit.Record(&models.AuditEvent{
+			Type:   models.AuditEventLoginLocked,
+			UserID: userID,
+			Email:  normalizeLoginEmail(email),
+			IP:     ip,
+			Detail: fmt.Sprintf("%s %q locked for %s after %d failed logins", target.scope, target.key, lockout, throttle.Failures),
+		})
+	}
+	return nil
+}
+
+// RecordSuccess clears the account's failure count after a complete login.
+// The IP's count is left alone, so one valid account can't be used to keep
+// resetting the counter of an address guessing at others.
+func (s *LoginThrottleService) RecordSuccess(email, ip string, userID uint) error {
+	if s == nil {
+		return nil
+	}
+	s.audit.Record(&models.AuditEvent{
+		Type: models.AuditEventLoginSucceeded, UserID: &userID, Email: normalizeLoginEmail(email), IP: ip,
+	})
+	err := s.throttleRepo.Delete(models.LoginThrottleScopeAccount, normalizeLoginEmail(email))
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+// Locked lists the accounts and IP addresses that are currently locked out.
+func (s *LoginThrottleService) Locked() ([]models.LoginThrottle, error) {
+	if s == nil {
+		return []models.LoginThrottle{}, nil
+	}
+	return s.throttleRepo.FindLocked(s.now())
+}
+
+// Unlock clears the lock and failure count of an account or IP address.
+func (s *LoginThrottleService) Unlock(scope models.LoginThrottleScope, key string, adminID uint) error {
+	if s == nil {
+		return ErrLockoutNotFound
+	}
+	switch scope {
+	case models.LoginThrottleScopeAccount:
+		key = normalizeLoginEmail(key)
+	case models.LoginThrottleScopeIP:
+	default:
+		return ErrInvalidInput
+	}
+	if err := s.throttleRepo.Delete(scope, key); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrLockoutNotFound
+		}
+		return err
+	}
+	s.audit.Record(&models.AuditEvent{
+		Type:    models.AuditEventLoginUnlocked,
+		ActorID: &adminID,
+		Detail:  fmt.Sprintf("%s %q unlocked", scope, key),
+	})
+	return nil
+}
+
+// lockoutFor returns the lockout after the given number of failures past the limit:
+// the base lockout, doubled for each one, up to the maximum.
+func (s *LoginThrottleService) lockoutFor(excessFailures int) time.Duration {
+	lockout := s.opts.BaseLockout
+	for i := 0; i < excessFailures && lockout < s.opts.MaxLockout; i++ {
+		lockout *= 2
+	}
+	if lockout > s.opts.MaxLockout {
+		lockout = s.opts.MaxLockout
+	}
+	return lockout
+}
+
+type throttleTarget struct {
+	scope models.LoginThrottleScope
+	key   string
+	limit int
+}
+
+// targets lists the counters a login attempt is checked against. Unknown emails
+// are counted too, so lockouts don't reveal which accounts exist.
+func (s *LoginThrottleService) targets(email, ip string) []throttleTarget {
+	targets := []throttleTarget{{models.LoginThrottleScopeAccount, normalizeLoginEmail(email), s.opts.MaxAccountFailures}}
+	if ip != "" {
+		targets = append(targets, throttleTarget{models.LoginThrottleScopeIP, ip, s.opts.MaxIPFailures})
+	}
+	return targets
+}
+
+func normalizeLoginEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
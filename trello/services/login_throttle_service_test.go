@@ -0,0 +1,245 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/repositories"
+	"github.com/zayyadi/trello/utils"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// MockAuditEventRepository keeps audit events in memory
+type MockAuditEventRepository struct {
+	Events []models.AuditEvent
+}
+
+func (m *MockAuditEventRepository) Create(event *models.AuditEvent) error {
+	event.ID = uint(len(m.Events) + 1)
+	m.Events = append(m.Events, *event)
+	return nil
+}
+
+func (m *MockAuditEventRepository) List(eventType models.AuditEventType, userID *uint, limit int) ([]models.AuditEvent, error) {
+	var events []models.AuditEvent
+	for i := len(m.Events) - 1; i >= 0 && len(events) < limit; i-- {
+		if eventType == "" || m.Events[i].Type == eventType {
+			events = append(events, m.Events[i])
+		}
+	}
+	return events, nil
+}
+
+// types returns the types of the recorded events, in order.
+func (m *MockAuditEventRepository) types() []models.AuditEventType {
+	types := make([]models.AuditEventType, len(m.Events))
+	for i, e := range m.Events {
+		types[i] = e.Type
+	}
+	return types
+}
+
+type throttleTestEnv struct {
+	throttle  *LoginThrottleService
+	auditRepo *MockAuditEventRepository
+	now       time.Time
+}
+
+func newThrottleTestEnv() *throttleTestEnv {
+	env := &throttleTestEnv{
+		auditRepo: &MockAuditEventRepository{},
+		now:       time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+	env.throttle = NewLoginThrottleService(repositories.NewMemoryLoginThrottleRepository(), NewAuditService(env.auditRepo), LoginThrottleOptions{
+		MaxAccountFailures: 3,
+		MaxIPFailures:      5,
+		BaseLockout:        time.Minute,
+		MaxLockout:         4 * time.Minute,
+		FailureWindow:      time.Hour,
+	})
+	env.throttle.now = func() time.Time { return env.now }
+	return env
+}
+
+func (e *throttleTestEnv) fail(t *testing.T, email, ip string) {
+	if err := e.throttle.RecordFailure(email, ip, nil, "wrong password"); err != nil {
+		t.Fatalf("RecordFailure: %v", err)
+	}
+}
+
+func retryAfter(t *testing.T, err error) time.Duration {
+	var locked *LoginLockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("expected *LoginLockedError, got %v", err)
+	}
+	return locked.RetryAfter
+}
+
+func TestLoginThrottle_AccountLockoutWithBackoff(t *testing.T) {
+	env := newThrottleTestEnv()
+
+	env.fail(t, "jane@example.com", "10.0.0.1")
+	env.fail(t, "jane@example.com", "10.0.0.2")
+	assert.NoError(t, env.throttle.Check("jane@example.com", "10.0.0.3"))
+
+	env.fail(t, "jane@example.com", "10.0.0.3")
+	err := env.throttle.Check("JANE@example.com ", "10.0.0.4") // Emails are compared normalized
+	assert.ErrorIs(t, err, ErrLoginLocked)
+	assert.Equal(t, time.Minute, retryAfter(t, err))
+	assert.NoError(t, env.throttle.Check("john@example.com", "10.0.0.4"), "other accounts are unaffected")
+
+	// Each further failure doubles the lockout, up to the maximum
+	env.now = env.now.Add(time.Minute)
+	assert.NoError(t, env.throttle.Check("jane@example.com", ""))
+	env.fail(t, "jane@example.com", "")
+	assert.Equal(t, 2*time.Minute, retryAfter(t, env.throttle.Check("jane@example.com", "")))
+	env.now = env.now.Add(2 * time.Minute)
+	env.fail(t, "jane@example.com", "")
+	assert.Equal(t, 4*time.Minute, retryAfter(t, env.throttle.Check("jane@example.com", "")))
+	env.now = env.now.Add(4 * time.Minute)
+	env.fail(t, "jane@example.com", "")
+	assert.Equal(t, 4*time.Minute, retryAfter(t, env.throttle.Check("jane@example.com", "")), "capped at the maximum")
+
+	// After a quiet failure window the count starts over
+	env.now = env.now.Add(2 * time.Hour)
+	assert.NoError(t, env.throttle.Check("jane@example.com", ""))
+	env.fail(t, "jane@example.com", "")
+	assert.NoError(t, env.throttle.Check("jane@example.com", ""))
+}
+
+func TestLoginThrottle_IPLockoutAcrossAccounts(t *testing.T) {
+	env := newThrottleTestEnv()
+
+	for i, email := range []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com", "e@example.com"} {
+		assert.NoError(t, env.throttle.Check(email, "10.0.0.9"), "attempt %d", i)
+		env.fail(t, email, "10.0.0.9")
+	}
+
+	err := env.throttle.Check("f@example.com", "10.0.0.9")
+	assert.ErrorIs(t, err, ErrLoginLocked)
+	assert.NoError(t, env.throttle.Check("f@example.com", "10.0.0.10"), "other addresses are unaffected")
+}
+
+func TestLoginThrottle_SuccessResetsAccountOnly(t *testing.T) {
+	env := newThrottleTestEnv()
+
+	env.fail(t, "jane@example.com", "10.0.0.1")
+	env.fail(t, "jane@example.com", "10.0.0.1")
+	assert.NoError(t, env.throttle.RecordSuccess("jane@example.com", "10.0.0.1", 7))
+
+	env.fail(t, "jane@example.com", "10.0.0.1")
+	env.fail(t, "jane@example.com", "10.0.0.1")
+	assert.NoError(t, env.throttle.Check("jane@example.com", "10.0.0.1"), "the account count was reset")
+
+	env.fail(t, "john@example.com", "10.0.0.1")
+	assert.ErrorIs(t, env.throttle.Check("john@example.com", "10.0.0.1"), ErrLoginLocked, "the IP count was not")
+}
+
+func TestLoginThrottle_ListUnlockAndAudit(t *testing.T) {
+	env := newThrottleTestEnv()
+	userID := uint(7)
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, env.throttle.RecordFailure("jane@example.com", "10.0.0.1", &userID, "wrong password"))
+	}
+
+	locked, err := env.throttle.Locked()
+	assert.NoError(t, err)
+	if assert.Len(t, locked, 1) {
+		assert.Equal(t, models.LoginThrottleScopeAccount, locked[0].Scope)
+		assert.Equal(t, "jane@example.com", locked[0].Key)
+		assert.Equal(t, 3, locked[0].Failures)
+	}
+
+	assert.ErrorIs(t, env.throttle.Unlock(models.LoginThrottleScopeAccount, "nobody@example.com", 1), ErrLockoutNotFound)
+	assert.ErrorIs(t, env.throttle.Unlock("user", "jane@example.com", 1), ErrInvalidInput)
+	assert.NoError(t, env.throttle.Unlock(models.LoginThrottleScopeAccount, "Jane@Example.com", 1))
+	assert.NoError(t, env.throttle.Check("jane@example.com", "10.0.0.1"))
+
+	assert.Equal(t, []models.AuditEventType{
+		models.AuditEventLoginFailed,
+		models.AuditEventLoginFailed,
+		models.AuditEventLoginFailed,
+		models.AuditEventLoginLocked,
+		models.AuditEventLoginUnlocked,
+	}, env.auditRepo.types())
+	assert.Equal(t, &userID, env.auditRepo.Events[0].UserID)
+	assert.Equal(t, "10.0.0.1", env.auditRepo.Events[0].IP)
+	unlock := env.auditRepo.Events[4]
+	if assert.NotNil(t, unlock.ActorID) {
+		assert.Equal(t, uint(1), *unlock.ActorID)
+	}
+}
+
+func TestLoginThrottle_NilIsDisabled(t *testing.T) {
+	var throttle *LoginThrottleService
+	assert.NoError(t, throttle.Check("jane@example.com", "10.0.0.1"))
+	assert.NoError(t, throttle.RecordFailure("jane@example.com", "10.0.0.1", nil, "wrong password"))
+	assert.NoError(t, throttle.RecordSuccess("jane@example.com", "10.0.0.1", 1))
+	locked, err := throttle.Locked()
+	assert.NoError(t, err)
+	assert.Empty(t, locked)
+}
+
+func TestAuthService_LoginLockout(t *testing.T) {
+	env := newThrottleTestEnv()
+	hashedPassword, _ := utils.HashPassword("password123")
+	userRepo := &MockUserRepository{}
+	inMemoryUsers(userRepo, &models.User{Model: gorm.Model{ID: 7}, Username: "jane", Email: "jane@example.com", Password: hashedPassword})
+	authService := NewAuthService(userRepo, &MockRefreshTokenRepository{}, &MockUserTokenRepository{}, &MockMailer{}, AuthOptions{
+		JWTSecretKey:  "test-secret",
+		LoginThrottle: env.throttle,
+	})
+
+	for i := 0; i < 3; i++ {
+		_, _, err := authService.Login("jane@example.com", "wrong", "10.0.0.1")
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	}
+	_, tokens, err := authService.Login("jane@example.com", "password123", "10.0.0.1")
+	assert.ErrorIs(t, err, ErrLoginLocked, "locked even with the right password")
+	assert.Nil(t, tokens)
+
+	// Unknown emails are throttled the same way
+	for i := 0; i < 3; i++ {
+		_, _, err = authService.Login("ghost@example.com", "wrong", "10.0.0.2")
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	}
+	_, _, err = authService.Login("ghost@example.com", "wrong", "10.0.0.2")
+	assert.ErrorIs(t, err, ErrLoginLocked)
+
+	env.now = env.now.Add(time.Minute)
+	_, tokens, err = authService.Login("jane@example.com", "password123", "10.0.0.1")
+	assert.NoError(t, err)
+	assert.NotNil(t, tokens)
+	assert.Contains(t, env.auditRepo.types(), models.AuditEventLoginSucceeded)
+
+	// The successful login cleared the count, so a single failure doesn't lock again
+	_, _, err = authService.Login("jane@example.com", "wrong", "10.0.0.1")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	_, _, err = authService.Login("jane@example.com", "password123", "10.0.0.1")
+	assert.NoError(t, err)
+}
+
+func TestTwoFactorService_WrongCodesCountTowardsLockout(t *testing.T) {
+	env := newTwoFactorTestEnv(t)
+	throttleEnv := newThrottleTestEnv()
+	env.authService.opts.LoginThrottle = throttleEnv.throttle
+	env.enable(t)
+
+	var challenge *TwoFactorRequiredError
+	_, _, err := env.authService.Login("jane@example.com", "password123", "10.0.0.1")
+	assert.True(t, errors.As(err, &challenge))
+	for i := 0; i < 3; i++ {
+		_, _, err = env.service.CompleteLogin(challenge.ChallengeToken, "000000", "10.0.0.1")
+		assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+	}
+
+	_, _, err = env.service.CompleteLogin(challenge.ChallengeToken, currentCode(t, env.user.TwoFactorSecret, 0), "10.0.0.1")
+	assert.ErrorIs(t, err, ErrLoginLocked)
+	_, _, err = env.authService.Login("jane@example.com", "password123", "10.0.0.1")
+	assert.ErrorIs(t, err, ErrLoginLocked)
+}
@@ -0,0 +1,228 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/oidc"
+	"github.com/zayyadi/trello/repositories"
+	"github.com/zayyadi/trello/utils"
+	"gorm.io/gorm"
+)
+
+// maxUsernameAttempts bounds how many numbered variants of a username we try
+// before giving up when creating a user just-in-time.
+const maxUsernameAttempts = 20
+
+var usernameDisallowed = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// OIDCService signs users in through an external OpenID Connect identity provider.
+// External accounts are linked to local users, which are created on first login.
+type OIDCService struct {
+	provider     *oidc.Provider
+	authService  *AuthService // Issues our own session once the provider has vouched for the user
+	userRepo     repositories.UserRepositoryInterface
+	identityRepo repositories.UserIdentityRepositoryInterface
+}
+
+func NewOIDCService(
+	provider *oidc.Provider,
+	authService *AuthService,
+	userRepo repositories.UserRepositoryInterface,
+	identityRepo repositories.UserIdentityRepositoryInterface,
+) *OIDCService {
+	return &OIDCService{
+		provider:     provider,
+		authService:  authService,
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+	}
+}
+
+// BeginLogin starts a login attempt. The caller must keep the returned flow
+// state (e.g. in a cookie) and send the user's browser to the returned URL.
+func (s *OIDCService) BeginLogin(ctx context.Context) (string, *oidc.FlowState, error) {
+	flow, err := oidc.NewFlowState()
+	if err != nil {
+		return "", nil, err
+	}
+	authURL, err := s.provider.AuthCodeURL(ctx, flow.State, flow.Nonce, flow.CodeVerifier)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %v", ErrOIDCLoginFailed, err)
+	}
+	return authURL, flow, nil
+}
+
+// CompleteLogin handles the provider's callback: it checks the state, redeems the
+// code, then signs in the linked local user (creating or linking one if needed).
+func (s *OIDCService) CompleteLogin(ctx context.Context, flow *oidc.FlowState, state, code string) (*models.User, *AuthTokens, error) {
+	if flow == nil || flow.CheckState(state) != nil {
+		return nil, nil, ErrOIDCStateMismatch
+	}
+	if code == "" {
+		return nil, nil, fmt.Errorf("%w: no authorization code in callback", ErrOIDCLoginFailed)
+	}
+
+	identity, err := s.provider.Exchange(ctx, code, flow.CodeVerifier, flow.Nonce)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrOIDCLoginFailed, err)
+	}
+	return s.loginIdentity(identity)
+}
+
+// loginIdentity resolves the local user for a verified external identity and
+// starts a session, or asks for a second factor like Login does.
+func (s *OIDCService) loginIdentity(identity *oidc.Identity) (*models.User, *AuthTokens, error) {
+	user, err := s.resolveUser(identity)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if s.authService.opts.RequireVerifiedEmail && !user.EmailVerified {
+		return nil, nil, ErrEmailNotVerified
+	}
+	if user.TwoFactorEnabled {
+		return nil, nil, s.authService.twoFactorChallenge(user) // The provider only vouches for the first factor
+	}
+
+	tokens, err := s.authService.startSession(user.ID)
+	if err != nil {
+		return user, nil, err
+	}
+	return user, tokens, nil
+}
+
+// resolveUser finds the user linked to the identity. Failing that it links the
+// identity to the local account with the same email, but only when the provider
+// has verified that email; otherwise it creates a new user.
+func (s *OIDCService) resolveUser(identity *oidc.Identity) (*models.User, error) {
+	link, err := s.identityRepo.FindByIssuerAndSubject(identity.Issuer, identity.Subject)
+	if err == nil {
+		user, err := s.userRepo.FindByID(link.UserID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrUserNotFound // Linked account has been deleted
+			}
+			return nil, err
+		}
+		s.syncIdentity(link, user, identity)
+		return user, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	// First login with this external account
+	if identity.Email == "" {
+		return nil, fmt.Errorf("%w: identity provider did not return an email address", ErrOIDCLoginFailed)
+	}
+
+	user, err := s.userRepo.FindByEmail(identity.Email)
+	if err == nil {
+		if !identity.EmailVerified {
+			// Linking on an unverified address would let anyone claim the account
+			return nil, ErrEmailExists
+		}
+		if !user.EmailVerified {
+			markEmailVerified(user)
+			if err := s.userRepo.Update(user); err != nil {
+				return nil, err
+			}
+		}
+	} else if errors.Is(err, gorm.ErrRecordNotFound) {
+		user, err = s.createUser(identity)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		return nil, err
+	}
+
+	if err := s.identityRepo.Create(&models.UserIdentity{
+		UserID:  user.ID,
+		Issuer:  identity.Issuer,
+		Subject: identity.Subject,
+		Email:   identity.Email,
+	}); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// createUser creates a local account for a first-time external login.
+// The account gets an unguessable password; the user can set a real one via password reset.
+func (s *OIDCService) createUser(identity *oidc.Identity) (*models.User, error) {
+	username, err := s.availableUsername(identity)
+	if err != nil {
+		return nil, err
+	}
+	randomPassword, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := utils.HashPassword(randomPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		Username: username,
+		Email:    identity.Email,
+		Password: hashedPassword,
+	}
+	if identity.EmailVerified {
+		markEmailVerified(user)
+	}
+	if err := s.userRepo.Create(user); err != nil {
+		return nil, err
+	}
+
+	if !user.EmailVerified {
+		if err := s.authService.sendVerificationEmail(user); err != nil {
+			log.Printf("Failed to send verification email to user %d: %v", user.ID, err)
+		}
+	}
+	return user, nil
+}
+
+// availableUsername derives a username from the identity, adding a numeric
+// suffix if it is already taken.
+func (s *OIDCService) availableUsername(identity *oidc.Identity) (string, error) {
+	base := identity.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(identity.Email, "@")
+	}
+	base = strings.Trim(usernameDisallowed.ReplaceAllString(base, ""), ".-")
+	if base == "" {
+		base = "user"
+	}
+
+	candidate := base
+	for i := 1; i <= maxUsernameAttempts; i++ {
+		_, err := s.userRepo.FindByUsername(candidate)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		candidate = fmt.Sprintf("%s%d", base, i+1)
+	}
+	return "", ErrUsernameExists
+}
+
+// syncIdentity records the email the provider currently reports for the identity.
+// Failures are only logged since they don't affect the login.
+func (s *OIDCService) syncIdentity(link *models.UserIdentity, user *models.User, identity *oidc.Identity) {
+	if link.Email == identity.Email {
+		return
+	}
+	link.Email = identity.Email
+	if err := s.identityRepo.Update(link); err != nil {
+		log.Printf("Failed to update identity %d for user %d: %v", link.ID, user.ID, err)
+	}
+}
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/oidc"
+	"github.com/zayyadi/trello/oidc/oidctest"
+	"github.com/zayyadi/trello/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// MockUserIdentityRepository is an in-memory implementation of UserIdentityRepositoryInterface
+type MockUserIdentityRepository struct {
+	Identities []*models.UserIdentity
+}
+
+func (m *MockUserIdentityRepository) Create(identity *models.UserIdentity) error {
+	identity.ID = uint(len(m.Identities) + 1)
+	m.Identities = append(m.Identities, identity)
+	return nil
+}
+
+func (m *MockUserIdentityRepository) FindByIssuerAndSubject(issuer, subject string) (*models.UserIdentity, error) {
+	for _, i := range m.Identities {
+		if i.Issuer == issuer && i.Subject == subject {
+			return i, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *MockUserIdentityRepository) Update(identity *models.UserIdentity) error {
+	return nil // Identities are stored by pointer, so updates are already visible
+}
+
+var _ repositories.UserIdentityRepositoryInterface = (*MockUserIdentityRepository)(nil)
+
+// inMemoryUsers wires mockRepo up to a simple slice of users.
+func inMemoryUsers(mockRepo *MockUserRepository, users ...*models.User) *[]*models.User {
+	store := &users
+	mockRepo.CreateFunc = func(user *models.User) error {
+		user.ID = uint(len(*store) + 100)
+		*store = append(*store, user)
+		return nil
+	}
+	find := func(match func(u *models.User) bool) (*models.User, error) {
+		for _, u := range *store {
+			if match(u) {
+				return u, nil
+			}
+		}
+		return nil, gorm.ErrRecordNotFound
+	}
+	mockRepo.FindByEmailFunc = func(email string) (*models.User, error) {
+		return find(func(u *models.User) bool { return u.Email == email })
+	}
+	mockRepo.FindByUsernameFunc = func(username string) (*models.User, error) {
+		return find(func(u *models.User) bool { return u.Username == username })
+	}
+	mockRepo.FindByIDFunc = func(id uint) (*models.User, error) {
+		return find(func(u *models.User) bool { return u.ID == id })
+	}
+	return store
+}
+
+type oidcTestEnv struct {
+	idp          *oidctest.Provider
+	service      *OIDCService
+	userRepo     *MockUserRepository
+	identityRepo *MockUserIdentityRepository
+	mailer       *MockMailer
+	users        *[]*models.User
+}
+
+func newOIDCTestEnv(t *testing.T, opts AuthOptions, existing ...*models.User) *oidcTestEnv {
+	idp := oidctest.NewProvider("trello-client", "trello-secret")
+	t.Cleanup(idp.Close)
+
+	userRepo := &MockUserRepository{}
+	users := inMemoryUsers(userRepo, existing...)
+	identityRepo := &MockUserIdentityRepository{}
+	mockMailer := &MockMailer{}
+	opts.JWTSecretKey = "test-secret"
+	authService := NewAuthService(userRepo, &MockRefreshTokenRepository{}, &MockUserTokenRepository{}, mockMailer, opts)
+
+	provider := oidc.NewProvider(oidc.Config{
+		IssuerURL:    idp.Issuer(),
+		ClientID:     "trello-client",
+		ClientSecret: "trello-secret",
+		RedirectURL:  "http://api.test/auth/oidc/callback",
+	}, nil)
+	return &oidcTestEnv{
+		idp:          idp,
+		service:      NewOIDCService(provider, authService, userRepo, identityRepo),
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+		mailer:       mockMailer,
+		users:        users,
+	}
+}
+
+// login runs the whole flow for user against the stand-in provider.
+func (e *oidcTestEnv) login(t *testing.T, user oidctest.User) (*models.User, *AuthTokens, error) {
+	authURL, flow, err := e.service.BeginLogin(context.Background())
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	u, _ := url.Parse(authURL)
+	q := u.Query()
+	code := e.idp.IssueCode(user, q.Get("nonce"), q.Get("redirect_uri"), q.Get("code_challenge"))
+	return e.service.CompleteLogin(context.Background(), flow, q.Get("state"), code)
+}
+
+func TestOIDCService_FirstLogin_CreatesUser(t *testing.T) {
+	env := newOIDCTestEnv(t, AuthOptions{})
+
+	user, tokens, err := env.login(t, oidctest.User{
+		Subject: "sub-1", Email: "jane@example.com", EmailVerified: true, PreferredUsername: "jane doe!",
+	})
+	assert.NoError(t, err)
+	if assert.NotNil(t, user) {
+		assert.Equal(t, "jane@example.com", user.Email)
+		assert.Equal(t, "janedoe", user.Username)
+		assert.True(t, user.EmailVerified)
+		assert.NotEmpty(t, user.Password, "JIT users get an unusable random password")
+	}
+	if assert.NotNil(t, tokens) {
+		assert.NotEmpty(t, tokens.AccessToken)
+		assert.NotEmpty(t, tokens.RefreshToken)
+	}
+	assert.Len(t, *env.users, 1)
+	if assert.Len(t, env.identityRepo.Identities, 1) {
+		assert.Equal(t, env.idp.Issuer(), env.identityRepo.Identities[0].Issuer)
+		assert.Equal(t, "sub-1", env.identityRepo.Identities[0].Subject)
+		assert.Equal(t, user.ID, env.identityRepo.Identities[0].UserID)
+	}
+	assert.Empty(t, env.mailer.Sent, "verified emails need no verification mail")
+
+	// Logging in again reuses the linked account
+	again, _, err := env.login(t, oidctest.User{Subject: "sub-1", Email: "jane@example.com", EmailVerified: true})
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, again.ID)
+	assert.Len(t, *env.users, 1)
+	assert.Len(t, env.identityRepo.Identities, 1)
+}
+
+func TestOIDCService_FirstLogin_UsernameTaken(t *testing.T) {
+	env := newOIDCTestEnv(t, AuthOptions{}, &models.User{Model: gorm.Model{ID: 1}, Username: "jane", Email: "other@example.com"})
+
+	user, _, err := env.login(t, oidctest.User{Subject: "sub-1", Email: "jane@example.com", EmailVerified: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "jane2", user.Username)
+}
+
+func TestOIDCService_FirstLogin_UnverifiedEmail(t *testing.T) {
+	env := newOIDCTestEnv(t, AuthOptions{RequireVerifiedEmail: true})
+
+	user, tokens, err := env.login(t, oidctest.User{Subject: "sub-1", Email: "jane@example.com", EmailVerified: false})
+	assert.ErrorIs(t, err, ErrEmailNotVerified)
+	assert.Nil(t, user)
+	assert.Nil(t, tokens)
+	// The account is still created and asked to verify its address
+	assert.Len(t, *env.users, 1)
+	assert.False(t, (*env.users)[0].EmailVerified)
+	if assert.Len(t, env.mailer.Sent, 1) {
+		assert.Equal(t, []string{"jane@example.com"}, env.mailer.Sent[0].To)
+	}
+}
+
+func TestOIDCService_LinksExistingUserByVerifiedEmail(t *testing.T) {
+	existing := &models.User{Model: gorm.Model{ID: 1}, Username: "jane", Email: "jane@example.com"}
+	env := newOIDCTestEnv(t, AuthOptions{}, existing)
+
+	user, tokens, err := env.login(t, oidctest.User{Subject: "sub-1", Email: "jane@example.com", EmailVerified: true})
+	assert.NoError(t, err)
+	assert.NotNil(t, tokens)
+	assert.Equal(t, existing.ID, user.ID)
+	assert.True(t, user.EmailVerified, "the provider vouched for the address")
+	assert.Len(t, *env.users, 1)
+	if assert.Len(t, env.identityRepo.Identities, 1) {
+		assert.Equal(t, existing.ID, env.identityRepo.Identities[0].UserID)
+	}
+}
+
+func TestOIDCService_RefusesLinkOnUnverifiedEmail(t *testing.T) {
+	existing := &models.User{Model: gorm.Model{ID: 1}, Username: "jane", Email: "jane@example.com"}
+	env := newOIDCTestEnv(t, AuthOptions{}, existing)
+
+	user, tokens, err := env.login(t, oidctest.User{Subject: "attacker", Email: "jane@example.com", EmailVerified: false})
+	assert.ErrorIs(t, err, ErrEmailExists)
+	assert.Nil(t, user)
+	assert.Nil(t, tokens)
+	assert.Empty(t, env.identityRepo.Identities)
+}
+
+func TestOIDCService_NoEmail(t *testing.T) {
+	env := newOIDCTestEnv(t, AuthOptions{})
+
+	_, _, err := env.login(t, oidctest.User{Subject: "sub-1"})
+	assert.ErrorIs(t, err, ErrOIDCLoginFailed)
+	assert.Empty(t, *env.users)
+}
+
+func TestOIDCService_CompleteLogin_StateMismatch(t *testing.T) {
+	env := newOIDCTestEnv(t, AuthOptions{})
+	_, flow, err := env.service.BeginLogin(context.Background())
+	assert.NoError(t, err)
+
+	_, _, err = env.service.CompleteLogin(context.Background(), flow, "forged-state", "code")
+	assert.ErrorIs(t, err, ErrOIDCStateMismatch)
+
+	_, _, err = env.service.CompleteLogin(context.Background(), nil, flow.State, "code")
+	assert.ErrorIs(t, err, ErrOIDCStateMismatch, "missing flow cookie")
+}
+
+func TestOIDCService_CompleteLogin_BadCode(t *testing.T) {
+	env := newOIDCTestEnv(t, AuthOptions{})
+	_, flow, err := env.service.BeginLogin(context.Background())
+	assert.NoError(t, err)
+
+	_, _, err = env.service.CompleteLogin(context.Background(), flow, flow.State, "not-a-code")
+	assert.ErrorIs(t, err, ErrOIDCLoginFailed)
+}
+
+func TestOIDCService_AsksForTwoFactorCode(t *testing.T) {
+	existing := &models.User{Model: gorm.Model{ID: 1}, Username: "jane", Email: "jane@example.com", EmailVerified: true, TwoFactorEnabled: true}
+	env := newOIDCTestEnv(t, AuthOptions{}, existing)
+
+	user, tokens, err := env.login(t, oidctest.User{Subject: "sub-1", Email: "jane@example.com", EmailVerified: true})
+	var challenge *TwoFactorRequiredError
+	if assert.ErrorAs(t, err, &challenge) {
+		assert.NotEmpty(t, challenge.ChallengeToken)
+	}
+	assert.Nil(t, user)
+	assert.Nil(t, tokens, "no session before the second factor")
+}
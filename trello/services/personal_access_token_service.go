@@ -0,0 +1,120 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/policy"
+	"github.com/zayyadi/trello/repositories"
+	"github.com/zayyadi/trello/utils"
+
+	"gorm.io/gorm"
+)
+
+// lastUsedResolution limits how often a token's last-used time is written,
+// so busy scripts don't cause a database write on every request.
+const lastUsedResolution = time.Minute
+
+// PersonalAccessTokenService manages long-lived tokens users create for scripts and integrations.
+type PersonalAccessTokenService struct {
+	tokenRepo       repositories.PersonalAccessTokenRepositoryInterface
+	boardRepo       repositories.BoardRepositoryInterface
+	boardMemberRepo repositories.BoardMemberRepositoryInterface
+}
+
+func NewPersonalAccessTokenService(
+	tokenRepo repositories.PersonalAccessTokenRepositoryInterface,
+	boardRepo repositories.BoardRepositoryInterface,
+	boardMemberRepo repositories.BoardMemberRepositoryInterface,
+) *PersonalAccessTokenService {
+	return &PersonalAccessTokenService{
+		tokenRepo:       tokenRepo,
+		boardRepo:       boardRepo,
+		boardMemberRepo: boardMemberRepo,
+	}
+}
+
+// CreateToken creates a token for the user and returns it along with its raw value,
+// which is not stored and cannot be shown again.
+// A boardID restricts the token to that board, which the user must have access to.
+func (s *PersonalAccessTokenService) CreateToken(userID uint, name string, readOnly bool, boardID *uint, expiresAt *time.Time) (*models.PersonalAccessToken, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", ErrInvalidInput
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, "", ErrInvalidInput
+	}
+	if boardID != nil {
+		if _, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, *boardID, userID, policy.ViewBoard); err != nil {
+			return nil, "", err
+		}
+	}
+
+	secret, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+	rawToken := models.PersonalAccessTokenPrefix + secret
+
+	token := &models.PersonalAccessToken{
+		UserID:    userID,
+		Name:      name,
+		TokenHash: utils.HashToken(rawToken),
+		Prefix:    rawToken[:len(models.PersonalAccessTokenPrefix)+6],
+		ReadOnly:  readOnly,
+		BoardID:   boardID,
+		ExpiresAt: expiresAt,
+	}
+	if err := s.tokenRepo.Create(token); err != nil {
+		return nil, "", err
+	}
+	return token, rawToken, nil
+}
+
+// ListTokens returns the user's unrevoked tokens.
+func (s *PersonalAccessTokenService) ListTokens(userID uint) ([]models.PersonalAccessToken, error) {
+	return s.tokenRepo.FindByUserID(userID)
+}
+
+// RevokeToken revokes one of the user's tokens.
+func (s *PersonalAccessTokenService) RevokeToken(tokenID, userID uint) error {
+	if err := s.tokenRepo.Revoke(tokenID, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAccessTokenNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// ValidatePersonalAccessToken looks up an active token by its raw value and records that it was used.
+func (s *PersonalAccessTokenService) ValidatePersonalAccessToken(rawToken string) (*models.PersonalAccessToken, error) {
+	if !strings.HasPrefix(rawToken, models.PersonalAccessTokenPrefix) {
+		return nil, ErrInvalidAccessToken
+	}
+	token, err := s.tokenRepo.FindByHash(utils.HashToken(rawToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAccessToken
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if !token.IsActive(now) {
+		return nil, ErrInvalidAccessToken
+	}
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= lastUsedResolution {
+		if err := s.tokenRepo.UpdateLastUsed(token.ID, now); err != nil {
+			// Not worth failing the request over
+			log.Printf("Failed to record use of personal access token %d: %v", token.ID, err)
+		} else {
+			token.LastUsedAt = &now
+		}
+	}
+	return token, nil
+}
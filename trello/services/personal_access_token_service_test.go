@@ -0,0 +1,172 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// MockPersonalAccessTokenRepository is an in-memory implementation of PersonalAccessTokenRepositoryInterface
+type MockPersonalAccessTokenRepository struct {
+	Tokens          []*models.PersonalAccessToken
+	LastUsedUpdates int
+}
+
+func (m *MockPersonalAccessTokenRepository) Create(token *models.PersonalAccessToken) error {
+	token.ID = uint(len(m.Tokens) + 1)
+	token.CreatedAt = time.Now()
+	m.Tokens = append(m.Tokens, token)
+	return nil
+}
+
+func (m *MockPersonalAccessTokenRepository) FindByHash(tokenHash string) (*models.PersonalAccessToken, error) {
+	for _, t := range m.Tokens {
+		if t.TokenHash == tokenHash {
+			copied := *t
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *MockPersonalAccessTokenRepository) FindByUserID(userID uint) ([]models.PersonalAccessToken, error) {
+	var tokens []models.PersonalAccessToken
+	for _, t := range m.Tokens {
+		if t.UserID == userID && t.RevokedAt == nil {
+			tokens = append(tokens, *t)
+		}
+	}
+	return tokens, nil
+}
+
+func (m *MockPersonalAccessTokenRepository) Revoke(id, userID uint) error {
+	for _, t := range m.Tokens {
+		if t.ID == id && t.UserID == userID && t.RevokedAt == nil {
+			now := time.Now()
+			t.RevokedAt = &now
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (m *MockPersonalAccessTokenRepository) RevokeAllForUser(userID uint) error {
+	now := time.Now()
+	for _, t := range m.Tokens {
+		if t.UserID == userID && t.RevokedAt == nil {
+			t.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (m *MockPersonalAccessTokenRepository) UpdateLastUsed(id uint, usedAt time.Time) error {
+	m.LastUsedUpdates++
+	for _, t := range m.Tokens {
+		if t.ID == id {
+			t.LastUsedAt = &usedAt
+		}
+	}
+	return nil
+}
+
+var _ repositories.PersonalAccessTokenRepositoryInterface = (*MockPersonalAccessTokenRepository)(nil)
+
+func newTokenServiceForTest() (*PersonalAccessTokenService, *MockPersonalAccessTokenRepository, *MockBoardRepository, *MockBoardMemberRepository) {
+	tokenRepo := &MockPersonalAccessTokenRepository{}
+	boardRepo := &MockBoardRepository{}
+	boardMemberRepo := &MockBoardMemberRepository{}
+	return NewPersonalAccessTokenService(tokenRepo, boardRepo, boardMemberRepo), tokenRepo, boardRepo, boardMemberRepo
+}
+
+func TestPersonalAccessTokenService_CreateAndValidate(t *testing.T) {
+	service, tokenRepo, _, _ := newTokenServiceForTest()
+
+	token, raw, err := service.CreateToken(7, "  deploy script ", true, nil, nil)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(raw, models.PersonalAccessTokenPrefix))
+	assert.Equal(t, "deploy script", token.Name)
+	assert.True(t, token.ReadOnly)
+	assert.True(t, strings.HasPrefix(raw, token.Prefix))
+	assert.NotContains(t, tokenRepo.Tokens[0].TokenHash, raw, "only a hash is stored")
+
+	validated, err := service.ValidatePersonalAccessToken(raw)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(7), validated.UserID)
+	assert.NotNil(t, validated.LastUsedAt)
+	assert.Equal(t, 1, tokenRepo.LastUsedUpdates)
+
+	// Used again straight away: last-used time is not rewritten
+	_, err = service.ValidatePersonalAccessToken(raw)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, tokenRepo.LastUsedUpdates)
+
+	_, err = service.ValidatePersonalAccessToken(raw + "x")
+	assert.ErrorIs(t, err, ErrInvalidAccessToken)
+	_, err = service.ValidatePersonalAccessToken("not-a-pat")
+	assert.ErrorIs(t, err, ErrInvalidAccessToken)
+}
+
+func TestPersonalAccessTokenService_CreateToken_InvalidInput(t *testing.T) {
+	service, _, _, _ := newTokenServiceForTest()
+
+	_, _, err := service.CreateToken(7, "   ", false, nil, nil)
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	past := time.Now().Add(-time.Hour)
+	_, _, err = service.CreateToken(7, "expired", false, nil, &past)
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
+
+func TestPersonalAccessTokenService_CreateToken_BoardScope(t *testing.T) {
+	service, _, boardRepo, boardMemberRepo := newTokenServiceForTest()
+	boardID := uint(5)
+	boardRepo.FindByIDFunc = func(id uint) (*models.Board, error) {
+		return &models.Board{Model: gorm.Model{ID: id}, OwnerID: 1}, nil
+	}
+	boardMemberRepo.GetRoleFunc = func(bID, userID uint) (models.BoardRole, error) {
+		if userID == 2 {
+			return models.BoardRoleViewer, nil
+		}
+		return "", gorm.ErrRecordNotFound
+	}
+
+	token, _, err := service.CreateToken(2, "board bot", false, &boardID, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, &boardID, token.BoardID)
+
+	_, _, err = service.CreateToken(3, "sneaky", false, &boardID, nil)
+	assert.ErrorIs(t, err, ErrForbidden, "cannot scope a token to a board you can't see")
+}
+
+func TestPersonalAccessTokenService_ExpiredAndRevoked(t *testing.T) {
+	service, tokenRepo, _, _ := newTokenServiceForTest()
+
+	soon := time.Now().Add(time.Hour)
+	_, raw, err := service.CreateToken(7, "short lived", false, nil, &soon)
+	assert.NoError(t, err)
+	past := time.Now().Add(-time.Minute)
+	tokenRepo.Tokens[0].ExpiresAt = &past
+	_, err = service.ValidatePersonalAccessToken(raw)
+	assert.ErrorIs(t, err, ErrInvalidAccessToken)
+
+	token, raw, err := service.CreateToken(7, "revoked", false, nil, nil)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, service.RevokeToken(token.ID, 8), ErrAccessTokenNotFound, "only the owner can revoke")
+	assert.NoError(t, service.RevokeToken(token.ID, 7))
+	_, err = service.ValidatePersonalAccessToken(raw)
+	assert.ErrorIs(t, err, ErrInvalidAccessToken)
+	assert.ErrorIs(t, service.RevokeToken(token.ID, 7), ErrAccessTokenNotFound)
+
+	tokens, err := service.ListTokens(7)
+	assert.NoError(t, err)
+	if assert.Len(t, tokens, 1) {
+		assert.Equal(t, "short lived", tokens[0].Name)
+	}
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/policy"
+	"github.com/zayyadi/trello/realtime"
+	"github.com/zayyadi/trello/repositories"
+	"github.com/zayyadi/trello/utils"
+
+	"gorm.io/gorm"
+)
+
+// PublicBoard is what a viewer who isn't logged in gets to see of a board.
+type PublicBoard struct {
+	Board       *models.Board
+	Lists       []models.List // The active lists with their active cards
+	ShareLinkID uint          // The share link it was opened with; zero for a public board
+}
+
+// PublicBoardService shows boards to people who aren't logged in: public boards,
+// and any board through an unlisted share link. They can only look. Boards they
+// can't see are reported as not found, so board IDs can't be probed.
+type PublicBoardService struct {
+	shareLinkRepo   repositories.BoardShareLinkRepositoryInterface
+	boardRepo       repositories.BoardRepositoryInterface
+	boardMemberRepo repositories.BoardMemberRepositoryInterface
+	listRepo        repositories.ListRepositoryInterface
+	hub             realtime.Broadcaster
+	appBaseURL      string           // Frontend URL used to build share links
+	now             func() time.Time // Replaced in tests
+}
+
+func NewPublicBoardService(
+	shareLinkRepo repositories.BoardShareLinkRepositoryInterface,
+	boardRepo repositories.BoardRepositoryInterface,
+	boardMemberRepo repositories.BoardMemberRepositoryInterface,
+	listRepo repositories.ListRepositoryInterface,
+	hub realtime.Broadcaster,
+	appBaseURL string,
+) *PublicBoardService {
+	return &PublicBoardService{
+		shareLinkRepo:   shareLinkRepo,
+		boardRepo:       boardRepo,
+		boardMemberRepo: boardMemberRepo,
+		listRepo:        listRepo,
+		hub:             hub,
+		appBaseURL:      appBaseURL,
+		now:             time.Now,
+	}
+}
+
+// GetPublicBoard returns a public board with its lists and cards.
+func (s *PublicBoardService) GetPublicBoard(boardID uint) (*PublicBoard, error) {
+	board, err := s.findPublicBoard(boardID)
+	if err != nil {
+		return nil, err
+	}
+	return s.withLists(board, 0)
+}
+
+// GetSharedBoard returns the board a share link token is for, with its lists and
+// cards, whatever the board's visibility.
+func (s *PublicBoardService) GetSharedBoard(token string) (*PublicBoard, error) {
+	board, link, err := s.findSharedBoard(token)
+	if err != nil {
+		return nil, err
+	}
+	return s.withLists(board, link.ID)
+}
+
+// AuthorizeViewer checks that someone who isn't logged in may follow a board's
+// changes: with a share link token if one is given, or else because boardID is
+// public. It returns the board and the share link used, zero for none.
+func (s *PublicBoardService) AuthorizeViewer(boardID uint, token string) (uint, uint, error) {
+	if token != "" {
+		board, link, err := s.findSharedBoard(token)
+		if err != nil {
+			return 0, 0, err
+		}
+		if boardID != 0 && boardID != board.ID {
+			return 0, 0, ErrBoardNotFound
+		}
+		return board.ID, link.ID, nil
+	}
+	board, err := s.findPublicBoard(boardID)
+	if err != nil {
+		return 0, 0, err
+	}
+	return board.ID, 0, nil
+}
+
+// CreateShareLink creates an unlisted link for viewing the board without logging in,
+// for board admins. expiresIn is how long it works, zero for no expiry. The raw
+// token is only returned here.
+func (s *PublicBoardService) CreateShareLink(boardID uint, expiresIn time.Duration, userID uint) (*models.BoardShareLink, string, error) {
+	if _, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, boardID, userID, policy.ShareBoard); err != nil {
+		return nil, "", err
+	}
+	if expiresIn < 0 {
+		return nil, "", fmt.Errorf("%w: expiry must not be negative", ErrInvalidInput)
+	}
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+	link := &models.BoardShareLink{
+		BoardID:     boardID,
+		CreatedByID: userID,
+		TokenHash:   utils.HashToken(token),
+	}
+	if expiresIn > 0 {
+		expiresAt := s.now().Add(expiresIn)
+		link.ExpiresAt = &expiresAt
+	}
+	if err := s.shareLinkRepo.Create(link); err != nil {
+		return nil, "", err
+	}
+	return link, token, nil
+}
+
+// ShareLinkURL is the frontend address to share for a share link token.
+func (s *PublicBoardService) ShareLinkURL(token string) string {
+	return fmt.Sprintf("%s/shared-board?token=%s", strings.TrimRight(s.appBaseURL, "/"), token)
+}
+
+// GetShareLinks lists the board's unrevoked share links for its admins.
+func (s *PublicBoardService) GetShareLinks(boardID, userID uint) ([]models.BoardShareLink, error) {
+	if _, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, boardID, userID, policy.ShareBoard); err != nil {
+		return nil, err
+	}
+	return s.shareLinkRepo.FindByBoard(boardID)
+}
+
+// RevokeShareLink stops the link from working and disconnects anyone following the board through it.
+func (s *PublicBoardService) RevokeShareLink(boardID, linkID, userID uint) error {
+	if _, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, boardID, userID, policy.ShareBoard); err != nil {
+		return err
+	}
+	if err := s.shareLinkRepo.Revoke(linkID, boardID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrShareLinkNotFound
+		}
+		return err
+	}
+	disconnectAnonymousViewers(s.hub, boardID, linkID)
+	return nil
+}
+
+func (s *PublicBoardService) findPublicBoard(boardID uint) (*models.Board, error) {
+	board, err := s.boardRepo.FindByID(boardID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBoardNotFound
+		}
+		return nil, err
+	}
+	if board.Visibility != models.BoardVisibilityPublic || board.ArchivedAt != nil {
+		return nil, ErrBoardNotFound // Archived boards are hidden until they are unarchived
+	}
+	return board, nil
+}
+
+// findSharedBoard finds the board an active share link is for.
+func (s *PublicBoardService) findSharedBoard(token string) (*models.Board, *models.BoardShareLink, error) {
+	link, err := s.shareLinkRepo.FindByHash(utils.HashToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrBoardNotFound
+		}
+		return nil, nil, err
+	}
+	if !link.IsActive(s.now()) {
+		return nil, nil, ErrBoardNotFound
+	}
+	board, err := s.boardRepo.FindByID(link.BoardID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrBoardNotFound // Deleted since
+		}
+		return nil, nil, err
+	}
+	if board.ArchivedAt != nil {
+		return nil, nil, ErrBoardNotFound
+	}
+	return board, link, nil
+}
+
+func (s *PublicBoardService) withLists(board *models.Board, shareLinkID uint) (*PublicBoard, error) {
+	lists, err := s.listRepo.FindByBoardID(board.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &PublicBoard{Board: board, Lists: lists, ShareLinkID: shareLinkID}, nil
+}
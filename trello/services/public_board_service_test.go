@@ -0,0 +1,156 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/realtime"
+	"github.com/zayyadi/trello/repositories"
+)
+
+// disconnectRecordingHub is a MockHub that also records which anonymous viewers
+// the services asked it to disconnect.
+type disconnectRecordingHub struct {
+	MockHub
+	disconnected [][2]uint // Board ID and share link ID
+}
+
+func (h *disconnectRecordingHub) DisconnectAnonymous(boardID, shareLinkID uint) {
+	h.disconnected = append(h.disconnected, [2]uint{boardID, shareLinkID})
+}
+
+// publicBoardTestEnv runs the public board service on SQLite. Alice owns a
+// private board with one list, Bob is a member.
+type publicBoardTestEnv struct {
+	public   *PublicBoardService
+	boards   BoardServiceInterface
+	archives *ArchiveService
+	hub      *disconnectRecordingHub
+	board    *models.Board
+	alice    uint
+	bob      uint
+}
+
+func newPublicBoardTestEnv(t *testing.T) *publicBoardTestEnv {
+	db := setupTestDB(t)
+	userRepo := repositories.NewUserRepository(db)
+	boardRepo := repositories.NewBoardRepository(db)
+	boardMemberRepo := repositories.NewBoardMemberRepository(db)
+	listRepo := repositories.NewListRepository(db)
+	hub := &disconnectRecordingHub{}
+
+	env := &publicBoardTestEnv{
+		public:   NewPublicBoardService(repositories.NewBoardShareLinkRepository(db), boardRepo, boardMemberRepo, listRepo, hub, "https://app.example.com/"),
+		boards:   NewBoardService(boardRepo, userRepo, boardMemberRepo, hub, false),
+		archives: NewArchiveService(repositories.NewArchiveRepository(db), boardRepo, boardMemberRepo, listRepo, repositories.NewCardRepository(db), hub, 0),
+		hub:      hub,
+	}
+	env.alice = createTestUser(t, userRepo, "alice")
+	env.bob = createTestUser(t, userRepo, "bob")
+	board := createTestBoard(t, env.boards, "Roadmap", "What's next", env.alice, map[uint]models.BoardRole{env.bob: models.BoardRoleMember})
+	if _, err := NewListService(listRepo, boardRepo, boardMemberRepo, hub).CreateList("Q3", board.ID, env.alice, nil); err != nil {
+		t.Fatal(err)
+	}
+	env.board = board
+	return env
+}
+
+func TestPublicBoardService_PublicBoards(t *testing.T) {
+	env := newPublicBoardTestEnv(t)
+
+	_, err := env.public.GetPublicBoard(env.board.ID)
+	assert.ErrorIs(t, err, ErrBoardNotFound, "private boards aren't shown")
+	_, err = env.boards.UpdateVisibility(env.board.ID, models.BoardVisibilityPublic, env.alice)
+	assert.NoError(t, err)
+
+	board, err := env.public.GetPublicBoard(env.board.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Roadmap", board.Board.Name)
+	assert.Len(t, board.Lists, 1)
+	assert.Zero(t, board.ShareLinkID)
+	boardID, linkID, err := env.public.AuthorizeViewer(env.board.ID, "")
+	assert.NoError(t, err)
+	assert.Equal(t, env.board.ID, boardID)
+	assert.Zero(t, linkID)
+
+	_, err = env.boards.UpdateVisibility(env.board.ID, models.BoardVisibilityPrivate, env.alice)
+	assert.NoError(t, err)
+	assert.Equal(t, [][2]uint{{env.board.ID, 0}}, env.hub.disconnected, "anonymous viewers lose the board")
+	_, _, err = env.public.AuthorizeViewer(env.board.ID, "")
+	assert.ErrorIs(t, err, ErrBoardNotFound)
+}
+
+func TestPublicBoardService_ShareLinks(t *testing.T) {
+	env := newPublicBoardTestEnv(t)
+
+	_, _, err := env.public.CreateShareLink(env.board.ID, 0, env.bob)
+	assert.ErrorIs(t, err, ErrForbidden, "only admins share boards")
+	link, token, err := env.public.CreateShareLink(env.board.ID, 0, env.alice)
+	assert.NoError(t, err)
+	assert.NotEqual(t, token, link.TokenHash)
+	assert.Equal(t, "https://app.example.com/shared-board?token="+token, env.public.ShareLinkURL(token))
+
+	board, err := env.public.GetSharedBoard(token)
+	assert.NoError(t, err, "share links work for private boards")
+	assert.Equal(t, env.board.ID, board.Board.ID)
+	assert.Equal(t, link.ID, board.ShareLinkID)
+	_, _, err = env.public.AuthorizeViewer(env.board.ID+1, token)
+	assert.ErrorIs(t, err, ErrBoardNotFound, "the token is for another board")
+	_, err = env.public.GetSharedBoard("not-a-token")
+	assert.ErrorIs(t, err, ErrBoardNotFound)
+
+	links, err := env.public.GetShareLinks(env.board.ID, env.alice)
+	assert.NoError(t, err)
+	assert.Len(t, links, 1)
+	assert.ErrorIs(t, env.public.RevokeShareLink(env.board.ID, link.ID, env.bob), ErrForbidden)
+	assert.NoError(t, env.public.RevokeShareLink(env.board.ID, link.ID, env.alice))
+	assert.Equal(t, [][2]uint{{env.board.ID, link.ID}}, env.hub.disconnected)
+	_, err = env.public.GetSharedBoard(token)
+	assert.ErrorIs(t, err, ErrBoardNotFound, "revoked")
+	assert.ErrorIs(t, env.public.RevokeShareLink(env.board.ID, link.ID, env.alice), ErrShareLinkNotFound)
+}
+
+func TestPublicBoardService_ExpiredShareLink(t *testing.T) {
+	env := newPublicBoardTestEnv(t)
+	_, token, err := env.public.CreateShareLink(env.board.ID, time.Hour, env.alice)
+	assert.NoError(t, err)
+
+	env.public.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	_, err = env.public.GetSharedBoard(token)
+	assert.ErrorIs(t, err, ErrBoardNotFound)
+}
+
+func TestPublicBoardService_DeletedBoardDropsAllViewers(t *testing.T) {
+	env := newPublicBoardTestEnv(t)
+	_, _, err := env.public.CreateShareLink(env.board.ID, 0, env.alice)
+	assert.NoError(t, err)
+
+	assert.NoError(t, env.boards.DeleteBoard(env.board.ID, env.alice))
+	assert.Equal(t, [][2]uint{{env.board.ID, realtime.AnyShareLink}}, env.hub.disconnected, "share link viewers too")
+}
+
+func TestPublicBoardService_ArchivedBoardIsHidden(t *testing.T) {
+	env := newPublicBoardTestEnv(t)
+	_, err := env.boards.UpdateVisibility(env.board.ID, models.BoardVisibilityPublic, env.alice)
+	assert.NoError(t, err)
+	_, token, err := env.public.CreateShareLink(env.board.ID, 0, env.alice)
+	assert.NoError(t, err)
+	env.hub.disconnected = nil
+
+	_, err = env.archives.ArchiveBoard(env.board.ID, env.alice)
+	assert.NoError(t, err)
+	assert.Equal(t, [][2]uint{{env.board.ID, realtime.AnyShareLink}}, env.hub.disconnected)
+	_, err = env.public.GetPublicBoard(env.board.ID)
+	assert.ErrorIs(t, err, ErrBoardNotFound)
+	_, err = env.public.GetSharedBoard(token)
+	assert.ErrorIs(t, err, ErrBoardNotFound, "share links too")
+	_, _, err = env.public.AuthorizeViewer(env.board.ID, "")
+	assert.ErrorIs(t, err, ErrBoardNotFound)
+
+	_, err = env.archives.UnarchiveBoard(env.board.ID, env.alice)
+	assert.NoError(t, err)
+	_, err = env.public.GetPublicBoard(env.board.ID)
+	assert.NoError(t, err, "shown again once unarchived")
+}
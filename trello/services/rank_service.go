@@ -0,0 +1,116 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/zayyadi/trello/realtime"
+	"github.com/zayyadi/trello/repositories"
+
+	"gorm.io/gorm"
+)
+
+// DefaultRankMaxLength is the longest rank left alone when RankService is given none.
+const DefaultRankMaxLength = 12
+
+// rankRebalanceBatch is how many boards or lists Rebalance looks up per query.
+const rankRebalanceBatch = 100
+
+// RankService keeps the ranks that order lists and cards short. Moving items
+// into the same gap again and again makes ranks longer, and two items moved at
+// the same moment may end up sharing one; rebalancing gives every list of a
+// board, or card of a list, a fresh evenly spread rank in the same order.
+type RankService struct {
+	rankRepo  repositories.RankRepositoryInterface
+	listRepo  repositories.ListRepositoryInterface
+	hub       realtime.Broadcaster
+	maxLength int // Ranks longer than this are rebalanced
+}
+
+func NewRankService(
+	rankRepo repositories.RankRepositoryInterface,
+	listRepo repositories.ListRepositoryInterface,
+	hub realtime.Broadcaster,
+	maxLength int,
+) *RankService {
+	if maxLength <= 0 {
+		maxLength = DefaultRankMaxLength
+	}
+	return &RankService{rankRepo: rankRepo, listRepo: listRepo, hub: hub, maxLength: maxLength}
+}
+
+// Rebalance respreads the ranks of every board's lists and list's cards that
+// have a rank longer than the maximum or share one, and tells the boards'
+// clients the new positions. It returns how many boards and lists it changed.
+func (s *RankService) Rebalance() (int, error) {
+	rebalanced := 0
+	for afterID := uint(0); ; {
+		boardIDs, err := s.rankRepo.FindBoardsToRebalance(s.maxLength, afterID, rankRebalanceBatch)
+		if err != nil {
+			return rebalanced, err
+		}
+		for _, boardID := range boardIDs {
+			listIDs, ranks, err := s.rankRepo.RebalanceLists(boardID)
+			if err != nil {
+				return rebalanced, err
+			}
+			broadcastMessage(s.hub, boardID, realtime.MessageTypeListsReordered,
+				realtime.ListsReorderedPayload{BoardID: boardID, ListIDs: listIDs, Positions: ranks})
+			rebalanced++
+			afterID = boardID
+		}
+		if len(boardIDs) < rankRebalanceBatch {
+			break
+		}
+	}
+
+	for afterID := uint(0); ; {
+		listIDs, err := s.rankRepo.FindListsToRebalance(s.maxLength, afterID, rankRebalanceBatch)
+		if err != nil {
+			return rebalanced, err
+		}
+		for _, listID := range listIDs {
+			cardIDs, ranks, err := s.rankRepo.RebalanceCards(listID)
+			if err != nil {
+				return rebalanced, err
+			}
+			rebalanced++
+			afterID = listID
+			boardID, err := s.listRepo.GetBoardIDByListID(listID)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue // The list is in the trash, so no one sees its cards
+			} else if err != nil {
+				return rebalanced, err
+			}
+			broadcastMessage(s.hub, boardID, realtime.MessageTypeCardsReordered,
+				realtime.CardsReorderedPayload{ListID: listID, BoardID: boardID, CardIDs: cardIDs, Positions: ranks})
+		}
+		if len(listIDs) < rankRebalanceBatch {
+			return rebalanced, nil
+		}
+	}
+}
+
+// RunRebalanceJob calls Rebalance right away and then every interval until stop
+// is closed. Run it in its own goroutine.
+func (s *RankService) RunRebalanceJob(interval time.Duration, stop <-chan struct{}) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		rebalanced, err := s.Rebalance()
+		if err != nil {
+			log.Printf("ERROR [RankService.RunRebalanceJob]: Failed to rebalance ranks: %v", err)
+		} else if rebalanced > 0 {
+			log.Printf("Rebalanced the ranks of %d boards and lists", rebalanced)
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
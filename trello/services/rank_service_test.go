@@ -0,0 +1,88 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/realtime"
+	"github.com/zayyadi/trello/repositories"
+)
+
+func TestRankService_Rebalance(t *testing.T) {
+	db := setupTestDB(t)
+	userRepo := repositories.NewUserRepository(db)
+	boardRepo := repositories.NewBoardRepository(db)
+	boardMemberRepo := repositories.NewBoardMemberRepository(db)
+	listRepo := repositories.NewListRepository(db)
+	cardRepo := repositories.NewCardRepository(db)
+
+	var messages []*realtime.WebSocketMessage
+	hub := &MockHub{SubmitFunc: func(msg *realtime.WebSocketMessage) { messages = append(messages, msg) }}
+	ranks := NewRankService(repositories.NewRankRepository(db), listRepo, hub, 4)
+	boards := NewBoardService(boardRepo, userRepo, boardMemberRepo, &MockHub{}, false)
+	lists := NewListService(listRepo, boardRepo, boardMemberRepo, &MockHub{})
+	cards := NewCardService(cardRepo, listRepo, boardRepo, boardMemberRepo, userRepo, &MockHub{})
+	alice := &models.User{Username: "alice", Email: "alice@example.com", Password: "x"}
+	assert.NoError(t, userRepo.Create(alice))
+	board, err := boards.CreateBoard("Launch", "", alice.ID)
+	assert.NoError(t, err)
+	todo, err := lists.CreateList("To do", board.ID, alice.ID, nil)
+	assert.NoError(t, err)
+	done, err := lists.CreateList("Done", board.ID, alice.ID, nil)
+	assert.NoError(t, err)
+	var ids []uint
+	for _, title := range []string{"Draft", "Review", "Publish"} {
+		card, err := cards.CreateCard(todo.ID, title, "", nil, nil, nil, nil, nil, alice.ID)
+		assert.NoError(t, err)
+		ids = append(ids, card.ID)
+	}
+
+	rebalanced, err := ranks.Rebalance()
+	assert.NoError(t, err)
+	assert.Zero(t, rebalanced)
+	assert.Empty(t, messages)
+
+	// Moving the last card up to second place again and again squeezes ranks into an ever smaller gap
+	for i := 0; i < 20; i++ {
+		_, err := cards.MoveCard(ids[2-i%2], todo.ID, 2, alice.ID)
+		assert.NoError(t, err)
+	}
+	before, err := cardRepo.FindByListID(todo.ID)
+	assert.NoError(t, err)
+	longest := 0
+	for _, card := range before {
+		longest = max(longest, len(card.Position))
+	}
+	assert.Greater(t, longest, 4)
+
+	rebalanced, err = ranks.Rebalance()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rebalanced, "the lists of the board are fine")
+	after, err := cardRepo.FindByListID(todo.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, after, 3) && assert.Len(t, messages, 1) {
+		payload := messages[0].Payload.(realtime.CardsReorderedPayload)
+		assert.Equal(t, realtime.MessageTypeCardsReordered, messages[0].Type)
+		assert.Equal(t, board.ID, messages[0].BoardID)
+		for i, card := range after {
+			assert.Equal(t, before[i].ID, card.ID, "the order stays the same")
+			assert.LessOrEqual(t, len(card.Position), 4)
+			assert.Equal(t, card.ID, payload.CardIDs[i])
+			assert.Equal(t, card.Position, payload.Positions[i])
+		}
+	}
+
+	// Lists sharing a rank are told apart
+	assert.NoError(t, db.Model(&models.List{}).Where("id = ?", done.ID).UpdateColumn("position", todo.Position).Error)
+	messages = nil
+	rebalanced, err = ranks.Rebalance()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rebalanced)
+	if assert.Len(t, messages, 1) {
+		assert.Equal(t, realtime.MessageTypeListsReordered, messages[0].Type)
+		payload := messages[0].Payload.(realtime.ListsReorderedPayload)
+		assert.Equal(t, []uint{todo.ID, done.ID}, payload.ListIDs)
+		assert.Less(t, payload.Positions[0], payload.Positions[1])
+	}
+}
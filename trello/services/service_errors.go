@@ -1,6 +1,9 @@
 package services
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 var (
 	ErrUserNotFound        = errors.New("user not found")
@@ -20,4 +23,77 @@ var (
 	ErrPositionOutOfBound  = errors.New("position out of bounds")
 	ErrUserNotCollaborator = errors.New("user is not a collaborator on this card")
 	ErrPermissionDenied    = errors.New("user does not have permission for this specific action on the card")
+	ErrInvalidBoardRole    = errors.New("invalid board role")
+	ErrCannotChangeOwner   = errors.New("cannot change the role of the board owner")
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrSessionRevoked      = errors.New("session has been revoked")
+	ErrInvalidResetToken   = errors.New("invalid or expired password reset token")
+	ErrInvalidVerifyToken  = errors.New("invalid or expired email verification token")
+	ErrEmailNotVerified    = errors.New("email address has not been verified")
+	ErrOIDCStateMismatch   = errors.New("invalid or expired single sign-on state")
+	ErrOIDCLoginFailed     = errors.New("single sign-on failed")
+	ErrAccessTokenNotFound = errors.New("personal access token not found")
+	ErrInvalidAccessToken  = errors.New("invalid, expired or revoked personal access token")
+
+	ErrTwoFactorRequired       = errors.New("two-factor authentication code required")
+	ErrInvalidTwoFactorCode    = errors.New("invalid two-factor authentication code")
+	ErrInvalidChallengeToken   = errors.New("invalid or expired two-factor login challenge")
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled     = errors.New("two-factor authentication is not enabled")
+
+	ErrLoginLocked     = errors.New("too many failed login attempts")
+	ErrLockoutNotFound = errors.New("no lockout for that account or IP address")
+
+	ErrIncorrectPassword = errors.New("current password is incorrect")
+
+	ErrWorkspaceNotFound       = errors.New("workspace not found")
+	ErrWorkspaceMemberNotFound = errors.New("workspace member not found")
+	ErrUserAlreadyInWorkspace  = errors.New("user is already a member of this workspace")
+	ErrInvalidWorkspaceRole    = errors.New("invalid workspace role")
+	ErrLastWorkspaceAdmin      = errors.New("a workspace must keep at least one admin")
+
+	ErrInvitationNotFound = errors.New("board invitation not found")
+	ErrInvalidInvitation  = errors.New("invalid, expired or already answered board invitation")
+	ErrInviteLinkNotFound = errors.New("board invite link not found")
+	ErrInvalidInviteLink  = errors.New("invalid, expired, revoked or used up board invite link")
+
+	ErrNewOwnerNotMember = errors.New("the new owner must already be a member of the board")
+	ErrAlreadyBoardOwner = errors.New("user already owns this board")
+
+	ErrBoardArchived = errors.New("board is archived")
+
+	ErrShareLinkNotFound = errors.New("board share link not found")
+
+	ErrLabelNotFound  = errors.New("label not found")
+	ErrLabelNotOnCard = errors.New("label is not on this card")
+
+	ErrChecklistNotFound     = errors.New("checklist not found")
+	ErrChecklistItemNotFound = errors.New("checklist item not found")
+
+	ErrAttachmentNotFound  = errors.New("attachment not found")
+	ErrAttachmentTooLarge  = errors.New("attachment is too large")
+	ErrUnsupportedFileType = errors.New("file type is not allowed for attachments")
 )
+
+// TwoFactorRequiredError is returned by Login when the password was correct, and by
+// OIDC logins, when the user has two-factor authentication enabled. The login is finished by presenting
+// ChallengeToken together with a code to TwoFactorService.CompleteLogin.
+// It matches ErrTwoFactorRequired with errors.Is.
+type TwoFactorRequiredError struct {
+	ChallengeToken string
+	ExpiresAt      time.Time
+}
+
+func (e *TwoFactorRequiredError) Error() string { return ErrTwoFactorRequired.Error() }
+
+func (e *TwoFactorRequiredError) Unwrap() error { return ErrTwoFactorRequired }
+
+// LoginLockedError is returned when logins are refused after too many failures.
+// It matches ErrLoginLocked with errors.Is.
+type LoginLockedError struct {
+	RetryAfter time.Duration // How long until logins are accepted again
+}
+
+func (e *LoginLockedError) Error() string { return ErrLoginLocked.Error() }
+
+func (e *LoginLockedError) Unwrap() error { return ErrLoginLocked }
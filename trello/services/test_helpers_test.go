@@ -5,6 +5,7 @@ import (
 
 	// "github.com/stretchr/testify/assert" // Not needed for setupTestDB itself
 	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/repositories"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -28,6 +29,24 @@ func setupTestDB(t *testing.T) *gorm.DB {
 		&models.BoardMember{},
 		&models.Comment{},
 		&models.CardCollaborator{}, // Ensure this is also migrated
+		&models.RefreshToken{},
+		&models.UserToken{},
+		&models.UserIdentity{},
+		&models.PersonalAccessToken{},
+		&models.RecoveryCode{},
+		&models.LoginThrottle{},
+		&models.AuditEvent{},
+		&models.Workspace{},
+		&models.WorkspaceMember{},
+		&models.BoardInvitation{},
+		&models.BoardInviteLink{},
+		&models.BoardShareLink{},
+		&models.BoardPreference{},
+		&models.Label{},
+		&models.CardLabel{},
+		&models.Checklist{},
+		&models.ChecklistItem{},
+		&models.Attachment{},
 	)
 	if err != nil {
 		t.Fatalf("Failed to migrate database for test: %v", err)
@@ -35,6 +54,31 @@ func setupTestDB(t *testing.T) *gorm.DB {
 	return db
 }
 
+// createTestUser creates a user with the address name@example.com, not yet
+// verified, and returns its ID.
+func createTestUser(t *testing.T, userRepo repositories.UserRepositoryInterface, name string) uint {
+	user := &models.User{Username: name, Email: name + "@example.com", Password: "x"}
+	if err := userRepo.Create(user); err != nil {
+		t.Fatal(err)
+	}
+	return user.ID
+}
+
+// createTestBoard creates a board owned by ownerID and adds members to it with
+// their roles.
+func createTestBoard(t *testing.T, boards BoardServiceInterface, name, description string, ownerID uint, members map[uint]models.BoardRole) *models.Board {
+	board, err := boards.CreateBoard(name, description, ownerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for userID, role := range members {
+		if _, err := boards.AddMemberToBoard(board.ID, nil, &userID, role, ownerID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return board
+}
+
 // To make this file a valid test file, add at least one test function.
 func TestMainServices(t *testing.T) {
     // This function can be used for package-level setup/teardown if needed,
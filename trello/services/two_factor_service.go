@@ -0,0 +1,309 @@
+package services
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/repositories"
+	"github.com/zayyadi/trello/utils"
+
+	"gorm.io/gorm"
+)
+
+const (
+	recoveryCodeCount    = 10
+	recoveryCodeLength   = 10 // Characters, shown as two groups of five
+	maxTwoFactorAttempts = 5  // Wrong codes allowed per login challenge
+	totpAllowedSkew      = 1  // Accept codes one time step either side of now
+)
+
+// Unambiguous characters for recovery codes (no 0/o, 1/l/i)
+const recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// TwoFactorEnrollment is what a user needs to add their account to an authenticator app.
+type TwoFactorEnrollment struct {
+	Secret          string
+	ProvisioningURI string
+}
+
+// TwoFactorService manages TOTP two-factor authentication: enrollment,
+// recovery codes and the second step of login.
+type TwoFactorService struct {
+	authService      *AuthService // Issues the session once the second factor checks out
+	userRepo         repositories.UserRepositoryInterface
+	userTokenRepo    repositories.UserTokenRepositoryInterface
+	recoveryCodeRepo repositories.RecoveryCodeRepositoryInterface
+	issuer           string // Shown as the account's name in authenticator apps
+}
+
+func NewTwoFactorService(
+	authService *AuthService,
+	userRepo repositories.UserRepositoryInterface,
+	userTokenRepo repositories.UserTokenRepositoryInterface,
+	recoveryCodeRepo repositories.RecoveryCodeRepositoryInterface,
+	issuer string,
+) *TwoFactorService {
+	return &TwoFactorService{
+		authService:      authService,
+		userRepo:         userRepo,
+		userTokenRepo:    userTokenRepo,
+		recoveryCodeRepo: recoveryCodeRepo,
+		issuer:           issuer,
+	}
+}
+
+// BeginEnrollment generates a new TOTP secret for the user. Two-factor auth is not
+// enabled until the user proves their app works with ConfirmEnrollment.
+// Calling it again replaces the pending secret.
+func (s *TwoFactorService) BeginEnrollment(userID uint) (*TwoFactorEnrollment, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	user.TwoFactorSecret = secret
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+	return &TwoFactorEnrollment{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmEnrollment enables two-factor auth once the user enters a valid code from their app.
+// It returns the user's recovery codes, which are only shown this once.
+func (s *TwoFactorService) ConfirmEnrollment(userID uint, code string) ([]string, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if user.TwoFactorSecret == "" {
+		return nil, ErrTwoFactorNotEnabled // Enrollment hasn't been started
+	}
+	if !s.checkTOTP(user, code) {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	user.TwoFactorEnabled = true
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+	return s.replaceRecoveryCodes(user.ID)
+}
+
+// Disable turns two-factor auth off. It takes a current TOTP or recovery code,
+// so a stolen session alone cannot remove the second factor.
+func (s *TwoFactorService) Disable(userID uint, code string) error {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return err
+	}
+	if !user.TwoFactorEnabled {
+		return ErrTwoFactorNotEnabled
+	}
+	ok, err := s.verifyCode(user, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+
+	user.TwoFactorEnabled = false
+	user.TwoFactorSecret = ""
+	user.TwoFactorLastStep = 0
+	if err := s.userRepo.Update(user); err != nil {
+		return err
+	}
+	return s.recoveryCodeRepo.ReplaceForUser(user.ID, nil)
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes. It takes a current TOTP code.
+func (s *TwoFactorService) RegenerateRecoveryCodes(userID uint, code string) ([]string, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TwoFactorEnabled {
+		return nil, ErrTwoFactorNotEnabled
+	}
+	if !s.checkTOTP(user, code) {
+		return nil, ErrInvalidTwoFactorCode
+	}
+	return s.replaceRecoveryCodes(user.ID)
+}
+
+// Status reports whether two-factor auth is enabled and how many recovery codes are left.
+func (s *TwoFactorService) Status(userID uint) (bool, int64, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return false, 0, err
+	}
+	if !user.TwoFactorEnabled {
+		return false, 0, nil
+	}
+	remaining, err := s.recoveryCodeRepo.CountUnused(user.ID)
+	if err != nil {
+		return false, 0, err
+	}
+	return true, remaining, nil
+}
+
+// CompleteLogin finishes a login that Login answered with a *TwoFactorRequiredError.
+// code may be a TOTP code or a recovery code. After too many wrong codes the
+// challenge is burned and the user has to enter their password again. Wrong codes
+// also count towards the account's login lockout.
+func (s *TwoFactorService) CompleteLogin(challengeToken, code, clientIP string) (*models.User, *AuthTokens, error) {
+	stored, err := s.userTokenRepo.FindByHash(models.TokenPurposeTwoFactorChallenge, utils.HashToken(challengeToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidChallengeToken
+		}
+		return nil, nil, err
+	}
+	if !stored.IsUsable(time.Now()) || stored.Attempts >= maxTwoFactorAttempts {
+		return nil, nil, ErrInvalidChallengeToken
+	}
+
+	user, err := s.userRepo.FindByID(stored.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidChallengeToken
+		}
+		return nil, nil, err
+	}
+	if !user.TwoFactorEnabled {
+		return nil, nil, ErrInvalidChallengeToken // Disabled since the password was entered
+	}
+	throttle := s.authService.opts.LoginThrottle
+	if err := throttle.Check(user.Email, clientIP); err != nil {
+		return nil, nil, err
+	}
+
+	ok, err := s.verifyCode(user, code)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
+		if err := s.userTokenRepo.IncrementAttempts(stored.ID); err != nil {
+			return nil, nil, err
+		}
+		if err := throttle.RecordFailure(user.Email, clientIP, &user.ID, "wrong two-factor code"); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, ErrInvalidTwoFactorCode
+	}
+
+	if err := s.userTokenRepo.MarkUsed(stored.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidChallengeToken // Redeemed concurrently
+		}
+		return nil, nil, err
+	}
+	if err := throttle.RecordSuccess(user.Email, clientIP, user.ID); err != nil {
+		return nil, nil, err
+	}
+
+	tokens, err := s.authService.startSession(user.ID)
+	if err != nil {
+		return user, nil, err
+	}
+	return user, tokens, nil
+}
+
+func (s *TwoFactorService) findUser(userID uint) (*models.User, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// verifyCode accepts either a TOTP code or an unused recovery code (which is consumed).
+func (s *TwoFactorService) verifyCode(user *models.User, code string) (bool, error) {
+	if s.checkTOTP(user, code) {
+		return true, nil
+	}
+	normalized := normalizeRecoveryCode(code)
+	if len(normalized) != recoveryCodeLength {
+		return false, nil
+	}
+	if err := s.recoveryCodeRepo.Consume(user.ID, utils.HashToken(normalized)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// checkTOTP validates a TOTP code and records its time step so it cannot be used twice.
+func (s *TwoFactorService) checkTOTP(user *models.User, code string) bool {
+	if user.TwoFactorSecret == "" {
+		return false
+	}
+	step, ok := utils.ValidateTOTP(user.TwoFactorSecret, code, time.Now(), totpAllowedSkew)
+	if !ok || step <= user.TwoFactorLastStep {
+		return false
+	}
+	user.TwoFactorLastStep = step
+	if err := s.userRepo.Update(user); err != nil {
+		return false // Fail closed: without the recorded step the code could be replayed
+	}
+	return true
+}
+
+// replaceRecoveryCodes generates a fresh set of recovery codes and stores their hashes.
+func (s *TwoFactorService) replaceRecoveryCodes(userID uint) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code[:recoveryCodeLength/2] + "-" + code[recoveryCodeLength/2:]
+		hashes[i] = utils.HashToken(code)
+	}
+	if err := s.recoveryCodeRepo.ReplaceForUser(userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func generateRecoveryCode() (string, error) {
+	var b strings.Builder
+	max := big.NewInt(int64(len(recoveryCodeAlphabet)))
+	for i := 0; i < recoveryCodeLength; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(recoveryCodeAlphabet[n.Int64()])
+	}
+	return b.String(), nil
+}
+
+// normalizeRecoveryCode makes codes typed with different case, spaces or dashes compare equal.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
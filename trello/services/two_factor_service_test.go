@@ -0,0 +1,240 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/repositories"
+	"github.com/zayyadi/trello/utils"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// MockRecoveryCodeRepository is an in-memory implementation of RecoveryCodeRepositoryInterface
+type MockRecoveryCodeRepository struct {
+	Codes []*models.RecoveryCode
+}
+
+func (m *MockRecoveryCodeRepository) ReplaceForUser(userID uint, codeHashes []string) error {
+	kept := m.Codes[:0]
+	for _, c := range m.Codes {
+		if c.UserID != userID {
+			kept = append(kept, c)
+		}
+	}
+	m.Codes = kept
+	for _, hash := range codeHashes {
+		m.Codes = append(m.Codes, &models.RecoveryCode{UserID: userID, CodeHash: hash})
+	}
+	return nil
+}
+
+func (m *MockRecoveryCodeRepository) Consume(userID uint, codeHash string) error {
+	for _, c := range m.Codes {
+		if c.UserID == userID && c.CodeHash == codeHash && c.UsedAt == nil {
+			now := time.Now()
+			c.UsedAt = &now
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (m *MockRecoveryCodeRepository) CountUnused(userID uint) (int64, error) {
+	var count int64
+	for _, c := range m.Codes {
+		if c.UserID == userID && c.UsedAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+var _ repositories.RecoveryCodeRepositoryInterface = (*MockRecoveryCodeRepository)(nil)
+
+type twoFactorTestEnv struct {
+	authService      *AuthService
+	service          *TwoFactorService
+	user             *models.User
+	recoveryCodeRepo *MockRecoveryCodeRepository
+}
+
+func newTwoFactorTestEnv(t *testing.T) *twoFactorTestEnv {
+	hashedPassword, _ := utils.HashPassword("password123")
+	user := &models.User{Model: gorm.Model{ID: 7}, Username: "jane", Email: "jane@example.com", Password: hashedPassword}
+	userRepo := &MockUserRepository{}
+	inMemoryUsers(userRepo, user)
+	userTokenRepo := &MockUserTokenRepository{}
+	recoveryCodeRepo := &MockRecoveryCodeRepository{}
+
+	authService := NewAuthService(userRepo, &MockRefreshTokenRepository{}, userTokenRepo, &MockMailer{}, AuthOptions{JWTSecretKey: "test-secret"})
+	return &twoFactorTestEnv{
+		authService:      authService,
+		service:          NewTwoFactorService(authService, userRepo, userTokenRepo, recoveryCodeRepo, "Trello Test"),
+		user:             user,
+		recoveryCodeRepo: recoveryCodeRepo,
+	}
+}
+
+// currentCode returns the TOTP code for the given offset from the current time step.
+func currentCode(t *testing.T, secret string, stepOffset int64) string {
+	code, err := utils.TOTPCode(secret, utils.TOTPCounter(time.Now())+stepOffset)
+	if err != nil {
+		t.Fatalf("computing TOTP code: %v", err)
+	}
+	return code
+}
+
+// enable runs enrollment for the env's user and returns their recovery codes.
+func (e *twoFactorTestEnv) enable(t *testing.T) []string {
+	enrollment, err := e.service.BeginEnrollment(e.user.ID)
+	if err != nil {
+		t.Fatalf("enrollment failed: %v", err)
+	}
+	// Use the previous step so tests can still use the current one afterwards
+	codes, err := e.service.ConfirmEnrollment(e.user.ID, currentCode(t, enrollment.Secret, -1))
+	if err != nil {
+		t.Fatalf("confirmation failed: %v", err)
+	}
+	return codes
+}
+
+func TestTwoFactorService_Enrollment(t *testing.T) {
+	env := newTwoFactorTestEnv(t)
+
+	enrollment, err := env.service.BeginEnrollment(env.user.ID)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, enrollment.Secret)
+	assert.True(t, strings.HasPrefix(enrollment.ProvisioningURI, "otpauth://totp/Trello%20Test:jane@example.com?"))
+	assert.Contains(t, enrollment.ProvisioningURI, "secret="+enrollment.Secret)
+	assert.False(t, env.user.TwoFactorEnabled, "not enabled until confirmed")
+
+	_, err = env.service.ConfirmEnrollment(env.user.ID, "000000")
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+	assert.False(t, env.user.TwoFactorEnabled)
+
+	codes, err := env.service.ConfirmEnrollment(env.user.ID, currentCode(t, enrollment.Secret, 0))
+	assert.NoError(t, err)
+	assert.True(t, env.user.TwoFactorEnabled)
+	assert.Len(t, codes, recoveryCodeCount)
+	assert.Len(t, env.recoveryCodeRepo.Codes, recoveryCodeCount)
+	for _, stored := range env.recoveryCodeRepo.Codes {
+		assert.NotContains(t, codes, stored.CodeHash, "only hashes are stored")
+	}
+
+	_, err = env.service.BeginEnrollment(env.user.ID)
+	assert.ErrorIs(t, err, ErrTwoFactorAlreadyEnabled)
+
+	enabled, remaining, err := env.service.Status(env.user.ID)
+	assert.NoError(t, err)
+	assert.True(t, enabled)
+	assert.Equal(t, int64(recoveryCodeCount), remaining)
+}
+
+func TestTwoFactorService_ConfirmWithoutEnrollment(t *testing.T) {
+	env := newTwoFactorTestEnv(t)
+	_, err := env.service.ConfirmEnrollment(env.user.ID, "123456")
+	assert.ErrorIs(t, err, ErrTwoFactorNotEnabled)
+}
+
+func TestTwoFactorService_TwoStepLogin(t *testing.T) {
+	env := newTwoFactorTestEnv(t)
+	env.enable(t)
+
+	user, tokens, err := env.authService.Login("jane@example.com", "password123", "")
+	assert.Nil(t, user)
+	assert.Nil(t, tokens, "no session before the second factor")
+	assert.ErrorIs(t, err, ErrTwoFactorRequired)
+	var challenge *TwoFactorRequiredError
+	if !assert.True(t, errors.As(err, &challenge)) {
+		return
+	}
+	assert.NotEmpty(t, challenge.ChallengeToken)
+	assert.True(t, challenge.ExpiresAt.After(time.Now()))
+
+	_, _, err = env.service.CompleteLogin(challenge.ChallengeToken, "000000", "")
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+
+	code := currentCode(t, env.user.TwoFactorSecret, 0)
+	user, tokens, err = env.service.CompleteLogin(challenge.ChallengeToken, code, "")
+	assert.NoError(t, err)
+	assert.Equal(t, env.user.ID, user.ID)
+	if assert.NotNil(t, tokens) {
+		assert.NotEmpty(t, tokens.AccessToken)
+	}
+
+	// The challenge is single use, and so is the code
+	_, _, err = env.service.CompleteLogin(challenge.ChallengeToken, code, "")
+	assert.ErrorIs(t, err, ErrInvalidChallengeToken)
+	_, _, err = env.authService.Login("jane@example.com", "password123", "")
+	errors.As(err, &challenge)
+	_, _, err = env.service.CompleteLogin(challenge.ChallengeToken, code, "")
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode, "a TOTP code cannot be replayed")
+}
+
+func TestTwoFactorService_LoginWithRecoveryCode(t *testing.T) {
+	env := newTwoFactorTestEnv(t)
+	codes := env.enable(t)
+
+	var challenge *TwoFactorRequiredError
+	_, _, err := env.authService.Login("jane@example.com", "password123", "")
+	assert.True(t, errors.As(err, &challenge))
+
+	// Recovery codes are accepted regardless of case and dashes
+	typed := strings.ToUpper(strings.ReplaceAll(codes[0], "-", " "))
+	_, tokens, err := env.service.CompleteLogin(challenge.ChallengeToken, typed, "")
+	assert.NoError(t, err)
+	assert.NotNil(t, tokens)
+
+	_, remaining, _ := env.service.Status(env.user.ID)
+	assert.Equal(t, int64(recoveryCodeCount-1), remaining)
+
+	_, _, err = env.authService.Login("jane@example.com", "password123", "")
+	errors.As(err, &challenge)
+	_, _, err = env.service.CompleteLogin(challenge.ChallengeToken, codes[0], "")
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode, "recovery codes are single use")
+}
+
+func TestTwoFactorService_ChallengeBurnedAfterTooManyAttempts(t *testing.T) {
+	env := newTwoFactorTestEnv(t)
+	env.enable(t)
+
+	var challenge *TwoFactorRequiredError
+	_, _, err := env.authService.Login("jane@example.com", "password123", "")
+	assert.True(t, errors.As(err, &challenge))
+
+	for i := 0; i < maxTwoFactorAttempts; i++ {
+		_, _, err = env.service.CompleteLogin(challenge.ChallengeToken, "000000", "")
+		assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+	}
+	_, _, err = env.service.CompleteLogin(challenge.ChallengeToken, currentCode(t, env.user.TwoFactorSecret, 0), "")
+	assert.ErrorIs(t, err, ErrInvalidChallengeToken)
+}
+
+func TestTwoFactorService_DisableAndRegenerate(t *testing.T) {
+	env := newTwoFactorTestEnv(t)
+	codes := env.enable(t)
+
+	_, err := env.service.RegenerateRecoveryCodes(env.user.ID, "000000")
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+	newCodes, err := env.service.RegenerateRecoveryCodes(env.user.ID, currentCode(t, env.user.TwoFactorSecret, 0))
+	assert.NoError(t, err)
+	assert.Len(t, newCodes, recoveryCodeCount)
+	assert.NotEqual(t, codes, newCodes)
+
+	assert.ErrorIs(t, env.service.Disable(env.user.ID, codes[0]), ErrInvalidTwoFactorCode, "old recovery codes were replaced")
+	assert.NoError(t, env.service.Disable(env.user.ID, newCodes[0]))
+	assert.False(t, env.user.TwoFactorEnabled)
+	assert.Empty(t, env.user.TwoFactorSecret)
+	assert.Empty(t, env.recoveryCodeRepo.Codes)
+	assert.ErrorIs(t, env.service.Disable(env.user.ID, newCodes[1]), ErrTwoFactorNotEnabled)
+
+	// Password alone is enough again
+	_, tokens, err := env.authService.Login("jane@example.com", "password123", "")
+	assert.NoError(t, err)
+	assert.NotNil(t, tokens)
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/repositories"
+	"github.com/zayyadi/trello/utils"
+	"gorm.io/gorm"
+)
+
+// ProfileUpdate holds the profile fields to change. Nil fields are left as they are;
+// an empty DisplayName or AvatarURL clears it.
+type ProfileUpdate struct {
+	Username    *string
+	Email       *string
+	DisplayName *string
+	AvatarURL   *string
+	// CurrentPassword is required to change the email, since whoever controls
+	// the email can reset the password.
+	CurrentPassword string
+}
+
+// UserService lets users view and manage their own account.
+type UserService struct {
+	userRepo    repositories.UserRepositoryInterface
+	authService *AuthService // For verification emails and sessions
+}
+
+func NewUserService(userRepo repositories.UserRepositoryInterface, authService *AuthService) *UserService {
+	return &UserService{userRepo: userRepo, authService: authService}
+}
+
+func (s *UserService) GetProfile(userID uint) (*models.User, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// UpdateProfile changes the user's profile. A new email address has to be verified again.
+func (s *UserService) UpdateProfile(userID uint, update ProfileUpdate) (*models.User, error) {
+	user, err := s.GetProfile(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Username != nil && *update.Username != user.Username {
+		if err := s.checkUsernameAvailable(*update.Username, user.ID); err != nil {
+			return nil, err
+		}
+		user.Username = *update.Username
+	}
+
+	emailChanged := update.Email != nil && *update.Email != user.Email
+	if emailChanged {
+		if !utils.CheckPasswordHash(update.CurrentPassword, user.Password) {
+			return nil, ErrIncorrectPassword
+		}
+		if err := s.checkEmailAvailable(*update.Email, user.ID); err != nil {
+			return nil, err
+		}
+		user.Email = *update.Email
+		user.EmailVerified = false
+		user.EmailVerifiedAt = nil
+	}
+
+	if update.DisplayName != nil {
+		user.DisplayName = strings.TrimSpace(*update.DisplayName)
+	}
+	if update.AvatarURL != nil {
+		if err := validateAvatarURL(*update.AvatarURL); err != nil {
+			return nil, err
+		}
+		user.AvatarURL = *update.AvatarURL
+	}
+
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	if emailChanged {
+		if err := s.authService.sendVerificationEmail(user); err != nil {
+			// The change is saved; the user can ask for the email again
+			log.Printf("Failed to send verification email to user %d: %v", user.ID, err)
+		}
+	}
+	return user, nil
+}
+
+// ChangePassword sets a new password after checking the current one. Every existing
+// session is signed out and personal access tokens are revoked, and a fresh
+// session is returned so the caller stays logged in.
+func (s *UserService) ChangePassword(userID uint, currentPassword, newPassword string) (*AuthTokens, error) {
+	user, err := s.GetProfile(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !utils.CheckPasswordHash(currentPassword, user.Password) {
+		return nil, ErrIncorrectPassword
+	}
+
+	hashedPassword, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return nil, err
+	}
+	user.Password = hashedPassword
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	if err := s.authService.revokeAllCredentials(user.ID); err != nil {
+		return nil, err
+	}
+	return s.authService.startSession(user.ID)
+}
+
+func (s *UserService) checkUsernameAvailable(username string, userID uint) error {
+	existing, err := s.userRepo.FindByUsername(username)
+	if err == nil && existing.ID != userID {
+		return ErrUsernameExists
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+func (s *UserService) checkEmailAvailable(email string, userID uint) error {
+	existing, err := s.userRepo.FindByEmail(email)
+	if err == nil && existing.ID != userID {
+		return ErrEmailExists
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+// validateAvatarURL only allows absolute http(s) URLs, so clients can safely use it as an image source.
+func validateAvatarURL(raw string) error {
+	if raw == "" {
+		return nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: avatar URL must be an http or https URL", ErrInvalidInput)
+	}
+	return nil
+}
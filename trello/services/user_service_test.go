@@ -0,0 +1,128 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/utils"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type userServiceTestEnv struct {
+	service          *UserService
+	user             *models.User
+	mailer           *MockMailer
+	refreshTokenRepo *MockRefreshTokenRepository
+	patRepo          *MockPersonalAccessTokenRepository
+}
+
+func newUserServiceTestEnv(t *testing.T) *userServiceTestEnv {
+	hashedPassword, _ := utils.HashPassword("password123")
+	user := &models.User{Model: gorm.Model{ID: 7}, Username: "jane", Email: "jane@example.com", Password: hashedPassword, EmailVerified: true}
+	other := &models.User{Model: gorm.Model{ID: 8}, Username: "john", Email: "john@example.com"}
+	userRepo := &MockUserRepository{}
+	inMemoryUsers(userRepo, user, other)
+
+	env := &userServiceTestEnv{user: user, mailer: &MockMailer{}, refreshTokenRepo: &MockRefreshTokenRepository{}, patRepo: &MockPersonalAccessTokenRepository{}}
+	authService := NewAuthService(userRepo, env.refreshTokenRepo, &MockUserTokenRepository{}, env.mailer, AuthOptions{JWTSecretKey: "test-secret", PersonalAccessTokens: env.patRepo})
+	env.service = NewUserService(userRepo, authService)
+	return env
+}
+
+func strPtr(s string) *string { return &s }
+
+func TestUserService_GetProfile(t *testing.T) {
+	env := newUserServiceTestEnv(t)
+
+	user, err := env.service.GetProfile(7)
+	assert.NoError(t, err)
+	assert.Equal(t, "jane", user.Username)
+
+	_, err = env.service.GetProfile(99)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
+
+func TestUserService_UpdateProfile(t *testing.T) {
+	env := newUserServiceTestEnv(t)
+
+	user, err := env.service.UpdateProfile(7, ProfileUpdate{
+		Username:    strPtr("jane.doe"),
+		DisplayName: strPtr("  Jane Doe "),
+		AvatarURL:   strPtr("https://example.com/jane.png"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "jane.doe", user.Username)
+	assert.Equal(t, "Jane Doe", user.DisplayName)
+	assert.Equal(t, "https://example.com/jane.png", user.AvatarURL)
+	assert.Equal(t, "jane@example.com", user.Email, "unset fields are left alone")
+	assert.True(t, user.EmailVerified)
+
+	// Empty strings clear optional fields
+	user, err = env.service.UpdateProfile(7, ProfileUpdate{DisplayName: strPtr(""), AvatarURL: strPtr("")})
+	assert.NoError(t, err)
+	assert.Empty(t, user.DisplayName)
+	assert.Empty(t, user.AvatarURL)
+
+	// Keeping your own username is not a conflict
+	_, err = env.service.UpdateProfile(7, ProfileUpdate{Username: strPtr("jane.doe")})
+	assert.NoError(t, err)
+}
+
+func TestUserService_UpdateProfile_Conflicts(t *testing.T) {
+	env := newUserServiceTestEnv(t)
+
+	_, err := env.service.UpdateProfile(7, ProfileUpdate{Username: strPtr("john")})
+	assert.ErrorIs(t, err, ErrUsernameExists)
+	_, err = env.service.UpdateProfile(7, ProfileUpdate{Email: strPtr("john@example.com"), CurrentPassword: "password123"})
+	assert.ErrorIs(t, err, ErrEmailExists)
+	assert.Equal(t, "jane", env.user.Username)
+	assert.Equal(t, "jane@example.com", env.user.Email)
+}
+
+func TestUserService_UpdateProfile_InvalidAvatarURL(t *testing.T) {
+	env := newUserServiceTestEnv(t)
+
+	for _, avatar := range []string{"javascript:alert(1)", "/relative.png", "ftp://example.com/a.png", "https://"} {
+		_, err := env.service.UpdateProfile(7, ProfileUpdate{AvatarURL: strPtr(avatar)})
+		assert.ErrorIs(t, err, ErrInvalidInput, avatar)
+	}
+	assert.Empty(t, env.user.AvatarURL)
+}
+
+func TestUserService_UpdateProfile_ChangeEmail(t *testing.T) {
+	env := newUserServiceTestEnv(t)
+
+	_, err := env.service.UpdateProfile(7, ProfileUpdate{Email: strPtr("jane@new.example.com")})
+	assert.ErrorIs(t, err, ErrIncorrectPassword, "changing the email needs the password")
+	_, err = env.service.UpdateProfile(7, ProfileUpdate{Email: strPtr("jane@new.example.com"), CurrentPassword: "wrong"})
+	assert.ErrorIs(t, err, ErrIncorrectPassword)
+	assert.Equal(t, "jane@example.com", env.user.Email)
+
+	user, err := env.service.UpdateProfile(7, ProfileUpdate{Email: strPtr("jane@new.example.com"), CurrentPassword: "password123"})
+	assert.NoError(t, err)
+	assert.Equal(t, "jane@new.example.com", user.Email)
+	assert.False(t, user.EmailVerified, "the new address has to be verified")
+	if assert.Len(t, env.mailer.Sent, 1) {
+		assert.Equal(t, []string{"jane@new.example.com"}, env.mailer.Sent[0].To)
+	}
+}
+
+func TestUserService_ChangePassword(t *testing.T) {
+	env := newUserServiceTestEnv(t)
+	assert.NoError(t, env.patRepo.Create(&models.PersonalAccessToken{UserID: 7, Name: "deploy script"}))
+	assert.NoError(t, env.patRepo.Create(&models.PersonalAccessToken{UserID: 8, Name: "backup"}))
+
+	_, err := env.service.ChangePassword(7, "wrong", "newPassword456")
+	assert.ErrorIs(t, err, ErrIncorrectPassword)
+	assert.True(t, utils.CheckPasswordHash("password123", env.user.Password))
+
+	tokens, err := env.service.ChangePassword(7, "password123", "newPassword456")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.True(t, utils.CheckPasswordHash("newPassword456", env.user.Password))
+	assert.Equal(t, uint(7), env.refreshTokenRepo.RevokeAllCalledWithUser, "other sessions are signed out")
+	assert.NotNil(t, env.patRepo.Tokens[0].RevokedAt, "personal access tokens are revoked")
+	assert.Nil(t, env.patRepo.Tokens[1].RevokedAt, "other users' tokens are left alone")
+}
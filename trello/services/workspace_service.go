@@ -0,0 +1,378 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zayyadi/trello/dto"
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/policy"
+	"github.com/zayyadi/trello/realtime"
+	"github.com/zayyadi/trello/repositories"
+
+	"gorm.io/gorm"
+)
+
+// WorkspaceService manages workspaces, their members and the boards in them.
+// Access to the boards themselves is decided in board_access.go, which gives
+// workspace members a role on the workspace's shared boards.
+type WorkspaceService struct {
+	workspaceRepo        repositories.WorkspaceRepositoryInterface
+	workspaceMemberRepo  repositories.WorkspaceMemberRepositoryInterface
+	boardRepo            repositories.BoardRepositoryInterface
+	boardMemberRepo      repositories.BoardMemberRepositoryInterface
+	userRepo             repositories.UserRepositoryInterface
+	hub                  realtime.Broadcaster
+	requireVerifiedEmail bool // Refuse to add users who haven't verified their email
+}
+
+func NewWorkspaceService(
+	workspaceRepo repositories.WorkspaceRepositoryInterface,
+	workspaceMemberRepo repositories.WorkspaceMemberRepositoryInterface,
+	boardRepo repositories.BoardRepositoryInterface,
+	boardMemberRepo repositories.BoardMemberRepositoryInterface,
+	userRepo repositories.UserRepositoryInterface,
+	hub realtime.Broadcaster,
+	requireVerifiedEmail bool,
+) *WorkspaceService {
+	return &WorkspaceService{
+		workspaceRepo:        workspaceRepo,
+		workspaceMemberRepo:  workspaceMemberRepo,
+		boardRepo:            boardRepo,
+		boardMemberRepo:      boardMemberRepo,
+		userRepo:             userRepo,
+		hub:                  hub,
+		requireVerifiedEmail: requireVerifiedEmail,
+	}
+}
+
+// CreateWorkspace creates a workspace with the creator as its first admin.
+func (s *WorkspaceService) CreateWorkspace(name, description string, userID uint) (*models.Workspace, error) {
+	workspace := &models.Workspace{
+		Name:        name,
+		Description: description,
+		Members:     []models.WorkspaceMember{{UserID: userID, Role: models.WorkspaceRoleAdmin}},
+	}
+	if err := s.workspaceRepo.Create(workspace); err != nil {
+		return nil, err
+	}
+	return s.findWorkspace(workspace.ID)
+}
+
+func (s *WorkspaceService) GetWorkspace(workspaceID, userID uint) (*models.Workspace, error) {
+	if _, err := s.authorize(workspaceID, userID, policy.ViewWorkspace); err != nil {
+		return nil, err
+	}
+	return s.findWorkspace(workspaceID)
+}
+
+// GetWorkspacesForUser lists the workspaces the user belongs to.
+func (s *WorkspaceService) GetWorkspacesForUser(userID uint) ([]models.Workspace, error) {
+	return s.workspaceRepo.FindByMember(userID)
+}
+
+func (s *WorkspaceService) UpdateWorkspace(workspaceID uint, name, description *string, userID uint) (*models.Workspace, error) {
+	if _, err := s.authorize(workspaceID, userID, policy.UpdateWorkspace); err != nil {
+		return nil, err
+	}
+	workspace, err := s.findWorkspace(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if name != nil {
+		workspace.Name = *name
+	}
+	if description != nil {
+		workspace.Description = *description
+	}
+	if err := s.workspaceRepo.Update(workspace); err != nil {
+		return nil, err
+	}
+	return s.findWorkspace(workspaceID)
+}
+
+// DeleteWorkspace removes the workspace. Its boards are kept but become private,
+// so only their own members can still open them.
+func (s *WorkspaceService) DeleteWorkspace(workspaceID, userID uint) error {
+	if _, err := s.authorize(workspaceID, userID, policy.DeleteWorkspace); err != nil {
+		return err
+	}
+	return s.workspaceRepo.Delete(workspaceID)
+}
+
+func (s *WorkspaceService) GetMembers(workspaceID, userID uint) ([]models.WorkspaceMember, error) {
+	if _, err := s.authorize(workspaceID, userID, policy.ViewWorkspace); err != nil {
+		return nil, err
+	}
+	return s.workspaceMemberRepo.FindMembers(workspaceID)
+}
+
+// AddMember adds a user, found by email or ID, to the workspace. Role defaults to member.
+func (s *WorkspaceService) AddMember(workspaceID uint, email *string, memberUserID *uint, role models.WorkspaceRole, currentUserID uint) (*models.WorkspaceMember, error) {
+	if _, err := s.authorize(workspaceID, currentUserID, policy.ManageWorkspaceMembers); err != nil {
+		return nil, err
+	}
+	if role == "" {
+		role = models.WorkspaceRoleMember
+	}
+	if !role.IsValid() {
+		return nil, ErrInvalidWorkspaceRole
+	}
+
+	var targetUser *models.User
+	var err error
+	if email != nil && *email != "" {
+		targetUser, err = s.userRepo.FindByEmail(*email)
+	} else if memberUserID != nil && *memberUserID != 0 {
+		targetUser, err = s.userRepo.FindByID(*memberUserID)
+	} else {
+		return nil, fmt.Errorf("%w: either email or userID must be provided", ErrInvalidInput)
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if s.requireVerifiedEmail && !targetUser.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+
+	if _, err := s.workspaceMemberRepo.GetRole(workspaceID, targetUser.ID); err == nil {
+		return nil, ErrUserAlreadyInWorkspace
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err := s.workspaceMemberRepo.AddMember(&models.WorkspaceMember{WorkspaceID: workspaceID, UserID: targetUser.ID, Role: role}); err != nil {
+		return nil, err
+	}
+	return s.workspaceMemberRepo.FindMember(workspaceID, targetUser.ID)
+}
+
+// UpdateMemberRole changes a member's role. The last admin cannot be demoted.
+func (s *WorkspaceService) UpdateMemberRole(workspaceID, memberUserID uint, role models.WorkspaceRole, currentUserID uint) (*models.WorkspaceMember, error) {
+	if _, err := s.authorize(workspaceID, currentUserID, policy.ManageWorkspaceMembers); err != nil {
+		return nil, err
+	}
+	if !role.IsValid() {
+		return nil, ErrInvalidWorkspaceRole
+	}
+	current, err := s.memberRole(workspaceID, memberUserID)
+	if err != nil {
+		return nil, err
+	}
+	if current == models.WorkspaceRoleAdmin && role != models.WorkspaceRoleAdmin {
+		if err := s.ensureAnotherAdmin(workspaceID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.workspaceMemberRepo.UpdateRole(workspaceID, memberUserID, role); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWorkspaceMemberNotFound
+		}
+		return nil, err
+	}
+	return s.workspaceMemberRepo.FindMember(workspaceID, memberUserID)
+}
+
+// RemoveMember takes a member out of the workspace. Admins may remove anyone and
+// members may remove themselves (leave), but the last admin has to stay.
+// Explicit board memberships are kept; only the access that came through the workspace goes.
+func (s *WorkspaceService) RemoveMember(workspaceID, memberUserID, currentUserID uint) error {
+	if memberUserID != currentUserID {
+		if _, err := s.authorize(workspaceID, currentUserID, policy.ManageWorkspaceMembers); err != nil {
+			return err
+		}
+	}
+	role, err := s.memberRole(workspaceID, memberUserID)
+	if err != nil {
+		return err
+	}
+	if role == models.WorkspaceRoleAdmin {
+		if err := s.ensureAnotherAdmin(workspaceID); err != nil {
+			return err
+		}
+	}
+	boards, err := s.boardRepo.FindByWorkspace(workspaceID, memberUserID)
+	if err != nil {
+		return err
+	}
+	if err := s.workspaceMemberRepo.RemoveMember(workspaceID, memberUserID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrWorkspaceMemberNotFound
+		}
+		return err
+	}
+	for _, board := range boards {
+		disconnectFormerViewer(s.hub, s.boardRepo, s.boardMemberRepo, board.ID, memberUserID)
+	}
+	return nil
+}
+
+// GetBoards lists the workspace's boards the user can see: every workspace-visible
+// board, plus private ones they own or were added to. Archived boards are left out.
+func (s *WorkspaceService) GetBoards(workspaceID, userID uint) ([]models.Board, error) {
+	if _, err := s.authorize(workspaceID, userID, policy.ViewWorkspace); err != nil {
+		return nil, err
+	}
+	boards, err := s.boardRepo.FindByWorkspace(workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return filterArchivedBoards(boards, false), nil
+}
+
+// CreateBoard creates a board in the workspace, owned by the caller.
+// Visibility defaults to workspace, so the whole team can use the board straight away.
+func (s *WorkspaceService) CreateBoard(workspaceID uint, name, description string, visibility models.BoardVisibility, userID uint) (*models.Board, error) {
+	if _, err := s.authorize(workspaceID, userID, policy.CreateWorkspaceBoard); err != nil {
+		return nil, err
+	}
+	if visibility == "" {
+		visibility = models.BoardVisibilityWorkspace
+	}
+	if !visibility.IsValid() {
+		return nil, fmt.Errorf("%w: visibility must be one of: private, workspace, public", ErrInvalidInput)
+	}
+
+	board := &models.Board{
+		Name:        name,
+		Description: description,
+		OwnerID:     userID,
+		WorkspaceID: &workspaceID,
+		Visibility:  visibility,
+	}
+	if err := s.boardRepo.Create(board); err != nil {
+		return nil, err
+	}
+	// Add owner as an admin member automatically, as for personal boards
+	if err := s.boardMemberRepo.AddMember(&models.BoardMember{BoardID: board.ID, UserID: userID, Role: models.BoardRoleAdmin}); err != nil {
+		return nil, err
+	}
+	createdBoard, err := s.boardRepo.FindByID(board.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	broadcastMessage(
+		s.hub,
+		createdBoard.ID,
+		realtime.MessageTypeBoardCreated,
+		dto.MapBoardToResponse(createdBoard, true, false),
+		userID,
+	)
+	return createdBoard, nil
+}
+
+// MoveBoard puts an existing board into a workspace, or takes it out when
+// workspaceID is nil. The caller must be able to update the board and, when
+// moving it in, be a member of the target workspace. Boards taken out of a
+// workspace become private, and so do boards moved into one until shared
+// explicitly, public ones too: workspace members get roles on public boards.
+// Otherwise visibility is left alone when empty.
+func (s *WorkspaceService) MoveBoard(boardID uint, workspaceID *uint, visibility models.BoardVisibility, userID uint) (*models.Board, error) {
+	board, _, err := authorizeBoardAction(s.boardRepo, s.boardMemberRepo, boardID, userID, policy.UpdateBoard)
+	if err != nil {
+		return nil, err
+	}
+	if visibility != "" && !visibility.IsValid() {
+		return nil, fmt.Errorf("%w: visibility must be one of: private, workspace, public", ErrInvalidInput)
+	}
+	wasPublic := board.Visibility == models.BoardVisibilityPublic
+
+	if workspaceID == nil {
+		if visibility == models.BoardVisibilityWorkspace {
+			return nil, fmt.Errorf("%w: a board outside a workspace cannot have workspace visibility", ErrInvalidInput)
+		}
+		if board.WorkspaceID != nil {
+			board.Visibility = models.BoardVisibilityPrivate
+		}
+		board.WorkspaceID = nil
+		if visibility != "" {
+			board.Visibility = visibility
+		}
+	} else {
+		if _, err := s.authorize(*workspaceID, userID, policy.CreateWorkspaceBoard); err != nil {
+			return nil, err
+		}
+		if board.WorkspaceID == nil || *board.WorkspaceID != *workspaceID {
+			board.Visibility = models.BoardVisibilityPrivate // Don't share with a new team implicitly
+		}
+		board.WorkspaceID = workspaceID
+		if visibility != "" {
+			board.Visibility = visibility
+		}
+	}
+
+	if err := s.boardRepo.Update(board); err != nil {
+		return nil, err
+	}
+	updatedBoard, err := s.boardRepo.FindByID(board.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	broadcastMessage(
+		s.hub,
+		updatedBoard.ID,
+		realtime.MessageTypeBoardUpdated,
+		dto.MapBoardToResponse(updatedBoard, true, false),
+		userID,
+	)
+	if wasPublic && updatedBoard.Visibility != models.BoardVisibilityPublic {
+		disconnectAnonymousViewers(s.hub, boardID, 0)
+	}
+	return updatedBoard, nil
+}
+
+// authorize checks userID's workspace role against the policy. Non-members get
+// ErrWorkspaceNotFound rather than ErrForbidden, so workspace IDs can't be probed.
+func (s *WorkspaceService) authorize(workspaceID, userID uint, action policy.Action) (policy.WorkspaceSubject, error) {
+	role, err := s.workspaceMemberRepo.GetRole(workspaceID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return policy.WorkspaceSubject{}, ErrWorkspaceNotFound
+		}
+		return policy.WorkspaceSubject{}, err
+	}
+	subject := policy.WorkspaceSubject{UserID: userID, Role: role}
+	if !policy.CanInWorkspace(subject, action) {
+		return policy.WorkspaceSubject{}, ErrForbidden
+	}
+	return subject, nil
+}
+
+func (s *WorkspaceService) findWorkspace(workspaceID uint) (*models.Workspace, error) {
+	workspace, err := s.workspaceRepo.FindByID(workspaceID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWorkspaceNotFound
+		}
+		return nil, err
+	}
+	return workspace, nil
+}
+
+func (s *WorkspaceService) memberRole(workspaceID, userID uint) (models.WorkspaceRole, error) {
+	role, err := s.workspaceMemberRepo.GetRole(workspaceID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrWorkspaceMemberNotFound
+		}
+		return "", err
+	}
+	return role, nil
+}
+
+// ensureAnotherAdmin fails with ErrLastWorkspaceAdmin if the workspace has only one admin.
+func (s *WorkspaceService) ensureAnotherAdmin(workspaceID uint) error {
+	admins, err := s.workspaceMemberRepo.CountAdmins(workspaceID)
+	if err != nil {
+		return err
+	}
+	if admins <= 1 {
+		return ErrLastWorkspaceAdmin
+	}
+	return nil
+}
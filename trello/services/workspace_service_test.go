@@ -0,0 +1,238 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zayyadi/trello/models"
+	"github.com/zayyadi/trello/repositories"
+)
+
+// workspaceTestEnv wires the workspace and board services to real repositories
+// on SQLite, since access to a board now depends on several tables at once.
+type workspaceTestEnv struct {
+	workspaces *WorkspaceService
+	boards     BoardServiceInterface
+	alice      uint // Creates the workspace, so its first admin
+	bob        uint
+	carol      uint // Not in the workspace
+	hub        *userDisconnectRecordingHub
+}
+
+func newWorkspaceTestEnv(t *testing.T) *workspaceTestEnv {
+	db := setupTestDB(t)
+	userRepo := repositories.NewUserRepository(db)
+	boardRepo := repositories.NewBoardRepository(db)
+	boardMemberRepo := repositories.NewBoardMemberRepository(db)
+
+	env := &workspaceTestEnv{hub: &userDisconnectRecordingHub{}}
+	env.workspaces = NewWorkspaceService(repositories.NewWorkspaceRepository(db), repositories.NewWorkspaceMemberRepository(db), boardRepo, boardMemberRepo, userRepo, env.hub, false)
+	env.boards = NewBoardService(boardRepo, userRepo, boardMemberRepo, env.hub, false)
+	env.alice = createTestUser(t, userRepo, "alice")
+	env.bob = createTestUser(t, userRepo, "bob")
+	env.carol = createTestUser(t, userRepo, "carol")
+	return env
+}
+
+func (env *workspaceTestEnv) createWorkspaceWithBob(t *testing.T, role models.WorkspaceRole) *models.Workspace {
+	t.Helper()
+	workspace, err := env.workspaces.CreateWorkspace("Team", "", env.alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.workspaces.AddMember(workspace.ID, nil, &env.bob, role, env.alice); err != nil {
+		t.Fatal(err)
+	}
+	return workspace
+}
+
+func TestWorkspaceService_CreateWorkspace_CreatorIsAdmin(t *testing.T) {
+	env := newWorkspaceTestEnv(t)
+
+	workspace, err := env.workspaces.CreateWorkspace("Team", "Our boards", env.alice)
+	assert.NoError(t, err)
+	if assert.Len(t, workspace.Members, 1) {
+		assert.Equal(t, env.alice, workspace.Members[0].UserID)
+		assert.Equal(t, models.WorkspaceRoleAdmin, workspace.Members[0].Role)
+	}
+
+	list, err := env.workspaces.GetWorkspacesForUser(env.alice)
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+	_, err = env.workspaces.GetWorkspace(workspace.ID, env.carol)
+	assert.ErrorIs(t, err, ErrWorkspaceNotFound, "outsiders can't tell the workspace exists")
+}
+
+func TestWorkspaceService_MembersSeeWorkspaceBoards(t *testing.T) {
+	env := newWorkspaceTestEnv(t)
+	workspace := env.createWorkspaceWithBob(t, models.WorkspaceRoleMember)
+
+	shared, err := env.workspaces.CreateBoard(workspace.ID, "Shared", "", "", env.alice)
+	assert.NoError(t, err)
+	assert.Equal(t, models.BoardVisibilityWorkspace, shared.Visibility)
+	private, err := env.workspaces.CreateBoard(workspace.ID, "Private", "", models.BoardVisibilityPrivate, env.alice)
+	assert.NoError(t, err)
+
+	// Bob was never added to either board, but can use the shared one
+	ok, err := env.boards.IsUserMemberOfBoard(env.bob, shared.ID)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	_, err = env.boards.UpdateBoard(shared.ID, strPtr("Renamed"), nil, env.bob)
+	assert.ErrorIs(t, err, ErrForbidden, "workspace members work on boards, they don't administer them")
+	ok, err = env.boards.IsUserMemberOfBoard(env.bob, private.ID)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = env.boards.IsUserMemberOfBoard(env.carol, shared.ID)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	boards, err := env.boards.GetBoardsForUser(env.bob)
+	assert.NoError(t, err)
+	if assert.Len(t, boards, 1) {
+		assert.Equal(t, shared.ID, boards[0].ID)
+	}
+	boards, err = env.workspaces.GetBoards(workspace.ID, env.bob)
+	assert.NoError(t, err)
+	assert.Len(t, boards, 1)
+	boards, err = env.workspaces.GetBoards(workspace.ID, env.alice)
+	assert.NoError(t, err)
+	assert.Len(t, boards, 2, "the owner also sees their private board")
+
+	// Bob keeps following the shared board after leaving it, through the workspace
+	_, err = env.boards.AddMemberToBoard(shared.ID, nil, &env.bob, models.BoardRoleMember, env.alice)
+	assert.NoError(t, err)
+	assert.NoError(t, env.boards.RemoveMemberFromBoard(shared.ID, env.bob, env.alice))
+	assert.Empty(t, env.hub.users)
+
+	// Leaving the workspace takes the access away, and closes Bob's WebSockets on the board
+	assert.NoError(t, env.workspaces.RemoveMember(workspace.ID, env.bob, env.bob))
+	ok, err = env.boards.IsUserMemberOfBoard(env.bob, shared.ID)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, [][2]uint{{env.bob, shared.ID}}, env.hub.users)
+}
+
+func TestWorkspaceService_WorkspaceAdminsAdministerSharedBoards(t *testing.T) {
+	env := newWorkspaceTestEnv(t)
+	workspace := env.createWorkspaceWithBob(t, models.WorkspaceRoleAdmin)
+	board, err := env.workspaces.CreateBoard(workspace.ID, "Shared", "", "", env.alice)
+	assert.NoError(t, err)
+
+	updated, err := env.boards.UpdateBoard(board.ID, strPtr("Renamed"), nil, env.bob)
+	assert.NoError(t, err)
+	assert.Equal(t, "Renamed", updated.Name)
+	assert.ErrorIs(t, env.boards.DeleteBoard(board.ID, env.bob), ErrForbidden, "only the owner deletes a board")
+}
+
+func TestWorkspaceService_MoveBoard(t *testing.T) {
+	env := newWorkspaceTestEnv(t)
+	workspace := env.createWorkspaceWithBob(t, models.WorkspaceRoleMember)
+	board, err := env.boards.CreateBoard("Personal", "", env.alice)
+	assert.NoError(t, err)
+
+	// Carol can't move her board into a workspace she's not in
+	carols, err := env.boards.CreateBoard("Carol's", "", env.carol)
+	assert.NoError(t, err)
+	_, err = env.workspaces.MoveBoard(carols.ID, &workspace.ID, "", env.carol)
+	assert.ErrorIs(t, err, ErrWorkspaceNotFound)
+
+	// Moved boards stay private until shared
+	moved, err := env.workspaces.MoveBoard(board.ID, &workspace.ID, "", env.alice)
+	assert.NoError(t, err)
+	assert.Equal(t, workspace.ID, *moved.WorkspaceID)
+	assert.Equal(t, models.BoardVisibilityPrivate, moved.Visibility)
+	ok, _ := env.boards.IsUserMemberOfBoard(env.bob, board.ID)
+	assert.False(t, ok)
+
+	shared, err := env.boards.UpdateVisibility(board.ID, models.BoardVisibilityWorkspace, env.alice)
+	assert.NoError(t, err)
+	assert.Equal(t, models.BoardVisibilityWorkspace, shared.Visibility)
+	ok, _ = env.boards.IsUserMemberOfBoard(env.bob, board.ID)
+	assert.True(t, ok)
+
+	out, err := env.workspaces.MoveBoard(board.ID, nil, "", env.alice)
+	assert.NoError(t, err)
+	assert.Nil(t, out.WorkspaceID)
+	assert.Equal(t, models.BoardVisibilityPrivate, out.Visibility)
+	_, err = env.boards.UpdateVisibility(board.ID, models.BoardVisibilityWorkspace, env.alice)
+	assert.ErrorIs(t, err, ErrInvalidInput, "a board outside a workspace can't be shared with one")
+
+	// Public boards too, as workspace members would get roles on them
+	_, err = env.boards.UpdateVisibility(board.ID, models.BoardVisibilityPublic, env.alice)
+	assert.NoError(t, err)
+	moved, err = env.workspaces.MoveBoard(board.ID, &workspace.ID, "", env.alice)
+	assert.NoError(t, err)
+	assert.Equal(t, models.BoardVisibilityPrivate, moved.Visibility)
+	ok, _ = env.boards.IsUserMemberOfBoard(env.bob, board.ID)
+	assert.False(t, ok)
+}
+
+func TestWorkspaceService_MemberManagement(t *testing.T) {
+	env := newWorkspaceTestEnv(t)
+	workspace := env.createWorkspaceWithBob(t, models.WorkspaceRoleMember)
+
+	_, err := env.workspaces.AddMember(workspace.ID, nil, &env.bob, "", env.alice)
+	assert.ErrorIs(t, err, ErrUserAlreadyInWorkspace)
+	_, err = env.workspaces.AddMember(workspace.ID, nil, &env.carol, "", env.bob)
+	assert.ErrorIs(t, err, ErrForbidden, "only admins add members")
+	_, err = env.workspaces.AddMember(workspace.ID, nil, &env.carol, models.WorkspaceRole("owner"), env.alice)
+	assert.ErrorIs(t, err, ErrInvalidWorkspaceRole)
+
+	// The last admin can neither leave nor be demoted
+	assert.ErrorIs(t, env.workspaces.RemoveMember(workspace.ID, env.alice, env.alice), ErrLastWorkspaceAdmin)
+	_, err = env.workspaces.UpdateMemberRole(workspace.ID, env.alice, models.WorkspaceRoleMember, env.alice)
+	assert.ErrorIs(t, err, ErrLastWorkspaceAdmin)
+
+	promoted, err := env.workspaces.UpdateMemberRole(workspace.ID, env.bob, models.WorkspaceRoleAdmin, env.alice)
+	assert.NoError(t, err)
+	assert.Equal(t, models.WorkspaceRoleAdmin, promoted.Role)
+	assert.NoError(t, env.workspaces.RemoveMember(workspace.ID, env.alice, env.alice))
+
+	members, err := env.workspaces.GetMembers(workspace.ID, env.bob)
+	assert.NoError(t, err)
+	if assert.Len(t, members, 1) {
+		assert.Equal(t, env.bob, members[0].UserID)
+	}
+	assert.ErrorIs(t, env.workspaces.RemoveMember(workspace.ID, env.carol, env.bob), ErrWorkspaceMemberNotFound)
+}
+
+func TestWorkspaceService_DeleteWorkspaceKeepsBoardsPrivate(t *testing.T) {
+	env := newWorkspaceTestEnv(t)
+	workspace := env.createWorkspaceWithBob(t, models.WorkspaceRoleMember)
+	board, err := env.workspaces.CreateBoard(workspace.ID, "Shared", "", "", env.alice)
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, env.workspaces.DeleteWorkspace(workspace.ID, env.bob), ErrForbidden)
+	assert.NoError(t, env.workspaces.DeleteWorkspace(workspace.ID, env.alice))
+
+	kept, err := env.boards.GetBoardByID(board.ID, env.alice)
+	assert.NoError(t, err)
+	assert.Nil(t, kept.WorkspaceID)
+	assert.Equal(t, models.BoardVisibilityPrivate, kept.Visibility)
+	ok, _ := env.boards.IsUserMemberOfBoard(env.bob, board.ID)
+	assert.False(t, ok)
+	_, err = env.workspaces.GetWorkspace(workspace.ID, env.alice)
+	assert.ErrorIs(t, err, ErrWorkspaceNotFound)
+}
+
+func TestWorkspaceService_PublicBoardsStaySharedAndPublic(t *testing.T) {
+	env := newWorkspaceTestEnv(t)
+	workspace := env.createWorkspaceWithBob(t, models.WorkspaceRoleMember)
+	board, err := env.workspaces.CreateBoard(workspace.ID, "Roadmap", "", models.BoardVisibilityPublic, env.alice)
+	assert.NoError(t, err)
+
+	// Workspace members work on public boards like on shared ones
+	ok, _ := env.boards.IsUserMemberOfBoard(env.bob, board.ID)
+	assert.True(t, ok)
+	boards, err := env.workspaces.GetBoards(workspace.ID, env.bob)
+	assert.NoError(t, err)
+	assert.Len(t, boards, 1)
+	ok, _ = env.boards.IsUserMemberOfBoard(env.carol, board.ID)
+	assert.False(t, ok, "outsiders only get the read-only public view")
+
+	assert.NoError(t, env.workspaces.DeleteWorkspace(workspace.ID, env.alice))
+	kept, err := env.boards.GetBoardByID(board.ID, env.alice)
+	assert.NoError(t, err)
+	assert.Nil(t, kept.WorkspaceID)
+	assert.Equal(t, models.BoardVisibilityPublic, kept.Visibility)
+}
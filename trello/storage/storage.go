@@ -0,0 +1,44 @@
+// Package storage keeps uploaded files such as card attachments.
+//
+// Services depend on the BlobStore interface. LocalStore writes blobs to a
+// directory on disk; S3Store talks to Amazon S3 or any S3-compatible server
+// (MinIO, or the in-process stand-in in s3test).
+package storage
+
+import (
+	"errors"
+	"io"
+	"strings"
+)
+
+// ErrNotFound is returned by Open and Stat for keys with no blob.
+var ErrNotFound = errors.New("blob not found")
+
+// BlobInfo describes a stored blob.
+type BlobInfo struct {
+	Size        int64
+	ContentType string
+}
+
+// BlobStore stores opaque blobs under slash-separated keys.
+type BlobStore interface {
+	// Put stores size bytes read from body under key, replacing any blob already there.
+	Put(key string, body io.Reader, size int64, contentType string) error
+	// Open returns the blob under key. The caller must close it.
+	Open(key string) (io.ReadCloser, BlobInfo, error)
+	// Delete removes the blob under key. Deleting a missing blob is not an error.
+	Delete(key string) error
+}
+
+// validKey rejects keys that could escape the store, such as "../x" or "/etc/x".
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,82 @@
+package storage_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zayyadi/trello/storage"
+	"github.com/zayyadi/trello/storage/s3test"
+)
+
+// testBlobStore runs the behaviour every BlobStore must have against store.
+func testBlobStore(t *testing.T, store storage.BlobStore) {
+	data := []byte("hello, attachments")
+	assert.NoError(t, store.Put("cards/1/abc", bytes.NewReader(data), int64(len(data)), "text/plain"))
+
+	body, info, err := store.Open("cards/1/abc")
+	assert.NoError(t, err)
+	got, _ := io.ReadAll(body)
+	body.Close()
+	assert.Equal(t, data, got)
+	assert.Equal(t, int64(len(data)), info.Size)
+	assert.Equal(t, "text/plain", info.ContentType)
+
+	// Putting again replaces the blob
+	assert.NoError(t, store.Put("cards/1/abc", bytes.NewReader([]byte("v2")), 2, "text/csv"))
+	body, info, err = store.Open("cards/1/abc")
+	assert.NoError(t, err)
+	got, _ = io.ReadAll(body)
+	body.Close()
+	assert.Equal(t, "v2", string(got))
+	assert.Equal(t, "text/csv", info.ContentType)
+
+	assert.NoError(t, store.Delete("cards/1/abc"))
+	_, _, err = store.Open("cards/1/abc")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	assert.NoError(t, store.Delete("cards/1/abc"), "deleting a missing blob is fine")
+
+	for _, key := range []string{"", "../secret", "/etc/passwd", "cards//x", "cards/../../x"} {
+		assert.Error(t, store.Put(key, bytes.NewReader(data), int64(len(data)), "text/plain"), key)
+	}
+}
+
+func TestLocalStore(t *testing.T) {
+	testBlobStore(t, storage.NewLocalStore(t.TempDir()))
+}
+
+func TestS3Store(t *testing.T) {
+	server := s3test.NewServer("attachments")
+	defer server.Close()
+	store := storage.NewS3Store(storage.S3Config{
+		Endpoint:        server.URL(),
+		Region:          server.Region,
+		Bucket:          server.Bucket,
+		AccessKeyID:     server.AccessKeyID,
+		SecretAccessKey: server.SecretAccessKey,
+	}, nil)
+
+	testBlobStore(t, store)
+
+	assert.NoError(t, store.Put("cards/2/def", bytes.NewReader([]byte("x")), 1, "text/plain"))
+	got, ok := server.Object("cards/2/def")
+	assert.True(t, ok, "stored in the bucket under its key")
+	assert.Equal(t, "x", string(got))
+}
+
+func TestS3Store_WrongSecretIsRejected(t *testing.T) {
+	server := s3test.NewServer("attachments")
+	defer server.Close()
+	store := storage.NewS3Store(storage.S3Config{
+		Endpoint:        server.URL(),
+		Region:          server.Region,
+		Bucket:          server.Bucket,
+		AccessKeyID:     server.AccessKeyID,
+		SecretAccessKey: "wrong",
+	}, nil)
+
+	err := store.Put("cards/1/abc", bytes.NewReader([]byte("x")), 1, "text/plain")
+	assert.ErrorContains(t, err, "403")
+	assert.Equal(t, 0, server.Len())
+}
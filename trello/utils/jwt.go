@@ -1,44 +1,194 @@
 package utils
 
 import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
 	"fmt"
+	"math/big"
+	"os"
+	"sort"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/zayyadi/trello/models"
 )
 
-// GenerateJWT creates a new JWT token for a given user ID
-func GenerateJWT(userID uint, secretKey string) (string, error) {
-	if secretKey == "FORCE_JWT_ERROR_FOR_TEST" {
+// Smallest RSA key accepted for signing or verifying tokens
+const minRSAKeyBits = 2048
+
+// JWTKey is one key used to sign or verify access tokens.
+// Keys loaded from a public key can only verify.
+type JWTKey struct {
+	ID        string // "kid" header; the RFC 7638 thumbprint for RSA/Ed25519 keys, empty for HMAC
+	Method    jwt.SigningMethod
+	signKey   interface{} // nil for verification-only keys
+	verifyKey interface{}
+}
+
+// CanSign reports whether the key holds private material.
+func (k *JWTKey) CanSign() bool {
+	return k.signKey != nil
+}
+
+// JWTKeySet holds the key new tokens are signed with and every key tokens
+// are still accepted from. Several verification keys let the signing key be
+// rotated without logging everyone out.
+type JWTKeySet struct {
+	signer *JWTKey
+	keys   map[string]*JWTKey // By ID, including the signer
+}
+
+// NewHMACKeySet signs and verifies with a single HS256 shared secret.
+// The secret never leaves the process, so JWKS() is empty for this key set.
+func NewHMACKeySet(secret string) *JWTKeySet {
+	key := &JWTKey{Method: jwt.SigningMethodHS256, signKey: []byte(secret), verifyKey: []byte(secret)}
+	return &JWTKeySet{signer: key, keys: map[string]*JWTKey{"": key}}
+}
+
+// NewJWTKeySet signs with signer and also accepts tokens signed by any of the verification keys.
+func NewJWTKeySet(signer *JWTKey, verification ...*JWTKey) (*JWTKeySet, error) {
+	if signer == nil || !signer.CanSign() {
+		return nil, fmt.Errorf("JWT signing key must be a private key")
+	}
+	keys := map[string]*JWTKey{signer.ID: signer}
+	for _, key := range verification {
+		if _, exists := keys[key.ID]; exists {
+			continue // Same key listed twice (e.g. the signing key is also in the verification list)
+		}
+		keys[key.ID] = key
+	}
+	return &JWTKeySet{signer: signer, keys: keys}, nil
+}
+
+// SigningKeyID is the "kid" of the key new tokens are signed with (empty for HMAC).
+func (ks *JWTKeySet) SigningKeyID() string {
+	return ks.signer.ID
+}
+
+// LoadJWTKeySet reads a PEM private key to sign with and PEM keys (public or
+// private) that are only used to verify, such as the previous signing key.
+func LoadJWTKeySet(signingKeyFile string, verificationKeyFiles []string) (*JWTKeySet, error) {
+	signer, err := loadJWTKeyFile(signingKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	var verification []*JWTKey
+	for _, file := range verificationKeyFiles {
+		key, err := loadJWTKeyFile(file)
+		if err != nil {
+			return nil, err
+		}
+		key.signKey = nil // Only ever used to verify
+		verification = append(verification, key)
+	}
+	return NewJWTKeySet(signer, verification...)
+}
+
+func loadJWTKeyFile(path string) (*JWTKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading JWT key: %w", err)
+	}
+	key, err := ParseJWTKeyPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("JWT key %s: %w", path, err)
+	}
+	return key, nil
+}
+
+// ParseJWTKeyPEM parses an RSA or Ed25519 key. Private keys ("PRIVATE KEY" or
+// "RSA PRIVATE KEY") can sign; public keys ("PUBLIC KEY") can only verify.
+// RSA keys sign with RS256 and Ed25519 keys with EdDSA.
+func ParseJWTKeyPEM(data []byte) (*JWTKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var key *JWTKey
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key = &JWTKey{Method: jwt.SigningMethodRS256, signKey: k, verifyKey: &k.PublicKey}
+	case *rsa.PublicKey:
+		key = &JWTKey{Method: jwt.SigningMethodRS256, verifyKey: k}
+	case ed25519.PrivateKey:
+		key = &JWTKey{Method: jwt.SigningMethodEdDSA, signKey: k, verifyKey: k.Public()}
+	case ed25519.PublicKey:
+		key = &JWTKey{Method: jwt.SigningMethodEdDSA, verifyKey: k}
+	default:
+		return nil, fmt.Errorf("unsupported key type %T (want RSA or Ed25519)", parsed)
+	}
+	if pub, ok := key.verifyKey.(*rsa.PublicKey); ok && pub.N.BitLen() < minRSAKeyBits {
+		return nil, fmt.Errorf("RSA key is %d bits, need at least %d", pub.N.BitLen(), minRSAKeyBits)
+	}
+	key.ID = jwkThumbprint(key.jwk())
+	return key, nil
+}
+
+// GenerateJWT creates a new JWT token for a given user ID and login session.
+// The token expires after ttl and carries the signing key's ID in its "kid" header.
+func GenerateJWT(userID uint, sessionID string, ttl time.Duration, keys *JWTKeySet) (string, error) {
+	if secret, ok := keys.signer.signKey.([]byte); ok && string(secret) == "FORCE_JWT_ERROR_FOR_TEST" {
 		return "", fmt.Errorf("forced JWT error for testing")
 	}
 	claims := &models.Claims{
-		UserID: userID,
+		UserID:    userID,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 72)), // Token expires in 72 hours
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 			Issuer:    "trello",
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString([]byte(secretKey))
+	token := jwt.NewWithClaims(keys.signer.Method, claims)
+	if keys.signer.ID != "" {
+		token.Header["kid"] = keys.signer.ID
+	}
+	signedToken, err := token.SignedString(keys.signer.signKey)
 	if err != nil {
 		return "", fmt.Errorf("error signing token: %w", err)
 	}
 	return signedToken, nil
 }
 
-// ValidateJWT validates a JWT token string and returns the claims
-func ValidateJWT(tokenString string, secretKey string) (*models.Claims, error) {
+// ValidateJWT validates a JWT token string and returns the claims.
+// The "kid" header picks the verification key; tokens without one are checked
+// against the HMAC secret, if the key set has one.
+func ValidateJWT(tokenString string, keys *JWTKeySet) (*models.Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &models.Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// Check the signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := keys.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		// Check the signing method matches the key, so a public key can't be used as an HMAC secret
+		if token.Method.Alg() != key.Method.Alg() {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte(secretKey), nil
+		return key.verifyKey, nil
 	})
 
 	if err != nil {
@@ -50,3 +200,75 @@ func ValidateJWT(tokenString string, secretKey string) (*models.Claims, error) {
 	}
 	return nil, fmt.Errorf("invalid token or claims type")
 }
+
+// JWK is a public key in JSON Web Key format (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Kid string `json:"kid,omitempty"`
+	Crv string `json:"crv,omitempty"` // OKP keys
+	X   string `json:"x,omitempty"`   // OKP keys
+	N   string `json:"n,omitempty"`   // RSA keys
+	E   string `json:"e,omitempty"`   // RSA keys
+}
+
+// JWKS is the document served at /.well-known/jwks.json.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public half of every asymmetric key in the set, so other
+// services can verify our tokens. HMAC secrets are never included.
+func (ks *JWTKeySet) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	for _, key := range ks.keys {
+		if jwk := key.jwk(); jwk != nil {
+			jwk.Use = "sig"
+			jwk.Alg = key.Method.Alg()
+			jwk.Kid = key.ID
+			set.Keys = append(set.Keys, *jwk)
+		}
+	}
+	// Map order is random; keep the document stable for caches
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
+
+// jwk returns the key's public parameters, or nil for HMAC keys.
+func (k *JWTKey) jwk() *JWK {
+	switch pub := k.verifyKey.(type) {
+	case *rsa.PublicKey:
+		return &JWK{
+			Kty: "RSA",
+			N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}
+	case ed25519.PublicKey:
+		return &JWK{Kty: "OKP", Crv: "Ed25519", X: base64.RawURLEncoding.EncodeToString(pub)}
+	}
+	return nil
+}
+
+// jwkThumbprint computes the RFC 7638 thumbprint used as the key ID.
+// It hashes only the required members, in lexicographic order.
+func jwkThumbprint(jwk *JWK) string {
+	var members interface{}
+	switch jwk.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	case "OKP":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	}
+	data, _ := json.Marshal(members) // Only strings, cannot fail
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,225 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func rsaKeyPEM(t *testing.T) (private, public []byte) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encodeKeyPair(t, key, &key.PublicKey)
+}
+
+func ed25519KeyPEM(t *testing.T) (private, public []byte) {
+	t.Helper()
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encodeKeyPair(t, key, pub)
+}
+
+func encodeKeyPair(t *testing.T, private, public interface{}) ([]byte, []byte) {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})
+}
+
+func mustParseKey(t *testing.T, data []byte) *JWTKey {
+	t.Helper()
+	key, err := ParseJWTKeyPEM(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestJWT_AsymmetricRoundTrip(t *testing.T) {
+	rsaPrivate, _ := rsaKeyPEM(t)
+	edPrivate, _ := ed25519KeyPEM(t)
+	for name, data := range map[string][]byte{"RS256": rsaPrivate, "EdDSA": edPrivate} {
+		t.Run(name, func(t *testing.T) {
+			key := mustParseKey(t, data)
+			if key.Method.Alg() != name {
+				t.Fatalf("alg = %s, want %s", key.Method.Alg(), name)
+			}
+			keys, err := NewJWTKeySet(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			token, err := GenerateJWT(42, "session", time.Minute, keys)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if parsed.Header["kid"] != key.ID || key.ID == "" {
+				t.Errorf("kid header = %v, want %q", parsed.Header["kid"], key.ID)
+			}
+
+			claims, err := ValidateJWT(token, keys)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if claims.UserID != 42 || claims.SessionID != "session" {
+				t.Errorf("claims = %+v", claims)
+			}
+		})
+	}
+}
+
+func TestJWT_RotationKeepsOldTokensValid(t *testing.T) {
+	oldPrivate, oldPublic := rsaKeyPEM(t)
+	newPrivate, _ := ed25519KeyPEM(t)
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	oldPrivateFile := write("old.pem", oldPrivate)
+	oldPublicFile := write("old.pub.pem", oldPublic)
+	newPrivateFile := write("new.pem", newPrivate)
+
+	before, err := LoadJWTKeySet(oldPrivateFile, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldToken, err := GenerateJWT(1, "s", time.Minute, before)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// New signing key, old key kept for verification only
+	after, err := LoadJWTKeySet(newPrivateFile, []string{oldPublicFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ValidateJWT(oldToken, after); err != nil {
+		t.Errorf("token from the previous key rejected: %v", err)
+	}
+	newToken, err := GenerateJWT(1, "s", time.Minute, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ValidateJWT(newToken, after); err != nil {
+		t.Errorf("token from the new key rejected: %v", err)
+	}
+	if _, err := ValidateJWT(newToken, before); err == nil {
+		t.Error("token from an unknown key accepted")
+	}
+	if len(after.JWKS().Keys) != 2 {
+		t.Errorf("JWKS has %d keys, want 2", len(after.JWKS().Keys))
+	}
+
+	// Once the old key is dropped its tokens stop working
+	dropped, err := LoadJWTKeySet(newPrivateFile, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ValidateJWT(oldToken, dropped); err == nil {
+		t.Error("token from a removed key accepted")
+	}
+}
+
+func TestJWT_RejectsAlgorithmConfusion(t *testing.T) {
+	private, public := rsaKeyPEM(t)
+	keys, err := NewJWTKeySet(mustParseKey(t, private))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// HS256 token using the public key as the shared secret, with the right kid
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": 1, "exp": time.Now().Add(time.Minute).Unix()})
+	forged.Header["kid"] = keys.SigningKeyID()
+	token, err := forged.SignedString(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ValidateJWT(token, keys); err == nil {
+		t.Error("HS256 token accepted by an RSA key set")
+	}
+
+	// Token without kid, as signed by the HMAC secret before switching to keys
+	legacy, err := GenerateJWT(1, "s", time.Minute, NewHMACKeySet("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ValidateJWT(legacy, keys); err == nil {
+		t.Error("token without kid accepted by an RSA key set")
+	}
+}
+
+func TestJWT_HMACKeySet(t *testing.T) {
+	keys := NewHMACKeySet("secret")
+	token, err := GenerateJWT(3, "s", time.Minute, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ValidateJWT(token, keys); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ValidateJWT(token, NewHMACKeySet("other")); err == nil {
+		t.Error("token accepted with the wrong secret")
+	}
+	if n := len(keys.JWKS().Keys); n != 0 {
+		t.Errorf("HMAC secret published in JWKS (%d keys)", n)
+	}
+}
+
+func TestJWT_KeyValidation(t *testing.T) {
+	_, public := rsaKeyPEM(t)
+	if _, err := NewJWTKeySet(mustParseKey(t, public)); err == nil {
+		t.Error("public key accepted as the signing key")
+	}
+
+	small, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	smallPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(small)})
+	if _, err := ParseJWTKeyPEM(smallPEM); err == nil {
+		t.Error("1024-bit RSA key accepted")
+	}
+	if _, err := ParseJWTKeyPEM([]byte("not a key")); err == nil {
+		t.Error("garbage accepted as a key")
+	}
+}
+
+// RFC 7638 section 3.1 example key and thumbprint.
+func TestJWKThumbprint_RFC7638(t *testing.T) {
+	jwk := &JWK{
+		Kty: "RSA",
+		E:   "AQAB",
+		N:   "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+	}
+	if got, want := jwkThumbprint(jwk), "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"; got != want {
+		t.Errorf("thumbprint = %s, want %s", got, want)
+	}
+}
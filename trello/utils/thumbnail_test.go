@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestMakeThumbnail_ScalesDownKeepingAspectRatio(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 800, 400))
+	for y := 0; y < 400; y++ {
+		for x := 0; x < 800; x++ {
+			src.SetNRGBA(x, y, color.NRGBA{R: 200, G: 100, B: 50, A: 255})
+		}
+	}
+
+	thumb, contentType, err := MakeThumbnail(encodePNG(t, src), 200)
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", contentType)
+	img, err := png.Decode(bytes.NewReader(thumb))
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 200, 100), img.Bounds())
+	assert.Equal(t, color.NRGBA{R: 200, G: 100, B: 50, A: 255}, color.NRGBAModel.Convert(img.At(50, 50)))
+}
+
+func TestMakeThumbnail_KeepsSmallImagesAndJPEG(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 30, 60)), nil))
+
+	thumb, contentType, err := MakeThumbnail(buf.Bytes(), 200)
+	assert.NoError(t, err)
+	assert.Equal(t, "image/jpeg", contentType)
+	img, err := jpeg.Decode(bytes.NewReader(thumb))
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 30, 60), img.Bounds())
+}
+
+func TestMakeThumbnail_RejectsNonImages(t *testing.T) {
+	_, _, err := MakeThumbnail([]byte("%PDF-1.4 not an image"), 200)
+	assert.Error(t, err)
+}
@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 6238 appendix B test vectors for SHA-1, truncated to 6 digits.
+// The secret is the ASCII string "12345678901234567890".
+func TestTOTPCode_RFC6238Vectors(t *testing.T) {
+	secret := totpEncoding.EncodeToString([]byte("12345678901234567890"))
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+	for unix, want := range vectors {
+		got, err := TOTPCode(secret, TOTPCounter(time.Unix(unix, 0)))
+		if err != nil {
+			t.Fatalf("TOTPCode(%d): %v", unix, err)
+		}
+		if got != want {
+			t.Errorf("TOTPCode at %d = %s, want %s", unix, got, want)
+		}
+	}
+}
+
+func TestValidateTOTP_Skew(t *testing.T) {
+	secret := totpEncoding.EncodeToString([]byte("12345678901234567890"))
+	const code = "287082" // Valid for the time step starting at 30s
+
+	if step, ok := ValidateTOTP(secret, code, time.Unix(59, 0), 1); !ok || step != 1 {
+		t.Errorf("code should be accepted in its own step, got step %d ok %t", step, ok)
+	}
+	if step, ok := ValidateTOTP(secret, code, time.Unix(89, 0), 1); !ok || step != 1 {
+		t.Errorf("code from the previous step should be accepted with skew 1, got step %d ok %t", step, ok)
+	}
+	if _, ok := ValidateTOTP(secret, code, time.Unix(120, 0), 1); ok {
+		t.Errorf("code from two steps ago should be rejected with skew 1")
+	}
+	if _, ok := ValidateTOTP(secret, "28708", time.Unix(59, 0), 1); ok {
+		t.Errorf("short code should be rejected")
+	}
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := TOTPProvisioningURI("Trello Clone", "jane@example.com", "ABCDEF")
+	if !strings.HasPrefix(uri, "otpauth://totp/Trello%20Clone:jane@example.com?") {
+		t.Errorf("unexpected URI label: %s", uri)
+	}
+	for _, part := range []string{"secret=ABCDEF", "issuer=Trello+Clone", "digits=6", "period=30"} {
+		if !strings.Contains(uri, part) {
+			t.Errorf("URI %s is missing %s", uri, part)
+		}
+	}
+}